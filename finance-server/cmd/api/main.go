@@ -2,16 +2,27 @@ package main
 
 import (
 	"context"
+	"expvar"
+	"fmt"
 	"log"
+	"net/http"
+	"time"
 
 	"github.com/nnc/finance-tracker/server/internal/config"
 	"github.com/nnc/finance-tracker/server/internal/db"
 	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
 	"github.com/nnc/finance-tracker/server/internal/handler"
+	"github.com/nnc/finance-tracker/server/internal/mailer"
 	"github.com/nnc/finance-tracker/server/internal/router"
 	"github.com/nnc/finance-tracker/server/internal/service"
+	"github.com/nnc/finance-tracker/server/internal/storage"
+	"github.com/nnc/finance-tracker/server/internal/worker"
 )
 
+// refreshTokenRetention is how long expired and revoked refresh tokens are
+// kept before the maintenance worker deletes them.
+const refreshTokenRetention = 30 * 24 * time.Hour
+
 func main() {
 	cfg := config.Load()
 
@@ -23,15 +34,98 @@ func main() {
 
 	queries := sqlc.New(pool)
 	authDB := handler.NewPgAuthDB(queries)
-	categoryDB := handler.NewPgCategoryDB(queries)
-	expenseDB := handler.NewPgExpenseDB(queries)
+	categoryDB := handler.NewPgCategoryDB(queries, pool)
+	categoryRuleDB := handler.NewPgCategoryRuleDB(queries)
+	expenseDB := handler.NewPgExpenseDB(queries, pool)
+	incomeDB := handler.NewPgIncomeDB(queries)
+	recurringDB := handler.NewPgRecurringDB(queries)
 	summaryDB := handler.NewPgSummaryDB(queries)
-	familyDB := handler.NewPgFamilyDB(queries)
+	budgetDB := handler.NewPgBudgetDB(queries)
+	familyDB := handler.NewPgFamilyDB(queries, pool)
 	familyViewDB := handler.NewPgFamilyViewDB(queries)
+	currencyDB := handler.NewPgCurrencyDB(queries)
+	splitDB := handler.NewPgSplitDB(queries, pool)
+	attachmentDB := handler.NewPgAttachmentDB(queries)
+	activityDB := handler.NewPgActivityDB(queries)
+	familyCategoryDB := handler.NewPgFamilyCategoryDB(queries)
 	authSvc := service.NewAuthService(cfg.JWTSecret)
 
-	r := router.Setup(authDB, categoryDB, expenseDB, summaryDB, familyDB, familyViewDB, authSvc)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := newStorage(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Unable to set up attachment storage: %v", err)
+	}
+
+	mail, err := newMailer(cfg)
+	if err != nil {
+		log.Fatalf("Unable to set up mail delivery: %v", err)
+	}
+
+	worker.NewRecurringWorker(pool, time.Hour).Start(ctx)
+	worker.NewAttachmentWorker(pool, store, time.Minute).Start(ctx)
+	worker.NewMaintenanceWorker(pool, refreshTokenRetention, time.Hour).Start(ctx)
+
+	if cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler())
+		go func() {
+			log.Printf("Metrics listening on %s", cfg.MetricsAddr)
+			log.Printf("Metrics server stopped: %v", http.ListenAndServe(cfg.MetricsAddr, mux))
+		}()
+	}
+
+	if cfg.OperatorAddr != "" {
+		operator := router.SetupOperator(currencyDB)
+		go func() {
+			log.Printf("Operator endpoints listening on %s", cfg.OperatorAddr)
+			log.Printf("Operator server stopped: %v", operator.Run(cfg.OperatorAddr))
+		}()
+	}
+
+	r := router.Setup(authDB, categoryDB, categoryRuleDB, expenseDB, incomeDB, recurringDB, summaryDB, budgetDB, familyDB, familyViewDB, currencyDB, splitDB, attachmentDB, activityDB, familyCategoryDB, store, mail, cfg.AppURL, authSvc)
 
 	log.Printf("Server starting on :%s", cfg.Port)
 	log.Fatal(r.Run(":" + cfg.Port))
 }
+
+// newStorage returns the blob store selected by cfg.StorageDriver.
+func newStorage(ctx context.Context, cfg *config.Config) (storage.Storage, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		return storage.NewLocalStorage(cfg.StorageDir), nil
+	case "s3":
+		s3 := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+		if err := s3.EnsureBucket(ctx); err != nil {
+			return nil, err
+		}
+		return s3, nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", cfg.StorageDriver)
+	}
+}
+
+// newMailer returns the mailer selected by cfg.MailDriver.
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.MailDriver {
+	case "file":
+		return mailer.NewFileMailer(cfg.MailDir, cfg.MailFrom), nil
+	case "smtp":
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q", cfg.MailDriver)
+	}
+}
@@ -12,6 +12,39 @@ type Config struct {
 	Port        string
 	GinMode     string
 	JWTSecret   string
+
+	// StorageDriver selects where receipt attachments are kept: "local"
+	// (files under StorageDir) or "s3" (an S3-compatible bucket such as MinIO).
+	StorageDriver string
+	StorageDir    string
+	S3Endpoint    string
+	S3Region      string
+	S3Bucket      string
+	S3AccessKey   string
+	S3SecretKey   string
+
+	// AppURL is the public address of the web app, used to build links in
+	// outgoing mail.
+	AppURL string
+
+	// MailDriver selects how mail is delivered: "file" (messages written as
+	// .eml files under MailDir, for local development) or "smtp".
+	MailDriver   string
+	MailDir      string
+	MailFrom     string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// MetricsAddr, when set, serves expvar metrics at /debug/vars on a
+	// separate listener, e.g. "localhost:9090".
+	MetricsAddr string
+
+	// OperatorAddr, when set, serves operator-only endpoints such as setting
+	// exchange rates on a separate listener, e.g. "localhost:9091". It has
+	// no authentication and must not be exposed publicly.
+	OperatorAddr string
 }
 
 // Load reads environment variables and returns a Config.
@@ -24,6 +57,27 @@ func Load() *Config {
 		Port:        getEnv("PORT", "8080"),
 		GinMode:     getEnv("GIN_MODE", "debug"),
 		JWTSecret:   getEnv("JWT_SECRET", "dev-jwt-secret-change-in-production"),
+
+		StorageDriver: getEnv("STORAGE_DRIVER", "local"),
+		StorageDir:    getEnv("STORAGE_DIR", "data/attachments"),
+		S3Endpoint:    getEnv("S3_ENDPOINT", "http://localhost:9000"),
+		S3Region:      getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:      getEnv("S3_BUCKET", "finance-attachments"),
+		S3AccessKey:   getEnv("S3_ACCESS_KEY", "minioadmin"),
+		S3SecretKey:   getEnv("S3_SECRET_KEY", "minioadmin"),
+
+		AppURL: getEnv("APP_URL", "http://localhost:3000"),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "file"),
+		MailDir:      getEnv("MAIL_DIR", "data/mail"),
+		MailFrom:     getEnv("MAIL_FROM", "Finance Tracker <noreply@localhost>"),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		MetricsAddr:  getEnv("METRICS_ADDR", ""),
+		OperatorAddr: getEnv("OPERATOR_ADDR", ""),
 	}
 }
 
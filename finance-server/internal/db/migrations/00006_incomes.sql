@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE categories
+    ADD COLUMN kind TEXT NOT NULL DEFAULT 'expense' CHECK (kind IN ('expense', 'income'));
+
+CREATE INDEX idx_categories_user_kind ON categories(user_id, kind);
+
+CREATE TABLE incomes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    note TEXT NOT NULL DEFAULT '',
+    income_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_incomes_user_id ON incomes(user_id);
+CREATE INDEX idx_incomes_user_date ON incomes(user_id, income_date);
+
+-- +goose Down
+DROP TABLE IF EXISTS incomes;
+DROP INDEX IF EXISTS idx_categories_user_kind;
+ALTER TABLE categories DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE recurring_expenses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    note TEXT NOT NULL DEFAULT '',
+    frequency TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    start_date DATE NOT NULL,
+    end_date DATE CHECK (end_date IS NULL OR end_date >= start_date),
+    next_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recurring_expenses_user_id ON recurring_expenses(user_id);
+CREATE INDEX idx_recurring_expenses_next_date ON recurring_expenses(next_date);
+
+-- Materialized occurrences point back at their template. The unique
+-- constraint makes materialization idempotent across worker restarts.
+ALTER TABLE expenses ADD COLUMN recurring_id UUID REFERENCES recurring_expenses(id) ON DELETE SET NULL;
+ALTER TABLE expenses ADD CONSTRAINT expenses_recurring_id_expense_date_key UNIQUE (recurring_id, expense_date);
+
+-- +goose Down
+ALTER TABLE expenses DROP CONSTRAINT IF EXISTS expenses_recurring_id_expense_date_key;
+ALTER TABLE expenses DROP COLUMN IF EXISTS recurring_id;
+DROP TABLE IF EXISTS recurring_expenses;
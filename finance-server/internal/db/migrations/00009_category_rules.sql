@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE category_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    pattern TEXT NOT NULL CHECK (pattern <> ''),
+    priority INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_category_rules_user_id ON category_rules(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS category_rules;
@@ -0,0 +1,98 @@
+-- +goose Up
+CREATE TABLE currencies (
+    code TEXT PRIMARY KEY CHECK (code ~ '^[A-Z]{3}$'),
+    name TEXT NOT NULL
+);
+
+INSERT INTO currencies (code, name) VALUES
+    ('UAH', 'Ukrainian Hryvnia'),
+    ('EUR', 'Euro'),
+    ('USD', 'US Dollar');
+
+-- usd_rate is the value of one unit of the currency in USD, the same
+-- convention as finance-legacy's currencies.exchange_rate. Converting
+-- between two currencies divides their rates for the same date.
+CREATE TABLE exchange_rates (
+    currency TEXT NOT NULL REFERENCES currencies(code) ON DELETE CASCADE,
+    rate_date DATE NOT NULL,
+    usd_rate NUMERIC(18, 8) NOT NULL CHECK (usd_rate > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (currency, rate_date)
+);
+
+-- Starting rates are approximate; newer rates are added via the API.
+INSERT INTO exchange_rates (currency, rate_date, usd_rate) VALUES
+    ('USD', '2000-01-01', 1),
+    ('UAH', '2026-01-01', 0.024),
+    ('EUR', '2026-01-01', 1.08);
+
+-- +goose StatementBegin
+-- exchange_rate_date returns the date of the rate used for a currency on a
+-- given day: the latest rate on or before it, or the earliest rate when the
+-- day predates all stored rates.
+CREATE FUNCTION exchange_rate_date(code TEXT, on_date DATE) RETURNS DATE AS $$
+    SELECT COALESCE(
+        (SELECT MAX(rate_date) FROM exchange_rates WHERE currency = code AND rate_date <= on_date),
+        (SELECT MIN(rate_date) FROM exchange_rates WHERE currency = code)
+    )
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- convert_cents converts an amount between currencies using the rates
+-- in effect on on_date.
+CREATE FUNCTION convert_cents(amount BIGINT, from_code TEXT, to_code TEXT, on_date DATE) RETURNS BIGINT AS $$
+    SELECT CASE
+        WHEN from_code = to_code THEN amount
+        ELSE ROUND(amount
+            * (SELECT usd_rate FROM exchange_rates WHERE currency = from_code AND rate_date = exchange_rate_date(from_code, on_date))
+            / (SELECT usd_rate FROM exchange_rates WHERE currency = to_code AND rate_date = exchange_rate_date(to_code, on_date)))::BIGINT
+    END
+$$ LANGUAGE sql STABLE;
+-- +goose StatementEnd
+
+ALTER TABLE users ADD COLUMN base_currency TEXT NOT NULL DEFAULT 'UAH' REFERENCES currencies(code);
+ALTER TABLE families ADD COLUMN base_currency TEXT NOT NULL DEFAULT 'UAH' REFERENCES currencies(code);
+
+-- rate_date records which exchange rate an expense is converted with, so
+-- later rate updates do not change historical totals.
+ALTER TABLE expenses ADD COLUMN currency TEXT NOT NULL DEFAULT 'UAH' REFERENCES currencies(code);
+ALTER TABLE expenses ADD COLUMN rate_date DATE;
+UPDATE expenses SET rate_date = exchange_rate_date(currency, expense_date);
+ALTER TABLE expenses ALTER COLUMN rate_date SET NOT NULL;
+ALTER TABLE expenses ALTER COLUMN currency DROP DEFAULT;
+
+-- +goose StatementBegin
+-- Expenses inserted without a currency (imports, recurring occurrences)
+-- take the owner's base currency, and rate_date follows the currency and
+-- expense date so every write path records it the same way.
+CREATE FUNCTION expenses_set_rate_date() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.currency IS NULL THEN
+        SELECT base_currency INTO NEW.currency FROM users WHERE id = NEW.user_id;
+    END IF;
+    IF TG_OP = 'INSERT' THEN
+        NEW.rate_date := exchange_rate_date(NEW.currency, NEW.expense_date);
+    ELSIF NEW.currency IS DISTINCT FROM OLD.currency OR NEW.expense_date IS DISTINCT FROM OLD.expense_date THEN
+        NEW.rate_date := exchange_rate_date(NEW.currency, NEW.expense_date);
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER expenses_set_rate_date
+    BEFORE INSERT OR UPDATE ON expenses
+    FOR EACH ROW EXECUTE FUNCTION expenses_set_rate_date();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS expenses_set_rate_date ON expenses;
+DROP FUNCTION IF EXISTS expenses_set_rate_date();
+ALTER TABLE expenses DROP COLUMN IF EXISTS rate_date;
+ALTER TABLE expenses DROP COLUMN IF EXISTS currency;
+ALTER TABLE families DROP COLUMN IF EXISTS base_currency;
+ALTER TABLE users DROP COLUMN IF EXISTS base_currency;
+DROP FUNCTION IF EXISTS convert_cents(BIGINT, TEXT, TEXT, DATE);
+DROP FUNCTION IF EXISTS exchange_rate_date(TEXT, DATE);
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS currencies;
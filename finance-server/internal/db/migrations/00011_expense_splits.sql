@@ -0,0 +1,56 @@
+-- +goose Up
+-- An expense split assigns each participating family member a share of an
+-- expense. Shares are in the expense's currency and always sum to its
+-- amount; the payer is the expense owner and may be a participant too.
+CREATE TABLE expense_splits (
+    expense_id UUID NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method TEXT NOT NULL CHECK (method IN ('equal', 'shares', 'exact')),
+    shares INT NOT NULL DEFAULT 1 CHECK (shares > 0),
+    amount_cents BIGINT NOT NULL CHECK (amount_cents >= 0),
+    PRIMARY KEY (expense_id, user_id)
+);
+
+CREATE INDEX idx_expense_splits_family_id ON expense_splits(family_id);
+CREATE INDEX idx_expense_splits_user_id ON expense_splits(user_id);
+
+-- A settlement records money paid from one member to another outside the
+-- app to pay off split expenses.
+CREATE TABLE settlements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    from_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    currency TEXT NOT NULL REFERENCES currencies(code),
+    note TEXT NOT NULL DEFAULT '',
+    settled_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (from_user_id <> to_user_id)
+);
+
+CREATE INDEX idx_settlements_family_id ON settlements(family_id, settled_on DESC);
+
+-- +goose StatementBegin
+-- Shares stop adding up once the amount or currency of a split expense
+-- changes, so the split is dropped and has to be set again.
+CREATE FUNCTION expenses_clear_split() RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM expense_splits WHERE expense_id = NEW.id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER expenses_clear_split
+    AFTER UPDATE OF amount_cents, currency ON expenses
+    FOR EACH ROW
+    WHEN (NEW.amount_cents IS DISTINCT FROM OLD.amount_cents OR NEW.currency IS DISTINCT FROM OLD.currency)
+    EXECUTE FUNCTION expenses_clear_split();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS expenses_clear_split ON expenses;
+DROP FUNCTION IF EXISTS expenses_clear_split();
+DROP TABLE IF EXISTS settlements;
+DROP TABLE IF EXISTS expense_splits;
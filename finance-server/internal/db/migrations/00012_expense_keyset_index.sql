@@ -0,0 +1,9 @@
+-- +goose Up
+-- Covers keyset pagination on (expense_date, created_at, id) in both
+-- directions: newest first for listings, oldest first for exports.
+CREATE INDEX idx_expenses_user_keyset ON expenses(user_id, expense_date, created_at, id);
+DROP INDEX IF EXISTS idx_expenses_user_date;
+
+-- +goose Down
+CREATE INDEX idx_expenses_user_date ON expenses(user_id, expense_date);
+DROP INDEX IF EXISTS idx_expenses_user_keyset;
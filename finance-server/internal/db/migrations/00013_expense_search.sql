@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS unaccent SCHEMA public;
+
+-- +goose StatementBegin
+-- unaccent() is only STABLE because its dictionary can be swapped; pinning
+-- the dictionary makes it safe to use in index expressions.
+CREATE FUNCTION f_unaccent(input TEXT) RETURNS TEXT AS $$
+    SELECT public.unaccent('public.unaccent'::regdictionary, input)
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- search_document indexes text with English stemming plus the 'simple'
+-- configuration, which keeps Ukrainian words (there is no built-in
+-- Ukrainian configuration) as lowercased, unaccented tokens.
+CREATE FUNCTION search_document(input TEXT) RETURNS tsvector AS $$
+    SELECT to_tsvector('english'::regconfig, f_unaccent(input))
+        || to_tsvector('simple'::regconfig, f_unaccent(input))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- search_query parses user input with web search syntax ("quoted phrases",
+-- -exclusions, OR) against both configurations used by search_document.
+CREATE FUNCTION search_query(input TEXT) RETURNS tsquery AS $$
+    SELECT websearch_to_tsquery('english'::regconfig, f_unaccent(input))
+        || websearch_to_tsquery('simple'::regconfig, f_unaccent(input))
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+-- +goose StatementEnd
+
+CREATE INDEX idx_expenses_note_search ON expenses USING GIN (search_document(note));
+CREATE INDEX idx_categories_name_search ON categories USING GIN (search_document(name));
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_categories_name_search;
+DROP INDEX IF EXISTS idx_expenses_note_search;
+DROP FUNCTION IF EXISTS search_query(TEXT);
+DROP FUNCTION IF EXISTS search_document(TEXT);
+DROP FUNCTION IF EXISTS f_unaccent(TEXT);
+DROP EXTENSION IF EXISTS unaccent;
@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE TABLE expense_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    expense_id UUID NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL UNIQUE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_expense_attachments_expense_id ON expense_attachments(expense_id);
+
+-- Blobs live outside the database, so deleting an attachment row (directly
+-- or through the cascade from expenses and users) queues its key here and
+-- the attachment worker removes the blob afterwards.
+CREATE TABLE attachment_deletions (
+    storage_key TEXT PRIMARY KEY,
+    queued_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementBegin
+CREATE FUNCTION expense_attachments_queue_deletion() RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO attachment_deletions (storage_key) VALUES (OLD.storage_key)
+    ON CONFLICT DO NOTHING;
+    RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER expense_attachments_queue_deletion
+    AFTER DELETE ON expense_attachments
+    FOR EACH ROW
+    EXECUTE FUNCTION expense_attachments_queue_deletion();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS expense_attachments_queue_deletion ON expense_attachments;
+DROP FUNCTION IF EXISTS expense_attachments_queue_deletion();
+DROP TABLE IF EXISTS attachment_deletions;
+DROP TABLE IF EXISTS expense_attachments;
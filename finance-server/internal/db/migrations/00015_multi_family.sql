@@ -0,0 +1,24 @@
+-- +goose Up
+-- Users can belong to several families; membership is unique per family.
+ALTER TABLE family_members DROP CONSTRAINT family_members_user_id_key;
+ALTER TABLE family_members ADD CONSTRAINT family_members_family_id_user_id_key UNIQUE (family_id, user_id);
+
+-- active_family_id is a preference for which family /families/me resolves
+-- to. It is not kept in sync with membership: lookups ignore it when the
+-- user is no longer a member and fall back to the earliest joined family.
+ALTER TABLE users ADD COLUMN active_family_id UUID REFERENCES families(id) ON DELETE SET NULL;
+
+UPDATE users u SET active_family_id = fm.family_id
+FROM family_members fm
+WHERE fm.user_id = u.id;
+
+-- +goose Down
+-- Keep only each user's earliest membership so the old constraint holds.
+DELETE FROM family_members fm
+USING family_members earlier
+WHERE earlier.user_id = fm.user_id
+  AND (earlier.joined_at, earlier.id) < (fm.joined_at, fm.id);
+
+ALTER TABLE users DROP COLUMN IF EXISTS active_family_id;
+ALTER TABLE family_members DROP CONSTRAINT IF EXISTS family_members_family_id_user_id_key;
+ALTER TABLE family_members ADD CONSTRAINT family_members_user_id_key UNIQUE (user_id);
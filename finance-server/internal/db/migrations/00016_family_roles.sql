@@ -0,0 +1,19 @@
+-- +goose Up
+-- Roles from least to most privileged: viewers see the family's expenses,
+-- members also contribute theirs, admins manage invitations and members, and
+-- the owner (families.admin_user_id) can delete the family or hand it over.
+ALTER TABLE family_members DROP CONSTRAINT family_members_role_check;
+ALTER TABLE family_members ADD CONSTRAINT family_members_role_check
+    CHECK (role IN ('viewer', 'member', 'admin', 'owner'));
+
+UPDATE family_members fm SET role = 'owner'
+FROM families f
+WHERE f.id = fm.family_id AND f.admin_user_id = fm.user_id;
+
+-- +goose Down
+UPDATE family_members SET role = 'admin' WHERE role = 'owner';
+UPDATE family_members SET role = 'member' WHERE role = 'viewer';
+
+ALTER TABLE family_members DROP CONSTRAINT family_members_role_check;
+ALTER TABLE family_members ADD CONSTRAINT family_members_role_check
+    CHECK (role IN ('admin', 'member'));
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Invitations are addressed to an email and only that account can accept
+-- them. Invitations created before this have no email and stay bearer tokens
+-- until they expire.
+ALTER TABLE family_invitations ADD COLUMN email TEXT;
+
+CREATE INDEX idx_family_invitations_pending_email ON family_invitations(lower(email))
+    WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_family_invitations_pending_email;
+ALTER TABLE family_invitations DROP COLUMN IF EXISTS email;
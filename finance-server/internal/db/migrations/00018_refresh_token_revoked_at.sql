@@ -0,0 +1,17 @@
+-- +goose Up
+-- revoked_at lets the maintenance worker keep revoked refresh tokens for a
+-- retention window before deleting them. Tokens revoked before this
+-- migration start their window now.
+ALTER TABLE refresh_tokens ADD COLUMN revoked_at TIMESTAMPTZ;
+UPDATE refresh_tokens SET revoked_at = NOW() WHERE revoked;
+
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+CREATE INDEX idx_refresh_tokens_revoked_at ON refresh_tokens(revoked_at) WHERE revoked_at IS NOT NULL;
+CREATE INDEX idx_family_invitations_pending_expires_at ON family_invitations(expires_at)
+    WHERE status = 'pending';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_family_invitations_pending_expires_at;
+DROP INDEX IF EXISTS idx_refresh_tokens_revoked_at;
+DROP INDEX IF EXISTS idx_refresh_tokens_expires_at;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS revoked_at;
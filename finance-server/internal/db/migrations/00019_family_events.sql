@@ -0,0 +1,36 @@
+-- +goose Up
+-- An append-only activity log per family. actor_user_id deliberately has no
+-- foreign key so entries outlive the accounts that made them; before and
+-- after are JSON snapshots of the subject, NULL when it did not exist.
+CREATE TABLE family_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    actor_user_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    subject_id UUID,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_family_events_family_created ON family_events(family_id, created_at DESC, id DESC);
+
+-- +goose StatementBegin
+CREATE FUNCTION family_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    -- Rows only go away with their family (ON DELETE CASCADE).
+    IF TG_OP = 'DELETE' AND NOT EXISTS (SELECT 1 FROM families WHERE id = OLD.family_id) THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'family_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER family_events_append_only
+    BEFORE UPDATE OR DELETE ON family_events
+    FOR EACH ROW EXECUTE FUNCTION family_events_append_only();
+
+-- +goose Down
+DROP TABLE IF EXISTS family_events;
+DROP FUNCTION IF EXISTS family_events_append_only();
@@ -0,0 +1,51 @@
+-- +goose Up
+-- A category belongs either to a user or, shared by all its members, to a
+-- family.
+ALTER TABLE categories ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE categories ADD COLUMN family_id UUID REFERENCES families(id) ON DELETE CASCADE;
+ALTER TABLE categories ADD CONSTRAINT categories_owner_check CHECK ((user_id IS NULL) <> (family_id IS NULL));
+
+CREATE INDEX idx_categories_family_id ON categories(family_id) WHERE family_id IS NOT NULL;
+
+-- A member's personal category mapped onto a family category is counted as
+-- that family category in the family's summaries.
+CREATE TABLE category_mappings (
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    family_category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (family_id, category_id)
+);
+
+CREATE INDEX idx_category_mappings_family_category_id ON category_mappings(family_category_id);
+
+-- Expenses keep their category when a family is deleted: its categories
+-- pass to the owner instead of cascading into the expenses that use them.
+-- +goose StatementBegin
+CREATE FUNCTION families_release_categories() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE categories SET user_id = OLD.admin_user_id, family_id = NULL, updated_at = NOW()
+    WHERE family_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER families_release_categories
+    BEFORE DELETE ON families
+    FOR EACH ROW EXECUTE FUNCTION families_release_categories();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS families_release_categories ON families;
+DROP FUNCTION IF EXISTS families_release_categories();
+DROP TABLE IF EXISTS category_mappings;
+
+-- Family categories go back to the family owner, as on family deletion.
+UPDATE categories c SET user_id = f.admin_user_id, family_id = NULL
+FROM families f
+WHERE f.id = c.family_id;
+
+DROP INDEX IF EXISTS idx_categories_family_id;
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_owner_check;
+ALTER TABLE categories DROP COLUMN IF EXISTS family_id;
+ALTER TABLE categories ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1,38 @@
+-- +goose Up
+-- A private expense is kept out of its owner's families. The family's
+-- private_expenses setting decides how: 'exclude' leaves private expenses
+-- out of every family view, 'aggregate' counts their amounts in family
+-- totals without their note or category.
+ALTER TABLE categories ADD COLUMN default_visibility TEXT NOT NULL DEFAULT 'family'
+    CHECK (default_visibility IN ('family', 'private'));
+
+ALTER TABLE expenses ADD COLUMN visibility TEXT NOT NULL DEFAULT 'family'
+    CHECK (visibility IN ('family', 'private'));
+ALTER TABLE expenses ALTER COLUMN visibility DROP DEFAULT;
+
+ALTER TABLE families ADD COLUMN private_expenses TEXT NOT NULL DEFAULT 'exclude'
+    CHECK (private_expenses IN ('exclude', 'aggregate'));
+
+-- +goose StatementBegin
+-- Expenses inserted without a visibility (imports, recurring occurrences)
+-- take their category's default.
+CREATE FUNCTION expenses_set_visibility() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.visibility IS NULL THEN
+        SELECT default_visibility INTO NEW.visibility FROM categories WHERE id = NEW.category_id;
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER expenses_set_visibility
+    BEFORE INSERT ON expenses
+    FOR EACH ROW EXECUTE FUNCTION expenses_set_visibility();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS expenses_set_visibility ON expenses;
+DROP FUNCTION IF EXISTS expenses_set_visibility();
+ALTER TABLE families DROP COLUMN IF EXISTS private_expenses;
+ALTER TABLE expenses DROP COLUMN IF EXISTS visibility;
+ALTER TABLE categories DROP COLUMN IF EXISTS default_visibility;
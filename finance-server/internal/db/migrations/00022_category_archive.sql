@@ -0,0 +1,7 @@
+-- +goose Up
+-- An archived category is hidden from category pickers but keeps its
+-- expenses, so it still shows up in history and summaries.
+ALTER TABLE categories ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE categories DROP COLUMN IF EXISTS archived_at;
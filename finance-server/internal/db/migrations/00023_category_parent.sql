@@ -0,0 +1,10 @@
+-- +goose Up
+-- Subcategories point at their top-level category. Deleting a top-level
+-- category promotes its subcategories to the top level.
+ALTER TABLE categories ADD COLUMN parent_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_categories_parent_id;
+ALTER TABLE categories DROP COLUMN IF EXISTS parent_id;
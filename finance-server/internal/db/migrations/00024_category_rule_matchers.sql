@@ -0,0 +1,25 @@
+-- +goose Up
+-- Rules match the note either as a substring or as a regular expression,
+-- and may also require the amount to fall within a range. A rule may match
+-- on the amount alone, in which case its pattern is empty. The tag is
+-- attached to expenses the rule categorizes.
+ALTER TABLE category_rules
+    ADD COLUMN match_type TEXT NOT NULL DEFAULT 'contains' CHECK (match_type IN ('contains', 'regex')),
+    ADD COLUMN min_amount_cents BIGINT,
+    ADD COLUMN max_amount_cents BIGINT,
+    ADD COLUMN tag TEXT;
+
+ALTER TABLE category_rules DROP CONSTRAINT category_rules_pattern_check;
+ALTER TABLE category_rules ADD CONSTRAINT category_rules_matcher_check
+    CHECK (pattern <> '' OR min_amount_cents IS NOT NULL OR max_amount_cents IS NOT NULL);
+
+-- +goose Down
+DELETE FROM category_rules WHERE pattern = '';
+ALTER TABLE category_rules DROP CONSTRAINT IF EXISTS category_rules_matcher_check;
+ALTER TABLE category_rules ADD CONSTRAINT category_rules_pattern_check CHECK (pattern <> '');
+
+ALTER TABLE category_rules
+    DROP COLUMN IF EXISTS tag,
+    DROP COLUMN IF EXISTS max_amount_cents,
+    DROP COLUMN IF EXISTS min_amount_cents,
+    DROP COLUMN IF EXISTS match_type;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- Tags are free-form labels owned by a user. Unlike categories an expense
+-- can carry any number of them. Names are stored lowercased.
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL CHECK (name <> ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE expense_tags (
+    expense_id UUID NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (expense_id, tag_id)
+);
+
+CREATE INDEX idx_expense_tags_tag_id ON expense_tags(tag_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS expense_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- A refresh token family is the chain of tokens issued from one login, each
+-- refresh replacing the previous token with a new one in the same family.
+-- Presenting a token that was already replaced revokes the whole family.
+-- Existing tokens each start their own family.
+ALTER TABLE refresh_tokens ADD COLUMN family_id UUID;
+UPDATE refresh_tokens SET family_id = id;
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_refresh_tokens_family_id;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS family_id;
@@ -0,0 +1,38 @@
+-- +goose Up
+-- Incomes carry a currency and are converted to the base currency in
+-- summaries like expenses are, so net totals subtract like units. Existing
+-- incomes were entered in their owner's base currency.
+ALTER TABLE incomes ADD COLUMN currency TEXT REFERENCES currencies(code);
+ALTER TABLE incomes ADD COLUMN rate_date DATE;
+UPDATE incomes i SET currency = u.base_currency FROM users u WHERE u.id = i.user_id;
+UPDATE incomes SET rate_date = exchange_rate_date(currency, income_date);
+ALTER TABLE incomes ALTER COLUMN currency SET NOT NULL;
+ALTER TABLE incomes ALTER COLUMN rate_date SET NOT NULL;
+
+-- +goose StatementBegin
+-- Incomes inserted without a currency take the owner's base currency, and
+-- rate_date follows the currency and income date, as for expenses.
+CREATE FUNCTION incomes_set_rate_date() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.currency IS NULL THEN
+        SELECT base_currency INTO NEW.currency FROM users WHERE id = NEW.user_id;
+    END IF;
+    IF TG_OP = 'INSERT' THEN
+        NEW.rate_date := exchange_rate_date(NEW.currency, NEW.income_date);
+    ELSIF NEW.currency IS DISTINCT FROM OLD.currency OR NEW.income_date IS DISTINCT FROM OLD.income_date THEN
+        NEW.rate_date := exchange_rate_date(NEW.currency, NEW.income_date);
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER incomes_set_rate_date
+    BEFORE INSERT OR UPDATE ON incomes
+    FOR EACH ROW EXECUTE FUNCTION incomes_set_rate_date();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS incomes_set_rate_date ON incomes;
+DROP FUNCTION IF EXISTS incomes_set_rate_date();
+ALTER TABLE incomes DROP COLUMN IF EXISTS rate_date;
+ALTER TABLE incomes DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,58 @@
+-- +goose Up
+-- When a family is deleted, its categories still pass to the owner, but
+-- other members' records move off them first: onto the personal category
+-- the member mapped to the family category, or else onto a personal copy
+-- of it. Nobody's records end up in another user's category.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION families_release_categories() RETURNS TRIGGER AS $$
+DECLARE
+    fc categories%ROWTYPE;
+    member_id UUID;
+    personal_id UUID;
+BEGIN
+    FOR fc IN SELECT * FROM categories WHERE family_id = OLD.id LOOP
+        FOR member_id IN
+            SELECT user_id FROM expenses WHERE category_id = fc.id
+            UNION SELECT user_id FROM incomes WHERE category_id = fc.id
+            UNION SELECT user_id FROM recurring_expenses WHERE category_id = fc.id
+        LOOP
+            CONTINUE WHEN member_id = OLD.admin_user_id;
+
+            SELECT cm.category_id INTO personal_id
+            FROM category_mappings cm
+            JOIN categories c ON c.id = cm.category_id
+            WHERE cm.family_id = OLD.id AND cm.family_category_id = fc.id
+              AND c.user_id = member_id AND c.kind = fc.kind
+            LIMIT 1;
+
+            IF personal_id IS NULL THEN
+                INSERT INTO categories (user_id, name, icon, color, kind, default_visibility, archived_at, sort_order)
+                VALUES (member_id, fc.name, fc.icon, fc.color, fc.kind, fc.default_visibility, fc.archived_at,
+                    (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM categories
+                     WHERE user_id = member_id AND parent_id IS NULL))
+                RETURNING id INTO personal_id;
+            END IF;
+
+            UPDATE expenses SET category_id = personal_id WHERE category_id = fc.id AND user_id = member_id;
+            UPDATE incomes SET category_id = personal_id WHERE category_id = fc.id AND user_id = member_id;
+            UPDATE recurring_expenses SET category_id = personal_id WHERE category_id = fc.id AND user_id = member_id;
+        END LOOP;
+    END LOOP;
+
+    UPDATE categories SET user_id = OLD.admin_user_id, family_id = NULL, updated_at = NOW()
+    WHERE family_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION families_release_categories() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE categories SET user_id = OLD.admin_user_id, family_id = NULL, updated_at = NOW()
+    WHERE family_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
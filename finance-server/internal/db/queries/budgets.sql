@@ -0,0 +1,54 @@
+-- name: UpsertUserBudget :one
+INSERT INTO budgets (user_id, category_id, amount_cents)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, family_id, category_id)
+DO UPDATE SET amount_cents = EXCLUDED.amount_cents, updated_at = NOW()
+RETURNING *;
+
+-- name: GetUserBudgets :many
+SELECT b.id, b.category_id, c.name AS category_name, b.amount_cents, b.updated_at
+FROM budgets b
+LEFT JOIN categories c ON c.id = b.category_id
+WHERE b.user_id = $1
+ORDER BY b.category_id NULLS FIRST, c.name;
+
+-- name: ReassignBudgetCategory :execrows
+-- Copies the user's budgets onto the target category, adding them to any
+-- budget the target already has.
+INSERT INTO budgets (user_id, category_id, amount_cents)
+SELECT user_id, sqlc.arg('target_id'), amount_cents
+FROM budgets
+WHERE category_id = sqlc.arg('category_id') AND user_id = sqlc.arg('user_id')
+ON CONFLICT (user_id, family_id, category_id)
+DO UPDATE SET amount_cents = budgets.amount_cents + EXCLUDED.amount_cents, updated_at = NOW();
+
+-- name: DeleteUserBudget :execrows
+DELETE FROM budgets
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetFamilyBudgetCategoryKind :one
+-- The kind of a category the family may budget against: one of its shared
+-- categories or a category of one of its non-viewer members.
+SELECT c.kind
+FROM categories c
+WHERE c.id = $1
+  AND (c.family_id = $2 OR c.user_id IN (
+      SELECT fm.user_id FROM family_members fm WHERE fm.family_id = $2 AND fm.role <> 'viewer'));
+
+-- name: UpsertFamilyBudget :one
+INSERT INTO budgets (family_id, category_id, amount_cents)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, family_id, category_id)
+DO UPDATE SET amount_cents = EXCLUDED.amount_cents, updated_at = NOW()
+RETURNING *;
+
+-- name: GetFamilyBudgets :many
+SELECT b.id, b.category_id, c.name AS category_name, b.amount_cents, b.updated_at
+FROM budgets b
+LEFT JOIN categories c ON c.id = b.category_id
+WHERE b.family_id = $1
+ORDER BY b.category_id NULLS FIRST, c.name;
+
+-- name: DeleteFamilyBudget :execrows
+DELETE FROM budgets
+WHERE id = $1 AND family_id = $2;
@@ -1,28 +1,82 @@
 -- name: CreateCategory :one
-INSERT INTO categories (user_id, name, icon, color, sort_order)
-VALUES ($1, $2, $3, $4, (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM categories WHERE user_id = $1))
-RETURNING id, user_id, name, icon, color, sort_order, created_at, updated_at;
+-- Categories are ordered among their siblings, so a new category goes last
+-- under its parent.
+INSERT INTO categories (user_id, name, icon, color, kind, default_visibility, parent_id, sort_order)
+VALUES ($1, $2, $3, $4, $5, $6, sqlc.narg('parent_id'),
+    (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM categories
+     WHERE user_id = $1 AND parent_id IS NOT DISTINCT FROM sqlc.narg('parent_id')))
+RETURNING id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id;
 
 -- name: GetCategoriesByUser :many
-SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at
+SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id
 FROM categories
 WHERE user_id = $1
+  AND (kind = sqlc.narg('kind') OR sqlc.narg('kind') IS NULL)
+  AND (archived_at IS NULL OR sqlc.arg('include_archived')::BOOLEAN)
 ORDER BY sort_order ASC;
 
 -- name: GetCategoryByID :one
-SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at
+SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id
 FROM categories
 WHERE id = $1 AND user_id = $2;
 
+-- name: GetExpenseCategoryKind :one
+-- The kind of a category the user may file expenses under: one of their own
+-- or a shared category of a family they belong to. An archived category only
+-- qualifies for the expense_id already filed under it.
+SELECT c.kind
+FROM categories c
+WHERE c.id = $1
+  AND (c.user_id = $2 OR c.family_id IN (
+      SELECT fm.family_id FROM family_members fm WHERE fm.user_id = $2))
+  AND (c.archived_at IS NULL OR EXISTS (
+      SELECT 1 FROM expenses e WHERE e.id = sqlc.narg('expense_id') AND e.category_id = c.id));
+
+-- name: GetIncomeCategoryKind :one
+-- The kind of a category the user may file incomes under: one of their own
+-- or a shared category of a family they belong to. An archived category only
+-- qualifies for the income_id already filed under it.
+SELECT c.kind
+FROM categories c
+WHERE c.id = $1
+  AND (c.user_id = $2 OR c.family_id IN (
+      SELECT fm.family_id FROM family_members fm WHERE fm.user_id = $2))
+  AND (c.archived_at IS NULL OR EXISTS (
+      SELECT 1 FROM incomes i WHERE i.id = sqlc.narg('income_id') AND i.category_id = c.id));
+
 -- name: UpdateCategory :execrows
+-- The parent is only changed when set_parent is true; a NULL parent_id then
+-- moves the category to the top level.
 UPDATE categories
-SET name = $3, icon = $4, color = $5, updated_at = NOW()
+SET name = $3, icon = $4, color = $5,
+    default_visibility = COALESCE(sqlc.narg('default_visibility'), default_visibility),
+    parent_id = CASE WHEN sqlc.arg('set_parent')::BOOLEAN THEN sqlc.narg('parent_id') ELSE parent_id END,
+    updated_at = NOW()
 WHERE id = $1 AND user_id = $2;
 
 -- name: DeleteCategory :execrows
 DELETE FROM categories
 WHERE id = $1 AND user_id = $2;
 
+-- name: GetCategoryUsage :one
+-- Counts the records referencing one of the user's categories. The category
+-- stays locked until the transaction ends, so no new references appear.
+SELECT
+    (SELECT COUNT(*) FROM expenses e WHERE e.category_id = c.id)::BIGINT AS expenses,
+    (SELECT COUNT(*) FROM incomes i WHERE i.category_id = c.id)::BIGINT AS incomes,
+    (SELECT COUNT(*) FROM recurring_expenses r WHERE r.category_id = c.id)::BIGINT AS recurring_expenses,
+    (SELECT COUNT(*) FROM budgets b WHERE b.category_id = c.id)::BIGINT AS budgets,
+    (SELECT COUNT(*) FROM category_rules cr WHERE cr.category_id = c.id)::BIGINT AS category_rules,
+    (SELECT COUNT(*) FROM category_mappings cm WHERE cm.category_id = c.id)::BIGINT AS category_mappings
+FROM categories c
+WHERE c.id = $1 AND c.user_id = $2
+FOR UPDATE OF c;
+
+-- name: ArchiveCategory :execrows
+UPDATE categories
+SET archived_at = COALESCE(archived_at, NOW()), updated_at = NOW()
+WHERE id = $1 AND user_id = $2;
+
 -- name: UpdateCategorySortOrder :exec
 UPDATE categories
 SET sort_order = $3, updated_at = NOW()
@@ -0,0 +1,18 @@
+-- name: CreateCategoryRule :one
+INSERT INTO category_rules (user_id, category_id, pattern, priority, match_type, min_amount_cents, max_amount_cents, tag)
+VALUES ($1, $2, $3, $4, $5, sqlc.narg('min_amount_cents'), sqlc.narg('max_amount_cents'), sqlc.narg('tag'))
+RETURNING *;
+
+-- name: GetCategoryRulesByUser :many
+SELECT * FROM category_rules
+WHERE user_id = $1
+ORDER BY priority DESC, created_at;
+
+-- name: DeleteCategoryRule :execrows
+DELETE FROM category_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: ReassignCategoryRuleCategory :execrows
+UPDATE category_rules
+SET category_id = sqlc.arg('target_id')
+WHERE category_id = sqlc.arg('category_id') AND user_id = sqlc.arg('user_id');
@@ -0,0 +1,18 @@
+-- name: ListCurrencies :many
+-- Each currency with its most recent exchange rate.
+SELECT c.code, c.name, r.rate_date, r.usd_rate
+FROM currencies c
+JOIN LATERAL (
+    SELECT rate_date, usd_rate
+    FROM exchange_rates
+    WHERE currency = c.code
+    ORDER BY rate_date DESC
+    LIMIT 1
+) r ON TRUE
+ORDER BY c.code;
+
+-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (currency, rate_date, usd_rate)
+VALUES ($1, $2, $3)
+ON CONFLICT (currency, rate_date) DO UPDATE SET usd_rate = EXCLUDED.usd_rate
+RETURNING *;
@@ -0,0 +1,38 @@
+-- name: CreateExpenseAttachment :one
+-- Inserts nothing when the expense does not belong to the user.
+INSERT INTO expense_attachments (expense_id, storage_key, filename, content_type, size_bytes)
+SELECT e.id, $3, $4, $5, $6
+FROM expenses e
+WHERE e.id = $1 AND e.user_id = $2
+RETURNING id, expense_id, storage_key, filename, content_type, size_bytes, created_at;
+
+-- name: GetExpenseAttachments :many
+SELECT a.id, a.expense_id, a.storage_key, a.filename, a.content_type, a.size_bytes, a.created_at
+FROM expense_attachments a
+JOIN expenses e ON e.id = a.expense_id
+WHERE a.expense_id = $1 AND e.user_id = $2
+ORDER BY a.created_at, a.id;
+
+-- name: GetExpenseAttachment :one
+SELECT a.id, a.expense_id, a.storage_key, a.filename, a.content_type, a.size_bytes, a.created_at
+FROM expense_attachments a
+JOIN expenses e ON e.id = a.expense_id
+WHERE a.id = $1 AND a.expense_id = $2 AND e.user_id = $3;
+
+-- name: DeleteExpenseAttachment :execrows
+DELETE FROM expense_attachments a
+USING expenses e
+WHERE a.id = $1 AND a.expense_id = $2 AND e.id = a.expense_id AND e.user_id = $3;
+
+-- name: ExpenseExists :one
+SELECT EXISTS (SELECT 1 FROM expenses WHERE id = $1 AND user_id = $2);
+
+-- name: GetAttachmentDeletions :many
+SELECT storage_key
+FROM attachment_deletions
+ORDER BY queued_at
+LIMIT $1;
+
+-- name: DeleteAttachmentDeletion :exec
+DELETE FROM attachment_deletions
+WHERE storage_key = $1;
@@ -0,0 +1,75 @@
+-- name: DeleteExpenseSplits :execrows
+DELETE FROM expense_splits
+WHERE expense_id = $1 AND family_id = $2;
+
+-- name: CreateExpenseSplit :exec
+INSERT INTO expense_splits (expense_id, family_id, user_id, method, shares, amount_cents)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetExpenseSplits :many
+SELECT s.user_id, u.email AS user_email, s.method, s.shares, s.amount_cents
+FROM expense_splits s
+JOIN users u ON u.id = s.user_id
+WHERE s.expense_id = $1 AND s.family_id = $2
+ORDER BY u.email;
+
+-- name: GetFamilyBalances :many
+-- Net balance of each member in the family's base currency; positive means
+-- the member is owed money. Shares are converted at their expense's rate
+-- date and settlements at the date they were made.
+SELECT
+    fm.user_id,
+    u.email AS user_email,
+    (COALESCE(paid.cents, 0) - COALESCE(owed.cents, 0)
+        + COALESCE(sent.cents, 0) - COALESCE(received.cents, 0))::BIGINT AS net_cents
+FROM family_members fm
+JOIN families f ON f.id = fm.family_id
+JOIN users u ON u.id = fm.user_id
+LEFT JOIN LATERAL (
+    SELECT SUM(convert_cents(s.amount_cents, e.currency, f.base_currency, e.rate_date)) AS cents
+    FROM expense_splits s
+    JOIN expenses e ON e.id = s.expense_id
+    WHERE s.family_id = f.id AND e.user_id = fm.user_id
+) paid ON TRUE
+LEFT JOIN LATERAL (
+    SELECT SUM(convert_cents(s.amount_cents, e.currency, f.base_currency, e.rate_date)) AS cents
+    FROM expense_splits s
+    JOIN expenses e ON e.id = s.expense_id
+    WHERE s.family_id = f.id AND s.user_id = fm.user_id
+) owed ON TRUE
+LEFT JOIN LATERAL (
+    SELECT SUM(convert_cents(st.amount_cents, st.currency, f.base_currency, st.settled_on)) AS cents
+    FROM settlements st
+    WHERE st.family_id = f.id AND st.from_user_id = fm.user_id
+) sent ON TRUE
+LEFT JOIN LATERAL (
+    SELECT SUM(convert_cents(st.amount_cents, st.currency, f.base_currency, st.settled_on)) AS cents
+    FROM settlements st
+    WHERE st.family_id = f.id AND st.to_user_id = fm.user_id
+) received ON TRUE
+WHERE fm.family_id = $1
+ORDER BY u.email;
+
+-- name: CreateSettlement :one
+INSERT INTO settlements (family_id, from_user_id, to_user_id, amount_cents, currency, note, settled_on)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetFamilySettlements :many
+SELECT
+    st.id,
+    st.from_user_id,
+    fu.email AS from_user_email,
+    st.to_user_id,
+    tu.email AS to_user_email,
+    st.amount_cents,
+    st.currency,
+    st.note,
+    st.settled_on,
+    st.created_at
+FROM settlements st
+JOIN users fu ON fu.id = st.from_user_id
+JOIN users tu ON tu.id = st.to_user_id
+WHERE st.family_id = $1
+ORDER BY st.settled_on DESC, st.created_at DESC
+LIMIT $2 OFFSET $3;
@@ -1,31 +1,133 @@
 -- name: CreateExpense :one
-INSERT INTO expenses (user_id, category_id, amount_cents, note, expense_date)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at;
+-- A NULL currency defaults to the user's base currency (see the
+-- expenses_set_rate_date trigger), a NULL visibility to the category's
+-- default (see expenses_set_visibility).
+INSERT INTO expenses (user_id, category_id, amount_cents, note, expense_date, currency, visibility)
+VALUES ($1, $2, $3, $4, $5, sqlc.narg('currency'), sqlc.narg('visibility'))
+RETURNING id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at, recurring_id, currency, rate_date, visibility;
 
 -- name: GetExpensesByUser :many
-SELECT id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at
+SELECT id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at, recurring_id, currency, rate_date, visibility
 FROM expenses
 WHERE user_id = $1
 ORDER BY expense_date DESC, created_at DESC
 LIMIT $2 OFFSET $3;
 
+-- name: GetExpense :one
+SELECT id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at, recurring_id, currency, rate_date, visibility
+FROM expenses
+WHERE id = $1 AND user_id = $2;
+
 -- name: UpdateExpense :one
 UPDATE expenses
-SET category_id = $3, amount_cents = $4, note = $5, expense_date = $6, updated_at = NOW()
+SET category_id = $3, amount_cents = $4, note = $5, expense_date = $6,
+    currency = COALESCE(sqlc.narg('currency'), currency),
+    visibility = COALESCE(sqlc.narg('visibility'), visibility), updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at;
+RETURNING id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at, recurring_id, currency, rate_date, visibility;
 
 -- name: DeleteExpense :execrows
 DELETE FROM expenses
 WHERE id = $1 AND user_id = $2;
 
+-- name: ReassignExpenseCategory :execrows
+UPDATE expenses
+SET category_id = sqlc.arg('target_id'), updated_at = NOW()
+WHERE category_id = sqlc.arg('category_id') AND user_id = sqlc.arg('user_id');
+
 -- name: GetExpensesByUserFiltered :many
-SELECT id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at
+-- With a search query, results are ranked by relevance first; keyset
+-- pagination then no longer matches the ordering and callers page by offset.
+SELECT e.id, e.user_id, e.category_id, e.amount_cents, e.note, e.expense_date, e.created_at, e.updated_at, e.recurring_id, e.currency, e.rate_date, e.visibility
+FROM expenses e
+JOIN categories c ON c.id = e.category_id
+WHERE e.user_id = $1
+  AND (e.expense_date >= sqlc.narg('date_from')::DATE OR sqlc.narg('date_from') IS NULL)
+  AND (e.expense_date <= sqlc.narg('date_to')::DATE OR sqlc.narg('date_to') IS NULL)
+  AND (e.category_id = sqlc.narg('category_id') OR sqlc.narg('category_id') IS NULL)
+  AND (sqlc.narg('query')::TEXT IS NULL
+       OR search_document(e.note) @@ search_query(sqlc.narg('query'))
+       OR search_document(c.name) @@ search_query(sqlc.narg('query')))
+  AND (e.amount_cents >= sqlc.narg('min_amount')::BIGINT OR sqlc.narg('min_amount') IS NULL)
+  AND (e.amount_cents <= sqlc.narg('max_amount')::BIGINT OR sqlc.narg('max_amount') IS NULL)
+  AND (sqlc.narg('tags')::TEXT[] IS NULL
+       OR (SELECT COUNT(*) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+           WHERE et.expense_id = e.id AND t.name = ANY(sqlc.narg('tags')::TEXT[])) = cardinality(sqlc.narg('tags')::TEXT[]))
+  AND (sqlc.narg('before_id')::UUID IS NULL
+       OR (e.expense_date, e.created_at, e.id) < (sqlc.narg('before_date')::DATE, sqlc.narg('before_created_at')::TIMESTAMPTZ, sqlc.narg('before_id')::UUID))
+ORDER BY
+    COALESCE(ts_rank(search_document(e.note), search_query(sqlc.narg('query')))
+             + ts_rank(search_document(c.name), search_query(sqlc.narg('query'))), 0) DESC,
+    e.expense_date DESC, e.created_at DESC, e.id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetExpenseTotals :one
+-- Count and sum of the expenses matching a listing filter, converted to the
+-- user's base currency.
+SELECT
+    COUNT(*)::BIGINT AS expense_count,
+    COALESCE(SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date)), 0)::BIGINT AS total_cents
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+JOIN categories c ON c.id = e.category_id
+WHERE e.user_id = $1
+  AND (e.expense_date >= sqlc.narg('date_from')::DATE OR sqlc.narg('date_from') IS NULL)
+  AND (e.expense_date <= sqlc.narg('date_to')::DATE OR sqlc.narg('date_to') IS NULL)
+  AND (e.category_id = sqlc.narg('category_id') OR sqlc.narg('category_id') IS NULL)
+  AND (sqlc.narg('query')::TEXT IS NULL
+       OR search_document(e.note) @@ search_query(sqlc.narg('query'))
+       OR search_document(c.name) @@ search_query(sqlc.narg('query')))
+  AND (e.amount_cents >= sqlc.narg('min_amount')::BIGINT OR sqlc.narg('min_amount') IS NULL)
+  AND (e.amount_cents <= sqlc.narg('max_amount')::BIGINT OR sqlc.narg('max_amount') IS NULL)
+  AND (sqlc.narg('tags')::TEXT[] IS NULL
+       OR (SELECT COUNT(*) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+           WHERE et.expense_id = e.id AND t.name = ANY(sqlc.narg('tags')::TEXT[])) = cardinality(sqlc.narg('tags')::TEXT[]));
+
+-- name: GetExpenseFingerprints :many
+SELECT expense_date, amount_cents, note
 FROM expenses
 WHERE user_id = $1
-  AND (expense_date >= sqlc.narg('date_from')::DATE OR sqlc.narg('date_from') IS NULL)
-  AND (expense_date <= sqlc.narg('date_to')::DATE OR sqlc.narg('date_to') IS NULL)
-  AND (category_id = sqlc.narg('category_id') OR sqlc.narg('category_id') IS NULL)
-ORDER BY expense_date DESC, created_at DESC
-LIMIT $2 OFFSET $3;
+  AND expense_date >= sqlc.arg('date_from')::DATE
+  AND expense_date <= sqlc.arg('date_to')::DATE;
+
+-- name: GetExpensesForExport :many
+-- Keyset-paginated in ascending order so exports can stream in batches.
+SELECT
+    e.id,
+    e.category_id,
+    c.name AS category_name,
+    c.icon AS category_icon,
+    e.amount_cents,
+    e.note,
+    e.expense_date,
+    e.created_at,
+    e.currency
+FROM expenses e
+JOIN categories c ON c.id = e.category_id
+WHERE e.user_id = $1
+  AND (e.expense_date >= sqlc.narg('date_from')::DATE OR sqlc.narg('date_from') IS NULL)
+  AND (e.expense_date <= sqlc.narg('date_to')::DATE OR sqlc.narg('date_to') IS NULL)
+  AND (e.category_id = sqlc.narg('category_id') OR sqlc.narg('category_id') IS NULL)
+  AND (sqlc.narg('after_id')::UUID IS NULL
+       OR (e.expense_date, e.created_at, e.id) > (sqlc.narg('after_date')::DATE, sqlc.narg('after_created_at')::TIMESTAMPTZ, sqlc.narg('after_id')::UUID))
+ORDER BY e.expense_date, e.created_at, e.id
+LIMIT $2;
+
+-- name: GetCategorySuggestions :many
+-- Categories of the user's past expenses whose notes match the search
+-- query, ranked by the summed relevance of the matching expenses.
+SELECT
+    e.category_id,
+    c.name AS category_name,
+    c.icon AS category_icon,
+    c.color AS category_color,
+    COUNT(*)::BIGINT AS match_count,
+    MAX(e.expense_date)::DATE AS last_used
+FROM expenses e
+JOIN categories c ON c.id = e.category_id
+WHERE e.user_id = $1
+  AND c.archived_at IS NULL
+  AND search_document(e.note) @@ search_query(sqlc.arg('query'))
+GROUP BY e.category_id, c.name, c.icon, c.color
+ORDER BY SUM(ts_rank(search_document(e.note), search_query(sqlc.arg('query')))) DESC, last_used DESC
+LIMIT $2;
@@ -3,11 +3,35 @@ INSERT INTO families (name, admin_user_id)
 VALUES ($1, $2)
 RETURNING *;
 
--- name: GetFamilyByUserID :one
-SELECT f.*
+-- name: GetActiveFamily :one
+-- The user's preferred family, or their earliest joined family when no
+-- preference is set or they have left the preferred one.
+SELECT sqlc.embed(f), fm.role
 FROM families f
 JOIN family_members fm ON fm.family_id = f.id
-WHERE fm.user_id = $1;
+JOIN users u ON u.id = fm.user_id
+WHERE fm.user_id = $1
+ORDER BY (f.id = u.active_family_id) IS TRUE DESC, fm.joined_at, f.id
+LIMIT 1;
+
+-- name: GetFamilyForMember :one
+SELECT sqlc.embed(f), fm.role
+FROM families f
+JOIN family_members fm ON fm.family_id = f.id
+WHERE f.id = $1 AND fm.user_id = $2;
+
+-- name: GetUserFamilies :many
+SELECT sqlc.embed(f), fm.role
+FROM families f
+JOIN family_members fm ON fm.family_id = f.id
+WHERE fm.user_id = $1
+ORDER BY fm.joined_at, f.id;
+
+-- name: SetActiveFamily :execrows
+-- Only succeeds for families the user belongs to.
+UPDATE users SET active_family_id = $2, updated_at = NOW()
+WHERE id = $1
+  AND EXISTS (SELECT 1 FROM family_members WHERE family_id = $2 AND user_id = $1);
 
 -- name: GetFamilyMembers :many
 SELECT fm.id, fm.user_id, u.email, fm.role, fm.joined_at
@@ -23,7 +47,7 @@ RETURNING *;
 
 -- name: RemoveFamilyMember :execrows
 DELETE FROM family_members
-WHERE family_id = $1 AND user_id = $2 AND role != 'admin';
+WHERE family_id = $1 AND user_id = $2 AND role != 'owner';
 
 -- name: DeleteFamily :execrows
 DELETE FROM families
@@ -31,3 +55,24 @@ WHERE id = $1 AND admin_user_id = $2;
 
 -- name: GetFamilyMemberCount :one
 SELECT COUNT(*) FROM family_members WHERE family_id = $1;
+
+-- name: LockFamily :exec
+-- Serializes membership changes of a family until the transaction ends.
+SELECT id FROM families WHERE id = $1 FOR UPDATE;
+
+-- name: SetFamilyBaseCurrency :execrows
+UPDATE families SET base_currency = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFamilyMemberRole :execrows
+UPDATE family_members SET role = $3
+WHERE family_id = $1 AND user_id = $2;
+
+-- name: SetFamilyOwner :execrows
+-- Guarded by the current owner so concurrent transfers cannot both succeed.
+UPDATE families SET admin_user_id = sqlc.arg('new_owner_id'), updated_at = NOW()
+WHERE id = $1 AND admin_user_id = $2;
+
+-- name: SetFamilyPrivateExpenses :execrows
+UPDATE families SET private_expenses = $2, updated_at = NOW()
+WHERE id = $1;
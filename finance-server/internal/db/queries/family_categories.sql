@@ -0,0 +1,62 @@
+-- name: CreateFamilyCategory :one
+INSERT INTO categories (family_id, name, icon, color, kind, sort_order)
+VALUES ($1, $2, $3, $4, $5, (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM categories WHERE family_id = $1))
+RETURNING id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id;
+
+-- name: GetFamilyCategories :many
+SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id
+FROM categories
+WHERE family_id = $1
+  AND (kind = sqlc.narg('kind') OR sqlc.narg('kind') IS NULL)
+ORDER BY sort_order ASC;
+
+-- name: GetFamilyCategoryByID :one
+SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id
+FROM categories
+WHERE id = $1 AND family_id = $2;
+
+-- name: UpdateFamilyCategory :execrows
+UPDATE categories
+SET name = $3, icon = $4, color = $5, updated_at = NOW()
+WHERE id = $1 AND family_id = $2;
+
+-- name: DeleteFamilyCategory :execrows
+DELETE FROM categories
+WHERE id = $1 AND family_id = $2;
+
+-- name: GetCategoryMappings :many
+-- The user's own mappings in the family.
+SELECT cm.category_id, cm.family_category_id
+FROM category_mappings cm
+JOIN categories c ON c.id = cm.category_id
+WHERE cm.family_id = $1 AND c.user_id = $2
+ORDER BY cm.created_at, cm.category_id;
+
+-- name: UpsertCategoryMapping :execrows
+-- Maps one of the user's categories onto a family category of the same kind;
+-- no row is written when either category does not qualify.
+INSERT INTO category_mappings (family_id, category_id, family_category_id)
+SELECT fc.family_id, c.id, fc.id
+FROM categories c, categories fc
+WHERE c.id = sqlc.arg('category_id') AND c.user_id = sqlc.arg('user_id')
+  AND fc.id = sqlc.arg('family_category_id') AND fc.family_id = sqlc.arg('family_id')
+  AND fc.kind = c.kind
+ON CONFLICT (family_id, category_id) DO UPDATE SET family_category_id = EXCLUDED.family_category_id;
+
+-- name: DeleteCategoryMapping :execrows
+DELETE FROM category_mappings cm
+USING categories c
+WHERE cm.family_id = $1 AND cm.category_id = $2
+  AND c.id = cm.category_id AND c.user_id = $3;
+
+-- name: ReassignCategoryMappingCategory :execrows
+-- Moves the mappings of one of the user's categories to the target, except
+-- in families where the target is mapped already.
+UPDATE category_mappings cm
+SET category_id = sqlc.arg('target_id')
+FROM categories c
+WHERE cm.category_id = sqlc.arg('category_id')
+  AND c.id = cm.category_id AND c.user_id = sqlc.arg('user_id')
+  AND NOT EXISTS (
+      SELECT 1 FROM category_mappings t
+      WHERE t.family_id = cm.family_id AND t.category_id = sqlc.arg('target_id'));
@@ -0,0 +1,24 @@
+-- name: CreateFamilyEvent :exec
+INSERT INTO family_events (family_id, actor_user_id, action, subject_id, before, after)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: CreateMemberFamilyEvents :execrows
+-- Records the event in every family where the actor's expenses are shared,
+-- which excludes families they only view.
+INSERT INTO family_events (family_id, actor_user_id, action, subject_id, before, after)
+SELECT fm.family_id, sqlc.arg('actor_user_id')::UUID, sqlc.arg('action')::TEXT,
+       sqlc.narg('subject_id')::UUID, sqlc.narg('before')::JSONB, sqlc.narg('after')::JSONB
+FROM family_members fm
+WHERE fm.user_id = sqlc.arg('actor_user_id')::UUID AND fm.role <> 'viewer';
+
+-- name: GetFamilyEvents :many
+-- Newest first; a cursor selects the events strictly before it.
+SELECT fe.id, fe.family_id, fe.actor_user_id, COALESCE(u.email, '')::TEXT AS actor_email,
+       fe.action, fe.subject_id, fe.before, fe.after, fe.created_at
+FROM family_events fe
+LEFT JOIN users u ON u.id = fe.actor_user_id
+WHERE fe.family_id = $1
+  AND (sqlc.narg('before_id')::UUID IS NULL
+       OR (fe.created_at, fe.id) < (sqlc.narg('before_created_at')::TIMESTAMPTZ, sqlc.narg('before_id')::UUID))
+ORDER BY fe.created_at DESC, fe.id DESC
+LIMIT $2;
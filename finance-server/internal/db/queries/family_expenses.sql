@@ -1,52 +1,196 @@
 -- name: GetFamilyExpenses :many
+-- Ranked by relevance first when a search query is given, like
+-- GetExpensesByUserFiltered.
+-- Private expenses only appear when the family aggregates them, and then
+-- without their category or note; filtering by category or tag, or
+-- searching, leaves them out.
 SELECT
     e.id,
     e.user_id,
     u.email AS user_email,
-    e.category_id,
-    c.name AS category_name,
-    c.color AS category_color,
-    c.icon AS category_icon,
+    c.id AS category_id,
+    COALESCE(c.name, '')::TEXT AS category_name,
+    COALESCE(c.color, '')::TEXT AS category_color,
+    COALESCE(c.icon, '')::TEXT AS category_icon,
     e.amount_cents,
-    e.note,
+    (CASE WHEN e.visibility = 'family' THEN e.note ELSE '' END)::TEXT AS note,
     e.expense_date,
-    e.created_at
+    e.created_at,
+    e.currency,
+    EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = e.id) AS is_split,
+    e.visibility = 'private' AS is_private
 FROM expenses e
 JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
 JOIN users u ON u.id = e.user_id
-JOIN categories c ON c.id = e.category_id
+LEFT JOIN categories c ON c.id = e.category_id AND e.visibility = 'family'
 WHERE fm.family_id = $1
-ORDER BY e.expense_date DESC, e.created_at DESC
+  AND fm.role <> 'viewer'
+  AND (e.expense_date >= sqlc.narg('date_from')::DATE OR sqlc.narg('date_from') IS NULL)
+  AND (e.expense_date <= sqlc.narg('date_to')::DATE OR sqlc.narg('date_to') IS NULL)
+  AND (e.category_id = sqlc.narg('category_id') OR sqlc.narg('category_id') IS NULL)
+  AND (sqlc.narg('query')::TEXT IS NULL
+       OR search_document(e.note) @@ search_query(sqlc.narg('query'))
+       OR search_document(c.name) @@ search_query(sqlc.narg('query')))
+  AND (e.amount_cents >= sqlc.narg('min_amount')::BIGINT OR sqlc.narg('min_amount') IS NULL)
+  AND (e.amount_cents <= sqlc.narg('max_amount')::BIGINT OR sqlc.narg('max_amount') IS NULL)
+  AND (sqlc.narg('tags')::TEXT[] IS NULL
+       OR (SELECT COUNT(*) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+           WHERE et.expense_id = e.id AND t.name = ANY(sqlc.narg('tags')::TEXT[])) = cardinality(sqlc.narg('tags')::TEXT[]))
+  AND (e.visibility = 'family'
+       OR (f.private_expenses = 'aggregate' AND sqlc.narg('category_id')::UUID IS NULL AND sqlc.narg('query')::TEXT IS NULL
+           AND sqlc.narg('tags')::TEXT[] IS NULL))
+  AND (sqlc.narg('before_id')::UUID IS NULL
+       OR (e.expense_date, e.created_at, e.id) < (sqlc.narg('before_date')::DATE, sqlc.narg('before_created_at')::TIMESTAMPTZ, sqlc.narg('before_id')::UUID))
+ORDER BY
+    COALESCE(ts_rank(search_document(e.note), search_query(sqlc.narg('query')))
+             + ts_rank(search_document(c.name), search_query(sqlc.narg('query'))), 0) DESC,
+    e.expense_date DESC, e.created_at DESC, e.id DESC
 LIMIT $2 OFFSET $3;
 
+-- name: GetFamilyExpenseTotals :one
+-- Count and sum of the family expenses matching a feed filter, converted to
+-- the family's base currency. Private expenses are counted like in
+-- GetFamilyExpenses.
+SELECT
+    COUNT(*)::BIGINT AS expense_count,
+    COALESCE(SUM(convert_cents(e.amount_cents, e.currency, f.base_currency, e.rate_date)), 0)::BIGINT AS total_cents
+FROM expenses e
+JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
+LEFT JOIN categories c ON c.id = e.category_id AND e.visibility = 'family'
+WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
+  AND (e.expense_date >= sqlc.narg('date_from')::DATE OR sqlc.narg('date_from') IS NULL)
+  AND (e.expense_date <= sqlc.narg('date_to')::DATE OR sqlc.narg('date_to') IS NULL)
+  AND (e.category_id = sqlc.narg('category_id') OR sqlc.narg('category_id') IS NULL)
+  AND (sqlc.narg('query')::TEXT IS NULL
+       OR search_document(e.note) @@ search_query(sqlc.narg('query'))
+       OR search_document(c.name) @@ search_query(sqlc.narg('query')))
+  AND (e.amount_cents >= sqlc.narg('min_amount')::BIGINT OR sqlc.narg('min_amount') IS NULL)
+  AND (e.amount_cents <= sqlc.narg('max_amount')::BIGINT OR sqlc.narg('max_amount') IS NULL)
+  AND (sqlc.narg('tags')::TEXT[] IS NULL
+       OR (SELECT COUNT(*) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+           WHERE et.expense_id = e.id AND t.name = ANY(sqlc.narg('tags')::TEXT[])) = cardinality(sqlc.narg('tags')::TEXT[]))
+  AND (e.visibility = 'family'
+       OR (f.private_expenses = 'aggregate' AND sqlc.narg('category_id')::UUID IS NULL AND sqlc.narg('query')::TEXT IS NULL
+           AND sqlc.narg('tags')::TEXT[] IS NULL));
+
+-- name: GetFamilyExpenseByID :one
+-- Private expenses are not shared with the family and cannot be split.
+SELECT e.id, e.user_id, e.amount_cents, e.currency
+FROM expenses e
+JOIN family_members fm ON fm.user_id = e.user_id
+WHERE e.id = $1 AND fm.family_id = $2 AND fm.role <> 'viewer'
+  AND e.visibility = 'family';
+
 -- name: GetFamilyMemberTotals :many
+-- Private expenses are counted only when the family aggregates them.
 SELECT
     e.user_id,
     u.email AS user_email,
-    SUM(e.amount_cents)::BIGINT AS total_cents,
+    SUM(convert_cents(e.amount_cents, e.currency, f.base_currency, e.rate_date))::BIGINT AS total_cents,
     COUNT(*)::INT AS expense_count
 FROM expenses e
 JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
 JOIN users u ON u.id = e.user_id
 WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
   AND e.expense_date >= $2
   AND e.expense_date <= $3
+  AND (e.visibility = 'family' OR f.private_expenses = 'aggregate')
 GROUP BY e.user_id, u.email
 ORDER BY total_cents DESC;
 
 -- name: GetFamilyCategoryTotals :many
+-- Personal categories that members mapped onto a family category are
+-- counted under the family category. Aggregated private expenses are
+-- counted in a single row without a category.
+SELECT
+    c.id AS category_id,
+    COALESCE(c.name, '')::TEXT AS category_name,
+    COALESCE(c.color, '')::TEXT AS category_color,
+    COALESCE(c.icon, '')::TEXT AS category_icon,
+    c.parent_id,
+    SUM(convert_cents(e.amount_cents, e.currency, f.base_currency, e.rate_date))::BIGINT AS total_cents,
+    COUNT(*)::INT AS expense_count
+FROM expenses e
+JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
+LEFT JOIN category_mappings cm ON cm.family_id = fm.family_id AND cm.category_id = e.category_id
+LEFT JOIN categories c ON c.id = COALESCE(cm.family_category_id, e.category_id) AND e.visibility = 'family'
+WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
+  AND e.expense_date >= $2
+  AND e.expense_date <= $3
+  AND (e.visibility = 'family' OR f.private_expenses = 'aggregate')
+GROUP BY c.id, c.name, c.color, c.icon, c.parent_id
+ORDER BY total_cents DESC;
+
+-- name: GetFamilyCategoryRollupTotals :many
+-- Totals per top-level category, counting the expenses of its
+-- subcategories along with its own. Categories are mapped and private
+-- expenses aggregated as in GetFamilyCategoryTotals.
 SELECT
-    e.category_id,
-    c.name AS category_name,
-    c.color AS category_color,
-    c.icon AS category_icon,
-    SUM(e.amount_cents)::BIGINT AS total_cents,
+    r.id AS category_id,
+    COALESCE(r.name, '')::TEXT AS category_name,
+    COALESCE(r.color, '')::TEXT AS category_color,
+    COALESCE(r.icon, '')::TEXT AS category_icon,
+    SUM(convert_cents(e.amount_cents, e.currency, f.base_currency, e.rate_date))::BIGINT AS total_cents,
     COUNT(*)::INT AS expense_count
 FROM expenses e
 JOIN family_members fm ON fm.user_id = e.user_id
-JOIN categories c ON c.id = e.category_id
+JOIN families f ON f.id = fm.family_id
+LEFT JOIN category_mappings cm ON cm.family_id = fm.family_id AND cm.category_id = e.category_id
+LEFT JOIN categories c ON c.id = COALESCE(cm.family_category_id, e.category_id) AND e.visibility = 'family'
+LEFT JOIN categories r ON r.id = COALESCE(c.parent_id, c.id)
 WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
   AND e.expense_date >= $2
   AND e.expense_date <= $3
-GROUP BY e.category_id, c.name, c.color, c.icon
+  AND (e.visibility = 'family' OR f.private_expenses = 'aggregate')
+GROUP BY r.id, r.name, r.color, r.icon
 ORDER BY total_cents DESC;
+
+-- name: GetFamilyIncomeTotal :one
+SELECT COALESCE(SUM(convert_cents(i.amount_cents, i.currency, f.base_currency, i.rate_date)), 0)::BIGINT AS total_cents
+FROM incomes i
+JOIN family_members fm ON fm.user_id = i.user_id
+JOIN families f ON f.id = fm.family_id
+WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
+  AND i.income_date >= $2
+  AND i.income_date <= $3;
+
+-- name: GetFamilyExpensesForExport :many
+-- Keyset-paginated in ascending order so exports can stream in batches.
+-- Private expenses are included like in GetFamilyExpenses.
+SELECT
+    e.id,
+    e.user_id,
+    u.email AS user_email,
+    c.id AS category_id,
+    COALESCE(c.name, '')::TEXT AS category_name,
+    COALESCE(c.color, '')::TEXT AS category_color,
+    COALESCE(c.icon, '')::TEXT AS category_icon,
+    e.amount_cents,
+    (CASE WHEN e.visibility = 'family' THEN e.note ELSE '' END)::TEXT AS note,
+    e.expense_date,
+    e.created_at,
+    e.currency
+FROM expenses e
+JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
+JOIN users u ON u.id = e.user_id
+LEFT JOIN categories c ON c.id = e.category_id AND e.visibility = 'family'
+WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
+  AND (e.expense_date >= sqlc.narg('date_from')::DATE OR sqlc.narg('date_from') IS NULL)
+  AND (e.expense_date <= sqlc.narg('date_to')::DATE OR sqlc.narg('date_to') IS NULL)
+  AND (e.category_id = sqlc.narg('category_id') OR sqlc.narg('category_id') IS NULL)
+  AND (e.visibility = 'family' OR (f.private_expenses = 'aggregate' AND sqlc.narg('category_id')::UUID IS NULL))
+  AND (sqlc.narg('after_id')::UUID IS NULL
+       OR (e.expense_date, e.created_at, e.id) > (sqlc.narg('after_date')::DATE, sqlc.narg('after_created_at')::TIMESTAMPTZ, sqlc.narg('after_id')::UUID))
+ORDER BY e.expense_date, e.created_at, e.id
+LIMIT $2;
@@ -0,0 +1,32 @@
+-- name: CreateIncome :one
+-- A NULL currency defaults to the user's base currency (see the
+-- incomes_set_rate_date trigger).
+INSERT INTO incomes (user_id, category_id, amount_cents, note, income_date, currency)
+VALUES ($1, $2, $3, $4, $5, sqlc.narg('currency'))
+RETURNING id, user_id, category_id, amount_cents, note, income_date, created_at, updated_at, currency, rate_date;
+
+-- name: GetIncomesByUserFiltered :many
+SELECT id, user_id, category_id, amount_cents, note, income_date, created_at, updated_at, currency, rate_date
+FROM incomes
+WHERE user_id = $1
+  AND (income_date >= sqlc.narg('date_from')::DATE OR sqlc.narg('date_from') IS NULL)
+  AND (income_date <= sqlc.narg('date_to')::DATE OR sqlc.narg('date_to') IS NULL)
+  AND (category_id = sqlc.narg('category_id') OR sqlc.narg('category_id') IS NULL)
+ORDER BY income_date DESC, created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateIncome :one
+UPDATE incomes
+SET category_id = $3, amount_cents = $4, note = $5, income_date = $6,
+    currency = COALESCE(sqlc.narg('currency'), currency), updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, category_id, amount_cents, note, income_date, created_at, updated_at, currency, rate_date;
+
+-- name: DeleteIncome :execrows
+DELETE FROM incomes
+WHERE id = $1 AND user_id = $2;
+
+-- name: ReassignIncomeCategory :execrows
+UPDATE incomes
+SET category_id = sqlc.arg('target_id'), updated_at = NOW()
+WHERE category_id = sqlc.arg('category_id') AND user_id = sqlc.arg('user_id');
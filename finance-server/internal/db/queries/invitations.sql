@@ -1,6 +1,6 @@
 -- name: CreateInvitation :one
-INSERT INTO family_invitations (family_id, inviter_user_id, token_hash, expires_at)
-VALUES ($1, $2, $3, $4)
+INSERT INTO family_invitations (family_id, inviter_user_id, token_hash, expires_at, email)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: GetInvitationByTokenHash :one
@@ -9,16 +9,34 @@ FROM family_invitations fi
 JOIN families f ON f.id = fi.family_id
 WHERE fi.token_hash = $1 AND fi.status = 'pending' AND fi.expires_at > NOW();
 
+-- name: GetInvitationByID :one
+SELECT fi.*, f.name as family_name
+FROM family_invitations fi
+JOIN families f ON f.id = fi.family_id
+WHERE fi.id = $1 AND fi.status = 'pending' AND fi.expires_at > NOW();
+
 -- name: AcceptInvitation :execrows
 UPDATE family_invitations SET status = 'accepted'
-WHERE id = $1 AND status = 'pending';
+WHERE id = $1 AND status = 'pending' AND expires_at > NOW();
 
 -- name: RevokeInvitation :execrows
 UPDATE family_invitations SET status = 'revoked'
 WHERE id = $1 AND family_id = $2 AND status = 'pending';
 
+-- name: ExpireInvitations :execrows
+UPDATE family_invitations SET status = 'expired'
+WHERE status = 'pending' AND expires_at <= NOW();
+
 -- name: GetPendingInvitations :many
-SELECT id, family_id, inviter_user_id, status, expires_at, created_at
+SELECT id, family_id, inviter_user_id, email, status, expires_at, created_at
 FROM family_invitations
 WHERE family_id = $1 AND status = 'pending' AND expires_at > NOW()
 ORDER BY created_at DESC;
+
+-- name: GetPendingInvitationsForEmail :many
+SELECT fi.id, fi.family_id, f.name AS family_name, u.email AS inviter_email, fi.expires_at, fi.created_at
+FROM family_invitations fi
+JOIN families f ON f.id = fi.family_id
+JOIN users u ON u.id = fi.inviter_user_id
+WHERE lower(fi.email) = lower(sqlc.arg('email')) AND fi.status = 'pending' AND fi.expires_at > NOW()
+ORDER BY fi.created_at DESC;
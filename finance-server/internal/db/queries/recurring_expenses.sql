@@ -0,0 +1,52 @@
+-- name: CreateRecurringExpense :one
+INSERT INTO recurring_expenses (user_id, category_id, amount_cents, note, frequency, start_date, end_date, next_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $6)
+RETURNING *;
+
+-- name: GetRecurringExpensesByUser :many
+SELECT * FROM recurring_expenses
+WHERE user_id = $1
+ORDER BY next_date, created_at;
+
+-- name: GetRecurringExpenseByID :one
+SELECT * FROM recurring_expenses
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateRecurringExpense :one
+UPDATE recurring_expenses
+SET category_id = $3, amount_cents = $4, note = $5, frequency = $6,
+    start_date = $7, end_date = $8, next_date = $9, updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteRecurringExpense :execrows
+DELETE FROM recurring_expenses
+WHERE id = $1 AND user_id = $2;
+
+-- name: ReassignRecurringExpenseCategory :execrows
+UPDATE recurring_expenses
+SET category_id = sqlc.arg('target_id'), updated_at = NOW()
+WHERE category_id = sqlc.arg('category_id') AND user_id = sqlc.arg('user_id');
+
+-- name: GetDueRecurringExpenses :many
+-- Locks due templates so concurrent workers never materialize the same one.
+-- Templates whose category was archived are skipped.
+SELECT * FROM recurring_expenses
+WHERE next_date <= $1
+  AND (end_date IS NULL OR next_date <= end_date)
+  AND NOT EXISTS (
+      SELECT 1 FROM categories c
+      WHERE c.id = recurring_expenses.category_id AND c.archived_at IS NOT NULL)
+ORDER BY next_date
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: SetRecurringExpenseNextDate :exec
+UPDATE recurring_expenses
+SET next_date = $2
+WHERE id = $1;
+
+-- name: CreateRecurringOccurrence :execrows
+INSERT INTO expenses (user_id, category_id, amount_cents, note, expense_date, recurring_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (recurring_id, expense_date) DO NOTHING;
@@ -4,23 +4,80 @@ SELECT
     c.name AS category_name,
     c.color AS category_color,
     c.icon AS category_icon,
-    SUM(e.amount_cents)::BIGINT AS total_cents,
+    c.parent_id,
+    SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date))::BIGINT AS total_cents,
     COUNT(*)::INT AS expense_count
 FROM expenses e
+JOIN users u ON u.id = e.user_id
 JOIN categories c ON c.id = e.category_id
 WHERE e.user_id = $1
   AND e.expense_date >= $2
   AND e.expense_date <= $3
-GROUP BY e.category_id, c.name, c.color, c.icon
+GROUP BY e.category_id, c.name, c.color, c.icon, c.parent_id
+ORDER BY total_cents DESC;
+
+-- name: GetCategoryRollupTotals :many
+-- Totals per top-level category, counting the expenses of its
+-- subcategories along with its own.
+SELECT
+    r.id AS category_id,
+    r.name AS category_name,
+    r.color AS category_color,
+    r.icon AS category_icon,
+    SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date))::BIGINT AS total_cents,
+    COUNT(*)::INT AS expense_count
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+JOIN categories c ON c.id = e.category_id
+JOIN categories r ON r.id = COALESCE(c.parent_id, c.id)
+WHERE e.user_id = $1
+  AND e.expense_date >= $2
+  AND e.expense_date <= $3
+GROUP BY r.id, r.name, r.color, r.icon
 ORDER BY total_cents DESC;
 
 -- name: GetDailyTotals :many
 SELECT
-    expense_date AS date,
-    SUM(amount_cents)::BIGINT AS total_cents
-FROM expenses
-WHERE user_id = $1
-  AND expense_date >= $2
-  AND expense_date <= $3
-GROUP BY expense_date
-ORDER BY expense_date ASC;
+    e.expense_date AS date,
+    SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date))::BIGINT AS total_cents
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+WHERE e.user_id = $1
+  AND e.expense_date >= $2
+  AND e.expense_date <= $3
+GROUP BY e.expense_date
+ORDER BY e.expense_date ASC;
+
+-- name: GetIncomeCategoryTotals :many
+SELECT
+    i.category_id,
+    c.name AS category_name,
+    c.color AS category_color,
+    c.icon AS category_icon,
+    SUM(convert_cents(i.amount_cents, i.currency, u.base_currency, i.rate_date))::BIGINT AS total_cents,
+    COUNT(*)::INT AS income_count
+FROM incomes i
+JOIN users u ON u.id = i.user_id
+JOIN categories c ON c.id = i.category_id
+WHERE i.user_id = $1
+  AND i.income_date >= $2
+  AND i.income_date <= $3
+GROUP BY i.category_id, c.name, c.color, c.icon
+ORDER BY total_cents DESC;
+
+-- name: GetTagTotals :many
+-- Totals per tag. An expense with several tags counts towards each of them.
+SELECT
+    t.id AS tag_id,
+    t.name AS tag_name,
+    SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date))::BIGINT AS total_cents,
+    COUNT(*)::INT AS expense_count
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+JOIN expense_tags et ON et.expense_id = e.id
+JOIN tags t ON t.id = et.tag_id
+WHERE e.user_id = $1
+  AND e.expense_date >= $2
+  AND e.expense_date <= $3
+GROUP BY t.id, t.name
+ORDER BY total_cents DESC;
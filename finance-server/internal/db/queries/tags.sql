@@ -0,0 +1,23 @@
+-- name: CreateTags :exec
+-- Creates those of the named tags the user does not have yet.
+INSERT INTO tags (user_id, name)
+SELECT sqlc.arg('user_id')::UUID, unnest(sqlc.arg('names')::TEXT[])
+ON CONFLICT (user_id, name) DO NOTHING;
+
+-- name: AddExpenseTags :exec
+INSERT INTO expense_tags (expense_id, tag_id)
+SELECT sqlc.arg('expense_id')::UUID, t.id
+FROM tags t
+WHERE t.user_id = sqlc.arg('user_id') AND t.name = ANY(sqlc.arg('names')::TEXT[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetExpenseTags :many
+SELECT et.expense_id, t.name
+FROM expense_tags et
+JOIN tags t ON t.id = et.tag_id
+WHERE et.expense_id = ANY(sqlc.arg('expense_ids')::UUID[])
+ORDER BY t.name;
+
+-- name: DeleteExpenseTags :exec
+DELETE FROM expense_tags
+WHERE expense_id = $1;
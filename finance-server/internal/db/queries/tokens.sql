@@ -1,15 +1,34 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (user_id, token_hash, expires_at)
-VALUES ($1, $2, $3)
+-- Stores a token in the given family, or in a new one when family_id is null.
+INSERT INTO refresh_tokens (user_id, token_hash, expires_at, family_id)
+VALUES ($1, $2, $3, COALESCE(sqlc.narg('family_id')::UUID, gen_random_uuid()))
 RETURNING id;
 
 -- name: GetRefreshTokenByHash :one
-SELECT id, user_id, token_hash, expires_at, revoked
+-- Revoked tokens are returned too, so that their reuse can be detected.
+SELECT id, user_id, token_hash, expires_at, revoked, family_id
 FROM refresh_tokens
-WHERE token_hash = $1 AND revoked = FALSE AND expires_at > NOW();
+WHERE token_hash = $1 AND expires_at > NOW();
 
--- name: RevokeRefreshToken :exec
-UPDATE refresh_tokens SET revoked = TRUE WHERE token_hash = $1;
+-- name: RevokeRefreshToken :execrows
+-- Affects no rows when the token is already revoked, so that concurrent
+-- refreshes with the same token cannot both succeed.
+UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW()
+WHERE token_hash = $1 AND revoked = FALSE;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens SET revoked = TRUE, revoked_at = COALESCE(revoked_at, NOW())
+WHERE family_id = $1;
 
 -- name: RevokeAllUserTokens :exec
-UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1;
+UPDATE refresh_tokens SET revoked = TRUE, revoked_at = COALESCE(revoked_at, NOW())
+WHERE user_id = $1;
+
+-- name: DeleteStaleRefreshTokens :execrows
+-- Deletes up to batch_size tokens that expired or were revoked before cutoff.
+DELETE FROM refresh_tokens
+WHERE id IN (
+    SELECT id FROM refresh_tokens
+    WHERE expires_at < sqlc.arg('cutoff') OR revoked_at < sqlc.arg('cutoff')
+    LIMIT sqlc.arg('batch_size')
+);
@@ -4,6 +4,16 @@ VALUES ($1, $2)
 RETURNING id, email, created_at, updated_at;
 
 -- name: GetUserByEmail :one
-SELECT id, email, password_hash, created_at, updated_at
+SELECT id, email, password_hash, created_at, updated_at, base_currency
 FROM users
 WHERE email = $1;
+
+-- name: GetUserEmail :one
+SELECT email FROM users WHERE id = $1;
+
+-- name: GetUserBaseCurrency :one
+SELECT base_currency FROM users WHERE id = $1;
+
+-- name: SetUserBaseCurrency :execrows
+UPDATE users SET base_currency = $2, updated_at = NOW()
+WHERE id = $1;
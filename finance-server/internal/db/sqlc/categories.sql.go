@@ -11,25 +11,54 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const archiveCategory = `-- name: ArchiveCategory :execrows
+UPDATE categories
+SET archived_at = COALESCE(archived_at, NOW()), updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+`
+
+type ArchiveCategoryParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) ArchiveCategory(ctx context.Context, arg ArchiveCategoryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, archiveCategory, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createCategory = `-- name: CreateCategory :one
-INSERT INTO categories (user_id, name, icon, color, sort_order)
-VALUES ($1, $2, $3, $4, (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM categories WHERE user_id = $1))
-RETURNING id, user_id, name, icon, color, sort_order, created_at, updated_at
+INSERT INTO categories (user_id, name, icon, color, kind, default_visibility, parent_id, sort_order)
+VALUES ($1, $2, $3, $4, $5, $6, $7,
+    (SELECT COALESCE(MAX(sort_order), -1) + 1 FROM categories
+     WHERE user_id = $1 AND parent_id IS NOT DISTINCT FROM $7))
+RETURNING id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id
 `
 
 type CreateCategoryParams struct {
-	UserID pgtype.UUID `json:"user_id"`
-	Name   string      `json:"name"`
-	Icon   string      `json:"icon"`
-	Color  string      `json:"color"`
+	UserID            pgtype.UUID `json:"user_id"`
+	Name              string      `json:"name"`
+	Icon              string      `json:"icon"`
+	Color             string      `json:"color"`
+	Kind              string      `json:"kind"`
+	DefaultVisibility string      `json:"default_visibility"`
+	ParentID          pgtype.UUID `json:"parent_id"`
 }
 
+// Categories are ordered among their siblings, so a new category goes last
+// under its parent.
 func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
 	row := q.db.QueryRow(ctx, createCategory,
 		arg.UserID,
 		arg.Name,
 		arg.Icon,
 		arg.Color,
+		arg.Kind,
+		arg.DefaultVisibility,
+		arg.ParentID,
 	)
 	var i Category
 	err := row.Scan(
@@ -41,6 +70,11 @@ func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams)
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Kind,
+		&i.FamilyID,
+		&i.DefaultVisibility,
+		&i.ArchivedAt,
+		&i.ParentID,
 	)
 	return i, err
 }
@@ -64,14 +98,22 @@ func (q *Queries) DeleteCategory(ctx context.Context, arg DeleteCategoryParams)
 }
 
 const getCategoriesByUser = `-- name: GetCategoriesByUser :many
-SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at
+SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id
 FROM categories
 WHERE user_id = $1
+  AND (kind = $2 OR $2 IS NULL)
+  AND (archived_at IS NULL OR $3::BOOLEAN)
 ORDER BY sort_order ASC
 `
 
-func (q *Queries) GetCategoriesByUser(ctx context.Context, userID pgtype.UUID) ([]Category, error) {
-	rows, err := q.db.Query(ctx, getCategoriesByUser, userID)
+type GetCategoriesByUserParams struct {
+	UserID          pgtype.UUID `json:"user_id"`
+	Kind            pgtype.Text `json:"kind"`
+	IncludeArchived bool        `json:"include_archived"`
+}
+
+func (q *Queries) GetCategoriesByUser(ctx context.Context, arg GetCategoriesByUserParams) ([]Category, error) {
+	rows, err := q.db.Query(ctx, getCategoriesByUser, arg.UserID, arg.Kind, arg.IncludeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +130,11 @@ func (q *Queries) GetCategoriesByUser(ctx context.Context, userID pgtype.UUID) (
 			&i.SortOrder,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Kind,
+			&i.FamilyID,
+			&i.DefaultVisibility,
+			&i.ArchivedAt,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -100,7 +147,7 @@ func (q *Queries) GetCategoriesByUser(ctx context.Context, userID pgtype.UUID) (
 }
 
 const getCategoryByID = `-- name: GetCategoryByID :one
-SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at
+SELECT id, user_id, name, icon, color, sort_order, created_at, updated_at, kind, family_id, default_visibility, archived_at, parent_id
 FROM categories
 WHERE id = $1 AND user_id = $2
 `
@@ -122,24 +169,132 @@ func (q *Queries) GetCategoryByID(ctx context.Context, arg GetCategoryByIDParams
 		&i.SortOrder,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Kind,
+		&i.FamilyID,
+		&i.DefaultVisibility,
+		&i.ArchivedAt,
+		&i.ParentID,
+	)
+	return i, err
+}
+
+const getCategoryUsage = `-- name: GetCategoryUsage :one
+SELECT
+    (SELECT COUNT(*) FROM expenses e WHERE e.category_id = c.id)::BIGINT AS expenses,
+    (SELECT COUNT(*) FROM incomes i WHERE i.category_id = c.id)::BIGINT AS incomes,
+    (SELECT COUNT(*) FROM recurring_expenses r WHERE r.category_id = c.id)::BIGINT AS recurring_expenses,
+    (SELECT COUNT(*) FROM budgets b WHERE b.category_id = c.id)::BIGINT AS budgets,
+    (SELECT COUNT(*) FROM category_rules cr WHERE cr.category_id = c.id)::BIGINT AS category_rules,
+    (SELECT COUNT(*) FROM category_mappings cm WHERE cm.category_id = c.id)::BIGINT AS category_mappings
+FROM categories c
+WHERE c.id = $1 AND c.user_id = $2
+FOR UPDATE OF c
+`
+
+type GetCategoryUsageParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+type GetCategoryUsageRow struct {
+	Expenses          int64 `json:"expenses"`
+	Incomes           int64 `json:"incomes"`
+	RecurringExpenses int64 `json:"recurring_expenses"`
+	Budgets           int64 `json:"budgets"`
+	CategoryRules     int64 `json:"category_rules"`
+	CategoryMappings  int64 `json:"category_mappings"`
+}
+
+// Counts the records referencing one of the user's categories. The category
+// stays locked until the transaction ends, so no new references appear.
+func (q *Queries) GetCategoryUsage(ctx context.Context, arg GetCategoryUsageParams) (GetCategoryUsageRow, error) {
+	row := q.db.QueryRow(ctx, getCategoryUsage, arg.ID, arg.UserID)
+	var i GetCategoryUsageRow
+	err := row.Scan(
+		&i.Expenses,
+		&i.Incomes,
+		&i.RecurringExpenses,
+		&i.Budgets,
+		&i.CategoryRules,
+		&i.CategoryMappings,
 	)
 	return i, err
 }
 
+const getExpenseCategoryKind = `-- name: GetExpenseCategoryKind :one
+SELECT c.kind
+FROM categories c
+WHERE c.id = $1
+  AND (c.user_id = $2 OR c.family_id IN (
+      SELECT fm.family_id FROM family_members fm WHERE fm.user_id = $2))
+  AND (c.archived_at IS NULL OR EXISTS (
+      SELECT 1 FROM expenses e WHERE e.id = $3 AND e.category_id = c.id))
+`
+
+type GetExpenseCategoryKindParams struct {
+	ID        pgtype.UUID `json:"id"`
+	UserID    pgtype.UUID `json:"user_id"`
+	ExpenseID pgtype.UUID `json:"expense_id"`
+}
+
+// The kind of a category the user may file expenses under: one of their own
+// or a shared category of a family they belong to. An archived category only
+// qualifies for the expense_id already filed under it.
+func (q *Queries) GetExpenseCategoryKind(ctx context.Context, arg GetExpenseCategoryKindParams) (string, error) {
+	row := q.db.QueryRow(ctx, getExpenseCategoryKind, arg.ID, arg.UserID, arg.ExpenseID)
+	var kind string
+	err := row.Scan(&kind)
+	return kind, err
+}
+
+const getIncomeCategoryKind = `-- name: GetIncomeCategoryKind :one
+SELECT c.kind
+FROM categories c
+WHERE c.id = $1
+  AND (c.user_id = $2 OR c.family_id IN (
+      SELECT fm.family_id FROM family_members fm WHERE fm.user_id = $2))
+  AND (c.archived_at IS NULL OR EXISTS (
+      SELECT 1 FROM incomes i WHERE i.id = $3 AND i.category_id = c.id))
+`
+
+type GetIncomeCategoryKindParams struct {
+	ID       pgtype.UUID `json:"id"`
+	UserID   pgtype.UUID `json:"user_id"`
+	IncomeID pgtype.UUID `json:"income_id"`
+}
+
+// The kind of a category the user may file incomes under: one of their own
+// or a shared category of a family they belong to. An archived category only
+// qualifies for the income_id already filed under it.
+func (q *Queries) GetIncomeCategoryKind(ctx context.Context, arg GetIncomeCategoryKindParams) (string, error) {
+	row := q.db.QueryRow(ctx, getIncomeCategoryKind, arg.ID, arg.UserID, arg.IncomeID)
+	var kind string
+	err := row.Scan(&kind)
+	return kind, err
+}
+
 const updateCategory = `-- name: UpdateCategory :execrows
 UPDATE categories
-SET name = $3, icon = $4, color = $5, updated_at = NOW()
+SET name = $3, icon = $4, color = $5,
+    default_visibility = COALESCE($6, default_visibility),
+    parent_id = CASE WHEN $7::BOOLEAN THEN $8 ELSE parent_id END,
+    updated_at = NOW()
 WHERE id = $1 AND user_id = $2
 `
 
 type UpdateCategoryParams struct {
-	ID     pgtype.UUID `json:"id"`
-	UserID pgtype.UUID `json:"user_id"`
-	Name   string      `json:"name"`
-	Icon   string      `json:"icon"`
-	Color  string      `json:"color"`
+	ID                pgtype.UUID `json:"id"`
+	UserID            pgtype.UUID `json:"user_id"`
+	Name              string      `json:"name"`
+	Icon              string      `json:"icon"`
+	Color             string      `json:"color"`
+	DefaultVisibility pgtype.Text `json:"default_visibility"`
+	SetParent         bool        `json:"set_parent"`
+	ParentID          pgtype.UUID `json:"parent_id"`
 }
 
+// The parent is only changed when set_parent is true; a NULL parent_id then
+// moves the category to the top level.
 func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (int64, error) {
 	result, err := q.db.Exec(ctx, updateCategory,
 		arg.ID,
@@ -147,6 +302,9 @@ func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams)
 		arg.Name,
 		arg.Icon,
 		arg.Color,
+		arg.DefaultVisibility,
+		arg.SetParent,
+		arg.ParentID,
 	)
 	if err != nil {
 		return 0, err
@@ -12,9 +12,9 @@ import (
 )
 
 const createExpense = `-- name: CreateExpense :one
-INSERT INTO expenses (user_id, category_id, amount_cents, note, expense_date)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at
+INSERT INTO expenses (user_id, category_id, amount_cents, note, expense_date, currency, visibility)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at, recurring_id, currency, rate_date, visibility
 `
 
 type CreateExpenseParams struct {
@@ -23,8 +23,13 @@ type CreateExpenseParams struct {
 	AmountCents int64       `json:"amount_cents"`
 	Note        string      `json:"note"`
 	ExpenseDate pgtype.Date `json:"expense_date"`
+	Currency    pgtype.Text `json:"currency"`
+	Visibility  pgtype.Text `json:"visibility"`
 }
 
+// A NULL currency defaults to the user's base currency (see the
+// expenses_set_rate_date trigger), a NULL visibility to the category's
+// default (see expenses_set_visibility).
 func (q *Queries) CreateExpense(ctx context.Context, arg CreateExpenseParams) (Expense, error) {
 	row := q.db.QueryRow(ctx, createExpense,
 		arg.UserID,
@@ -32,6 +37,8 @@ func (q *Queries) CreateExpense(ctx context.Context, arg CreateExpenseParams) (E
 		arg.AmountCents,
 		arg.Note,
 		arg.ExpenseDate,
+		arg.Currency,
+		arg.Visibility,
 	)
 	var i Expense
 	err := row.Scan(
@@ -43,6 +50,10 @@ func (q *Queries) CreateExpense(ctx context.Context, arg CreateExpenseParams) (E
 		&i.ExpenseDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RecurringID,
+		&i.Currency,
+		&i.RateDate,
+		&i.Visibility,
 	)
 	return i, err
 }
@@ -65,8 +76,196 @@ func (q *Queries) DeleteExpense(ctx context.Context, arg DeleteExpenseParams) (i
 	return result.RowsAffected(), nil
 }
 
+const getCategorySuggestions = `-- name: GetCategorySuggestions :many
+SELECT
+    e.category_id,
+    c.name AS category_name,
+    c.icon AS category_icon,
+    c.color AS category_color,
+    COUNT(*)::BIGINT AS match_count,
+    MAX(e.expense_date)::DATE AS last_used
+FROM expenses e
+JOIN categories c ON c.id = e.category_id
+WHERE e.user_id = $1
+  AND c.archived_at IS NULL
+  AND search_document(e.note) @@ search_query($3)
+GROUP BY e.category_id, c.name, c.icon, c.color
+ORDER BY SUM(ts_rank(search_document(e.note), search_query($3))) DESC, last_used DESC
+LIMIT $2
+`
+
+type GetCategorySuggestionsParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Limit  int32       `json:"limit"`
+	Query  string      `json:"query"`
+}
+
+type GetCategorySuggestionsRow struct {
+	CategoryID    pgtype.UUID `json:"category_id"`
+	CategoryName  string      `json:"category_name"`
+	CategoryIcon  string      `json:"category_icon"`
+	CategoryColor string      `json:"category_color"`
+	MatchCount    int64       `json:"match_count"`
+	LastUsed      pgtype.Date `json:"last_used"`
+}
+
+// Categories of the user's past expenses whose notes match the search
+// query, ranked by the summed relevance of the matching expenses.
+func (q *Queries) GetCategorySuggestions(ctx context.Context, arg GetCategorySuggestionsParams) ([]GetCategorySuggestionsRow, error) {
+	rows, err := q.db.Query(ctx, getCategorySuggestions, arg.UserID, arg.Limit, arg.Query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCategorySuggestionsRow
+	for rows.Next() {
+		var i GetCategorySuggestionsRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.CategoryName,
+			&i.CategoryIcon,
+			&i.CategoryColor,
+			&i.MatchCount,
+			&i.LastUsed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExpense = `-- name: GetExpense :one
+SELECT id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at, recurring_id, currency, rate_date, visibility
+FROM expenses
+WHERE id = $1 AND user_id = $2
+`
+
+type GetExpenseParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) GetExpense(ctx context.Context, arg GetExpenseParams) (Expense, error) {
+	row := q.db.QueryRow(ctx, getExpense, arg.ID, arg.UserID)
+	var i Expense
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CategoryID,
+		&i.AmountCents,
+		&i.Note,
+		&i.ExpenseDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecurringID,
+		&i.Currency,
+		&i.RateDate,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getExpenseFingerprints = `-- name: GetExpenseFingerprints :many
+SELECT expense_date, amount_cents, note
+FROM expenses
+WHERE user_id = $1
+  AND expense_date >= $2::DATE
+  AND expense_date <= $3::DATE
+`
+
+type GetExpenseFingerprintsParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	DateFrom pgtype.Date `json:"date_from"`
+	DateTo   pgtype.Date `json:"date_to"`
+}
+
+type GetExpenseFingerprintsRow struct {
+	ExpenseDate pgtype.Date `json:"expense_date"`
+	AmountCents int64       `json:"amount_cents"`
+	Note        string      `json:"note"`
+}
+
+func (q *Queries) GetExpenseFingerprints(ctx context.Context, arg GetExpenseFingerprintsParams) ([]GetExpenseFingerprintsRow, error) {
+	rows, err := q.db.Query(ctx, getExpenseFingerprints, arg.UserID, arg.DateFrom, arg.DateTo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetExpenseFingerprintsRow
+	for rows.Next() {
+		var i GetExpenseFingerprintsRow
+		if err := rows.Scan(&i.ExpenseDate, &i.AmountCents, &i.Note); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExpenseTotals = `-- name: GetExpenseTotals :one
+SELECT
+    COUNT(*)::BIGINT AS expense_count,
+    COALESCE(SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date)), 0)::BIGINT AS total_cents
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+JOIN categories c ON c.id = e.category_id
+WHERE e.user_id = $1
+  AND (e.expense_date >= $2::DATE OR $2 IS NULL)
+  AND (e.expense_date <= $3::DATE OR $3 IS NULL)
+  AND (e.category_id = $4 OR $4 IS NULL)
+  AND ($5::TEXT IS NULL
+       OR search_document(e.note) @@ search_query($5)
+       OR search_document(c.name) @@ search_query($5))
+  AND (e.amount_cents >= $6::BIGINT OR $6 IS NULL)
+  AND (e.amount_cents <= $7::BIGINT OR $7 IS NULL)
+  AND ($8::TEXT[] IS NULL
+       OR (SELECT COUNT(*) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+           WHERE et.expense_id = e.id AND t.name = ANY($8::TEXT[])) = cardinality($8::TEXT[]))
+`
+
+type GetExpenseTotalsParams struct {
+	UserID     pgtype.UUID `json:"user_id"`
+	DateFrom   pgtype.Date `json:"date_from"`
+	DateTo     pgtype.Date `json:"date_to"`
+	CategoryID pgtype.UUID `json:"category_id"`
+	Query      pgtype.Text `json:"query"`
+	MinAmount  pgtype.Int8 `json:"min_amount"`
+	MaxAmount  pgtype.Int8 `json:"max_amount"`
+	Tags       []string    `json:"tags"`
+}
+
+type GetExpenseTotalsRow struct {
+	ExpenseCount int64 `json:"expense_count"`
+	TotalCents   int64 `json:"total_cents"`
+}
+
+// Count and sum of the expenses matching a listing filter, converted to the
+// user's base currency.
+func (q *Queries) GetExpenseTotals(ctx context.Context, arg GetExpenseTotalsParams) (GetExpenseTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getExpenseTotals,
+		arg.UserID,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.CategoryID,
+		arg.Query,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+	)
+	var i GetExpenseTotalsRow
+	err := row.Scan(&i.ExpenseCount, &i.TotalCents)
+	return i, err
+}
+
 const getExpensesByUser = `-- name: GetExpensesByUser :many
-SELECT id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at
+SELECT id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at, recurring_id, currency, rate_date, visibility
 FROM expenses
 WHERE user_id = $1
 ORDER BY expense_date DESC, created_at DESC
@@ -97,6 +296,10 @@ func (q *Queries) GetExpensesByUser(ctx context.Context, arg GetExpensesByUserPa
 			&i.ExpenseDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RecurringID,
+			&i.Currency,
+			&i.RateDate,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -109,25 +312,48 @@ func (q *Queries) GetExpensesByUser(ctx context.Context, arg GetExpensesByUserPa
 }
 
 const getExpensesByUserFiltered = `-- name: GetExpensesByUserFiltered :many
-SELECT id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at
-FROM expenses
-WHERE user_id = $1
-  AND (expense_date >= $4::DATE OR $4 IS NULL)
-  AND (expense_date <= $5::DATE OR $5 IS NULL)
-  AND (category_id = $6 OR $6 IS NULL)
-ORDER BY expense_date DESC, created_at DESC
+SELECT e.id, e.user_id, e.category_id, e.amount_cents, e.note, e.expense_date, e.created_at, e.updated_at, e.recurring_id, e.currency, e.rate_date, e.visibility
+FROM expenses e
+JOIN categories c ON c.id = e.category_id
+WHERE e.user_id = $1
+  AND (e.expense_date >= $4::DATE OR $4 IS NULL)
+  AND (e.expense_date <= $5::DATE OR $5 IS NULL)
+  AND (e.category_id = $6 OR $6 IS NULL)
+  AND ($7::TEXT IS NULL
+       OR search_document(e.note) @@ search_query($7)
+       OR search_document(c.name) @@ search_query($7))
+  AND (e.amount_cents >= $8::BIGINT OR $8 IS NULL)
+  AND (e.amount_cents <= $9::BIGINT OR $9 IS NULL)
+  AND ($10::TEXT[] IS NULL
+       OR (SELECT COUNT(*) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+           WHERE et.expense_id = e.id AND t.name = ANY($10::TEXT[])) = cardinality($10::TEXT[]))
+  AND ($11::UUID IS NULL
+       OR (e.expense_date, e.created_at, e.id) < ($12::DATE, $13::TIMESTAMPTZ, $11::UUID))
+ORDER BY
+    COALESCE(ts_rank(search_document(e.note), search_query($7))
+             + ts_rank(search_document(c.name), search_query($7)), 0) DESC,
+    e.expense_date DESC, e.created_at DESC, e.id DESC
 LIMIT $2 OFFSET $3
 `
 
 type GetExpensesByUserFilteredParams struct {
-	UserID     pgtype.UUID `json:"user_id"`
-	Limit      int32       `json:"limit"`
-	Offset     int32       `json:"offset"`
-	DateFrom   pgtype.Date `json:"date_from"`
-	DateTo     pgtype.Date `json:"date_to"`
-	CategoryID pgtype.UUID `json:"category_id"`
+	UserID          pgtype.UUID        `json:"user_id"`
+	Limit           int32              `json:"limit"`
+	Offset          int32              `json:"offset"`
+	DateFrom        pgtype.Date        `json:"date_from"`
+	DateTo          pgtype.Date        `json:"date_to"`
+	CategoryID      pgtype.UUID        `json:"category_id"`
+	Query           pgtype.Text        `json:"query"`
+	MinAmount       pgtype.Int8        `json:"min_amount"`
+	MaxAmount       pgtype.Int8        `json:"max_amount"`
+	Tags            []string           `json:"tags"`
+	BeforeID        pgtype.UUID        `json:"before_id"`
+	BeforeDate      pgtype.Date        `json:"before_date"`
+	BeforeCreatedAt pgtype.Timestamptz `json:"before_created_at"`
 }
 
+// With a search query, results are ranked by relevance first; keyset
+// pagination then no longer matches the ordering and callers page by offset.
 func (q *Queries) GetExpensesByUserFiltered(ctx context.Context, arg GetExpensesByUserFilteredParams) ([]Expense, error) {
 	rows, err := q.db.Query(ctx, getExpensesByUserFiltered,
 		arg.UserID,
@@ -136,6 +362,13 @@ func (q *Queries) GetExpensesByUserFiltered(ctx context.Context, arg GetExpenses
 		arg.DateFrom,
 		arg.DateTo,
 		arg.CategoryID,
+		arg.Query,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+		arg.BeforeID,
+		arg.BeforeDate,
+		arg.BeforeCreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -153,6 +386,10 @@ func (q *Queries) GetExpensesByUserFiltered(ctx context.Context, arg GetExpenses
 			&i.ExpenseDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RecurringID,
+			&i.Currency,
+			&i.RateDate,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -164,11 +401,119 @@ func (q *Queries) GetExpensesByUserFiltered(ctx context.Context, arg GetExpenses
 	return items, nil
 }
 
+const getExpensesForExport = `-- name: GetExpensesForExport :many
+SELECT
+    e.id,
+    e.category_id,
+    c.name AS category_name,
+    c.icon AS category_icon,
+    e.amount_cents,
+    e.note,
+    e.expense_date,
+    e.created_at,
+    e.currency
+FROM expenses e
+JOIN categories c ON c.id = e.category_id
+WHERE e.user_id = $1
+  AND (e.expense_date >= $3::DATE OR $3 IS NULL)
+  AND (e.expense_date <= $4::DATE OR $4 IS NULL)
+  AND (e.category_id = $5 OR $5 IS NULL)
+  AND ($6::UUID IS NULL
+       OR (e.expense_date, e.created_at, e.id) > ($7::DATE, $8::TIMESTAMPTZ, $6::UUID))
+ORDER BY e.expense_date, e.created_at, e.id
+LIMIT $2
+`
+
+type GetExpensesForExportParams struct {
+	UserID         pgtype.UUID        `json:"user_id"`
+	Limit          int32              `json:"limit"`
+	DateFrom       pgtype.Date        `json:"date_from"`
+	DateTo         pgtype.Date        `json:"date_to"`
+	CategoryID     pgtype.UUID        `json:"category_id"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	AfterDate      pgtype.Date        `json:"after_date"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+}
+
+type GetExpensesForExportRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	CategoryID   pgtype.UUID        `json:"category_id"`
+	CategoryName string             `json:"category_name"`
+	CategoryIcon string             `json:"category_icon"`
+	AmountCents  int64              `json:"amount_cents"`
+	Note         string             `json:"note"`
+	ExpenseDate  pgtype.Date        `json:"expense_date"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	Currency     string             `json:"currency"`
+}
+
+// Keyset-paginated in ascending order so exports can stream in batches.
+func (q *Queries) GetExpensesForExport(ctx context.Context, arg GetExpensesForExportParams) ([]GetExpensesForExportRow, error) {
+	rows, err := q.db.Query(ctx, getExpensesForExport,
+		arg.UserID,
+		arg.Limit,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.CategoryID,
+		arg.AfterID,
+		arg.AfterDate,
+		arg.AfterCreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetExpensesForExportRow
+	for rows.Next() {
+		var i GetExpensesForExportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.CategoryName,
+			&i.CategoryIcon,
+			&i.AmountCents,
+			&i.Note,
+			&i.ExpenseDate,
+			&i.CreatedAt,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reassignExpenseCategory = `-- name: ReassignExpenseCategory :execrows
+UPDATE expenses
+SET category_id = $1, updated_at = NOW()
+WHERE category_id = $2 AND user_id = $3
+`
+
+type ReassignExpenseCategoryParams struct {
+	TargetID   pgtype.UUID `json:"target_id"`
+	CategoryID pgtype.UUID `json:"category_id"`
+	UserID     pgtype.UUID `json:"user_id"`
+}
+
+func (q *Queries) ReassignExpenseCategory(ctx context.Context, arg ReassignExpenseCategoryParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reassignExpenseCategory, arg.TargetID, arg.CategoryID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const updateExpense = `-- name: UpdateExpense :one
 UPDATE expenses
-SET category_id = $3, amount_cents = $4, note = $5, expense_date = $6, updated_at = NOW()
+SET category_id = $3, amount_cents = $4, note = $5, expense_date = $6,
+    currency = COALESCE($7, currency),
+    visibility = COALESCE($8, visibility), updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at
+RETURNING id, user_id, category_id, amount_cents, note, expense_date, created_at, updated_at, recurring_id, currency, rate_date, visibility
 `
 
 type UpdateExpenseParams struct {
@@ -178,6 +523,8 @@ type UpdateExpenseParams struct {
 	AmountCents int64       `json:"amount_cents"`
 	Note        string      `json:"note"`
 	ExpenseDate pgtype.Date `json:"expense_date"`
+	Currency    pgtype.Text `json:"currency"`
+	Visibility  pgtype.Text `json:"visibility"`
 }
 
 func (q *Queries) UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (Expense, error) {
@@ -188,6 +535,8 @@ func (q *Queries) UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (E
 		arg.AmountCents,
 		arg.Note,
 		arg.ExpenseDate,
+		arg.Currency,
+		arg.Visibility,
 	)
 	var i Expense
 	err := row.Scan(
@@ -199,6 +548,10 @@ func (q *Queries) UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (E
 		&i.ExpenseDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RecurringID,
+		&i.Currency,
+		&i.RateDate,
+		&i.Visibility,
 	)
 	return i, err
 }
@@ -39,7 +39,7 @@ func (q *Queries) AddFamilyMember(ctx context.Context, arg AddFamilyMemberParams
 const createFamily = `-- name: CreateFamily :one
 INSERT INTO families (name, admin_user_id)
 VALUES ($1, $2)
-RETURNING id, name, admin_user_id, created_at, updated_at
+RETURNING id, name, admin_user_id, created_at, updated_at, base_currency, private_expenses
 `
 
 type CreateFamilyParams struct {
@@ -56,6 +56,8 @@ func (q *Queries) CreateFamily(ctx context.Context, arg CreateFamilyParams) (Fam
 		&i.AdminUserID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.BaseCurrency,
+		&i.PrivateExpenses,
 	)
 	return i, err
 }
@@ -78,22 +80,68 @@ func (q *Queries) DeleteFamily(ctx context.Context, arg DeleteFamilyParams) (int
 	return result.RowsAffected(), nil
 }
 
-const getFamilyByUserID = `-- name: GetFamilyByUserID :one
-SELECT f.id, f.name, f.admin_user_id, f.created_at, f.updated_at
+const getActiveFamily = `-- name: GetActiveFamily :one
+SELECT f.id, f.name, f.admin_user_id, f.created_at, f.updated_at, f.base_currency, f.private_expenses, fm.role
 FROM families f
 JOIN family_members fm ON fm.family_id = f.id
+JOIN users u ON u.id = fm.user_id
 WHERE fm.user_id = $1
+ORDER BY (f.id = u.active_family_id) IS TRUE DESC, fm.joined_at, f.id
+LIMIT 1
 `
 
-func (q *Queries) GetFamilyByUserID(ctx context.Context, userID pgtype.UUID) (Family, error) {
-	row := q.db.QueryRow(ctx, getFamilyByUserID, userID)
-	var i Family
+type GetActiveFamilyRow struct {
+	Family Family `json:"family"`
+	Role   string `json:"role"`
+}
+
+// The user's preferred family, or their earliest joined family when no
+// preference is set or they have left the preferred one.
+func (q *Queries) GetActiveFamily(ctx context.Context, userID pgtype.UUID) (GetActiveFamilyRow, error) {
+	row := q.db.QueryRow(ctx, getActiveFamily, userID)
+	var i GetActiveFamilyRow
 	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.AdminUserID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.Family.ID,
+		&i.Family.Name,
+		&i.Family.AdminUserID,
+		&i.Family.CreatedAt,
+		&i.Family.UpdatedAt,
+		&i.Family.BaseCurrency,
+		&i.Family.PrivateExpenses,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getFamilyForMember = `-- name: GetFamilyForMember :one
+SELECT f.id, f.name, f.admin_user_id, f.created_at, f.updated_at, f.base_currency, f.private_expenses, fm.role
+FROM families f
+JOIN family_members fm ON fm.family_id = f.id
+WHERE f.id = $1 AND fm.user_id = $2
+`
+
+type GetFamilyForMemberParams struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+type GetFamilyForMemberRow struct {
+	Family Family `json:"family"`
+	Role   string `json:"role"`
+}
+
+func (q *Queries) GetFamilyForMember(ctx context.Context, arg GetFamilyForMemberParams) (GetFamilyForMemberRow, error) {
+	row := q.db.QueryRow(ctx, getFamilyForMember, arg.ID, arg.UserID)
+	var i GetFamilyForMemberRow
+	err := row.Scan(
+		&i.Family.ID,
+		&i.Family.Name,
+		&i.Family.AdminUserID,
+		&i.Family.CreatedAt,
+		&i.Family.UpdatedAt,
+		&i.Family.BaseCurrency,
+		&i.Family.PrivateExpenses,
+		&i.Role,
 	)
 	return i, err
 }
@@ -151,9 +199,61 @@ func (q *Queries) GetFamilyMembers(ctx context.Context, familyID pgtype.UUID) ([
 	return items, nil
 }
 
+const getUserFamilies = `-- name: GetUserFamilies :many
+SELECT f.id, f.name, f.admin_user_id, f.created_at, f.updated_at, f.base_currency, f.private_expenses, fm.role
+FROM families f
+JOIN family_members fm ON fm.family_id = f.id
+WHERE fm.user_id = $1
+ORDER BY fm.joined_at, f.id
+`
+
+type GetUserFamiliesRow struct {
+	Family Family `json:"family"`
+	Role   string `json:"role"`
+}
+
+func (q *Queries) GetUserFamilies(ctx context.Context, userID pgtype.UUID) ([]GetUserFamiliesRow, error) {
+	rows, err := q.db.Query(ctx, getUserFamilies, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserFamiliesRow
+	for rows.Next() {
+		var i GetUserFamiliesRow
+		if err := rows.Scan(
+			&i.Family.ID,
+			&i.Family.Name,
+			&i.Family.AdminUserID,
+			&i.Family.CreatedAt,
+			&i.Family.UpdatedAt,
+			&i.Family.BaseCurrency,
+			&i.Family.PrivateExpenses,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockFamily = `-- name: LockFamily :exec
+SELECT id FROM families WHERE id = $1 FOR UPDATE
+`
+
+// Serializes membership changes of a family until the transaction ends.
+func (q *Queries) LockFamily(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, lockFamily, id)
+	return err
+}
+
 const removeFamilyMember = `-- name: RemoveFamilyMember :execrows
 DELETE FROM family_members
-WHERE family_id = $1 AND user_id = $2 AND role != 'admin'
+WHERE family_id = $1 AND user_id = $2 AND role != 'owner'
 `
 
 type RemoveFamilyMemberParams struct {
@@ -168,3 +268,98 @@ func (q *Queries) RemoveFamilyMember(ctx context.Context, arg RemoveFamilyMember
 	}
 	return result.RowsAffected(), nil
 }
+
+const setActiveFamily = `-- name: SetActiveFamily :execrows
+UPDATE users SET active_family_id = $2, updated_at = NOW()
+WHERE id = $1
+  AND EXISTS (SELECT 1 FROM family_members WHERE family_id = $2 AND user_id = $1)
+`
+
+type SetActiveFamilyParams struct {
+	ID             pgtype.UUID `json:"id"`
+	ActiveFamilyID pgtype.UUID `json:"active_family_id"`
+}
+
+// Only succeeds for families the user belongs to.
+func (q *Queries) SetActiveFamily(ctx context.Context, arg SetActiveFamilyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setActiveFamily, arg.ID, arg.ActiveFamilyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setFamilyBaseCurrency = `-- name: SetFamilyBaseCurrency :execrows
+UPDATE families SET base_currency = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFamilyBaseCurrencyParams struct {
+	ID           pgtype.UUID `json:"id"`
+	BaseCurrency string      `json:"base_currency"`
+}
+
+func (q *Queries) SetFamilyBaseCurrency(ctx context.Context, arg SetFamilyBaseCurrencyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setFamilyBaseCurrency, arg.ID, arg.BaseCurrency)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setFamilyMemberRole = `-- name: SetFamilyMemberRole :execrows
+UPDATE family_members SET role = $3
+WHERE family_id = $1 AND user_id = $2
+`
+
+type SetFamilyMemberRoleParams struct {
+	FamilyID pgtype.UUID `json:"family_id"`
+	UserID   pgtype.UUID `json:"user_id"`
+	Role     string      `json:"role"`
+}
+
+func (q *Queries) SetFamilyMemberRole(ctx context.Context, arg SetFamilyMemberRoleParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setFamilyMemberRole, arg.FamilyID, arg.UserID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setFamilyOwner = `-- name: SetFamilyOwner :execrows
+UPDATE families SET admin_user_id = $3, updated_at = NOW()
+WHERE id = $1 AND admin_user_id = $2
+`
+
+type SetFamilyOwnerParams struct {
+	ID          pgtype.UUID `json:"id"`
+	AdminUserID pgtype.UUID `json:"admin_user_id"`
+	NewOwnerID  pgtype.UUID `json:"new_owner_id"`
+}
+
+// Guarded by the current owner so concurrent transfers cannot both succeed.
+func (q *Queries) SetFamilyOwner(ctx context.Context, arg SetFamilyOwnerParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setFamilyOwner, arg.ID, arg.AdminUserID, arg.NewOwnerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setFamilyPrivateExpenses = `-- name: SetFamilyPrivateExpenses :execrows
+UPDATE families SET private_expenses = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFamilyPrivateExpensesParams struct {
+	ID              pgtype.UUID `json:"id"`
+	PrivateExpenses string      `json:"private_expenses"`
+}
+
+func (q *Queries) SetFamilyPrivateExpenses(ctx context.Context, arg SetFamilyPrivateExpensesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setFamilyPrivateExpenses, arg.ID, arg.PrivateExpenses)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -11,21 +11,94 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const getFamilyCategoryRollupTotals = `-- name: GetFamilyCategoryRollupTotals :many
+SELECT
+    r.id AS category_id,
+    COALESCE(r.name, '')::TEXT AS category_name,
+    COALESCE(r.color, '')::TEXT AS category_color,
+    COALESCE(r.icon, '')::TEXT AS category_icon,
+    SUM(convert_cents(e.amount_cents, e.currency, f.base_currency, e.rate_date))::BIGINT AS total_cents,
+    COUNT(*)::INT AS expense_count
+FROM expenses e
+JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
+LEFT JOIN category_mappings cm ON cm.family_id = fm.family_id AND cm.category_id = e.category_id
+LEFT JOIN categories c ON c.id = COALESCE(cm.family_category_id, e.category_id) AND e.visibility = 'family'
+LEFT JOIN categories r ON r.id = COALESCE(c.parent_id, c.id)
+WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
+  AND e.expense_date >= $2
+  AND e.expense_date <= $3
+  AND (e.visibility = 'family' OR f.private_expenses = 'aggregate')
+GROUP BY r.id, r.name, r.color, r.icon
+ORDER BY total_cents DESC
+`
+
+type GetFamilyCategoryRollupTotalsParams struct {
+	FamilyID      pgtype.UUID `json:"family_id"`
+	ExpenseDate   pgtype.Date `json:"expense_date"`
+	ExpenseDate_2 pgtype.Date `json:"expense_date_2"`
+}
+
+type GetFamilyCategoryRollupTotalsRow struct {
+	CategoryID    pgtype.UUID `json:"category_id"`
+	CategoryName  string      `json:"category_name"`
+	CategoryColor string      `json:"category_color"`
+	CategoryIcon  string      `json:"category_icon"`
+	TotalCents    int64       `json:"total_cents"`
+	ExpenseCount  int32       `json:"expense_count"`
+}
+
+// Totals per top-level category, counting the expenses of its
+// subcategories along with its own. Categories are mapped and private
+// expenses aggregated as in GetFamilyCategoryTotals.
+func (q *Queries) GetFamilyCategoryRollupTotals(ctx context.Context, arg GetFamilyCategoryRollupTotalsParams) ([]GetFamilyCategoryRollupTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getFamilyCategoryRollupTotals, arg.FamilyID, arg.ExpenseDate, arg.ExpenseDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFamilyCategoryRollupTotalsRow
+	for rows.Next() {
+		var i GetFamilyCategoryRollupTotalsRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.CategoryName,
+			&i.CategoryColor,
+			&i.CategoryIcon,
+			&i.TotalCents,
+			&i.ExpenseCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFamilyCategoryTotals = `-- name: GetFamilyCategoryTotals :many
 SELECT
-    e.category_id,
-    c.name AS category_name,
-    c.color AS category_color,
-    c.icon AS category_icon,
-    SUM(e.amount_cents)::BIGINT AS total_cents,
+    c.id AS category_id,
+    COALESCE(c.name, '')::TEXT AS category_name,
+    COALESCE(c.color, '')::TEXT AS category_color,
+    COALESCE(c.icon, '')::TEXT AS category_icon,
+    c.parent_id,
+    SUM(convert_cents(e.amount_cents, e.currency, f.base_currency, e.rate_date))::BIGINT AS total_cents,
     COUNT(*)::INT AS expense_count
 FROM expenses e
 JOIN family_members fm ON fm.user_id = e.user_id
-JOIN categories c ON c.id = e.category_id
+JOIN families f ON f.id = fm.family_id
+LEFT JOIN category_mappings cm ON cm.family_id = fm.family_id AND cm.category_id = e.category_id
+LEFT JOIN categories c ON c.id = COALESCE(cm.family_category_id, e.category_id) AND e.visibility = 'family'
 WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
   AND e.expense_date >= $2
   AND e.expense_date <= $3
-GROUP BY e.category_id, c.name, c.color, c.icon
+  AND (e.visibility = 'family' OR f.private_expenses = 'aggregate')
+GROUP BY c.id, c.name, c.color, c.icon, c.parent_id
 ORDER BY total_cents DESC
 `
 
@@ -40,10 +113,14 @@ type GetFamilyCategoryTotalsRow struct {
 	CategoryName  string      `json:"category_name"`
 	CategoryColor string      `json:"category_color"`
 	CategoryIcon  string      `json:"category_icon"`
+	ParentID      pgtype.UUID `json:"parent_id"`
 	TotalCents    int64       `json:"total_cents"`
 	ExpenseCount  int32       `json:"expense_count"`
 }
 
+// Personal categories that members mapped onto a family category are
+// counted under the family category. Aggregated private expenses are
+// counted in a single row without a category.
 func (q *Queries) GetFamilyCategoryTotals(ctx context.Context, arg GetFamilyCategoryTotalsParams) ([]GetFamilyCategoryTotalsRow, error) {
 	rows, err := q.db.Query(ctx, getFamilyCategoryTotals, arg.FamilyID, arg.ExpenseDate, arg.ExpenseDate_2)
 	if err != nil {
@@ -58,6 +135,7 @@ func (q *Queries) GetFamilyCategoryTotals(ctx context.Context, arg GetFamilyCate
 			&i.CategoryName,
 			&i.CategoryColor,
 			&i.CategoryIcon,
+			&i.ParentID,
 			&i.TotalCents,
 			&i.ExpenseCount,
 		); err != nil {
@@ -71,32 +149,160 @@ func (q *Queries) GetFamilyCategoryTotals(ctx context.Context, arg GetFamilyCate
 	return items, nil
 }
 
+const getFamilyExpenseByID = `-- name: GetFamilyExpenseByID :one
+SELECT e.id, e.user_id, e.amount_cents, e.currency
+FROM expenses e
+JOIN family_members fm ON fm.user_id = e.user_id
+WHERE e.id = $1 AND fm.family_id = $2 AND fm.role <> 'viewer'
+  AND e.visibility = 'family'
+`
+
+type GetFamilyExpenseByIDParams struct {
+	ID       pgtype.UUID `json:"id"`
+	FamilyID pgtype.UUID `json:"family_id"`
+}
+
+type GetFamilyExpenseByIDRow struct {
+	ID          pgtype.UUID `json:"id"`
+	UserID      pgtype.UUID `json:"user_id"`
+	AmountCents int64       `json:"amount_cents"`
+	Currency    string      `json:"currency"`
+}
+
+// Private expenses are not shared with the family and cannot be split.
+func (q *Queries) GetFamilyExpenseByID(ctx context.Context, arg GetFamilyExpenseByIDParams) (GetFamilyExpenseByIDRow, error) {
+	row := q.db.QueryRow(ctx, getFamilyExpenseByID, arg.ID, arg.FamilyID)
+	var i GetFamilyExpenseByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AmountCents,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const getFamilyExpenseTotals = `-- name: GetFamilyExpenseTotals :one
+SELECT
+    COUNT(*)::BIGINT AS expense_count,
+    COALESCE(SUM(convert_cents(e.amount_cents, e.currency, f.base_currency, e.rate_date)), 0)::BIGINT AS total_cents
+FROM expenses e
+JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
+LEFT JOIN categories c ON c.id = e.category_id AND e.visibility = 'family'
+WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
+  AND (e.expense_date >= $2::DATE OR $2 IS NULL)
+  AND (e.expense_date <= $3::DATE OR $3 IS NULL)
+  AND (e.category_id = $4 OR $4 IS NULL)
+  AND ($5::TEXT IS NULL
+       OR search_document(e.note) @@ search_query($5)
+       OR search_document(c.name) @@ search_query($5))
+  AND (e.amount_cents >= $6::BIGINT OR $6 IS NULL)
+  AND (e.amount_cents <= $7::BIGINT OR $7 IS NULL)
+  AND ($8::TEXT[] IS NULL
+       OR (SELECT COUNT(*) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+           WHERE et.expense_id = e.id AND t.name = ANY($8::TEXT[])) = cardinality($8::TEXT[]))
+  AND (e.visibility = 'family'
+       OR (f.private_expenses = 'aggregate' AND $4::UUID IS NULL AND $5::TEXT IS NULL
+           AND $8::TEXT[] IS NULL))
+`
+
+type GetFamilyExpenseTotalsParams struct {
+	FamilyID   pgtype.UUID `json:"family_id"`
+	DateFrom   pgtype.Date `json:"date_from"`
+	DateTo     pgtype.Date `json:"date_to"`
+	CategoryID pgtype.UUID `json:"category_id"`
+	Query      pgtype.Text `json:"query"`
+	MinAmount  pgtype.Int8 `json:"min_amount"`
+	MaxAmount  pgtype.Int8 `json:"max_amount"`
+	Tags       []string    `json:"tags"`
+}
+
+type GetFamilyExpenseTotalsRow struct {
+	ExpenseCount int64 `json:"expense_count"`
+	TotalCents   int64 `json:"total_cents"`
+}
+
+// Count and sum of the family expenses matching a feed filter, converted to
+// the family's base currency. Private expenses are counted like in
+// GetFamilyExpenses.
+func (q *Queries) GetFamilyExpenseTotals(ctx context.Context, arg GetFamilyExpenseTotalsParams) (GetFamilyExpenseTotalsRow, error) {
+	row := q.db.QueryRow(ctx, getFamilyExpenseTotals,
+		arg.FamilyID,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.CategoryID,
+		arg.Query,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+	)
+	var i GetFamilyExpenseTotalsRow
+	err := row.Scan(&i.ExpenseCount, &i.TotalCents)
+	return i, err
+}
+
 const getFamilyExpenses = `-- name: GetFamilyExpenses :many
 SELECT
     e.id,
     e.user_id,
     u.email AS user_email,
-    e.category_id,
-    c.name AS category_name,
-    c.color AS category_color,
-    c.icon AS category_icon,
+    c.id AS category_id,
+    COALESCE(c.name, '')::TEXT AS category_name,
+    COALESCE(c.color, '')::TEXT AS category_color,
+    COALESCE(c.icon, '')::TEXT AS category_icon,
     e.amount_cents,
-    e.note,
+    (CASE WHEN e.visibility = 'family' THEN e.note ELSE '' END)::TEXT AS note,
     e.expense_date,
-    e.created_at
+    e.created_at,
+    e.currency,
+    EXISTS (SELECT 1 FROM expense_splits s WHERE s.expense_id = e.id) AS is_split,
+    e.visibility = 'private' AS is_private
 FROM expenses e
 JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
 JOIN users u ON u.id = e.user_id
-JOIN categories c ON c.id = e.category_id
+LEFT JOIN categories c ON c.id = e.category_id AND e.visibility = 'family'
 WHERE fm.family_id = $1
-ORDER BY e.expense_date DESC, e.created_at DESC
+  AND fm.role <> 'viewer'
+  AND (e.expense_date >= $4::DATE OR $4 IS NULL)
+  AND (e.expense_date <= $5::DATE OR $5 IS NULL)
+  AND (e.category_id = $6 OR $6 IS NULL)
+  AND ($7::TEXT IS NULL
+       OR search_document(e.note) @@ search_query($7)
+       OR search_document(c.name) @@ search_query($7))
+  AND (e.amount_cents >= $8::BIGINT OR $8 IS NULL)
+  AND (e.amount_cents <= $9::BIGINT OR $9 IS NULL)
+  AND ($10::TEXT[] IS NULL
+       OR (SELECT COUNT(*) FROM expense_tags et JOIN tags t ON t.id = et.tag_id
+           WHERE et.expense_id = e.id AND t.name = ANY($10::TEXT[])) = cardinality($10::TEXT[]))
+  AND (e.visibility = 'family'
+       OR (f.private_expenses = 'aggregate' AND $6::UUID IS NULL AND $7::TEXT IS NULL
+           AND $10::TEXT[] IS NULL))
+  AND ($11::UUID IS NULL
+       OR (e.expense_date, e.created_at, e.id) < ($12::DATE, $13::TIMESTAMPTZ, $11::UUID))
+ORDER BY
+    COALESCE(ts_rank(search_document(e.note), search_query($7))
+             + ts_rank(search_document(c.name), search_query($7)), 0) DESC,
+    e.expense_date DESC, e.created_at DESC, e.id DESC
 LIMIT $2 OFFSET $3
 `
 
 type GetFamilyExpensesParams struct {
-	FamilyID pgtype.UUID `json:"family_id"`
-	Limit    int32       `json:"limit"`
-	Offset   int32       `json:"offset"`
+	FamilyID        pgtype.UUID        `json:"family_id"`
+	Limit           int32              `json:"limit"`
+	Offset          int32              `json:"offset"`
+	DateFrom        pgtype.Date        `json:"date_from"`
+	DateTo          pgtype.Date        `json:"date_to"`
+	CategoryID      pgtype.UUID        `json:"category_id"`
+	Query           pgtype.Text        `json:"query"`
+	MinAmount       pgtype.Int8        `json:"min_amount"`
+	MaxAmount       pgtype.Int8        `json:"max_amount"`
+	Tags            []string           `json:"tags"`
+	BeforeID        pgtype.UUID        `json:"before_id"`
+	BeforeDate      pgtype.Date        `json:"before_date"`
+	BeforeCreatedAt pgtype.Timestamptz `json:"before_created_at"`
 }
 
 type GetFamilyExpensesRow struct {
@@ -111,10 +317,32 @@ type GetFamilyExpensesRow struct {
 	Note          string             `json:"note"`
 	ExpenseDate   pgtype.Date        `json:"expense_date"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Currency      string             `json:"currency"`
+	IsSplit       bool               `json:"is_split"`
+	IsPrivate     bool               `json:"is_private"`
 }
 
+// Ranked by relevance first when a search query is given, like
+// GetExpensesByUserFiltered.
+// Private expenses only appear when the family aggregates them, and then
+// without their category or note; filtering by category or tag, or
+// searching, leaves them out.
 func (q *Queries) GetFamilyExpenses(ctx context.Context, arg GetFamilyExpensesParams) ([]GetFamilyExpensesRow, error) {
-	rows, err := q.db.Query(ctx, getFamilyExpenses, arg.FamilyID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, getFamilyExpenses,
+		arg.FamilyID,
+		arg.Limit,
+		arg.Offset,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.CategoryID,
+		arg.Query,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Tags,
+		arg.BeforeID,
+		arg.BeforeDate,
+		arg.BeforeCreatedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -134,6 +362,110 @@ func (q *Queries) GetFamilyExpenses(ctx context.Context, arg GetFamilyExpensesPa
 			&i.Note,
 			&i.ExpenseDate,
 			&i.CreatedAt,
+			&i.Currency,
+			&i.IsSplit,
+			&i.IsPrivate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFamilyExpensesForExport = `-- name: GetFamilyExpensesForExport :many
+SELECT
+    e.id,
+    e.user_id,
+    u.email AS user_email,
+    c.id AS category_id,
+    COALESCE(c.name, '')::TEXT AS category_name,
+    COALESCE(c.color, '')::TEXT AS category_color,
+    COALESCE(c.icon, '')::TEXT AS category_icon,
+    e.amount_cents,
+    (CASE WHEN e.visibility = 'family' THEN e.note ELSE '' END)::TEXT AS note,
+    e.expense_date,
+    e.created_at,
+    e.currency
+FROM expenses e
+JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
+JOIN users u ON u.id = e.user_id
+LEFT JOIN categories c ON c.id = e.category_id AND e.visibility = 'family'
+WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
+  AND (e.expense_date >= $3::DATE OR $3 IS NULL)
+  AND (e.expense_date <= $4::DATE OR $4 IS NULL)
+  AND (e.category_id = $5 OR $5 IS NULL)
+  AND (e.visibility = 'family' OR (f.private_expenses = 'aggregate' AND $5::UUID IS NULL))
+  AND ($6::UUID IS NULL
+       OR (e.expense_date, e.created_at, e.id) > ($7::DATE, $8::TIMESTAMPTZ, $6::UUID))
+ORDER BY e.expense_date, e.created_at, e.id
+LIMIT $2
+`
+
+type GetFamilyExpensesForExportParams struct {
+	FamilyID       pgtype.UUID        `json:"family_id"`
+	Limit          int32              `json:"limit"`
+	DateFrom       pgtype.Date        `json:"date_from"`
+	DateTo         pgtype.Date        `json:"date_to"`
+	CategoryID     pgtype.UUID        `json:"category_id"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	AfterDate      pgtype.Date        `json:"after_date"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+}
+
+type GetFamilyExpensesForExportRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	UserID        pgtype.UUID        `json:"user_id"`
+	UserEmail     string             `json:"user_email"`
+	CategoryID    pgtype.UUID        `json:"category_id"`
+	CategoryName  string             `json:"category_name"`
+	CategoryColor string             `json:"category_color"`
+	CategoryIcon  string             `json:"category_icon"`
+	AmountCents   int64              `json:"amount_cents"`
+	Note          string             `json:"note"`
+	ExpenseDate   pgtype.Date        `json:"expense_date"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Currency      string             `json:"currency"`
+}
+
+// Keyset-paginated in ascending order so exports can stream in batches.
+// Private expenses are included like in GetFamilyExpenses.
+func (q *Queries) GetFamilyExpensesForExport(ctx context.Context, arg GetFamilyExpensesForExportParams) ([]GetFamilyExpensesForExportRow, error) {
+	rows, err := q.db.Query(ctx, getFamilyExpensesForExport,
+		arg.FamilyID,
+		arg.Limit,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.CategoryID,
+		arg.AfterID,
+		arg.AfterDate,
+		arg.AfterCreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFamilyExpensesForExportRow
+	for rows.Next() {
+		var i GetFamilyExpensesForExportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.UserEmail,
+			&i.CategoryID,
+			&i.CategoryName,
+			&i.CategoryColor,
+			&i.CategoryIcon,
+			&i.AmountCents,
+			&i.Note,
+			&i.ExpenseDate,
+			&i.CreatedAt,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -145,18 +477,45 @@ func (q *Queries) GetFamilyExpenses(ctx context.Context, arg GetFamilyExpensesPa
 	return items, nil
 }
 
+const getFamilyIncomeTotal = `-- name: GetFamilyIncomeTotal :one
+SELECT COALESCE(SUM(convert_cents(i.amount_cents, i.currency, f.base_currency, i.rate_date)), 0)::BIGINT AS total_cents
+FROM incomes i
+JOIN family_members fm ON fm.user_id = i.user_id
+JOIN families f ON f.id = fm.family_id
+WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
+  AND i.income_date >= $2
+  AND i.income_date <= $3
+`
+
+type GetFamilyIncomeTotalParams struct {
+	FamilyID     pgtype.UUID `json:"family_id"`
+	IncomeDate   pgtype.Date `json:"income_date"`
+	IncomeDate_2 pgtype.Date `json:"income_date_2"`
+}
+
+func (q *Queries) GetFamilyIncomeTotal(ctx context.Context, arg GetFamilyIncomeTotalParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getFamilyIncomeTotal, arg.FamilyID, arg.IncomeDate, arg.IncomeDate_2)
+	var total_cents int64
+	err := row.Scan(&total_cents)
+	return total_cents, err
+}
+
 const getFamilyMemberTotals = `-- name: GetFamilyMemberTotals :many
 SELECT
     e.user_id,
     u.email AS user_email,
-    SUM(e.amount_cents)::BIGINT AS total_cents,
+    SUM(convert_cents(e.amount_cents, e.currency, f.base_currency, e.rate_date))::BIGINT AS total_cents,
     COUNT(*)::INT AS expense_count
 FROM expenses e
 JOIN family_members fm ON fm.user_id = e.user_id
+JOIN families f ON f.id = fm.family_id
 JOIN users u ON u.id = e.user_id
 WHERE fm.family_id = $1
+  AND fm.role <> 'viewer'
   AND e.expense_date >= $2
   AND e.expense_date <= $3
+  AND (e.visibility = 'family' OR f.private_expenses = 'aggregate')
 GROUP BY e.user_id, u.email
 ORDER BY total_cents DESC
 `
@@ -174,6 +533,7 @@ type GetFamilyMemberTotalsRow struct {
 	ExpenseCount int32       `json:"expense_count"`
 }
 
+// Private expenses are counted only when the family aggregates them.
 func (q *Queries) GetFamilyMemberTotals(ctx context.Context, arg GetFamilyMemberTotalsParams) ([]GetFamilyMemberTotalsRow, error) {
 	rows, err := q.db.Query(ctx, getFamilyMemberTotals, arg.FamilyID, arg.ExpenseDate, arg.ExpenseDate_2)
 	if err != nil {
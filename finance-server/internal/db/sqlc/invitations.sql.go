@@ -13,7 +13,7 @@ import (
 
 const acceptInvitation = `-- name: AcceptInvitation :execrows
 UPDATE family_invitations SET status = 'accepted'
-WHERE id = $1 AND status = 'pending'
+WHERE id = $1 AND status = 'pending' AND expires_at > NOW()
 `
 
 func (q *Queries) AcceptInvitation(ctx context.Context, id pgtype.UUID) (int64, error) {
@@ -25,9 +25,9 @@ func (q *Queries) AcceptInvitation(ctx context.Context, id pgtype.UUID) (int64,
 }
 
 const createInvitation = `-- name: CreateInvitation :one
-INSERT INTO family_invitations (family_id, inviter_user_id, token_hash, expires_at)
-VALUES ($1, $2, $3, $4)
-RETURNING id, family_id, inviter_user_id, token_hash, status, expires_at, created_at
+INSERT INTO family_invitations (family_id, inviter_user_id, token_hash, expires_at, email)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, family_id, inviter_user_id, token_hash, status, expires_at, created_at, email
 `
 
 type CreateInvitationParams struct {
@@ -35,6 +35,7 @@ type CreateInvitationParams struct {
 	InviterUserID pgtype.UUID        `json:"inviter_user_id"`
 	TokenHash     string             `json:"token_hash"`
 	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	Email         pgtype.Text        `json:"email"`
 }
 
 func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationParams) (FamilyInvitation, error) {
@@ -43,6 +44,7 @@ func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationPara
 		arg.InviterUserID,
 		arg.TokenHash,
 		arg.ExpiresAt,
+		arg.Email,
 	)
 	var i FamilyInvitation
 	err := row.Scan(
@@ -53,12 +55,62 @@ func (q *Queries) CreateInvitation(ctx context.Context, arg CreateInvitationPara
 		&i.Status,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.Email,
+	)
+	return i, err
+}
+
+const expireInvitations = `-- name: ExpireInvitations :execrows
+UPDATE family_invitations SET status = 'expired'
+WHERE status = 'pending' AND expires_at <= NOW()
+`
+
+func (q *Queries) ExpireInvitations(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, expireInvitations)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getInvitationByID = `-- name: GetInvitationByID :one
+SELECT fi.id, fi.family_id, fi.inviter_user_id, fi.token_hash, fi.status, fi.expires_at, fi.created_at, fi.email, f.name as family_name
+FROM family_invitations fi
+JOIN families f ON f.id = fi.family_id
+WHERE fi.id = $1 AND fi.status = 'pending' AND fi.expires_at > NOW()
+`
+
+type GetInvitationByIDRow struct {
+	ID            pgtype.UUID        `json:"id"`
+	FamilyID      pgtype.UUID        `json:"family_id"`
+	InviterUserID pgtype.UUID        `json:"inviter_user_id"`
+	TokenHash     string             `json:"token_hash"`
+	Status        string             `json:"status"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Email         pgtype.Text        `json:"email"`
+	FamilyName    string             `json:"family_name"`
+}
+
+func (q *Queries) GetInvitationByID(ctx context.Context, id pgtype.UUID) (GetInvitationByIDRow, error) {
+	row := q.db.QueryRow(ctx, getInvitationByID, id)
+	var i GetInvitationByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.FamilyID,
+		&i.InviterUserID,
+		&i.TokenHash,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+		&i.Email,
+		&i.FamilyName,
 	)
 	return i, err
 }
 
 const getInvitationByTokenHash = `-- name: GetInvitationByTokenHash :one
-SELECT fi.id, fi.family_id, fi.inviter_user_id, fi.token_hash, fi.status, fi.expires_at, fi.created_at, f.name as family_name
+SELECT fi.id, fi.family_id, fi.inviter_user_id, fi.token_hash, fi.status, fi.expires_at, fi.created_at, fi.email, f.name as family_name
 FROM family_invitations fi
 JOIN families f ON f.id = fi.family_id
 WHERE fi.token_hash = $1 AND fi.status = 'pending' AND fi.expires_at > NOW()
@@ -72,6 +124,7 @@ type GetInvitationByTokenHashRow struct {
 	Status        string             `json:"status"`
 	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Email         pgtype.Text        `json:"email"`
 	FamilyName    string             `json:"family_name"`
 }
 
@@ -86,13 +139,14 @@ func (q *Queries) GetInvitationByTokenHash(ctx context.Context, tokenHash string
 		&i.Status,
 		&i.ExpiresAt,
 		&i.CreatedAt,
+		&i.Email,
 		&i.FamilyName,
 	)
 	return i, err
 }
 
 const getPendingInvitations = `-- name: GetPendingInvitations :many
-SELECT id, family_id, inviter_user_id, status, expires_at, created_at
+SELECT id, family_id, inviter_user_id, email, status, expires_at, created_at
 FROM family_invitations
 WHERE family_id = $1 AND status = 'pending' AND expires_at > NOW()
 ORDER BY created_at DESC
@@ -102,6 +156,7 @@ type GetPendingInvitationsRow struct {
 	ID            pgtype.UUID        `json:"id"`
 	FamilyID      pgtype.UUID        `json:"family_id"`
 	InviterUserID pgtype.UUID        `json:"inviter_user_id"`
+	Email         pgtype.Text        `json:"email"`
 	Status        string             `json:"status"`
 	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
@@ -120,6 +175,7 @@ func (q *Queries) GetPendingInvitations(ctx context.Context, familyID pgtype.UUI
 			&i.ID,
 			&i.FamilyID,
 			&i.InviterUserID,
+			&i.Email,
 			&i.Status,
 			&i.ExpiresAt,
 			&i.CreatedAt,
@@ -134,6 +190,51 @@ func (q *Queries) GetPendingInvitations(ctx context.Context, familyID pgtype.UUI
 	return items, nil
 }
 
+const getPendingInvitationsForEmail = `-- name: GetPendingInvitationsForEmail :many
+SELECT fi.id, fi.family_id, f.name AS family_name, u.email AS inviter_email, fi.expires_at, fi.created_at
+FROM family_invitations fi
+JOIN families f ON f.id = fi.family_id
+JOIN users u ON u.id = fi.inviter_user_id
+WHERE lower(fi.email) = lower($1) AND fi.status = 'pending' AND fi.expires_at > NOW()
+ORDER BY fi.created_at DESC
+`
+
+type GetPendingInvitationsForEmailRow struct {
+	ID           pgtype.UUID        `json:"id"`
+	FamilyID     pgtype.UUID        `json:"family_id"`
+	FamilyName   string             `json:"family_name"`
+	InviterEmail string             `json:"inviter_email"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) GetPendingInvitationsForEmail(ctx context.Context, email string) ([]GetPendingInvitationsForEmailRow, error) {
+	rows, err := q.db.Query(ctx, getPendingInvitationsForEmail, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPendingInvitationsForEmailRow
+	for rows.Next() {
+		var i GetPendingInvitationsForEmailRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FamilyID,
+			&i.FamilyName,
+			&i.InviterEmail,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const revokeInvitation = `-- name: RevokeInvitation :execrows
 UPDATE family_invitations SET status = 'revoked'
 WHERE id = $1 AND family_id = $2 AND status = 'pending'
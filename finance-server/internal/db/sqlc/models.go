@@ -8,15 +8,67 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AttachmentDeletion struct {
+	StorageKey string             `json:"storage_key"`
+	QueuedAt   pgtype.Timestamptz `json:"queued_at"`
+}
+
+type Budget struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	FamilyID    pgtype.UUID        `json:"family_id"`
+	CategoryID  pgtype.UUID        `json:"category_id"`
+	AmountCents int64              `json:"amount_cents"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Category struct {
-	ID        pgtype.UUID        `json:"id"`
-	UserID    pgtype.UUID        `json:"user_id"`
-	Name      string             `json:"name"`
-	Icon      string             `json:"icon"`
-	Color     string             `json:"color"`
-	SortOrder int32              `json:"sort_order"`
+	ID                pgtype.UUID        `json:"id"`
+	UserID            pgtype.UUID        `json:"user_id"`
+	Name              string             `json:"name"`
+	Icon              string             `json:"icon"`
+	Color             string             `json:"color"`
+	SortOrder         int32              `json:"sort_order"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt         pgtype.Timestamptz `json:"updated_at"`
+	Kind              string             `json:"kind"`
+	FamilyID          pgtype.UUID        `json:"family_id"`
+	DefaultVisibility string             `json:"default_visibility"`
+	ArchivedAt        pgtype.Timestamptz `json:"archived_at"`
+	ParentID          pgtype.UUID        `json:"parent_id"`
+}
+
+type CategoryMapping struct {
+	FamilyID         pgtype.UUID        `json:"family_id"`
+	CategoryID       pgtype.UUID        `json:"category_id"`
+	FamilyCategoryID pgtype.UUID        `json:"family_category_id"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type CategoryRule struct {
+	ID             pgtype.UUID        `json:"id"`
+	UserID         pgtype.UUID        `json:"user_id"`
+	CategoryID     pgtype.UUID        `json:"category_id"`
+	Pattern        string             `json:"pattern"`
+	Priority       int32              `json:"priority"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	MatchType      string             `json:"match_type"`
+	MinAmountCents pgtype.Int8        `json:"min_amount_cents"`
+	MaxAmountCents pgtype.Int8        `json:"max_amount_cents"`
+	Tag            pgtype.Text        `json:"tag"`
+}
+
+type Currency struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type ExchangeRate struct {
+	Currency  string             `json:"currency"`
+	RateDate  pgtype.Date        `json:"rate_date"`
+	UsdRate   pgtype.Numeric     `json:"usd_rate"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Expense struct {
@@ -28,14 +80,55 @@ type Expense struct {
 	ExpenseDate pgtype.Date        `json:"expense_date"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	RecurringID pgtype.UUID        `json:"recurring_id"`
+	Currency    string             `json:"currency"`
+	RateDate    pgtype.Date        `json:"rate_date"`
+	Visibility  string             `json:"visibility"`
+}
+
+type ExpenseAttachment struct {
+	ID          pgtype.UUID        `json:"id"`
+	ExpenseID   pgtype.UUID        `json:"expense_id"`
+	StorageKey  string             `json:"storage_key"`
+	Filename    string             `json:"filename"`
+	ContentType string             `json:"content_type"`
+	SizeBytes   int64              `json:"size_bytes"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type ExpenseSplit struct {
+	ExpenseID   pgtype.UUID `json:"expense_id"`
+	FamilyID    pgtype.UUID `json:"family_id"`
+	UserID      pgtype.UUID `json:"user_id"`
+	Method      string      `json:"method"`
+	Shares      int32       `json:"shares"`
+	AmountCents int64       `json:"amount_cents"`
+}
+
+type ExpenseTag struct {
+	ExpenseID pgtype.UUID `json:"expense_id"`
+	TagID     pgtype.UUID `json:"tag_id"`
 }
 
 type Family struct {
+	ID              pgtype.UUID        `json:"id"`
+	Name            string             `json:"name"`
+	AdminUserID     pgtype.UUID        `json:"admin_user_id"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt       pgtype.Timestamptz `json:"updated_at"`
+	BaseCurrency    string             `json:"base_currency"`
+	PrivateExpenses string             `json:"private_expenses"`
+}
+
+type FamilyEvent struct {
 	ID          pgtype.UUID        `json:"id"`
-	Name        string             `json:"name"`
-	AdminUserID pgtype.UUID        `json:"admin_user_id"`
+	FamilyID    pgtype.UUID        `json:"family_id"`
+	ActorUserID pgtype.UUID        `json:"actor_user_id"`
+	Action      string             `json:"action"`
+	SubjectID   pgtype.UUID        `json:"subject_id"`
+	Before      []byte             `json:"before"`
+	After       []byte             `json:"after"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
 
 type FamilyInvitation struct {
@@ -46,6 +139,7 @@ type FamilyInvitation struct {
 	Status        string             `json:"status"`
 	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
 	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	Email         pgtype.Text        `json:"email"`
 }
 
 type FamilyMember struct {
@@ -56,6 +150,33 @@ type FamilyMember struct {
 	JoinedAt pgtype.Timestamptz `json:"joined_at"`
 }
 
+type Income struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	CategoryID  pgtype.UUID        `json:"category_id"`
+	AmountCents int64              `json:"amount_cents"`
+	Note        string             `json:"note"`
+	IncomeDate  pgtype.Date        `json:"income_date"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	Currency    string             `json:"currency"`
+	RateDate    pgtype.Date        `json:"rate_date"`
+}
+
+type RecurringExpense struct {
+	ID          pgtype.UUID        `json:"id"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	CategoryID  pgtype.UUID        `json:"category_id"`
+	AmountCents int64              `json:"amount_cents"`
+	Note        string             `json:"note"`
+	Frequency   string             `json:"frequency"`
+	StartDate   pgtype.Date        `json:"start_date"`
+	EndDate     pgtype.Date        `json:"end_date"`
+	NextDate    pgtype.Date        `json:"next_date"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type RefreshToken struct {
 	ID        pgtype.UUID        `json:"id"`
 	UserID    pgtype.UUID        `json:"user_id"`
@@ -63,6 +184,8 @@ type RefreshToken struct {
 	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
 	Revoked   bool               `json:"revoked"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+	FamilyID  pgtype.UUID        `json:"family_id"`
 }
 
 type SchemaInfo struct {
@@ -70,10 +193,31 @@ type SchemaInfo struct {
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
+type Settlement struct {
+	ID          pgtype.UUID        `json:"id"`
+	FamilyID    pgtype.UUID        `json:"family_id"`
+	FromUserID  pgtype.UUID        `json:"from_user_id"`
+	ToUserID    pgtype.UUID        `json:"to_user_id"`
+	AmountCents int64              `json:"amount_cents"`
+	Currency    string             `json:"currency"`
+	Note        string             `json:"note"`
+	SettledOn   pgtype.Date        `json:"settled_on"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type Tag struct {
+	ID        pgtype.UUID        `json:"id"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type User struct {
-	ID           pgtype.UUID        `json:"id"`
-	Email        string             `json:"email"`
-	PasswordHash string             `json:"password_hash"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	ID             pgtype.UUID        `json:"id"`
+	Email          string             `json:"email"`
+	PasswordHash   string             `json:"password_hash"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	BaseCurrency   string             `json:"base_currency"`
+	ActiveFamilyID pgtype.UUID        `json:"active_family_id"`
 }
@@ -12,40 +12,208 @@ import (
 
 type Querier interface {
 	AcceptInvitation(ctx context.Context, id pgtype.UUID) (int64, error)
+	AddExpenseTags(ctx context.Context, arg AddExpenseTagsParams) error
 	AddFamilyMember(ctx context.Context, arg AddFamilyMemberParams) (FamilyMember, error)
+	AdvisoryUnlock(ctx context.Context, key int64) (bool, error)
+	ArchiveCategory(ctx context.Context, arg ArchiveCategoryParams) (int64, error)
+	// Categories are ordered among their siblings, so a new category goes last
+	// under its parent.
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateCategoryRule(ctx context.Context, arg CreateCategoryRuleParams) (CategoryRule, error)
+	// A NULL currency defaults to the user's base currency (see the
+	// expenses_set_rate_date trigger), a NULL visibility to the category's
+	// default (see expenses_set_visibility).
 	CreateExpense(ctx context.Context, arg CreateExpenseParams) (Expense, error)
+	// Inserts nothing when the expense does not belong to the user.
+	CreateExpenseAttachment(ctx context.Context, arg CreateExpenseAttachmentParams) (ExpenseAttachment, error)
+	CreateExpenseSplit(ctx context.Context, arg CreateExpenseSplitParams) error
 	CreateFamily(ctx context.Context, arg CreateFamilyParams) (Family, error)
+	CreateFamilyCategory(ctx context.Context, arg CreateFamilyCategoryParams) (Category, error)
+	CreateFamilyEvent(ctx context.Context, arg CreateFamilyEventParams) error
+	// A NULL currency defaults to the user's base currency (see the
+	// incomes_set_rate_date trigger).
+	CreateIncome(ctx context.Context, arg CreateIncomeParams) (Income, error)
 	CreateInvitation(ctx context.Context, arg CreateInvitationParams) (FamilyInvitation, error)
+	// Records the event in every family where the actor's expenses are shared,
+	// which excludes families they only view.
+	CreateMemberFamilyEvents(ctx context.Context, arg CreateMemberFamilyEventsParams) (int64, error)
+	CreateRecurringExpense(ctx context.Context, arg CreateRecurringExpenseParams) (RecurringExpense, error)
+	CreateRecurringOccurrence(ctx context.Context, arg CreateRecurringOccurrenceParams) (int64, error)
+	// Stores a token in the given family, or in a new one when family_id is null.
 	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (pgtype.UUID, error)
+	CreateSettlement(ctx context.Context, arg CreateSettlementParams) (Settlement, error)
+	// Creates those of the named tags the user does not have yet.
+	CreateTags(ctx context.Context, arg CreateTagsParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
+	DeleteAttachmentDeletion(ctx context.Context, storageKey string) error
 	DeleteCategory(ctx context.Context, arg DeleteCategoryParams) (int64, error)
+	DeleteCategoryMapping(ctx context.Context, arg DeleteCategoryMappingParams) (int64, error)
+	DeleteCategoryRule(ctx context.Context, arg DeleteCategoryRuleParams) (int64, error)
 	DeleteExpense(ctx context.Context, arg DeleteExpenseParams) (int64, error)
+	DeleteExpenseAttachment(ctx context.Context, arg DeleteExpenseAttachmentParams) (int64, error)
+	DeleteExpenseSplits(ctx context.Context, arg DeleteExpenseSplitsParams) (int64, error)
+	DeleteExpenseTags(ctx context.Context, expenseID pgtype.UUID) error
 	DeleteFamily(ctx context.Context, arg DeleteFamilyParams) (int64, error)
-	GetCategoriesByUser(ctx context.Context, userID pgtype.UUID) ([]Category, error)
+	DeleteFamilyBudget(ctx context.Context, arg DeleteFamilyBudgetParams) (int64, error)
+	DeleteFamilyCategory(ctx context.Context, arg DeleteFamilyCategoryParams) (int64, error)
+	DeleteIncome(ctx context.Context, arg DeleteIncomeParams) (int64, error)
+	DeleteRecurringExpense(ctx context.Context, arg DeleteRecurringExpenseParams) (int64, error)
+	// Deletes up to batch_size tokens that expired or were revoked before cutoff.
+	DeleteStaleRefreshTokens(ctx context.Context, arg DeleteStaleRefreshTokensParams) (int64, error)
+	DeleteUserBudget(ctx context.Context, arg DeleteUserBudgetParams) (int64, error)
+	ExpenseExists(ctx context.Context, arg ExpenseExistsParams) (bool, error)
+	ExpireInvitations(ctx context.Context) (int64, error)
+	// The user's preferred family, or their earliest joined family when no
+	// preference is set or they have left the preferred one.
+	GetActiveFamily(ctx context.Context, userID pgtype.UUID) (GetActiveFamilyRow, error)
+	GetAttachmentDeletions(ctx context.Context, limit int32) ([]string, error)
+	GetCategoriesByUser(ctx context.Context, arg GetCategoriesByUserParams) ([]Category, error)
 	GetCategoryByID(ctx context.Context, arg GetCategoryByIDParams) (Category, error)
+	// The user's own mappings in the family.
+	GetCategoryMappings(ctx context.Context, arg GetCategoryMappingsParams) ([]GetCategoryMappingsRow, error)
+	// Totals per top-level category, counting the expenses of its
+	// subcategories along with its own.
+	GetCategoryRollupTotals(ctx context.Context, arg GetCategoryRollupTotalsParams) ([]GetCategoryRollupTotalsRow, error)
+	GetCategoryRulesByUser(ctx context.Context, userID pgtype.UUID) ([]CategoryRule, error)
+	// Categories of the user's past expenses whose notes match the search
+	// query, ranked by the summed relevance of the matching expenses.
+	GetCategorySuggestions(ctx context.Context, arg GetCategorySuggestionsParams) ([]GetCategorySuggestionsRow, error)
 	GetCategoryTotals(ctx context.Context, arg GetCategoryTotalsParams) ([]GetCategoryTotalsRow, error)
+	// Counts the records referencing one of the user's categories. The category
+	// stays locked until the transaction ends, so no new references appear.
+	GetCategoryUsage(ctx context.Context, arg GetCategoryUsageParams) (GetCategoryUsageRow, error)
 	GetDailyTotals(ctx context.Context, arg GetDailyTotalsParams) ([]GetDailyTotalsRow, error)
+	// Locks due templates so concurrent workers never materialize the same one.
+	// Templates whose category was archived are skipped.
+	GetDueRecurringExpenses(ctx context.Context, arg GetDueRecurringExpensesParams) ([]RecurringExpense, error)
+	GetExpense(ctx context.Context, arg GetExpenseParams) (Expense, error)
+	GetExpenseAttachment(ctx context.Context, arg GetExpenseAttachmentParams) (ExpenseAttachment, error)
+	GetExpenseAttachments(ctx context.Context, arg GetExpenseAttachmentsParams) ([]ExpenseAttachment, error)
+	// The kind of a category the user may file expenses under: one of their own
+	// or a shared category of a family they belong to. An archived category only
+	// qualifies for the expense_id already filed under it.
+	GetExpenseCategoryKind(ctx context.Context, arg GetExpenseCategoryKindParams) (string, error)
+	GetExpenseFingerprints(ctx context.Context, arg GetExpenseFingerprintsParams) ([]GetExpenseFingerprintsRow, error)
+	GetExpenseSplits(ctx context.Context, arg GetExpenseSplitsParams) ([]GetExpenseSplitsRow, error)
+	GetExpenseTags(ctx context.Context, expenseIds []pgtype.UUID) ([]GetExpenseTagsRow, error)
+	// Count and sum of the expenses matching a listing filter, converted to the
+	// user's base currency.
+	GetExpenseTotals(ctx context.Context, arg GetExpenseTotalsParams) (GetExpenseTotalsRow, error)
 	GetExpensesByUser(ctx context.Context, arg GetExpensesByUserParams) ([]Expense, error)
+	// With a search query, results are ranked by relevance first; keyset
+	// pagination then no longer matches the ordering and callers page by offset.
 	GetExpensesByUserFiltered(ctx context.Context, arg GetExpensesByUserFilteredParams) ([]Expense, error)
-	GetFamilyByUserID(ctx context.Context, userID pgtype.UUID) (Family, error)
+	// Keyset-paginated in ascending order so exports can stream in batches.
+	GetExpensesForExport(ctx context.Context, arg GetExpensesForExportParams) ([]GetExpensesForExportRow, error)
+	// Net balance of each member in the family's base currency; positive means
+	// the member is owed money. Shares are converted at their expense's rate
+	// date and settlements at the date they were made.
+	GetFamilyBalances(ctx context.Context, familyID pgtype.UUID) ([]GetFamilyBalancesRow, error)
+	// The kind of a category the family may budget against: one of its shared
+	// categories or a category of one of its non-viewer members.
+	GetFamilyBudgetCategoryKind(ctx context.Context, arg GetFamilyBudgetCategoryKindParams) (string, error)
+	GetFamilyBudgets(ctx context.Context, familyID pgtype.UUID) ([]GetFamilyBudgetsRow, error)
+	GetFamilyCategories(ctx context.Context, arg GetFamilyCategoriesParams) ([]Category, error)
+	GetFamilyCategoryByID(ctx context.Context, arg GetFamilyCategoryByIDParams) (Category, error)
+	// Totals per top-level category, counting the expenses of its
+	// subcategories along with its own. Categories are mapped and private
+	// expenses aggregated as in GetFamilyCategoryTotals.
+	GetFamilyCategoryRollupTotals(ctx context.Context, arg GetFamilyCategoryRollupTotalsParams) ([]GetFamilyCategoryRollupTotalsRow, error)
+	// Personal categories that members mapped onto a family category are
+	// counted under the family category. Aggregated private expenses are
+	// counted in a single row without a category.
 	GetFamilyCategoryTotals(ctx context.Context, arg GetFamilyCategoryTotalsParams) ([]GetFamilyCategoryTotalsRow, error)
+	// Newest first; a cursor selects the events strictly before it.
+	GetFamilyEvents(ctx context.Context, arg GetFamilyEventsParams) ([]GetFamilyEventsRow, error)
+	// Private expenses are not shared with the family and cannot be split.
+	GetFamilyExpenseByID(ctx context.Context, arg GetFamilyExpenseByIDParams) (GetFamilyExpenseByIDRow, error)
+	// Count and sum of the family expenses matching a feed filter, converted to
+	// the family's base currency. Private expenses are counted like in
+	// GetFamilyExpenses.
+	GetFamilyExpenseTotals(ctx context.Context, arg GetFamilyExpenseTotalsParams) (GetFamilyExpenseTotalsRow, error)
+	// Ranked by relevance first when a search query is given, like
+	// GetExpensesByUserFiltered.
+	// Private expenses only appear when the family aggregates them, and then
+	// without their category or note; filtering by category or tag, or
+	// searching, leaves them out.
 	GetFamilyExpenses(ctx context.Context, arg GetFamilyExpensesParams) ([]GetFamilyExpensesRow, error)
+	// Keyset-paginated in ascending order so exports can stream in batches.
+	// Private expenses are included like in GetFamilyExpenses.
+	GetFamilyExpensesForExport(ctx context.Context, arg GetFamilyExpensesForExportParams) ([]GetFamilyExpensesForExportRow, error)
+	GetFamilyForMember(ctx context.Context, arg GetFamilyForMemberParams) (GetFamilyForMemberRow, error)
+	GetFamilyIncomeTotal(ctx context.Context, arg GetFamilyIncomeTotalParams) (int64, error)
 	GetFamilyMemberCount(ctx context.Context, familyID pgtype.UUID) (int64, error)
+	// Private expenses are counted only when the family aggregates them.
 	GetFamilyMemberTotals(ctx context.Context, arg GetFamilyMemberTotalsParams) ([]GetFamilyMemberTotalsRow, error)
 	GetFamilyMembers(ctx context.Context, familyID pgtype.UUID) ([]GetFamilyMembersRow, error)
+	GetFamilySettlements(ctx context.Context, arg GetFamilySettlementsParams) ([]GetFamilySettlementsRow, error)
+	// The kind of a category the user may file incomes under: one of their own
+	// or a shared category of a family they belong to. An archived category only
+	// qualifies for the income_id already filed under it.
+	GetIncomeCategoryKind(ctx context.Context, arg GetIncomeCategoryKindParams) (string, error)
+	GetIncomeCategoryTotals(ctx context.Context, arg GetIncomeCategoryTotalsParams) ([]GetIncomeCategoryTotalsRow, error)
+	GetIncomesByUserFiltered(ctx context.Context, arg GetIncomesByUserFilteredParams) ([]Income, error)
+	GetInvitationByID(ctx context.Context, id pgtype.UUID) (GetInvitationByIDRow, error)
 	GetInvitationByTokenHash(ctx context.Context, tokenHash string) (GetInvitationByTokenHashRow, error)
 	GetPendingInvitations(ctx context.Context, familyID pgtype.UUID) ([]GetPendingInvitationsRow, error)
+	GetPendingInvitationsForEmail(ctx context.Context, email string) ([]GetPendingInvitationsForEmailRow, error)
+	GetRecurringExpenseByID(ctx context.Context, arg GetRecurringExpenseByIDParams) (RecurringExpense, error)
+	GetRecurringExpensesByUser(ctx context.Context, userID pgtype.UUID) ([]RecurringExpense, error)
+	// Revoked tokens are returned too, so that their reuse can be detected.
 	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (GetRefreshTokenByHashRow, error)
+	// Totals per tag. An expense with several tags counts towards each of them.
+	GetTagTotals(ctx context.Context, arg GetTagTotalsParams) ([]GetTagTotalsRow, error)
+	GetUserBaseCurrency(ctx context.Context, id pgtype.UUID) (string, error)
+	GetUserBudgets(ctx context.Context, userID pgtype.UUID) ([]GetUserBudgetsRow, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserEmail(ctx context.Context, id pgtype.UUID) (string, error)
+	GetUserFamilies(ctx context.Context, userID pgtype.UUID) ([]GetUserFamiliesRow, error)
+	// Each currency with its most recent exchange rate.
+	ListCurrencies(ctx context.Context) ([]ListCurrenciesRow, error)
+	// Serializes membership changes of a family until the transaction ends.
+	LockFamily(ctx context.Context, id pgtype.UUID) error
 	Ping(ctx context.Context) (int32, error)
+	// Copies the user's budgets onto the target category, adding them to any
+	// budget the target already has.
+	ReassignBudgetCategory(ctx context.Context, arg ReassignBudgetCategoryParams) (int64, error)
+	// Moves the mappings of one of the user's categories to the target, except
+	// in families where the target is mapped already.
+	ReassignCategoryMappingCategory(ctx context.Context, arg ReassignCategoryMappingCategoryParams) (int64, error)
+	ReassignCategoryRuleCategory(ctx context.Context, arg ReassignCategoryRuleCategoryParams) (int64, error)
+	ReassignExpenseCategory(ctx context.Context, arg ReassignExpenseCategoryParams) (int64, error)
+	ReassignIncomeCategory(ctx context.Context, arg ReassignIncomeCategoryParams) (int64, error)
+	ReassignRecurringExpenseCategory(ctx context.Context, arg ReassignRecurringExpenseCategoryParams) (int64, error)
 	RemoveFamilyMember(ctx context.Context, arg RemoveFamilyMemberParams) (int64, error)
 	RevokeAllUserTokens(ctx context.Context, userID pgtype.UUID) error
 	RevokeInvitation(ctx context.Context, arg RevokeInvitationParams) (int64, error)
-	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+	// Affects no rows when the token is already revoked, so that concurrent
+	// refreshes with the same token cannot both succeed.
+	RevokeRefreshToken(ctx context.Context, tokenHash string) (int64, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID pgtype.UUID) error
+	// Only succeeds for families the user belongs to.
+	SetActiveFamily(ctx context.Context, arg SetActiveFamilyParams) (int64, error)
+	SetFamilyBaseCurrency(ctx context.Context, arg SetFamilyBaseCurrencyParams) (int64, error)
+	SetFamilyMemberRole(ctx context.Context, arg SetFamilyMemberRoleParams) (int64, error)
+	// Guarded by the current owner so concurrent transfers cannot both succeed.
+	SetFamilyOwner(ctx context.Context, arg SetFamilyOwnerParams) (int64, error)
+	SetFamilyPrivateExpenses(ctx context.Context, arg SetFamilyPrivateExpensesParams) (int64, error)
+	SetRecurringExpenseNextDate(ctx context.Context, arg SetRecurringExpenseNextDateParams) error
+	SetUserBaseCurrency(ctx context.Context, arg SetUserBaseCurrencyParams) (int64, error)
+	TryAdvisoryLock(ctx context.Context, key int64) (bool, error)
+	// The parent is only changed when set_parent is true; a NULL parent_id then
+	// moves the category to the top level.
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (int64, error)
 	UpdateCategorySortOrder(ctx context.Context, arg UpdateCategorySortOrderParams) error
 	UpdateExpense(ctx context.Context, arg UpdateExpenseParams) (Expense, error)
+	UpdateFamilyCategory(ctx context.Context, arg UpdateFamilyCategoryParams) (int64, error)
+	UpdateIncome(ctx context.Context, arg UpdateIncomeParams) (Income, error)
+	UpdateRecurringExpense(ctx context.Context, arg UpdateRecurringExpenseParams) (RecurringExpense, error)
+	// Maps one of the user's categories onto a family category of the same kind;
+	// no row is written when either category does not qualify.
+	UpsertCategoryMapping(ctx context.Context, arg UpsertCategoryMappingParams) (int64, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	UpsertFamilyBudget(ctx context.Context, arg UpsertFamilyBudgetParams) (Budget, error)
+	UpsertUserBudget(ctx context.Context, arg UpsertUserBudgetParams) (Budget, error)
 }
 
 var _ Querier = (*Queries)(nil)
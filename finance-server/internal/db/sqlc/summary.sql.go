@@ -11,20 +11,85 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const getCategoryRollupTotals = `-- name: GetCategoryRollupTotals :many
+SELECT
+    r.id AS category_id,
+    r.name AS category_name,
+    r.color AS category_color,
+    r.icon AS category_icon,
+    SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date))::BIGINT AS total_cents,
+    COUNT(*)::INT AS expense_count
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+JOIN categories c ON c.id = e.category_id
+JOIN categories r ON r.id = COALESCE(c.parent_id, c.id)
+WHERE e.user_id = $1
+  AND e.expense_date >= $2
+  AND e.expense_date <= $3
+GROUP BY r.id, r.name, r.color, r.icon
+ORDER BY total_cents DESC
+`
+
+type GetCategoryRollupTotalsParams struct {
+	UserID        pgtype.UUID `json:"user_id"`
+	ExpenseDate   pgtype.Date `json:"expense_date"`
+	ExpenseDate_2 pgtype.Date `json:"expense_date_2"`
+}
+
+type GetCategoryRollupTotalsRow struct {
+	CategoryID    pgtype.UUID `json:"category_id"`
+	CategoryName  string      `json:"category_name"`
+	CategoryColor string      `json:"category_color"`
+	CategoryIcon  string      `json:"category_icon"`
+	TotalCents    int64       `json:"total_cents"`
+	ExpenseCount  int32       `json:"expense_count"`
+}
+
+// Totals per top-level category, counting the expenses of its
+// subcategories along with its own.
+func (q *Queries) GetCategoryRollupTotals(ctx context.Context, arg GetCategoryRollupTotalsParams) ([]GetCategoryRollupTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getCategoryRollupTotals, arg.UserID, arg.ExpenseDate, arg.ExpenseDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCategoryRollupTotalsRow
+	for rows.Next() {
+		var i GetCategoryRollupTotalsRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.CategoryName,
+			&i.CategoryColor,
+			&i.CategoryIcon,
+			&i.TotalCents,
+			&i.ExpenseCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getCategoryTotals = `-- name: GetCategoryTotals :many
 SELECT
     e.category_id,
     c.name AS category_name,
     c.color AS category_color,
     c.icon AS category_icon,
-    SUM(e.amount_cents)::BIGINT AS total_cents,
+    c.parent_id,
+    SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date))::BIGINT AS total_cents,
     COUNT(*)::INT AS expense_count
 FROM expenses e
+JOIN users u ON u.id = e.user_id
 JOIN categories c ON c.id = e.category_id
 WHERE e.user_id = $1
   AND e.expense_date >= $2
   AND e.expense_date <= $3
-GROUP BY e.category_id, c.name, c.color, c.icon
+GROUP BY e.category_id, c.name, c.color, c.icon, c.parent_id
 ORDER BY total_cents DESC
 `
 
@@ -39,6 +104,7 @@ type GetCategoryTotalsRow struct {
 	CategoryName  string      `json:"category_name"`
 	CategoryColor string      `json:"category_color"`
 	CategoryIcon  string      `json:"category_icon"`
+	ParentID      pgtype.UUID `json:"parent_id"`
 	TotalCents    int64       `json:"total_cents"`
 	ExpenseCount  int32       `json:"expense_count"`
 }
@@ -57,6 +123,7 @@ func (q *Queries) GetCategoryTotals(ctx context.Context, arg GetCategoryTotalsPa
 			&i.CategoryName,
 			&i.CategoryColor,
 			&i.CategoryIcon,
+			&i.ParentID,
 			&i.TotalCents,
 			&i.ExpenseCount,
 		); err != nil {
@@ -72,14 +139,15 @@ func (q *Queries) GetCategoryTotals(ctx context.Context, arg GetCategoryTotalsPa
 
 const getDailyTotals = `-- name: GetDailyTotals :many
 SELECT
-    expense_date AS date,
-    SUM(amount_cents)::BIGINT AS total_cents
-FROM expenses
-WHERE user_id = $1
-  AND expense_date >= $2
-  AND expense_date <= $3
-GROUP BY expense_date
-ORDER BY expense_date ASC
+    e.expense_date AS date,
+    SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date))::BIGINT AS total_cents
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+WHERE e.user_id = $1
+  AND e.expense_date >= $2
+  AND e.expense_date <= $3
+GROUP BY e.expense_date
+ORDER BY e.expense_date ASC
 `
 
 type GetDailyTotalsParams struct {
@@ -112,3 +180,119 @@ func (q *Queries) GetDailyTotals(ctx context.Context, arg GetDailyTotalsParams)
 	}
 	return items, nil
 }
+
+const getIncomeCategoryTotals = `-- name: GetIncomeCategoryTotals :many
+SELECT
+    i.category_id,
+    c.name AS category_name,
+    c.color AS category_color,
+    c.icon AS category_icon,
+    SUM(convert_cents(i.amount_cents, i.currency, u.base_currency, i.rate_date))::BIGINT AS total_cents,
+    COUNT(*)::INT AS income_count
+FROM incomes i
+JOIN users u ON u.id = i.user_id
+JOIN categories c ON c.id = i.category_id
+WHERE i.user_id = $1
+  AND i.income_date >= $2
+  AND i.income_date <= $3
+GROUP BY i.category_id, c.name, c.color, c.icon
+ORDER BY total_cents DESC
+`
+
+type GetIncomeCategoryTotalsParams struct {
+	UserID       pgtype.UUID `json:"user_id"`
+	IncomeDate   pgtype.Date `json:"income_date"`
+	IncomeDate_2 pgtype.Date `json:"income_date_2"`
+}
+
+type GetIncomeCategoryTotalsRow struct {
+	CategoryID    pgtype.UUID `json:"category_id"`
+	CategoryName  string      `json:"category_name"`
+	CategoryColor string      `json:"category_color"`
+	CategoryIcon  string      `json:"category_icon"`
+	TotalCents    int64       `json:"total_cents"`
+	IncomeCount   int32       `json:"income_count"`
+}
+
+func (q *Queries) GetIncomeCategoryTotals(ctx context.Context, arg GetIncomeCategoryTotalsParams) ([]GetIncomeCategoryTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getIncomeCategoryTotals, arg.UserID, arg.IncomeDate, arg.IncomeDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetIncomeCategoryTotalsRow
+	for rows.Next() {
+		var i GetIncomeCategoryTotalsRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.CategoryName,
+			&i.CategoryColor,
+			&i.CategoryIcon,
+			&i.TotalCents,
+			&i.IncomeCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTagTotals = `-- name: GetTagTotals :many
+SELECT
+    t.id AS tag_id,
+    t.name AS tag_name,
+    SUM(convert_cents(e.amount_cents, e.currency, u.base_currency, e.rate_date))::BIGINT AS total_cents,
+    COUNT(*)::INT AS expense_count
+FROM expenses e
+JOIN users u ON u.id = e.user_id
+JOIN expense_tags et ON et.expense_id = e.id
+JOIN tags t ON t.id = et.tag_id
+WHERE e.user_id = $1
+  AND e.expense_date >= $2
+  AND e.expense_date <= $3
+GROUP BY t.id, t.name
+ORDER BY total_cents DESC
+`
+
+type GetTagTotalsParams struct {
+	UserID        pgtype.UUID `json:"user_id"`
+	ExpenseDate   pgtype.Date `json:"expense_date"`
+	ExpenseDate_2 pgtype.Date `json:"expense_date_2"`
+}
+
+type GetTagTotalsRow struct {
+	TagID        pgtype.UUID `json:"tag_id"`
+	TagName      string      `json:"tag_name"`
+	TotalCents   int64       `json:"total_cents"`
+	ExpenseCount int32       `json:"expense_count"`
+}
+
+// Totals per tag. An expense with several tags counts towards each of them.
+func (q *Queries) GetTagTotals(ctx context.Context, arg GetTagTotalsParams) ([]GetTagTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getTagTotals, arg.UserID, arg.ExpenseDate, arg.ExpenseDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTagTotalsRow
+	for rows.Next() {
+		var i GetTagTotalsRow
+		if err := rows.Scan(
+			&i.TagID,
+			&i.TagName,
+			&i.TotalCents,
+			&i.ExpenseCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
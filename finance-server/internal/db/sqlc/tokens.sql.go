@@ -12,8 +12,8 @@ import (
 )
 
 const createRefreshToken = `-- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (user_id, token_hash, expires_at)
-VALUES ($1, $2, $3)
+INSERT INTO refresh_tokens (user_id, token_hash, expires_at, family_id)
+VALUES ($1, $2, $3, COALESCE($4::UUID, gen_random_uuid()))
 RETURNING id
 `
 
@@ -21,19 +21,49 @@ type CreateRefreshTokenParams struct {
 	UserID    pgtype.UUID        `json:"user_id"`
 	TokenHash string             `json:"token_hash"`
 	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	FamilyID  pgtype.UUID        `json:"family_id"`
 }
 
+// Stores a token in the given family, or in a new one when family_id is null.
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, createRefreshToken, arg.UserID, arg.TokenHash, arg.ExpiresAt)
+	row := q.db.QueryRow(ctx, createRefreshToken,
+		arg.UserID,
+		arg.TokenHash,
+		arg.ExpiresAt,
+		arg.FamilyID,
+	)
 	var id pgtype.UUID
 	err := row.Scan(&id)
 	return id, err
 }
 
+const deleteStaleRefreshTokens = `-- name: DeleteStaleRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE id IN (
+    SELECT id FROM refresh_tokens
+    WHERE expires_at < $1 OR revoked_at < $1
+    LIMIT $2
+)
+`
+
+type DeleteStaleRefreshTokensParams struct {
+	Cutoff    pgtype.Timestamptz `json:"cutoff"`
+	BatchSize int32              `json:"batch_size"`
+}
+
+// Deletes up to batch_size tokens that expired or were revoked before cutoff.
+func (q *Queries) DeleteStaleRefreshTokens(ctx context.Context, arg DeleteStaleRefreshTokensParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteStaleRefreshTokens, arg.Cutoff, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getRefreshTokenByHash = `-- name: GetRefreshTokenByHash :one
-SELECT id, user_id, token_hash, expires_at, revoked
+SELECT id, user_id, token_hash, expires_at, revoked, family_id
 FROM refresh_tokens
-WHERE token_hash = $1 AND revoked = FALSE AND expires_at > NOW()
+WHERE token_hash = $1 AND expires_at > NOW()
 `
 
 type GetRefreshTokenByHashRow struct {
@@ -42,8 +72,10 @@ type GetRefreshTokenByHashRow struct {
 	TokenHash string             `json:"token_hash"`
 	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
 	Revoked   bool               `json:"revoked"`
+	FamilyID  pgtype.UUID        `json:"family_id"`
 }
 
+// Revoked tokens are returned too, so that their reuse can be detected.
 func (q *Queries) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (GetRefreshTokenByHashRow, error) {
 	row := q.db.QueryRow(ctx, getRefreshTokenByHash, tokenHash)
 	var i GetRefreshTokenByHashRow
@@ -53,12 +85,14 @@ func (q *Queries) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (
 		&i.TokenHash,
 		&i.ExpiresAt,
 		&i.Revoked,
+		&i.FamilyID,
 	)
 	return i, err
 }
 
 const revokeAllUserTokens = `-- name: RevokeAllUserTokens :exec
-UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1
+UPDATE refresh_tokens SET revoked = TRUE, revoked_at = COALESCE(revoked_at, NOW())
+WHERE user_id = $1
 `
 
 func (q *Queries) RevokeAllUserTokens(ctx context.Context, userID pgtype.UUID) error {
@@ -66,11 +100,27 @@ func (q *Queries) RevokeAllUserTokens(ctx context.Context, userID pgtype.UUID) e
 	return err
 }
 
-const revokeRefreshToken = `-- name: RevokeRefreshToken :exec
-UPDATE refresh_tokens SET revoked = TRUE WHERE token_hash = $1
+const revokeRefreshToken = `-- name: RevokeRefreshToken :execrows
+UPDATE refresh_tokens SET revoked = TRUE, revoked_at = NOW()
+WHERE token_hash = $1 AND revoked = FALSE
+`
+
+// Affects no rows when the token is already revoked, so that concurrent
+// refreshes with the same token cannot both succeed.
+func (q *Queries) RevokeRefreshToken(ctx context.Context, tokenHash string) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeRefreshToken, tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeRefreshTokenFamily = `-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens SET revoked = TRUE, revoked_at = COALESCE(revoked_at, NOW())
+WHERE family_id = $1
 `
 
-func (q *Queries) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
-	_, err := q.db.Exec(ctx, revokeRefreshToken, tokenHash)
+func (q *Queries) RevokeRefreshTokenFamily(ctx context.Context, familyID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, revokeRefreshTokenFamily, familyID)
 	return err
 }
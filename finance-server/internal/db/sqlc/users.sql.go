@@ -41,8 +41,19 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 	return i, err
 }
 
+const getUserBaseCurrency = `-- name: GetUserBaseCurrency :one
+SELECT base_currency FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserBaseCurrency(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getUserBaseCurrency, id)
+	var base_currency string
+	err := row.Scan(&base_currency)
+	return base_currency, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password_hash, created_at, updated_at
+SELECT id, email, password_hash, created_at, updated_at, base_currency
 FROM users
 WHERE email = $1
 `
@@ -56,6 +67,36 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.BaseCurrency,
 	)
 	return i, err
 }
+
+const getUserEmail = `-- name: GetUserEmail :one
+SELECT email FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserEmail(ctx context.Context, id pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getUserEmail, id)
+	var email string
+	err := row.Scan(&email)
+	return email, err
+}
+
+const setUserBaseCurrency = `-- name: SetUserBaseCurrency :execrows
+UPDATE users SET base_currency = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetUserBaseCurrencyParams struct {
+	ID           pgtype.UUID `json:"id"`
+	BaseCurrency string      `json:"base_currency"`
+}
+
+func (q *Queries) SetUserBaseCurrency(ctx context.Context, arg SetUserBaseCurrencyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setUserBaseCurrency, arg.ID, arg.BaseCurrency)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
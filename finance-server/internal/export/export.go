@@ -0,0 +1,94 @@
+// Package export writes expense records as CSV, JSON or XLSX. Writers
+// stream records as they arrive, so callers can feed them from batched
+// database reads without holding the full result set in memory.
+package export
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported export formats.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXLSX = "xlsx"
+)
+
+// ErrUnsupportedFormat is returned by NewWriter for an unknown format.
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Record is a single exported expense.
+// MemberEmail is only written by writers created for family exports.
+type Record struct {
+	ID           string
+	ExpenseDate  time.Time
+	AmountCents  int64
+	Currency     string
+	Note         string
+	CategoryID   string
+	CategoryName string
+	CategoryIcon string
+	MemberEmail  string
+	CreatedAt    time.Time
+}
+
+// Writer streams records in a specific format.
+// Close must be called to finish the document; it does not close the
+// underlying io.Writer.
+type Writer interface {
+	Write(r Record) error
+	Close() error
+}
+
+// ContentType returns the MIME type for a format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+// ValidFormat reports whether format is supported.
+func ValidFormat(format string) bool {
+	return format == FormatCSV || format == FormatJSON || format == FormatXLSX
+}
+
+// columns returns the tabular header used by CSV and XLSX.
+func columns(includeMember bool) []string {
+	cols := []string{"date", "amount", "currency", "category", "category_icon", "note"}
+	if includeMember {
+		cols = append(cols, "member")
+	}
+	return append(cols, "id")
+}
+
+// formatAmount renders cents as a decimal string, e.g. 1234 -> "12.34".
+func formatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	frac := strconv.FormatInt(cents%100, 10)
+	if len(frac) == 1 {
+		frac = "0" + frac
+	}
+	return sign + strconv.FormatInt(cents/100, 10) + "." + frac
+}
+
+// sanitizeCell guards spreadsheet consumers against formula injection by
+// prefixing values that start with a formula trigger character.
+func sanitizeCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
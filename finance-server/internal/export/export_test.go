@@ -0,0 +1,133 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRecords() []Record {
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	return []Record{
+		{
+			ID:           "exp-1",
+			ExpenseDate:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			AmountCents:  1234,
+			Currency:     "UAH",
+			Note:         "Lunch & coffee",
+			CategoryID:   "cat-1",
+			CategoryName: "Food",
+			CategoryIcon: "🍔",
+			MemberEmail:  "a@example.com",
+			CreatedAt:    created,
+		},
+		{
+			ID:           "exp-2",
+			ExpenseDate:  time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+			AmountCents:  5,
+			Currency:     "EUR",
+			Note:         "=HYPERLINK(\"x\")",
+			CategoryID:   "cat-2",
+			CategoryName: "Misc",
+			CreatedAt:    created,
+		},
+	}
+}
+
+func writeAll(t *testing.T, format string, includeMember bool, records []Record) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf, includeMember)
+	if err != nil {
+		t.Fatalf("NewWriter(%s): %v", format, err)
+	}
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriter(t *testing.T) {
+	out := writeAll(t, FormatCSV, true, testRecords())
+	rows, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d", len(rows))
+	}
+	if strings.Join(rows[0], ",") != "date,amount,currency,category,category_icon,note,member,id" {
+		t.Fatalf("unexpected header: %v", rows[0])
+	}
+	if rows[1][0] != "2026-03-01" || rows[1][1] != "12.34" || rows[1][2] != "UAH" || rows[1][6] != "a@example.com" {
+		t.Fatalf("unexpected row: %v", rows[1])
+	}
+	if rows[2][1] != "0.05" || rows[2][5] != "'=HYPERLINK(\"x\")" {
+		t.Fatalf("expected escaped formula and padded cents: %v", rows[2])
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	var empty []map[string]any
+	if err := json.Unmarshal(writeAll(t, FormatJSON, false, nil), &empty); err != nil || len(empty) != 0 {
+		t.Fatalf("expected empty array, got %v (%v)", empty, err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(writeAll(t, FormatJSON, false, testRecords()), &got); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(got) != 2 || got[0]["amount_cents"] != float64(1234) || got[0]["category_name"] != "Food" {
+		t.Fatalf("unexpected records: %v", got)
+	}
+	if _, ok := got[0]["user_email"]; ok {
+		t.Fatal("user_email must be omitted for personal exports")
+	}
+}
+
+func TestXLSXWriter(t *testing.T) {
+	out := writeAll(t, FormatXLSX, false, testRecords())
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		sheet = string(b)
+	}
+	if sheet == "" {
+		t.Fatal("worksheet missing from archive")
+	}
+	if !strings.Contains(sheet, `<c r="B2"><v>12.34</v></c>`) {
+		t.Fatalf("expected numeric amount cell: %s", sheet)
+	}
+	if !strings.Contains(sheet, "Lunch &amp; coffee") || !strings.HasSuffix(sheet, "</sheetData></worksheet>") {
+		t.Fatalf("unexpected sheet: %s", sheet)
+	}
+}
+
+func TestNewWriter_UnsupportedFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", io.Discard, false); err != ErrUnsupportedFormat {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// NewWriter creates a streaming writer for format. includeMember adds the
+// member column used by family exports.
+func NewWriter(format string, w io.Writer, includeMember bool) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, includeMember)
+	case FormatJSON:
+		return &jsonWriter{w: w, includeMember: includeMember}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, includeMember)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	includeMember bool
+}
+
+func newCSVWriter(w io.Writer, includeMember bool) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), includeMember: includeMember}
+	if err := cw.w.Write(columns(includeMember)); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(r Record) error {
+	row := []string{
+		r.ExpenseDate.Format("2006-01-02"),
+		formatAmount(r.AmountCents),
+		r.Currency,
+		sanitizeCell(r.CategoryName),
+		r.CategoryIcon,
+		sanitizeCell(r.Note),
+	}
+	if cw.includeMember {
+		row = append(row, r.MemberEmail)
+	}
+	row = append(row, r.ID)
+	return cw.w.Write(row)
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonWriter streams a JSON array, writing the opening bracket lazily so an
+// empty export is still a valid "[]".
+type jsonWriter struct {
+	w             io.Writer
+	includeMember bool
+	started       bool
+}
+
+type jsonRecord struct {
+	ID           string `json:"id"`
+	ExpenseDate  string `json:"expense_date"`
+	AmountCents  int64  `json:"amount_cents"`
+	Currency     string `json:"currency"`
+	Note         string `json:"note"`
+	CategoryID   string `json:"category_id"`
+	CategoryName string `json:"category_name"`
+	CategoryIcon string `json:"category_icon"`
+	UserEmail    string `json:"user_email,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+func (jw *jsonWriter) Write(r Record) error {
+	sep := ","
+	if !jw.started {
+		sep = "["
+		jw.started = true
+	}
+	if _, err := io.WriteString(jw.w, sep); err != nil {
+		return err
+	}
+
+	rec := jsonRecord{
+		ID:           r.ID,
+		ExpenseDate:  r.ExpenseDate.Format("2006-01-02"),
+		AmountCents:  r.AmountCents,
+		Currency:     r.Currency,
+		Note:         r.Note,
+		CategoryID:   r.CategoryID,
+		CategoryName: r.CategoryName,
+		CategoryIcon: r.CategoryIcon,
+		CreatedAt:    r.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if jw.includeMember {
+		rec.UserEmail = r.MemberEmail
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = jw.w.Write(b)
+	return err
+}
+
+func (jw *jsonWriter) Close() error {
+	end := "]"
+	if !jw.started {
+		end = "[]"
+	}
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
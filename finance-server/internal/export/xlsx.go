@@ -0,0 +1,135 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The static parts of a minimal single-sheet workbook. The sheet itself is
+// the last zip entry so rows can be streamed into it.
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Expenses" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+const (
+	xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetFooter = `</sheetData></worksheet>`
+)
+
+// xlsxWriter streams rows into the worksheet entry of a zip archive.
+// Strings are written inline so no shared string table has to be built.
+type xlsxWriter struct {
+	zw            *zip.Writer
+	sheet         io.Writer
+	includeMember bool
+	row           int
+}
+
+func newXLSXWriter(w io.Writer, includeMember bool) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, xlsxSheetHeader); err != nil {
+		return nil, err
+	}
+
+	xw := &xlsxWriter{zw: zw, sheet: sheet, includeMember: includeMember}
+	header := columns(includeMember)
+	cells := make([]xlsxCell, len(header))
+	for i, name := range header {
+		cells[i] = xlsxCell{value: name}
+	}
+	if err := xw.writeRow(cells); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+type xlsxCell struct {
+	value   string
+	numeric bool
+}
+
+func (xw *xlsxWriter) Write(r Record) error {
+	cells := []xlsxCell{
+		{value: r.ExpenseDate.Format("2006-01-02")},
+		{value: formatAmount(r.AmountCents), numeric: true},
+		{value: r.Currency},
+		{value: sanitizeCell(r.CategoryName)},
+		{value: r.CategoryIcon},
+		{value: sanitizeCell(r.Note)},
+	}
+	if xw.includeMember {
+		cells = append(cells, xlsxCell{value: r.MemberEmail})
+	}
+	cells = append(cells, xlsxCell{value: r.ID})
+	return xw.writeRow(cells)
+}
+
+func (xw *xlsxWriter) writeRow(cells []xlsxCell) error {
+	xw.row++
+	rowNum := strconv.Itoa(xw.row)
+
+	var b strings.Builder
+	b.WriteString(`<row r="` + rowNum + `">`)
+	for i, cell := range cells {
+		ref := string(rune('A'+i)) + rowNum
+		if cell.numeric {
+			b.WriteString(`<c r="` + ref + `"><v>` + cell.value + `</v></c>`)
+			continue
+		}
+		b.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&b, []byte(cell.value)); err != nil {
+			return err
+		}
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+
+	_, err := io.WriteString(xw.sheet, b.String())
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	if _, err := io.WriteString(xw.sheet, xlsxSheetFooter); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Family activity actions. Expense and category actions are recorded in
+// every family that shares the actor's expenses; the rest in the family they
+// change.
+const (
+	ActionExpenseCreated       = "expense.created"
+	ActionExpenseUpdated       = "expense.updated"
+	ActionExpenseDeleted       = "expense.deleted"
+	ActionCategoryCreated      = "category.created"
+	ActionCategoryUpdated      = "category.updated"
+	ActionCategoryDeleted      = "category.deleted"
+	ActionCategoryArchived     = "category.archived"
+	ActionFamilyCreated        = "family.created"
+	ActionOwnershipTransferred = "family.ownership_transferred"
+	ActionMemberJoined         = "member.joined"
+	ActionMemberLeft           = "member.left"
+	ActionMemberRemoved        = "member.removed"
+	ActionMemberRoleChanged    = "member.role_changed"
+	ActionInvitationCreated    = "invitation.created"
+	ActionInvitationRevoked    = "invitation.revoked"
+)
+
+// MockFamilyEvent is an entry in a family's activity log. Before and After
+// are JSON snapshots of the subject; either is nil when it did not exist.
+type MockFamilyEvent struct {
+	ID          string
+	FamilyID    string
+	ActorUserID string
+	ActorEmail  string
+	Action      string
+	SubjectID   string
+	Before      json.RawMessage
+	After       json.RawMessage
+	CreatedAt   time.Time
+}
+
+// ActivityCursor is the position of an event in the newest-first activity
+// log; a cursor selects the events strictly before it.
+type ActivityCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// ActivityDB abstracts database operations for family activity logs.
+// The log is append-only.
+// This allows testing with mock implementations.
+type ActivityDB interface {
+	// RecordFamilyEvent appends the event to event.FamilyID's log.
+	RecordFamilyEvent(event MockFamilyEvent) error
+	// RecordMemberEvent appends the event to the log of every family that
+	// shares the actor's expenses; event.FamilyID is ignored.
+	RecordMemberEvent(event MockFamilyEvent) error
+	// GetFamilyEvents returns up to limit events, newest first, after the
+	// cursor (nil for the first page).
+	GetFamilyEvents(familyID string, before *ActivityCursor, limit int) ([]MockFamilyEvent, error)
+}
+
+// ActivityHandler handles family activity log HTTP requests.
+type ActivityHandler struct {
+	db       ActivityDB
+	familyDB FamilyDB
+}
+
+// NewActivityHandler creates an ActivityHandler with the given databases.
+func NewActivityHandler(db ActivityDB, familyDB FamilyDB) *ActivityHandler {
+	return &ActivityHandler{db: db, familyDB: familyDB}
+}
+
+// List handles GET /api/v1/families/:familyId/activity.
+// Events are returned newest first; pass the X-Next-Cursor of the previous
+// page as cursor to continue.
+func (h *ActivityHandler) List(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
+		return
+	}
+
+	limit, _ := parsePagination(c)
+
+	var before *ActivityCursor
+	if cur := c.Query("cursor"); cur != "" {
+		var err error
+		before, err = decodeActivityCursor(cur)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	// One extra row tells whether there is a next page.
+	events, err := h.db.GetFamilyEvents(family.ID, before, limit+1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	if len(events) > limit {
+		events = events[:limit]
+		last := events[limit-1]
+		c.Header(HeaderNextCursor, ActivityCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode())
+	}
+
+	result := make([]gin.H, len(events))
+	for i, e := range events {
+		var subjectID any
+		if e.SubjectID != "" {
+			subjectID = e.SubjectID
+		}
+		result[i] = gin.H{
+			"id":            e.ID,
+			"actor_user_id": e.ActorUserID,
+			"actor_email":   e.ActorEmail,
+			"action":        e.Action,
+			"subject_id":    subjectID,
+			"before":        e.Before,
+			"after":         e.After,
+			"created_at":    e.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// recordFamilyEvent appends an event by the current user to a family's log.
+// The change it describes has already been made, so failures are logged
+// rather than reported to the client.
+func recordFamilyEvent(c *gin.Context, db ActivityDB, familyID, action, subjectID string, before, after any) {
+	event := newFamilyEvent(c, action, subjectID, before, after)
+	event.FamilyID = familyID
+	if err := db.RecordFamilyEvent(event); err != nil {
+		log.Printf("Activity: record %s in family %s: %v", action, familyID, err)
+	}
+}
+
+// recordMemberEvent appends an event by the current user to the log of every
+// family that shares their expenses. Failures are logged, as for
+// recordFamilyEvent.
+func recordMemberEvent(c *gin.Context, db ActivityDB, action, subjectID string, before, after any) {
+	event := newFamilyEvent(c, action, subjectID, before, after)
+	if err := db.RecordMemberEvent(event); err != nil {
+		log.Printf("Activity: record %s for user %s: %v", action, event.ActorUserID, err)
+	}
+}
+
+func newFamilyEvent(c *gin.Context, action, subjectID string, before, after any) MockFamilyEvent {
+	return MockFamilyEvent{
+		ActorUserID: c.GetString("user_id"),
+		Action:      action,
+		SubjectID:   subjectID,
+		Before:      snapshot(before),
+		After:       snapshot(after),
+	}
+}
+
+// snapshot encodes v for an event's before or after field; nil stays nil.
+func snapshot(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// expenseSnapshot leaves out every detail of a private expense, so changing
+// an expense's visibility is logged without revealing it.
+func expenseSnapshot(e MockExpense) gin.H {
+	if e.Visibility == VisibilityPrivate {
+		return gin.H{"visibility": VisibilityPrivate}
+	}
+	return gin.H{
+		"category_id":  e.CategoryID,
+		"amount_cents": e.AmountCents,
+		"currency":     e.Currency,
+		"note":         e.Note,
+		"expense_date": e.ExpenseDate.Format("2006-01-02"),
+	}
+}
+
+func categorySnapshot(cat MockCategory) gin.H {
+	return gin.H{
+		"name":  cat.Name,
+		"icon":  cat.Icon,
+		"color": cat.Color,
+		"kind":  cat.Kind,
+	}
+}
+
+func memberSnapshot(m MockFamilyMember) gin.H {
+	return gin.H{
+		"user_id": m.UserID,
+		"email":   m.Email,
+		"role":    m.Role,
+	}
+}
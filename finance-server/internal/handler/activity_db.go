@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// PgActivityDB implements ActivityDB using sqlc-generated queries against PostgreSQL.
+type PgActivityDB struct {
+	queries *sqlc.Queries
+}
+
+// NewPgActivityDB creates a PgActivityDB wrapping sqlc.Queries.
+func NewPgActivityDB(queries *sqlc.Queries) *PgActivityDB {
+	return &PgActivityDB{queries: queries}
+}
+
+func (db *PgActivityDB) RecordFamilyEvent(event MockFamilyEvent) error {
+	return db.queries.CreateFamilyEvent(context.Background(), sqlc.CreateFamilyEventParams{
+		FamilyID:    stringToUUID(event.FamilyID),
+		ActorUserID: stringToUUID(event.ActorUserID),
+		Action:      event.Action,
+		SubjectID:   stringToNullableUUID(event.SubjectID),
+		Before:      event.Before,
+		After:       event.After,
+	})
+}
+
+func (db *PgActivityDB) RecordMemberEvent(event MockFamilyEvent) error {
+	_, err := db.queries.CreateMemberFamilyEvents(context.Background(), sqlc.CreateMemberFamilyEventsParams{
+		ActorUserID: stringToUUID(event.ActorUserID),
+		Action:      event.Action,
+		SubjectID:   stringToNullableUUID(event.SubjectID),
+		Before:      event.Before,
+		After:       event.After,
+	})
+	return err
+}
+
+func (db *PgActivityDB) GetFamilyEvents(familyID string, before *ActivityCursor, limit int) ([]MockFamilyEvent, error) {
+	params := sqlc.GetFamilyEventsParams{
+		FamilyID: stringToUUID(familyID),
+		Limit:    int32(limit),
+	}
+	if before != nil {
+		params.BeforeID = stringToUUID(before.ID)
+		params.BeforeCreatedAt = pgtype.Timestamptz{Time: before.CreatedAt, Valid: true}
+	}
+
+	rows, err := db.queries.GetFamilyEvents(context.Background(), params)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]MockFamilyEvent, len(rows))
+	for i, row := range rows {
+		events[i] = MockFamilyEvent{
+			ID:          uuidToString(row.ID),
+			FamilyID:    uuidToString(row.FamilyID),
+			ActorUserID: uuidToString(row.ActorUserID),
+			ActorEmail:  row.ActorEmail,
+			Action:      row.Action,
+			SubjectID:   uuidToString(row.SubjectID),
+			Before:      row.Before,
+			After:       row.After,
+			CreatedAt:   row.CreatedAt.Time,
+		}
+	}
+	return events, nil
+}
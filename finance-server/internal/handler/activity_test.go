@@ -0,0 +1,324 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+// mockActivityDB implements handler.ActivityDB for testing.
+type mockActivityDB struct {
+	events []handler.MockFamilyEvent
+	// sharedFamilies are the families that receive member events.
+	sharedFamilies []string
+	now            time.Time
+}
+
+func newMockActivityDB(sharedFamilies ...string) *mockActivityDB {
+	return &mockActivityDB{
+		sharedFamilies: sharedFamilies,
+		now:            time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func (m *mockActivityDB) RecordFamilyEvent(event handler.MockFamilyEvent) error {
+	event.ID = fmt.Sprintf("evt-%02d", len(m.events)+1)
+	event.ActorEmail = event.ActorUserID + "@test.com"
+	// Events recorded together share a timestamp, so ordering falls back to ID.
+	event.CreatedAt = m.now
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockActivityDB) RecordMemberEvent(event handler.MockFamilyEvent) error {
+	for _, familyID := range m.sharedFamilies {
+		event.FamilyID = familyID
+		m.RecordFamilyEvent(event)
+	}
+	return nil
+}
+
+func (m *mockActivityDB) GetFamilyEvents(familyID string, before *handler.ActivityCursor, limit int) ([]handler.MockFamilyEvent, error) {
+	var result []handler.MockFamilyEvent
+	for _, e := range m.events {
+		if e.FamilyID != familyID {
+			continue
+		}
+		if before != nil && (e.CreatedAt.After(before.CreatedAt) ||
+			(e.CreatedAt.Equal(before.CreatedAt) && e.ID >= before.ID)) {
+			continue
+		}
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// actions returns the actions recorded in familyID, oldest first.
+func (m *mockActivityDB) actions(familyID string) []string {
+	var result []string
+	for _, e := range m.events {
+		if e.FamilyID == familyID {
+			result = append(result, e.Action)
+		}
+	}
+	return result
+}
+
+func setupActivityRouter(db handler.ActivityDB, familyDB handler.FamilyDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewActivityHandler(db, familyDB)
+
+	r.GET("/api/v1/families/:familyId/activity", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User-ID"))
+		c.Next()
+	}, h.List)
+	return r
+}
+
+func jsonRequest(r http.Handler, method, path string, payload any) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	if payload != nil {
+		json.NewEncoder(&body).Encode(payload)
+	}
+	req := httptest.NewRequest(method, path, &body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestActivityList(t *testing.T) {
+	db := newMockActivityDB()
+	for i := 0; i < 3; i++ {
+		db.RecordFamilyEvent(handler.MockFamilyEvent{FamilyID: "family-1", ActorUserID: "user-1", Action: handler.ActionMemberJoined})
+	}
+	db.RecordFamilyEvent(handler.MockFamilyEvent{FamilyID: "family-2", ActorUserID: "user-9", Action: handler.ActionFamilyCreated})
+	r := setupActivityRouter(db, newRolesFamily())
+
+	t.Run("pages newest first", func(t *testing.T) {
+		w := familyRequest(r, http.MethodGet, "/api/v1/families/me/activity?limit=2", "user-4")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var page []map[string]any
+		json.Unmarshal(w.Body.Bytes(), &page)
+		if len(page) != 2 || page[0]["id"] != "evt-03" || page[1]["id"] != "evt-02" {
+			t.Fatalf("unexpected first page: %v", page)
+		}
+		if page[0]["actor_email"] != "user-1@test.com" || page[0]["subject_id"] != nil {
+			t.Fatalf("unexpected event: %v", page[0])
+		}
+
+		cursor := w.Header().Get(handler.HeaderNextCursor)
+		if cursor == "" {
+			t.Fatal("expected a next cursor")
+		}
+		w = familyRequest(r, http.MethodGet, "/api/v1/families/me/activity?limit=2&cursor="+cursor, "user-4")
+		json.Unmarshal(w.Body.Bytes(), &page)
+		if len(page) != 1 || page[0]["id"] != "evt-01" {
+			t.Fatalf("unexpected second page: %v", page)
+		}
+		if w.Header().Get(handler.HeaderNextCursor) != "" {
+			t.Fatal("last page should not have a next cursor")
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		w := familyRequest(r, http.MethodGet, "/api/v1/families/family-1/activity?cursor=nope", "user-1")
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("non-member", func(t *testing.T) {
+		w := familyRequest(r, http.MethodGet, "/api/v1/families/family-2/activity", "user-1")
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestActivity_ExpenseMutations(t *testing.T) {
+	activity := newMockActivityDB("family-1", "family-2")
+	r := setupExpenseRouterWithActivity(newMockExpenseDB(), activity)
+
+	expense := map[string]any{
+		"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+		"amount_cents": 1500,
+		"note":         "Lunch",
+		"expense_date": "2026-03-15",
+	}
+	if w := jsonRequest(r, http.MethodPost, "/api/v1/expenses", expense); w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	expense["amount_cents"] = 2500
+	if w := jsonRequest(r, http.MethodPut, "/api/v1/expenses/exp-1", expense); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := jsonRequest(r, http.MethodDelete, "/api/v1/expenses/exp-1", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := jsonRequest(r, http.MethodDelete, "/api/v1/expenses/exp-1", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("second delete: expected 404, got %d", w.Code)
+	}
+
+	want := []string{handler.ActionExpenseCreated, handler.ActionExpenseUpdated, handler.ActionExpenseDeleted}
+	for _, familyID := range []string{"family-1", "family-2"} {
+		if got := activity.actions(familyID); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("%s: expected %v, got %v", familyID, want, got)
+		}
+	}
+
+	update := activity.events[2]
+	var before, after map[string]any
+	json.Unmarshal(update.Before, &before)
+	json.Unmarshal(update.After, &after)
+	if update.ActorUserID != testUserID || update.SubjectID != "exp-1" {
+		t.Fatalf("unexpected update event: %+v", update)
+	}
+	if before["amount_cents"] != float64(1500) || after["amount_cents"] != float64(2500) {
+		t.Fatalf("unexpected snapshots: before=%v after=%v", before, after)
+	}
+	if deleted := activity.events[4]; deleted.Before == nil || deleted.After != nil {
+		t.Fatalf("delete should only have a before snapshot: %+v", deleted)
+	}
+}
+
+func TestActivity_PrivateExpenses(t *testing.T) {
+	activity := newMockActivityDB("family-1")
+	r := setupExpenseRouterWithActivity(newMockExpenseDB(), activity)
+
+	expense := map[string]any{
+		"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+		"amount_cents": 1500,
+		"visibility":   handler.VisibilityPrivate,
+		"note":         "Gift",
+		"expense_date": "2026-03-15",
+	}
+	steps := []struct {
+		method     string
+		visibility string
+	}{
+		{http.MethodPost, handler.VisibilityPrivate},
+		{http.MethodPut, handler.VisibilityPrivate},
+		{http.MethodPut, handler.VisibilityFamily},
+		{http.MethodPut, handler.VisibilityPrivate},
+		{http.MethodDelete, ""},
+	}
+	for _, step := range steps {
+		path := "/api/v1/expenses/exp-1"
+		if step.method == http.MethodPost {
+			path = "/api/v1/expenses"
+		}
+		var payload any
+		if step.method != http.MethodDelete {
+			expense["visibility"] = step.visibility
+			payload = expense
+		}
+		if w := jsonRequest(r, step.method, path, payload); w.Code >= 300 {
+			t.Fatalf("%s %s: got %d: %s", step.method, step.visibility, w.Code, w.Body.String())
+		}
+	}
+
+	// Only the changes to and from family visibility are logged.
+	want := []string{handler.ActionExpenseUpdated, handler.ActionExpenseUpdated}
+	if got := activity.actions("family-1"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	var unshared, hidden map[string]any
+	json.Unmarshal(activity.events[0].Before, &unshared)
+	json.Unmarshal(activity.events[1].After, &hidden)
+	for _, snapshot := range []map[string]any{unshared, hidden} {
+		if len(snapshot) != 1 || snapshot["visibility"] != handler.VisibilityPrivate {
+			t.Fatalf("private snapshot should only hold the visibility, got %v", snapshot)
+		}
+	}
+}
+
+func TestActivity_CategoryMutations(t *testing.T) {
+	activity := newMockActivityDB("family-1")
+	r := setupCategoryRouterWithActivity(newMockCategoryDB(), activity)
+
+	w := jsonRequest(r, http.MethodPost, "/api/v1/categories", map[string]string{"name": "Food", "icon": "restaurant", "color": "#FF7043"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created map[string]any
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := created["id"].(string)
+
+	if w := jsonRequest(r, http.MethodPut, "/api/v1/categories/"+id, map[string]string{"name": "Groceries", "icon": "cart", "color": "#FF7043"}); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := jsonRequest(r, http.MethodPut, "/api/v1/categories/cat-9", map[string]string{"name": "X", "icon": "x", "color": "#000000"}); w.Code != http.StatusNotFound {
+		t.Fatalf("update unknown: expected 404, got %d", w.Code)
+	}
+	if w := jsonRequest(r, http.MethodDelete, "/api/v1/categories/"+id, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	want := []string{handler.ActionCategoryCreated, handler.ActionCategoryUpdated, handler.ActionCategoryDeleted}
+	if got := activity.actions("family-1"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	var before, after map[string]any
+	json.Unmarshal(activity.events[1].Before, &before)
+	json.Unmarshal(activity.events[1].After, &after)
+	if before["name"] != "Food" || after["name"] != "Groceries" {
+		t.Fatalf("unexpected snapshots: before=%v after=%v", before, after)
+	}
+}
+
+func TestActivity_FamilyMutations(t *testing.T) {
+	activity := newMockActivityDB()
+	r := setupFamilyRouterWith(newRolesFamily(), activity, &mockMailer{})
+
+	if w := familyRequest(r, http.MethodPost, "/api/v1/families/family-1/members/user-3/promote", "user-1"); w.Code != http.StatusOK {
+		t.Fatalf("promote: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1/members/user-4", "user-2"); w.Code != http.StatusOK {
+		t.Fatalf("remove: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1/invitations/inv-9", "user-2"); w.Code != http.StatusNotFound {
+		t.Fatalf("revoke unknown: expected 404, got %d", w.Code)
+	}
+	// Rejected changes are not logged.
+	if w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1/members/user-1", "user-2"); w.Code != http.StatusForbidden {
+		t.Fatalf("remove owner: expected 403, got %d", w.Code)
+	}
+
+	want := []string{handler.ActionMemberRoleChanged, handler.ActionMemberRemoved}
+	if got := activity.actions("family-1"); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	promoted := activity.events[0]
+	if promoted.ActorUserID != "user-1" || promoted.SubjectID != "user-3" ||
+		string(promoted.Before) != `{"role":"member"}` || string(promoted.After) != `{"role":"admin"}` {
+		t.Fatalf("unexpected role change event: %+v", promoted)
+	}
+	removed := activity.events[1]
+	var before map[string]any
+	json.Unmarshal(removed.Before, &before)
+	if removed.ActorUserID != "user-2" || before["role"] != handler.RoleViewer || removed.After != nil {
+		t.Fatalf("unexpected removal event: %+v", removed)
+	}
+}
@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/storage"
+)
+
+const (
+	// maxAttachmentSize bounds a single uploaded receipt.
+	maxAttachmentSize = 10 << 20
+	// maxAttachmentsPerExpense bounds how many receipts one expense can have.
+	maxAttachmentsPerExpense = 10
+)
+
+// attachmentTypes are the accepted receipt content types.
+var attachmentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/heic": true,
+	"image/heif": true,
+}
+
+// Sentinel errors for attachment operations.
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+)
+
+// MockAttachment is the attachment representation used by the AttachmentDB interface.
+type MockAttachment struct {
+	ID          string
+	ExpenseID   string
+	StorageKey  string
+	Filename    string
+	ContentType string
+	SizeBytes   int64
+	CreatedAt   time.Time
+}
+
+// AttachmentDB abstracts database operations for expense attachments.
+// This allows testing with mock implementations.
+// Every method is scoped to expenses owned by userID and returns
+// ErrExpenseNotFound when the expense is missing or belongs to someone else.
+// Deleted rows queue their blobs for removal by the attachment worker.
+type AttachmentDB interface {
+	CreateAttachment(expenseID, userID string, a MockAttachment) (MockAttachment, error)
+	GetAttachments(expenseID, userID string) ([]MockAttachment, error)
+	GetAttachment(id, expenseID, userID string) (MockAttachment, error)
+	DeleteAttachment(id, expenseID, userID string) error
+}
+
+// AttachmentHandler handles receipt attachment HTTP requests.
+type AttachmentHandler struct {
+	db    AttachmentDB
+	store storage.Storage
+}
+
+// NewAttachmentHandler creates an AttachmentHandler with the given database
+// and blob store.
+func NewAttachmentHandler(db AttachmentDB, store storage.Storage) *AttachmentHandler {
+	return &AttachmentHandler{db: db, store: store}
+}
+
+// Upload handles POST /api/v1/expenses/:id/attachments.
+// It expects a multipart form with a "file" field holding a JPEG, PNG, WebP
+// or HEIC image of at most 10 MiB.
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	expenseID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	// Leave room for the multipart framing around the file itself.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAttachmentSize+(64<<10))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if fileHeader.Size == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is empty"})
+		return
+	}
+	if fileHeader.Size > maxAttachmentSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file must be at most 10 MiB"})
+		return
+	}
+
+	existing, err := h.db.GetAttachments(expenseID, userID)
+	if err != nil {
+		if errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	if len(existing) >= maxAttachmentsPerExpense {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("an expense can have at most %d attachments", maxAttachmentsPerExpense)})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	defer file.Close()
+
+	contentType, err := attachmentContentType(file, fileHeader.Header.Get("Content-Type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file must be a JPEG, PNG, WebP or HEIC image"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	key, err := h.store.Upload(ctx, file, contentType, fileHeader.Size)
+	if err != nil {
+		log.Printf("Attachments: upload: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	attachment, err := h.db.CreateAttachment(expenseID, userID, MockAttachment{
+		StorageKey:  key,
+		Filename:    filepath.Base(fileHeader.Filename),
+		ContentType: contentType,
+		SizeBytes:   fileHeader.Size,
+	})
+	if err != nil {
+		// The row was never written, so nothing else will clean up the blob.
+		if delErr := h.store.Delete(ctx, key); delErr != nil {
+			log.Printf("Attachments: remove orphaned blob %s: %v", key, delErr)
+		}
+		if errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachmentResponse(attachment))
+}
+
+// List handles GET /api/v1/expenses/:id/attachments.
+func (h *AttachmentHandler) List(c *gin.Context) {
+	attachments, err := h.db.GetAttachments(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		if errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(attachments))
+	for i, a := range attachments {
+		result[i] = attachmentResponse(a)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Download handles GET /api/v1/expenses/:id/attachments/:attachmentId.
+// The blob is streamed through the API so access checks stay in one place.
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	attachment, err := h.db.GetAttachment(c.Param("attachmentId"), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		if errors.Is(err, ErrAttachmentNotFound) || errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	blob, err := h.store.Open(c.Request.Context(), attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		log.Printf("Attachments: open %s: %v", attachment.StorageKey, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	defer blob.Close()
+
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename}))
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.Header("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	c.Header("Content-Type", attachment.ContentType)
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, blob); err != nil {
+		log.Printf("Attachments: stream %s: %v", attachment.StorageKey, err)
+	}
+}
+
+// Delete handles DELETE /api/v1/expenses/:id/attachments/:attachmentId.
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	err := h.db.DeleteAttachment(c.Param("attachmentId"), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		if errors.Is(err, ErrAttachmentNotFound) || errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// attachmentContentType sniffs the file to pick its content type, falling
+// back to the declared type for HEIC, which http.DetectContentType does not
+// recognise. The file is rewound before returning.
+func attachmentContentType(file io.ReadSeeker, declared string) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if contentType == "application/octet-stream" {
+		contentType, _, _ = mime.ParseMediaType(declared)
+	}
+	if !attachmentTypes[contentType] {
+		return "", errors.New("unsupported content type")
+	}
+	return contentType, nil
+}
+
+func attachmentResponse(a MockAttachment) gin.H {
+	return gin.H{
+		"id":           a.ID,
+		"expense_id":   a.ExpenseID,
+		"filename":     a.Filename,
+		"content_type": a.ContentType,
+		"size_bytes":   a.SizeBytes,
+		"created_at":   a.CreatedAt,
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// PgAttachmentDB implements AttachmentDB using sqlc-generated queries against PostgreSQL.
+type PgAttachmentDB struct {
+	queries *sqlc.Queries
+}
+
+// NewPgAttachmentDB creates a PgAttachmentDB wrapping sqlc.Queries.
+func NewPgAttachmentDB(queries *sqlc.Queries) *PgAttachmentDB {
+	return &PgAttachmentDB{queries: queries}
+}
+
+func (db *PgAttachmentDB) CreateAttachment(expenseID, userID string, a MockAttachment) (MockAttachment, error) {
+	row, err := db.queries.CreateExpenseAttachment(context.Background(), sqlc.CreateExpenseAttachmentParams{
+		ID:          stringToUUID(expenseID),
+		UserID:      stringToUUID(userID),
+		StorageKey:  a.StorageKey,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockAttachment{}, ErrExpenseNotFound
+		}
+		return MockAttachment{}, err
+	}
+	return attachmentFromRow(row), nil
+}
+
+func (db *PgAttachmentDB) GetAttachments(expenseID, userID string) ([]MockAttachment, error) {
+	ctx := context.Background()
+	eid := stringToUUID(expenseID)
+	uid := stringToUUID(userID)
+
+	rows, err := db.queries.GetExpenseAttachments(ctx, sqlc.GetExpenseAttachmentsParams{ExpenseID: eid, UserID: uid})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		exists, err := db.queries.ExpenseExists(ctx, sqlc.ExpenseExistsParams{ID: eid, UserID: uid})
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrExpenseNotFound
+		}
+	}
+
+	attachments := make([]MockAttachment, len(rows))
+	for i, row := range rows {
+		attachments[i] = attachmentFromRow(row)
+	}
+	return attachments, nil
+}
+
+func (db *PgAttachmentDB) GetAttachment(id, expenseID, userID string) (MockAttachment, error) {
+	row, err := db.queries.GetExpenseAttachment(context.Background(), sqlc.GetExpenseAttachmentParams{
+		ID:        stringToUUID(id),
+		ExpenseID: stringToUUID(expenseID),
+		UserID:    stringToUUID(userID),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockAttachment{}, ErrAttachmentNotFound
+		}
+		return MockAttachment{}, err
+	}
+	return attachmentFromRow(row), nil
+}
+
+func (db *PgAttachmentDB) DeleteAttachment(id, expenseID, userID string) error {
+	rowsAffected, err := db.queries.DeleteExpenseAttachment(context.Background(), sqlc.DeleteExpenseAttachmentParams{
+		ID:        stringToUUID(id),
+		ExpenseID: stringToUUID(expenseID),
+		UserID:    stringToUUID(userID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func attachmentFromRow(row sqlc.ExpenseAttachment) MockAttachment {
+	return MockAttachment{
+		ID:          uuidToString(row.ID),
+		ExpenseID:   uuidToString(row.ExpenseID),
+		StorageKey:  row.StorageKey,
+		Filename:    row.Filename,
+		ContentType: row.ContentType,
+		SizeBytes:   row.SizeBytes,
+		CreatedAt:   row.CreatedAt.Time,
+	}
+}
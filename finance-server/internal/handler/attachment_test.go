@@ -0,0 +1,314 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+	"github.com/nnc/finance-tracker/server/internal/storage"
+)
+
+var pngData = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 32)...)
+
+// mockAttachmentDB implements handler.AttachmentDB for testing.
+type mockAttachmentDB struct {
+	expenses    map[string]string // expense ID -> owner
+	attachments []handler.MockAttachment
+	nextID      int
+	createErr   error
+}
+
+func newMockAttachmentDB() *mockAttachmentDB {
+	return &mockAttachmentDB{expenses: map[string]string{"exp-1": testUserID, "exp-other": "someone-else"}}
+}
+
+func (m *mockAttachmentDB) owned(expenseID, userID string) bool {
+	return m.expenses[expenseID] == userID
+}
+
+func (m *mockAttachmentDB) CreateAttachment(expenseID, userID string, a handler.MockAttachment) (handler.MockAttachment, error) {
+	if m.createErr != nil {
+		return handler.MockAttachment{}, m.createErr
+	}
+	if !m.owned(expenseID, userID) {
+		return handler.MockAttachment{}, handler.ErrExpenseNotFound
+	}
+	m.nextID++
+	a.ID = fmt.Sprintf("att-%d", m.nextID)
+	a.ExpenseID = expenseID
+	a.CreatedAt = time.Now()
+	m.attachments = append(m.attachments, a)
+	return a, nil
+}
+
+func (m *mockAttachmentDB) GetAttachments(expenseID, userID string) ([]handler.MockAttachment, error) {
+	if !m.owned(expenseID, userID) {
+		return nil, handler.ErrExpenseNotFound
+	}
+	var result []handler.MockAttachment
+	for _, a := range m.attachments {
+		if a.ExpenseID == expenseID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAttachmentDB) GetAttachment(id, expenseID, userID string) (handler.MockAttachment, error) {
+	if !m.owned(expenseID, userID) {
+		return handler.MockAttachment{}, handler.ErrExpenseNotFound
+	}
+	for _, a := range m.attachments {
+		if a.ID == id && a.ExpenseID == expenseID {
+			return a, nil
+		}
+	}
+	return handler.MockAttachment{}, handler.ErrAttachmentNotFound
+}
+
+func (m *mockAttachmentDB) DeleteAttachment(id, expenseID, userID string) error {
+	if !m.owned(expenseID, userID) {
+		return handler.ErrExpenseNotFound
+	}
+	for i, a := range m.attachments {
+		if a.ID == id && a.ExpenseID == expenseID {
+			m.attachments = append(m.attachments[:i], m.attachments[i+1:]...)
+			return nil
+		}
+	}
+	return handler.ErrAttachmentNotFound
+}
+
+// mockStorage implements storage.Storage in memory.
+type mockStorage struct {
+	blobs  map[string][]byte
+	nextID int
+}
+
+func newMockStorage() *mockStorage {
+	return &mockStorage{blobs: make(map[string][]byte)}
+}
+
+func (s *mockStorage) Upload(_ context.Context, r io.Reader, _ string, _ int64) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	s.nextID++
+	key := fmt.Sprintf("blob-%d", s.nextID)
+	s.blobs[key] = data
+	return key, nil
+}
+
+func (s *mockStorage) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *mockStorage) Delete(_ context.Context, key string) error {
+	delete(s.blobs, key)
+	return nil
+}
+
+func setupAttachmentRouter(db handler.AttachmentDB, store storage.Storage) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewAttachmentHandler(db, store)
+
+	expenses := r.Group("/api/v1/expenses")
+	expenses.Use(func(c *gin.Context) {
+		c.Set("user_id", testUserID)
+		c.Next()
+	})
+	{
+		expenses.POST("/:id/attachments", h.Upload)
+		expenses.GET("/:id/attachments", h.List)
+		expenses.GET("/:id/attachments/:attachmentId", h.Download)
+		expenses.DELETE("/:id/attachments/:attachmentId", h.Delete)
+	}
+	return r
+}
+
+func uploadAttachment(r *gin.Engine, expenseID, filename, contentType string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filename))
+	header.Set("Content-Type", contentType)
+	part, _ := mw.CreatePart(header)
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses/"+expenseID+"/attachments", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestUploadAttachment_Success(t *testing.T) {
+	db := newMockAttachmentDB()
+	store := newMockStorage()
+	r := setupAttachmentRouter(db, store)
+
+	// The sniffed type wins over the declared one.
+	w := uploadAttachment(r, "exp-1", "../receipt.png", "application/octet-stream", pngData)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["filename"] != "receipt.png" || resp["content_type"] != "image/png" || resp["size_bytes"] != float64(len(pngData)) {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+	if _, ok := resp["storage_key"]; ok {
+		t.Fatal("storage key must not be exposed")
+	}
+	if len(store.blobs) != 1 || !bytes.Equal(store.blobs[db.attachments[0].StorageKey], pngData) {
+		t.Fatalf("expected blob to be stored under the recorded key")
+	}
+}
+
+func TestUploadAttachment_HEIC(t *testing.T) {
+	r := setupAttachmentRouter(newMockAttachmentDB(), newMockStorage())
+
+	w := uploadAttachment(r, "exp-1", "IMG_0001.HEIC", "image/heic", []byte("\x00\x00\x00\x18ftypheic"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadAttachment_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		expenseID string
+		data      []byte
+		declared  string
+		want      int
+	}{
+		{"not an image", "exp-1", []byte("%PDF-1.7 receipt"), "image/png", http.StatusBadRequest},
+		{"empty file", "exp-1", nil, "image/png", http.StatusBadRequest},
+		{"too large", "exp-1", append(append([]byte{}, pngData...), make([]byte, 10<<20)...), "image/png", http.StatusBadRequest},
+		{"unknown expense", "exp-missing", pngData, "image/png", http.StatusNotFound},
+		{"other user's expense", "exp-other", pngData, "image/png", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockStorage()
+			r := setupAttachmentRouter(newMockAttachmentDB(), store)
+			w := uploadAttachment(r, tt.expenseID, "receipt.png", tt.declared, tt.data)
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if len(store.blobs) != 0 {
+				t.Fatal("nothing should be stored")
+			}
+		})
+	}
+}
+
+func TestUploadAttachment_Limit(t *testing.T) {
+	db := newMockAttachmentDB()
+	r := setupAttachmentRouter(db, newMockStorage())
+
+	for i := 0; i < 10; i++ {
+		if w := uploadAttachment(r, "exp-1", "receipt.png", "image/png", pngData); w.Code != http.StatusCreated {
+			t.Fatalf("upload %d: expected 201, got %d", i, w.Code)
+		}
+	}
+	w := uploadAttachment(r, "exp-1", "receipt.png", "image/png", pngData)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadAttachment_DBErrorRemovesBlob(t *testing.T) {
+	db := newMockAttachmentDB()
+	db.createErr = errors.New("db down")
+	store := newMockStorage()
+	r := setupAttachmentRouter(db, store)
+
+	w := uploadAttachment(r, "exp-1", "receipt.png", "image/png", pngData)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(store.blobs) != 0 {
+		t.Fatal("orphaned blob should be removed")
+	}
+}
+
+func TestAttachments_ListDownloadDelete(t *testing.T) {
+	db := newMockAttachmentDB()
+	store := newMockStorage()
+	r := setupAttachmentRouter(db, store)
+	uploadAttachment(r, "exp-1", "receipt.png", "image/png", pngData)
+	id := db.attachments[0].ID
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/exp-1/attachments", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var list []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0]["id"] != id {
+		t.Fatalf("unexpected list: %v", list)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/expenses/exp-1/attachments/"+id, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "image/png" || !bytes.Equal(w.Body.Bytes(), pngData) {
+		t.Fatalf("unexpected download: %s %q", w.Header().Get("Content-Type"), w.Body.Bytes())
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `inline; filename=receipt.png` {
+		t.Fatalf("unexpected Content-Disposition: %q", cd)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/expenses/exp-1/attachments/"+id, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/expenses/exp-1/attachments/"+id, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestAttachments_OtherUsersExpense(t *testing.T) {
+	r := setupAttachmentRouter(newMockAttachmentDB(), newMockStorage())
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/expenses/exp-other/attachments"},
+		{http.MethodGet, "/api/v1/expenses/exp-other/attachments/att-1"},
+		{http.MethodDelete, "/api/v1/expenses/exp-other/attachments/att-1"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("%s %s: expected 404, got %d", tc.method, tc.path, w.Code)
+		}
+	}
+}
@@ -24,19 +24,28 @@ type MockUser struct {
 }
 
 // MockRefreshToken is the refresh token representation used by the AuthDB interface.
+// FamilyID identifies the chain of tokens issued from one login.
 type MockRefreshToken struct {
 	UserID    string
 	TokenHash string
+	FamilyID  string
+	Revoked   bool
 }
 
 // AuthDB abstracts database operations for authentication.
 // This allows testing with mock implementations.
+//
+// StoreRefreshToken starts a new token family when familyID is empty.
+// GetRefreshTokenByHash returns revoked tokens too, so that their reuse can
+// be detected. RevokeRefreshToken returns 0 when the token was already
+// revoked.
 type AuthDB interface {
 	CreateUser(email, passwordHash string) (MockUser, error)
 	GetUserByEmail(email string) (MockUser, error)
-	StoreRefreshToken(userID, tokenHash string, expiresInDays int) error
+	StoreRefreshToken(userID, tokenHash, familyID string, expiresInDays int) error
 	GetRefreshTokenByHash(tokenHash string) (MockRefreshToken, error)
-	RevokeRefreshToken(tokenHash string) error
+	RevokeRefreshToken(tokenHash string) (int64, error)
+	RevokeRefreshTokenFamily(familyID string) error
 }
 
 // AuthHandler handles authentication HTTP requests.
@@ -102,7 +111,7 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 
 	// Store refresh token hash
 	tokenHash := h.authSvc.HashRefreshToken(pair.RefreshToken)
-	if err := h.db.StoreRefreshToken(user.ID, tokenHash, 30); err != nil {
+	if err := h.db.StoreRefreshToken(user.ID, tokenHash, "", 30); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
@@ -156,7 +165,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// Store refresh token hash
 	tokenHash := h.authSvc.HashRefreshToken(pair.RefreshToken)
-	if err := h.db.StoreRefreshToken(user.ID, tokenHash, 30); err != nil {
+	if err := h.db.StoreRefreshToken(user.ID, tokenHash, "", 30); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
@@ -176,6 +185,9 @@ type refreshRequest struct {
 }
 
 // Refresh validates a refresh token, revokes it, and returns a new token pair.
+// The new refresh token joins the old one's family. A refresh token that was
+// already revoked is being reused, most likely because it was stolen, so the
+// whole family is revoked and its holder has to log in again.
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var req refreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -184,7 +196,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	}
 
 	// Validate the refresh JWT
-	claims, err := h.authSvc.ValidateAccessToken(req.RefreshToken)
+	claims, err := h.authSvc.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
 		return
@@ -192,15 +204,30 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 
 	// Look up token hash in DB
 	tokenHash := h.authSvc.HashRefreshToken(req.RefreshToken)
-	_, err = h.db.GetRefreshTokenByHash(tokenHash)
+	stored, err := h.db.GetRefreshTokenByHash(tokenHash)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
 		return
 	}
 
-	// Revoke old token
-	if err := h.db.RevokeRefreshToken(tokenHash); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	// Revoke old token. Only one request can revoke it, so a token that was
+	// already revoked, even by a concurrent refresh, is being reused.
+	var rows int64
+	if !stored.Revoked {
+		rows, err = h.db.RevokeRefreshToken(tokenHash)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+	}
+
+	// Revoke the whole family on reuse
+	if rows == 0 {
+		if err := h.db.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
 		return
 	}
 
@@ -213,7 +240,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 
 	// Store new refresh token hash
 	newTokenHash := h.authSvc.HashRefreshToken(pair.RefreshToken)
-	if err := h.db.StoreRefreshToken(claims.Subject, newTokenHash, 30); err != nil {
+	if err := h.db.StoreRefreshToken(claims.Subject, newTokenHash, stored.FamilyID, 30); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
@@ -237,7 +264,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	}
 
 	tokenHash := h.authSvc.HashRefreshToken(req.RefreshToken)
-	_ = h.db.RevokeRefreshToken(tokenHash)
+	_, _ = h.db.RevokeRefreshToken(tokenHash)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
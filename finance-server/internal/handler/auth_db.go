@@ -54,7 +54,7 @@ func (db *PgAuthDB) GetUserByEmail(email string) (MockUser, error) {
 	}, nil
 }
 
-func (db *PgAuthDB) StoreRefreshToken(userID, tokenHash string, expiresInDays int) error {
+func (db *PgAuthDB) StoreRefreshToken(userID, tokenHash, familyID string, expiresInDays int) error {
 	uid := stringToUUID(userID)
 	expiresAt := pgtype.Timestamptz{
 		Time:  time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour),
@@ -64,6 +64,7 @@ func (db *PgAuthDB) StoreRefreshToken(userID, tokenHash string, expiresInDays in
 		UserID:    uid,
 		TokenHash: tokenHash,
 		ExpiresAt: expiresAt,
+		FamilyID:  stringToNullableUUID(familyID),
 	})
 	return err
 }
@@ -78,13 +79,19 @@ func (db *PgAuthDB) GetRefreshTokenByHash(tokenHash string) (MockRefreshToken, e
 	return MockRefreshToken{
 		UserID:    uid,
 		TokenHash: row.TokenHash,
+		FamilyID:  uuidToString(row.FamilyID),
+		Revoked:   row.Revoked,
 	}, nil
 }
 
-func (db *PgAuthDB) RevokeRefreshToken(tokenHash string) error {
+func (db *PgAuthDB) RevokeRefreshToken(tokenHash string) (int64, error) {
 	return db.queries.RevokeRefreshToken(context.Background(), tokenHash)
 }
 
+func (db *PgAuthDB) RevokeRefreshTokenFamily(familyID string) error {
+	return db.queries.RevokeRefreshTokenFamily(context.Background(), stringToUUID(familyID))
+}
+
 // uuidToString converts a pgtype.UUID to its string representation.
 func uuidToString(u pgtype.UUID) string {
 	if !u.Valid {
@@ -3,6 +3,7 @@ package handler_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -18,6 +19,7 @@ const testJWTSecret = "test-secret-key-for-unit-tests-32b"
 type mockDB struct {
 	users         map[string]*handler.MockUser
 	refreshTokens map[string]*handler.MockRefreshToken
+	nextFamily    int
 }
 
 func newMockDB() *mockDB {
@@ -48,10 +50,15 @@ func (m *mockDB) GetUserByEmail(email string) (handler.MockUser, error) {
 	return *u, nil
 }
 
-func (m *mockDB) StoreRefreshToken(userID, tokenHash string, expiresInDays int) error {
+func (m *mockDB) StoreRefreshToken(userID, tokenHash, familyID string, expiresInDays int) error {
+	if familyID == "" {
+		m.nextFamily++
+		familyID = fmt.Sprintf("family-%d", m.nextFamily)
+	}
 	m.refreshTokens[tokenHash] = &handler.MockRefreshToken{
 		UserID:    userID,
 		TokenHash: tokenHash,
+		FamilyID:  familyID,
 	}
 	return nil
 }
@@ -64,8 +71,21 @@ func (m *mockDB) GetRefreshTokenByHash(tokenHash string) (handler.MockRefreshTok
 	return *rt, nil
 }
 
-func (m *mockDB) RevokeRefreshToken(tokenHash string) error {
-	delete(m.refreshTokens, tokenHash)
+func (m *mockDB) RevokeRefreshToken(tokenHash string) (int64, error) {
+	rt, exists := m.refreshTokens[tokenHash]
+	if !exists || rt.Revoked {
+		return 0, nil
+	}
+	rt.Revoked = true
+	return 1, nil
+}
+
+func (m *mockDB) RevokeRefreshTokenFamily(familyID string) error {
+	for _, rt := range m.refreshTokens {
+		if rt.FamilyID == familyID {
+			rt.Revoked = true
+		}
+	}
 	return nil
 }
 
@@ -294,3 +314,160 @@ func TestLogin_WrongPassword(t *testing.T) {
 		t.Fatalf("expected specific error, got: %v", resp["error"])
 	}
 }
+
+// signupTokens signs up a test user and returns the issued token pair.
+func signupTokens(t *testing.T, r *gin.Engine) (accessToken, refreshToken string) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{
+		"email":    "test@example.com",
+		"password": "password123",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/signup", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp["access_token"].(string), resp["refresh_token"].(string)
+}
+
+// loginTokens logs the test user in and returns the issued token pair.
+func loginTokens(t *testing.T, r *gin.Engine) (accessToken, refreshToken string) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{
+		"email":    "test@example.com",
+		"password": "password123",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp["access_token"].(string), resp["refresh_token"].(string)
+}
+
+// refresh posts a refresh token to the refresh endpoint.
+func refresh(r *gin.Engine, refreshToken string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"refresh_token": refreshToken})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRefresh_Success(t *testing.T) {
+	db := newMockDB()
+	authSvc := service.NewAuthService(testJWTSecret)
+	r := setupRouter(db, authSvc)
+	_, refreshToken := signupTokens(t, r)
+
+	w := refresh(r, refreshToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	newToken, _ := resp["refresh_token"].(string)
+	if newToken == "" || newToken == refreshToken {
+		t.Fatalf("expected a new refresh token, got %v", resp["refresh_token"])
+	}
+
+	oldRT := db.refreshTokens[authSvc.HashRefreshToken(refreshToken)]
+	newRT := db.refreshTokens[authSvc.HashRefreshToken(newToken)]
+	if !oldRT.Revoked {
+		t.Fatal("old refresh token should be revoked")
+	}
+	if newRT.Revoked || newRT.FamilyID != oldRT.FamilyID {
+		t.Fatalf("new refresh token should be active in the old one's family, got %+v", newRT)
+	}
+}
+
+func TestRefresh_RejectsAccessToken(t *testing.T) {
+	db := newMockDB()
+	authSvc := service.NewAuthService(testJWTSecret)
+	r := setupRouter(db, authSvc)
+	accessToken, _ := signupTokens(t, r)
+
+	// Even if its hash were stored, an access token is not a refresh token.
+	db.StoreRefreshToken("test-user-id", authSvc.HashRefreshToken(accessToken), "", 30)
+
+	w := refresh(r, accessToken)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	db := newMockDB()
+	authSvc := service.NewAuthService(testJWTSecret)
+	r := setupRouter(db, authSvc)
+	_, firstToken := signupTokens(t, r)
+
+	w := refresh(r, firstToken)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	secondToken := resp["refresh_token"].(string)
+
+	// A second login starts a separate family that must survive.
+	_, otherToken := loginTokens(t, r)
+
+	// Replaying the rotated token is rejected and revokes its successor.
+	if w := refresh(r, firstToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("reuse: expected 401, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := refresh(r, secondToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("successor: expected 401, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := refresh(r, otherToken); w.Code != http.StatusOK {
+		t.Fatalf("other family: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// racingDB revokes a refresh token right after it is looked up, as a
+// concurrent refresh with the same token would.
+type racingDB struct {
+	*mockDB
+}
+
+func (m racingDB) GetRefreshTokenByHash(tokenHash string) (handler.MockRefreshToken, error) {
+	rt, err := m.mockDB.GetRefreshTokenByHash(tokenHash)
+	if err == nil {
+		m.mockDB.refreshTokens[tokenHash].Revoked = true
+	}
+	return rt, err
+}
+
+func TestRefresh_ConcurrentReuseRevokesFamily(t *testing.T) {
+	db := newMockDB()
+	authSvc := service.NewAuthService(testJWTSecret)
+	_, token := signupTokens(t, setupRouter(db, authSvc))
+
+	// The competing refresh stored a successor in the same family.
+	familyID := db.refreshTokens[authSvc.HashRefreshToken(token)].FamilyID
+	db.StoreRefreshToken("test-user-id", "successor-hash", familyID, 30)
+
+	r := setupRouter(racingDB{db}, authSvc)
+	if w := refresh(r, token); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", w.Code, w.Body.String())
+	}
+	if !db.refreshTokens["successor-hash"].Revoked {
+		t.Fatal("the token family should be revoked")
+	}
+	if len(db.refreshTokens) != 2 {
+		t.Fatalf("no new refresh token should be stored, got %d tokens", len(db.refreshTokens))
+	}
+}
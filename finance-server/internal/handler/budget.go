@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sentinel errors for budget operations.
+var (
+	ErrBudgetNotFound        = errors.New("budget not found")
+	ErrInvalidBudgetCategory = errors.New("invalid budget category")
+)
+
+// MockBudget is the budget representation used by the BudgetDB interface.
+// An empty CategoryID means the budget is an overall monthly cap.
+type MockBudget struct {
+	ID           string
+	CategoryID   string
+	CategoryName string
+	AmountCents  int64
+	UpdatedAt    time.Time
+}
+
+// BudgetDB abstracts database operations for monthly budgets.
+// This allows testing with mock implementations.
+// Family budgets may only use the family's shared categories or its
+// non-viewer members' categories, and like user budgets only expense
+// categories; others return ErrInvalidBudgetCategory.
+type BudgetDB interface {
+	UpsertUserBudget(userID, categoryID string, amountCents int64) (MockBudget, error)
+	GetUserBudgets(userID string) ([]MockBudget, error)
+	DeleteUserBudget(id, userID string) error
+	UpsertFamilyBudget(familyID, categoryID string, amountCents int64) (MockBudget, error)
+	GetFamilyBudgets(familyID string) ([]MockBudget, error)
+	DeleteFamilyBudget(id, familyID string) error
+}
+
+// BudgetHandler handles budget HTTP requests.
+type BudgetHandler struct {
+	db       BudgetDB
+	familyDB FamilyDB
+}
+
+// NewBudgetHandler creates a BudgetHandler with the given databases.
+func NewBudgetHandler(db BudgetDB, familyDB FamilyDB) *BudgetHandler {
+	return &BudgetHandler{db: db, familyDB: familyDB}
+}
+
+type setBudgetRequest struct {
+	CategoryID  string `json:"category_id"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+// List handles GET /api/v1/budgets.
+func (h *BudgetHandler) List(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	budgets, err := h.db.GetUserBudgets(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budgetList(budgets))
+}
+
+// Set handles PUT /api/v1/budgets.
+// Omitting category_id sets the overall monthly cap.
+func (h *BudgetHandler) Set(c *gin.Context) {
+	var req setBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.AmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount_cents must be greater than 0"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	budget, err := h.db.UpsertUserBudget(userID, req.CategoryID, req.AmountCents)
+	if err != nil {
+		if errors.Is(err, ErrInvalidBudgetCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budgetResponse(budget))
+}
+
+// Delete handles DELETE /api/v1/budgets/:id.
+func (h *BudgetHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("user_id")
+
+	if err := h.db.DeleteUserBudget(id, userID); err != nil {
+		if errors.Is(err, ErrBudgetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListFamily handles GET /api/v1/families/:familyId/budgets.
+func (h *BudgetHandler) ListFamily(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
+		return
+	}
+
+	budgets, err := h.db.GetFamilyBudgets(family.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budgetList(budgets))
+}
+
+// SetFamily handles PUT /api/v1/families/:familyId/budgets.
+func (h *BudgetHandler) SetFamily(c *gin.Context) {
+	var req setBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.AmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount_cents must be greater than 0"})
+		return
+	}
+
+	family, ok := authorizeFamily(c, h.familyDB, PermManageFamily)
+	if !ok {
+		return
+	}
+
+	budget, err := h.db.UpsertFamilyBudget(family.ID, req.CategoryID, req.AmountCents)
+	if err != nil {
+		if errors.Is(err, ErrInvalidBudgetCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budgetResponse(budget))
+}
+
+// DeleteFamily handles DELETE /api/v1/families/:familyId/budgets/:id.
+func (h *BudgetHandler) DeleteFamily(c *gin.Context) {
+	id := c.Param("id")
+
+	family, ok := authorizeFamily(c, h.familyDB, PermManageFamily)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteFamilyBudget(id, family.ID); err != nil {
+		if errors.Is(err, ErrBudgetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Budget not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func budgetResponse(b MockBudget) gin.H {
+	var categoryID any
+	if b.CategoryID != "" {
+		categoryID = b.CategoryID
+	}
+	return gin.H{
+		"id":            b.ID,
+		"category_id":   categoryID,
+		"category_name": b.CategoryName,
+		"amount_cents":  b.AmountCents,
+		"updated_at":    b.UpdatedAt,
+	}
+}
+
+func budgetList(budgets []MockBudget) []gin.H {
+	result := make([]gin.H, len(budgets))
+	for i, b := range budgets {
+		result[i] = budgetResponse(b)
+	}
+	return result
+}
+
+// budgetStatus compares budgets against actual spend for a period.
+// spentByCategory maps category ID to spend; totalSpent is the spend across
+// all categories. The overall entry is nil when no overall cap is set.
+func budgetStatus(budgets []MockBudget, spentByCategory map[string]int64, totalSpent int64) gin.H {
+	var overall any
+	byCategory := make([]gin.H, 0, len(budgets))
+
+	for _, b := range budgets {
+		spent := totalSpent
+		if b.CategoryID != "" {
+			spent = spentByCategory[b.CategoryID]
+		}
+
+		entry := gin.H{
+			"budget_id":       b.ID,
+			"budget_cents":    b.AmountCents,
+			"spent_cents":     spent,
+			"remaining_cents": b.AmountCents - spent,
+			"over_budget":     spent > b.AmountCents,
+		}
+
+		if b.CategoryID == "" {
+			overall = entry
+			continue
+		}
+		entry["category_id"] = b.CategoryID
+		entry["category_name"] = b.CategoryName
+		byCategory = append(byCategory, entry)
+	}
+
+	return gin.H{
+		"overall":     overall,
+		"by_category": byCategory,
+	}
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// PgBudgetDB implements BudgetDB using sqlc-generated queries against PostgreSQL.
+type PgBudgetDB struct {
+	queries *sqlc.Queries
+}
+
+// NewPgBudgetDB creates a PgBudgetDB wrapping sqlc.Queries.
+func NewPgBudgetDB(queries *sqlc.Queries) *PgBudgetDB {
+	return &PgBudgetDB{queries: queries}
+}
+
+func (db *PgBudgetDB) UpsertUserBudget(userID, categoryID string, amountCents int64) (MockBudget, error) {
+	uid := stringToUUID(userID)
+	cid := stringToNullableUUID(categoryID)
+
+	if cid.Valid {
+		cat, err := db.queries.GetCategoryByID(context.Background(), sqlc.GetCategoryByIDParams{
+			ID:     cid,
+			UserID: uid,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return MockBudget{}, ErrInvalidBudgetCategory
+			}
+			return MockBudget{}, err
+		}
+		if cat.Kind != CategoryKindExpense {
+			return MockBudget{}, ErrInvalidBudgetCategory
+		}
+	}
+
+	row, err := db.queries.UpsertUserBudget(context.Background(), sqlc.UpsertUserBudgetParams{
+		UserID:      uid,
+		CategoryID:  cid,
+		AmountCents: amountCents,
+	})
+	if err != nil {
+		return MockBudget{}, err
+	}
+
+	return MockBudget{
+		ID:          uuidToString(row.ID),
+		CategoryID:  uuidToString(row.CategoryID),
+		AmountCents: row.AmountCents,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}, nil
+}
+
+func (db *PgBudgetDB) GetUserBudgets(userID string) ([]MockBudget, error) {
+	rows, err := db.queries.GetUserBudgets(context.Background(), stringToUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := make([]MockBudget, len(rows))
+	for i, row := range rows {
+		budgets[i] = MockBudget{
+			ID:           uuidToString(row.ID),
+			CategoryID:   uuidToString(row.CategoryID),
+			CategoryName: row.CategoryName.String,
+			AmountCents:  row.AmountCents,
+			UpdatedAt:    row.UpdatedAt.Time,
+		}
+	}
+	return budgets, nil
+}
+
+func (db *PgBudgetDB) DeleteUserBudget(id, userID string) error {
+	rowsAffected, err := db.queries.DeleteUserBudget(context.Background(), sqlc.DeleteUserBudgetParams{
+		ID:     stringToUUID(id),
+		UserID: stringToUUID(userID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrBudgetNotFound
+	}
+	return nil
+}
+
+func (db *PgBudgetDB) UpsertFamilyBudget(familyID, categoryID string, amountCents int64) (MockBudget, error) {
+	fid := stringToUUID(familyID)
+	cid := stringToNullableUUID(categoryID)
+
+	if cid.Valid {
+		kind, err := db.queries.GetFamilyBudgetCategoryKind(context.Background(), sqlc.GetFamilyBudgetCategoryKindParams{
+			ID:       cid,
+			FamilyID: fid,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return MockBudget{}, ErrInvalidBudgetCategory
+			}
+			return MockBudget{}, err
+		}
+		if kind != CategoryKindExpense {
+			return MockBudget{}, ErrInvalidBudgetCategory
+		}
+	}
+
+	row, err := db.queries.UpsertFamilyBudget(context.Background(), sqlc.UpsertFamilyBudgetParams{
+		FamilyID:    fid,
+		CategoryID:  cid,
+		AmountCents: amountCents,
+	})
+	if err != nil {
+		return MockBudget{}, err
+	}
+
+	return MockBudget{
+		ID:          uuidToString(row.ID),
+		CategoryID:  uuidToString(row.CategoryID),
+		AmountCents: row.AmountCents,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}, nil
+}
+
+func (db *PgBudgetDB) GetFamilyBudgets(familyID string) ([]MockBudget, error) {
+	rows, err := db.queries.GetFamilyBudgets(context.Background(), stringToUUID(familyID))
+	if err != nil {
+		return nil, err
+	}
+
+	budgets := make([]MockBudget, len(rows))
+	for i, row := range rows {
+		budgets[i] = MockBudget{
+			ID:           uuidToString(row.ID),
+			CategoryID:   uuidToString(row.CategoryID),
+			CategoryName: row.CategoryName.String,
+			AmountCents:  row.AmountCents,
+			UpdatedAt:    row.UpdatedAt.Time,
+		}
+	}
+	return budgets, nil
+}
+
+func (db *PgBudgetDB) DeleteFamilyBudget(id, familyID string) error {
+	rowsAffected, err := db.queries.DeleteFamilyBudget(context.Background(), sqlc.DeleteFamilyBudgetParams{
+		ID:       stringToUUID(id),
+		FamilyID: stringToUUID(familyID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrBudgetNotFound
+	}
+	return nil
+}
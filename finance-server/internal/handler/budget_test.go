@@ -0,0 +1,339 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+// mockBudgetDB implements handler.BudgetDB for testing.
+// Budgets are keyed by owner (user or family ID) and category ID.
+type mockBudgetDB struct {
+	userBudgets   map[string][]handler.MockBudget
+	familyBudgets map[string][]handler.MockBudget
+	nextID        int
+}
+
+func newMockBudgetDB() *mockBudgetDB {
+	return &mockBudgetDB{
+		userBudgets:   make(map[string][]handler.MockBudget),
+		familyBudgets: make(map[string][]handler.MockBudget),
+		nextID:        1,
+	}
+}
+
+func (m *mockBudgetDB) upsert(store map[string][]handler.MockBudget, ownerID, categoryID string, amountCents int64) handler.MockBudget {
+	for i, b := range store[ownerID] {
+		if b.CategoryID == categoryID {
+			store[ownerID][i].AmountCents = amountCents
+			store[ownerID][i].UpdatedAt = time.Now()
+			return store[ownerID][i]
+		}
+	}
+	b := handler.MockBudget{
+		ID:          "budget-" + string(rune('0'+m.nextID)),
+		CategoryID:  categoryID,
+		AmountCents: amountCents,
+		UpdatedAt:   time.Now(),
+	}
+	m.nextID++
+	store[ownerID] = append(store[ownerID], b)
+	return b
+}
+
+func (m *mockBudgetDB) remove(store map[string][]handler.MockBudget, id, ownerID string) error {
+	for i, b := range store[ownerID] {
+		if b.ID == id {
+			store[ownerID] = append(store[ownerID][:i], store[ownerID][i+1:]...)
+			return nil
+		}
+	}
+	return handler.ErrBudgetNotFound
+}
+
+func (m *mockBudgetDB) UpsertUserBudget(userID, categoryID string, amountCents int64) (handler.MockBudget, error) {
+	if categoryID == testIncomeCategoryID {
+		return handler.MockBudget{}, handler.ErrInvalidBudgetCategory
+	}
+	return m.upsert(m.userBudgets, userID, categoryID, amountCents), nil
+}
+
+func (m *mockBudgetDB) GetUserBudgets(userID string) ([]handler.MockBudget, error) {
+	return m.userBudgets[userID], nil
+}
+
+func (m *mockBudgetDB) DeleteUserBudget(id, userID string) error {
+	return m.remove(m.userBudgets, id, userID)
+}
+
+func (m *mockBudgetDB) UpsertFamilyBudget(familyID, categoryID string, amountCents int64) (handler.MockBudget, error) {
+	if categoryID == testIncomeCategoryID {
+		return handler.MockBudget{}, handler.ErrInvalidBudgetCategory
+	}
+	return m.upsert(m.familyBudgets, familyID, categoryID, amountCents), nil
+}
+
+func (m *mockBudgetDB) GetFamilyBudgets(familyID string) ([]handler.MockBudget, error) {
+	return m.familyBudgets[familyID], nil
+}
+
+func (m *mockBudgetDB) DeleteFamilyBudget(id, familyID string) error {
+	return m.remove(m.familyBudgets, id, familyID)
+}
+
+func setupBudgetRouter(db handler.BudgetDB, familyDB handler.FamilyDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewBudgetHandler(db, familyDB)
+
+	api := r.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User-ID"))
+		c.Next()
+	})
+	{
+		api.GET("/budgets", h.List)
+		api.PUT("/budgets", h.Set)
+		api.DELETE("/budgets/:id", h.Delete)
+		api.GET("/families/me/budgets", h.ListFamily)
+		api.PUT("/families/me/budgets", h.SetFamily)
+		api.DELETE("/families/me/budgets/:id", h.DeleteFamily)
+	}
+	return r
+}
+
+func putBudget(r *gin.Engine, path, userID string, payload map[string]any) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSetBudget(t *testing.T) {
+	t.Run("overall and per-category budgets", func(t *testing.T) {
+		db := newMockBudgetDB()
+		r := setupBudgetRouter(db, newMockFamilyDB())
+
+		w := putBudget(r, "/api/v1/budgets", testUserID, map[string]any{"amount_cents": 100000})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["category_id"] != nil {
+			t.Fatalf("expected null category_id for overall budget, got %v", resp["category_id"])
+		}
+
+		w = putBudget(r, "/api/v1/budgets", testUserID, map[string]any{
+			"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+			"amount_cents": 30000,
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/budgets", nil)
+		req.Header.Set("X-User-ID", testUserID)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var list []map[string]any
+		json.Unmarshal(w.Body.Bytes(), &list)
+		if len(list) != 2 {
+			t.Fatalf("expected 2 budgets, got %d", len(list))
+		}
+	})
+
+	t.Run("updates existing budget", func(t *testing.T) {
+		db := newMockBudgetDB()
+		r := setupBudgetRouter(db, newMockFamilyDB())
+
+		putBudget(r, "/api/v1/budgets", testUserID, map[string]any{"amount_cents": 100000})
+		w := putBudget(r, "/api/v1/budgets", testUserID, map[string]any{"amount_cents": 120000})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if len(db.userBudgets[testUserID]) != 1 {
+			t.Fatalf("expected 1 budget, got %d", len(db.userBudgets[testUserID]))
+		}
+		if db.userBudgets[testUserID][0].AmountCents != 120000 {
+			t.Fatalf("expected amount 120000, got %d", db.userBudgets[testUserID][0].AmountCents)
+		}
+	})
+
+	t.Run("rejects non-positive amount", func(t *testing.T) {
+		r := setupBudgetRouter(newMockBudgetDB(), newMockFamilyDB())
+		w := putBudget(r, "/api/v1/budgets", testUserID, map[string]any{"amount_cents": 0})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("rejects income category", func(t *testing.T) {
+		r := setupBudgetRouter(newMockBudgetDB(), newMockFamilyDB())
+		w := putBudget(r, "/api/v1/budgets", testUserID, map[string]any{
+			"category_id":  testIncomeCategoryID,
+			"amount_cents": 1000,
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestDeleteBudget(t *testing.T) {
+	db := newMockBudgetDB()
+	r := setupBudgetRouter(db, newMockFamilyDB())
+	b, _ := db.UpsertUserBudget(testUserID, "", 5000)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/budgets/"+b.ID, nil)
+	req.Header.Set("X-User-ID", testUserID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/budgets/"+b.ID, nil)
+	req.Header.Set("X-User-ID", testUserID)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestFamilyBudgets(t *testing.T) {
+	newFamily := func() *mockFamilyDB {
+		fdb := newMockFamilyDB()
+		fdb.families["family-1"] = &handler.MockFamily{ID: "family-1", Name: "Smith Family", AdminUserID: "user-1"}
+		fdb.userFamily["user-1"] = "family-1"
+		fdb.userFamily["user-2"] = "family-1"
+		return fdb
+	}
+
+	t.Run("admin can set budget", func(t *testing.T) {
+		db := newMockBudgetDB()
+		r := setupBudgetRouter(db, newFamily())
+
+		w := putBudget(r, "/api/v1/families/me/budgets", "user-1", map[string]any{"amount_cents": 200000})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if len(db.familyBudgets["family-1"]) != 1 {
+			t.Fatalf("expected 1 family budget, got %d", len(db.familyBudgets["family-1"]))
+		}
+	})
+
+	t.Run("rejects income category", func(t *testing.T) {
+		db := newMockBudgetDB()
+		r := setupBudgetRouter(db, newFamily())
+		w := putBudget(r, "/api/v1/families/me/budgets", "user-1", map[string]any{
+			"category_id":  testIncomeCategoryID,
+			"amount_cents": 1000,
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+		if len(db.familyBudgets["family-1"]) != 0 {
+			t.Fatalf("expected no family budget, got %d", len(db.familyBudgets["family-1"]))
+		}
+	})
+
+	t.Run("member cannot set budget", func(t *testing.T) {
+		r := setupBudgetRouter(newMockBudgetDB(), newFamily())
+		w := putBudget(r, "/api/v1/families/me/budgets", "user-2", map[string]any{"amount_cents": 200000})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("member can list budgets", func(t *testing.T) {
+		db := newMockBudgetDB()
+		db.UpsertFamilyBudget("family-1", "", 200000)
+		r := setupBudgetRouter(db, newFamily())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/budgets", nil)
+		req.Header.Set("X-User-ID", "user-2")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var list []map[string]any
+		json.Unmarshal(w.Body.Bytes(), &list)
+		if len(list) != 1 {
+			t.Fatalf("expected 1 budget, got %d", len(list))
+		}
+	})
+
+	t.Run("no family", func(t *testing.T) {
+		r := setupBudgetRouter(newMockBudgetDB(), newMockFamilyDB())
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/budgets", nil)
+		req.Header.Set("X-User-ID", "user-3")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestSummary_BudgetStatus(t *testing.T) {
+	const foodID = "550e8400-e29b-41d4-a716-446655440001"
+	const transportID = "550e8400-e29b-41d4-a716-446655440002"
+
+	db := &mockSummaryDB{
+		categoryTotals: []handler.CategoryTotal{
+			{CategoryID: foodID, CategoryName: "Food", TotalCents: 45000, Count: 12},
+			{CategoryID: transportID, CategoryName: "Transport", TotalCents: 30000, Count: 8},
+		},
+	}
+	budgetDB := newMockBudgetDB()
+	budgetDB.UpsertUserBudget(testUserID, "", 100000)
+	budgetDB.UpsertUserBudget(testUserID, foodID, 40000)
+	r := setupSummaryRouter(db, budgetDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?month=2026-03", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Budgets struct {
+			Overall struct {
+				SpentCents     int64 `json:"spent_cents"`
+				RemainingCents int64 `json:"remaining_cents"`
+				OverBudget     bool  `json:"over_budget"`
+			} `json:"overall"`
+			ByCategory []struct {
+				CategoryID     string `json:"category_id"`
+				SpentCents     int64  `json:"spent_cents"`
+				RemainingCents int64  `json:"remaining_cents"`
+				OverBudget     bool   `json:"over_budget"`
+			} `json:"by_category"`
+		} `json:"budgets"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp.Budgets.Overall.SpentCents != 75000 || resp.Budgets.Overall.RemainingCents != 25000 || resp.Budgets.Overall.OverBudget {
+		t.Fatalf("unexpected overall status: %+v", resp.Budgets.Overall)
+	}
+	if len(resp.Budgets.ByCategory) != 1 {
+		t.Fatalf("expected 1 category budget, got %d", len(resp.Budgets.ByCategory))
+	}
+	food := resp.Budgets.ByCategory[0]
+	if food.CategoryID != foodID || food.SpentCents != 45000 || food.RemainingCents != -5000 || !food.OverBudget {
+		t.Fatalf("unexpected food status: %+v", food)
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,43 +11,202 @@ import (
 // Sentinel errors for category operations.
 var (
 	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryInUse    = errors.New("category in use")
+)
+
+// Category kinds. Expense categories classify expenses, income categories
+// classify incomes.
+const (
+	CategoryKindExpense = "expense"
+	CategoryKindIncome  = "income"
 )
 
 // MockCategory is the category representation used by the CategoryDB interface.
+// Personal categories have a UserID, family categories a FamilyID.
+// DefaultVisibility is the visibility of expenses created in the category
+// without one. ArchivedAt is set once the category is archived. ParentID
+// is set for subcategories; categories nest one level deep.
 type MockCategory struct {
-	ID        string
-	UserID    string
-	Name      string
-	Icon      string
-	Color     string
-	SortOrder int
+	ID                string
+	UserID            string
+	FamilyID          string
+	Name              string
+	Icon              string
+	Color             string
+	Kind              string
+	SortOrder         int
+	DefaultVisibility string
+	ArchivedAt        *time.Time
+	ParentID          string
+}
+
+// CategoryUsage counts the records that reference a category.
+type CategoryUsage struct {
+	Expenses          int64
+	Incomes           int64
+	RecurringExpenses int64
+	Budgets           int64
+	CategoryRules     int64
+	CategoryMappings  int64
 }
 
 // CategoryDB abstracts database operations for categories.
 // This allows testing with mock implementations.
+// An empty defaultVisibility keeps the current default and a nil parentID
+// the current parent on update; an empty parentID means top level.
+// DeleteCategory returns ErrCategoryInUse, along with the counts, while
+// records still reference the category; ReassignCategory moves them to
+// targetID and deletes it atomically.
 type CategoryDB interface {
-	CreateCategory(userID, name, icon, color string) (MockCategory, error)
-	GetCategoriesByUser(userID string) ([]MockCategory, error)
+	CreateCategory(userID, name, icon, color, kind, defaultVisibility, parentID string) (MockCategory, error)
+	GetCategoriesByUser(userID, kind string, includeArchived bool) ([]MockCategory, error)
 	GetCategoryByID(id, userID string) (MockCategory, error)
-	UpdateCategory(id, userID, name, icon, color string) error
-	DeleteCategory(id, userID string) error
+	UpdateCategory(id, userID, name, icon, color, defaultVisibility string, parentID *string) error
+	DeleteCategory(id, userID string) (CategoryUsage, error)
+	ReassignCategory(id, userID, targetID string) error
+	ArchiveCategory(id, userID string) error
 	UpdateCategorySortOrder(id, userID string, sortOrder int) error
 }
 
 // CategoryHandler handles category HTTP requests.
+// Changes are recorded in the activity log of the families that share the
+// user's expenses.
 type CategoryHandler struct {
-	db CategoryDB
+	db       CategoryDB
+	activity ActivityDB
 }
 
-// NewCategoryHandler creates a CategoryHandler with the given database.
-func NewCategoryHandler(db CategoryDB) *CategoryHandler {
-	return &CategoryHandler{db: db}
+// NewCategoryHandler creates a CategoryHandler with the given databases.
+func NewCategoryHandler(db CategoryDB, activity ActivityDB) *CategoryHandler {
+	return &CategoryHandler{db: db, activity: activity}
 }
 
 type createCategoryRequest struct {
-	Name  string `json:"name"`
-	Icon  string `json:"icon"`
-	Color string `json:"color"`
+	Name              string `json:"name"`
+	Icon              string `json:"icon"`
+	Color             string `json:"color"`
+	Kind              string `json:"kind"`
+	DefaultVisibility string `json:"default_visibility"`
+	ParentID          string `json:"parent_id"`
+}
+
+// normalizeCategoryKind defaults an empty kind to expense and reports
+// whether the result is a known kind.
+func normalizeCategoryKind(kind string) (string, bool) {
+	if kind == "" {
+		return CategoryKindExpense, true
+	}
+	return kind, kind == CategoryKindExpense || kind == CategoryKindIncome
+}
+
+// normalizeVisibility defaults an empty visibility to family and reports
+// whether the result is a known visibility.
+func normalizeVisibility(visibility string) (string, bool) {
+	if visibility == "" {
+		return VisibilityFamily, true
+	}
+	return visibility, validVisibility(visibility)
+}
+
+func categoryJSON(cat MockCategory) gin.H {
+	var parentID any
+	if cat.ParentID != "" {
+		parentID = cat.ParentID
+	}
+	return gin.H{
+		"id":                 cat.ID,
+		"user_id":            cat.UserID,
+		"name":               cat.Name,
+		"icon":               cat.Icon,
+		"color":              cat.Color,
+		"kind":               cat.Kind,
+		"sort_order":         cat.SortOrder,
+		"default_visibility": cat.DefaultVisibility,
+		"archived_at":        cat.ArchivedAt,
+		"parent_id":          parentID,
+	}
+}
+
+// categoryTree nests subcategories under their parents, keeping the order
+// of cats within each level. Subcategories whose parent is not in cats are
+// listed at the top level.
+func categoryTree(cats []MockCategory) []gin.H {
+	present := make(map[string]bool, len(cats))
+	for _, cat := range cats {
+		present[cat.ID] = true
+	}
+
+	children := make(map[string][]gin.H)
+	for _, cat := range cats {
+		if cat.ParentID != "" && present[cat.ParentID] {
+			node := categoryJSON(cat)
+			node["children"] = []gin.H{}
+			children[cat.ParentID] = append(children[cat.ParentID], node)
+		}
+	}
+
+	roots := make([]gin.H, 0, len(cats))
+	for _, cat := range cats {
+		if cat.ParentID != "" && present[cat.ParentID] {
+			continue
+		}
+		kids := children[cat.ID]
+		if kids == nil {
+			kids = []gin.H{}
+		}
+		node := categoryJSON(cat)
+		node["children"] = kids
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// checkParent verifies that a category of the given kind can be placed
+// under parentID, writing a 400 response when it cannot. id is empty for
+// new categories.
+func (h *CategoryHandler) checkParent(c *gin.Context, userID, id, kind, parentID string) bool {
+	if parentID == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A category cannot be its own parent"})
+		return false
+	}
+
+	parent, err := h.db.GetCategoryByID(parentID, userID)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parent_id category not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	switch {
+	case parent.ParentID != "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Subcategories cannot have subcategories"})
+		return false
+	case parent.Kind != kind:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parent_id category must be of the same kind"})
+		return false
+	case parent.ArchivedAt != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parent_id category is archived"})
+		return false
+	}
+
+	if id == "" {
+		return true
+	}
+	cats, err := h.db.GetCategoriesByUser(userID, "", true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return false
+	}
+	for _, cat := range cats {
+		if cat.ParentID == id {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A category with subcategories cannot become a subcategory"})
+			return false
+		}
+	}
+	return true
 }
 
 // Create handles POST /api/v1/categories.
@@ -62,54 +222,67 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 		return
 	}
 
+	kind, ok := normalizeCategoryKind(req.Kind)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be expense or income"})
+		return
+	}
+
+	visibility, ok := normalizeVisibility(req.DefaultVisibility)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "default_visibility must be family or private"})
+		return
+	}
+
 	userID := c.GetString("user_id")
-	cat, err := h.db.CreateCategory(userID, req.Name, req.Icon, req.Color)
+	if req.ParentID != "" && !h.checkParent(c, userID, "", kind, req.ParentID) {
+		return
+	}
+
+	cat, err := h.db.CreateCategory(userID, req.Name, req.Icon, req.Color, kind, visibility, req.ParentID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"id":         cat.ID,
-		"user_id":    cat.UserID,
-		"name":       cat.Name,
-		"icon":       cat.Icon,
-		"color":      cat.Color,
-		"sort_order": cat.SortOrder,
-	})
+	recordMemberEvent(c, h.activity, ActionCategoryCreated, cat.ID, nil, categorySnapshot(cat))
+
+	c.JSON(http.StatusCreated, categoryJSON(cat))
 }
 
 // List handles GET /api/v1/categories.
+// An optional kind query parameter restricts the list to expense or income categories.
+// Archived categories are left out unless include_archived=true.
+// Top-level categories are returned with their subcategories as children.
 func (h *CategoryHandler) List(c *gin.Context) {
 	userID := c.GetString("user_id")
-	cats, err := h.db.GetCategoriesByUser(userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+
+	kind := c.Query("kind")
+	if kind != "" && kind != CategoryKindExpense && kind != CategoryKindIncome {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be expense or income"})
 		return
 	}
 
-	result := make([]gin.H, len(cats))
-	for i, cat := range cats {
-		result[i] = gin.H{
-			"id":         cat.ID,
-			"user_id":    cat.UserID,
-			"name":       cat.Name,
-			"icon":       cat.Icon,
-			"color":      cat.Color,
-			"sort_order": cat.SortOrder,
-		}
+	cats, err := h.db.GetCategoriesByUser(userID, kind, c.Query("include_archived") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusOK, categoryTree(cats))
 }
 
 type updateCategoryRequest struct {
-	Name  string `json:"name"`
-	Icon  string `json:"icon"`
-	Color string `json:"color"`
+	Name              string  `json:"name"`
+	Icon              string  `json:"icon"`
+	Color             string  `json:"color"`
+	DefaultVisibility string  `json:"default_visibility"`
+	ParentID          *string `json:"parent_id"`
 }
 
 // Update handles PUT /api/v1/categories/:id.
+// parent_id moves the category under another one, or to the top level when
+// empty; the parent is kept when parent_id is omitted.
 func (h *CategoryHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	userID := c.GetString("user_id")
@@ -125,7 +298,12 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 		return
 	}
 
-	err := h.db.UpdateCategory(id, userID, req.Name, req.Icon, req.Color)
+	if !validVisibility(req.DefaultVisibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "default_visibility must be family or private"})
+		return
+	}
+
+	before, err := h.db.GetCategoryByID(id, userID)
 	if err != nil {
 		if errors.Is(err, ErrCategoryNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
@@ -135,18 +313,129 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if req.ParentID != nil && *req.ParentID != "" && *req.ParentID != before.ParentID &&
+		!h.checkParent(c, userID, id, before.Kind, *req.ParentID) {
+		return
+	}
+
+	err = h.db.UpdateCategory(id, userID, req.Name, req.Icon, req.Color, req.DefaultVisibility, req.ParentID)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	after := before
+	after.Name, after.Icon, after.Color = req.Name, req.Icon, req.Color
+	recordMemberEvent(c, h.activity, ActionCategoryUpdated, id, categorySnapshot(before), categorySnapshot(after))
+
 	c.JSON(http.StatusOK, gin.H{"message": "Category updated"})
 }
 
 // Delete handles DELETE /api/v1/categories/:id.
+// A category still used by expenses, incomes, recurring expenses, budgets,
+// category rules or family category mappings is either deleted after moving
+// them to the reassign_to category, or kept as archived with archive=true so
+// history and summaries still show it. Without either option such a category
+// cannot be deleted and 409 is returned with the counts. Budgets moved onto
+// a target that has its own budget are added to it.
 func (h *CategoryHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	userID := c.GetString("user_id")
-	// Accept optional reassign_to param (no-op in Phase 3, used in Phase 4 when expenses exist)
-	_ = c.Query("reassign_to")
+	reassignTo := c.Query("reassign_to")
+	archive := c.Query("archive") == "true"
+
+	if reassignTo != "" && archive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reassign_to and archive cannot be combined"})
+		return
+	}
+
+	before, err := h.db.GetCategoryByID(id, userID)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	if archive {
+		h.archive(c, before)
+		return
+	}
+
+	var usage CategoryUsage
+	if reassignTo != "" {
+		if !h.checkReassignTarget(c, before, reassignTo) {
+			return
+		}
+		err = h.db.ReassignCategory(id, userID, reassignTo)
+	} else {
+		usage, err = h.db.DeleteCategory(id, userID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCategoryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		case errors.Is(err, ErrCategoryInUse):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Category is in use; pass reassign_to to move its records or archive=true to keep it",
+				"usage": gin.H{
+					"expenses":           usage.Expenses,
+					"incomes":            usage.Incomes,
+					"recurring_expenses": usage.RecurringExpenses,
+					"budgets":            usage.Budgets,
+					"category_rules":     usage.CategoryRules,
+					"category_mappings":  usage.CategoryMappings,
+				},
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		}
+		return
+	}
 
-	err := h.db.DeleteCategory(id, userID)
+	recordMemberEvent(c, h.activity, ActionCategoryDeleted, id, categorySnapshot(before), nil)
+
+	c.Status(http.StatusNoContent)
+}
+
+// checkReassignTarget verifies that records of cat can be moved to the
+// category targetID, writing a 409 response when they cannot.
+func (h *CategoryHandler) checkReassignTarget(c *gin.Context, cat MockCategory, targetID string) bool {
+	if targetID == cat.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot reassign a category to itself"})
+		return false
+	}
+
+	target, err := h.db.GetCategoryByID(targetID, cat.UserID)
 	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			c.JSON(http.StatusConflict, gin.H{"error": "reassign_to category not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	switch {
+	case target.Kind != cat.Kind:
+		c.JSON(http.StatusConflict, gin.H{"error": "reassign_to category must be of the same kind"})
+		return false
+	case target.ArchivedAt != nil:
+		c.JSON(http.StatusConflict, gin.H{"error": "reassign_to category is archived"})
+		return false
+	}
+	return true
+}
+
+// archive archives cat in place of deleting it.
+func (h *CategoryHandler) archive(c *gin.Context, cat MockCategory) {
+	if err := h.db.ArchiveCategory(cat.ID, cat.UserID); err != nil {
 		if errors.Is(err, ErrCategoryNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
 			return
@@ -155,6 +444,10 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if cat.ArchivedAt == nil {
+		recordMemberEvent(c, h.activity, ActionCategoryArchived, cat.ID, categorySnapshot(cat), nil)
+	}
+
 	c.Status(http.StatusNoContent)
 }
 
@@ -164,6 +457,8 @@ type reorderItem struct {
 }
 
 // Reorder handles PUT /api/v1/categories/reorder.
+// Sort orders are relative to siblings, so all categories in one request
+// must share a parent.
 func (h *CategoryHandler) Reorder(c *gin.Context) {
 	var items []reorderItem
 	if err := c.ShouldBindJSON(&items); err != nil {
@@ -172,6 +467,25 @@ func (h *CategoryHandler) Reorder(c *gin.Context) {
 	}
 
 	userID := c.GetString("user_id")
+	var parentID string
+	for i, item := range items {
+		cat, err := h.db.GetCategoryByID(item.ID, userID)
+		if err != nil {
+			if errors.Is(err, ErrCategoryNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		if i == 0 {
+			parentID = cat.ParentID
+		} else if cat.ParentID != parentID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Categories reordered together must share a parent"})
+			return
+		}
+	}
+
 	for _, item := range items {
 		if err := h.db.UpdateCategorySortOrder(item.ID, userID, item.SortOrder); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
@@ -208,20 +522,30 @@ func (h *CategoryHandler) BulkCreate(c *gin.Context) {
 			return
 		}
 
-		cat, err := h.db.CreateCategory(userID, catReq.Name, catReq.Icon, catReq.Color)
+		kind, ok := normalizeCategoryKind(catReq.Kind)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be expense or income"})
+			return
+		}
+
+		visibility, ok := normalizeVisibility(catReq.DefaultVisibility)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "default_visibility must be family or private"})
+			return
+		}
+
+		if catReq.ParentID != "" && !h.checkParent(c, userID, "", kind, catReq.ParentID) {
+			return
+		}
+
+		cat, err := h.db.CreateCategory(userID, catReq.Name, catReq.Icon, catReq.Color, kind, visibility, catReq.ParentID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
 		}
+		recordMemberEvent(c, h.activity, ActionCategoryCreated, cat.ID, nil, categorySnapshot(cat))
 
-		result = append(result, gin.H{
-			"id":         cat.ID,
-			"user_id":    cat.UserID,
-			"name":       cat.Name,
-			"icon":       cat.Icon,
-			"color":      cat.Color,
-			"sort_order": cat.SortOrder,
-		})
+		result = append(result, categoryJSON(cat))
 	}
 
 	c.JSON(http.StatusCreated, result)
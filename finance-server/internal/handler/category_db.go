@@ -2,59 +2,78 @@ package handler
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
 )
 
 // PgCategoryDB implements CategoryDB using sqlc-generated queries against PostgreSQL.
 type PgCategoryDB struct {
 	queries *sqlc.Queries
+	pool    *pgxpool.Pool
 }
 
 // NewPgCategoryDB creates a PgCategoryDB wrapping sqlc.Queries.
-func NewPgCategoryDB(queries *sqlc.Queries) *PgCategoryDB {
-	return &PgCategoryDB{queries: queries}
+// The pool is used to reassign a category's records in a transaction.
+func NewPgCategoryDB(queries *sqlc.Queries, pool *pgxpool.Pool) *PgCategoryDB {
+	return &PgCategoryDB{queries: queries, pool: pool}
 }
 
-func (db *PgCategoryDB) CreateCategory(userID, name, icon, color string) (MockCategory, error) {
+func categoryFromRow(row sqlc.Category) MockCategory {
+	cat := MockCategory{
+		ID:                uuidToString(row.ID),
+		UserID:            uuidToString(row.UserID),
+		Name:              row.Name,
+		Icon:              row.Icon,
+		Color:             row.Color,
+		Kind:              row.Kind,
+		SortOrder:         int(row.SortOrder),
+		DefaultVisibility: row.DefaultVisibility,
+		ParentID:          uuidToString(row.ParentID),
+	}
+	if row.ArchivedAt.Valid {
+		archivedAt := row.ArchivedAt.Time
+		cat.ArchivedAt = &archivedAt
+	}
+	return cat
+}
+
+func (db *PgCategoryDB) CreateCategory(userID, name, icon, color, kind, defaultVisibility, parentID string) (MockCategory, error) {
 	uid := stringToUUID(userID)
 	row, err := db.queries.CreateCategory(context.Background(), sqlc.CreateCategoryParams{
-		UserID: uid,
-		Name:   name,
-		Icon:   icon,
-		Color:  color,
+		UserID:            uid,
+		Name:              name,
+		Icon:              icon,
+		Color:             color,
+		Kind:              kind,
+		DefaultVisibility: defaultVisibility,
+		ParentID:          stringToNullableUUID(parentID),
 	})
 	if err != nil {
 		return MockCategory{}, err
 	}
 
-	return MockCategory{
-		ID:        uuidToString(row.ID),
-		UserID:    uuidToString(row.UserID),
-		Name:      row.Name,
-		Icon:      row.Icon,
-		Color:     row.Color,
-		SortOrder: int(row.SortOrder),
-	}, nil
+	return categoryFromRow(row), nil
 }
 
-func (db *PgCategoryDB) GetCategoriesByUser(userID string) ([]MockCategory, error) {
+func (db *PgCategoryDB) GetCategoriesByUser(userID, kind string, includeArchived bool) ([]MockCategory, error) {
 	uid := stringToUUID(userID)
-	rows, err := db.queries.GetCategoriesByUser(context.Background(), uid)
+	rows, err := db.queries.GetCategoriesByUser(context.Background(), sqlc.GetCategoriesByUserParams{
+		UserID:          uid,
+		Kind:            stringToNullableText(kind),
+		IncludeArchived: includeArchived,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	cats := make([]MockCategory, len(rows))
 	for i, row := range rows {
-		cats[i] = MockCategory{
-			ID:        uuidToString(row.ID),
-			UserID:    uuidToString(row.UserID),
-			Name:      row.Name,
-			Icon:      row.Icon,
-			Color:     row.Color,
-			SortOrder: int(row.SortOrder),
-		}
+		cats[i] = categoryFromRow(row)
 	}
 	return cats, nil
 }
@@ -70,25 +89,21 @@ func (db *PgCategoryDB) GetCategoryByID(id, userID string) (MockCategory, error)
 		return MockCategory{}, ErrCategoryNotFound
 	}
 
-	return MockCategory{
-		ID:        uuidToString(row.ID),
-		UserID:    uuidToString(row.UserID),
-		Name:      row.Name,
-		Icon:      row.Icon,
-		Color:     row.Color,
-		SortOrder: int(row.SortOrder),
-	}, nil
+	return categoryFromRow(row), nil
 }
 
-func (db *PgCategoryDB) UpdateCategory(id, userID, name, icon, color string) error {
+func (db *PgCategoryDB) UpdateCategory(id, userID, name, icon, color, defaultVisibility string, parentID *string) error {
 	cid := stringToUUID(id)
 	uid := stringToUUID(userID)
 	rowsAffected, err := db.queries.UpdateCategory(context.Background(), sqlc.UpdateCategoryParams{
-		ID:     cid,
-		UserID: uid,
-		Name:   name,
-		Icon:   icon,
-		Color:  color,
+		ID:                cid,
+		UserID:            uid,
+		Name:              name,
+		Icon:              icon,
+		Color:             color,
+		DefaultVisibility: stringToNullableText(defaultVisibility),
+		SetParent:         parentID != nil,
+		ParentID:          stringPtrToNullableUUID(parentID),
 	})
 	if err != nil {
 		return err
@@ -99,12 +114,93 @@ func (db *PgCategoryDB) UpdateCategory(id, userID, name, icon, color string) err
 	return nil
 }
 
-func (db *PgCategoryDB) DeleteCategory(id, userID string) error {
+func (db *PgCategoryDB) DeleteCategory(id, userID string) (CategoryUsage, error) {
+	ctx := context.Background()
 	cid := stringToUUID(id)
 	uid := stringToUUID(userID)
-	rowsAffected, err := db.queries.DeleteCategory(context.Background(), sqlc.DeleteCategoryParams{
-		ID:     cid,
-		UserID: uid,
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return CategoryUsage{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	row, err := qtx.GetCategoryUsage(ctx, sqlc.GetCategoryUsageParams{ID: cid, UserID: uid})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return CategoryUsage{}, ErrCategoryNotFound
+		}
+		return CategoryUsage{}, err
+	}
+	usage := CategoryUsage{
+		Expenses:          row.Expenses,
+		Incomes:           row.Incomes,
+		RecurringExpenses: row.RecurringExpenses,
+		Budgets:           row.Budgets,
+		CategoryRules:     row.CategoryRules,
+		CategoryMappings:  row.CategoryMappings,
+	}
+	if usage != (CategoryUsage{}) {
+		return usage, ErrCategoryInUse
+	}
+	rowsAffected, err := qtx.DeleteCategory(ctx, sqlc.DeleteCategoryParams{ID: cid, UserID: uid})
+	if err != nil {
+		return CategoryUsage{}, categoryInUseError(err)
+	}
+	if rowsAffected == 0 {
+		return CategoryUsage{}, ErrCategoryNotFound
+	}
+
+	return CategoryUsage{}, tx.Commit(ctx)
+}
+
+func (db *PgCategoryDB) ReassignCategory(id, userID, targetID string) error {
+	ctx := context.Background()
+	cid := stringToUUID(id)
+	uid := stringToUUID(userID)
+	tid := stringToUUID(targetID)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	if _, err := qtx.ReassignExpenseCategory(ctx, sqlc.ReassignExpenseCategoryParams{TargetID: tid, CategoryID: cid, UserID: uid}); err != nil {
+		return err
+	}
+	if _, err := qtx.ReassignIncomeCategory(ctx, sqlc.ReassignIncomeCategoryParams{TargetID: tid, CategoryID: cid, UserID: uid}); err != nil {
+		return err
+	}
+	if _, err := qtx.ReassignRecurringExpenseCategory(ctx, sqlc.ReassignRecurringExpenseCategoryParams{TargetID: tid, CategoryID: cid, UserID: uid}); err != nil {
+		return err
+	}
+	if _, err := qtx.ReassignBudgetCategory(ctx, sqlc.ReassignBudgetCategoryParams{TargetID: tid, CategoryID: cid, UserID: uid}); err != nil {
+		return err
+	}
+	if _, err := qtx.ReassignCategoryRuleCategory(ctx, sqlc.ReassignCategoryRuleCategoryParams{TargetID: tid, CategoryID: cid, UserID: uid}); err != nil {
+		return err
+	}
+	if _, err := qtx.ReassignCategoryMappingCategory(ctx, sqlc.ReassignCategoryMappingCategoryParams{TargetID: tid, CategoryID: cid, UserID: uid}); err != nil {
+		return err
+	}
+	rowsAffected, err := qtx.DeleteCategory(ctx, sqlc.DeleteCategoryParams{ID: cid, UserID: uid})
+	if err != nil {
+		return categoryInUseError(err)
+	}
+	if rowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (db *PgCategoryDB) ArchiveCategory(id, userID string) error {
+	rowsAffected, err := db.queries.ArchiveCategory(context.Background(), sqlc.ArchiveCategoryParams{
+		ID:     stringToUUID(id),
+		UserID: stringToUUID(userID),
 	})
 	if err != nil {
 		return err
@@ -115,6 +211,32 @@ func (db *PgCategoryDB) DeleteCategory(id, userID string) error {
 	return nil
 }
 
+// categoryInUseError maps a foreign key violation on delete, raised while
+// records still reference the category, to ErrCategoryInUse.
+func categoryInUseError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrCategoryInUse
+	}
+	return err
+}
+
+// stringPtrToNullableUUID converts a nil or empty string to a NULL UUID.
+func stringPtrToNullableUUID(s *string) pgtype.UUID {
+	if s == nil {
+		return pgtype.UUID{}
+	}
+	return stringToNullableUUID(*s)
+}
+
+// stringToNullableText converts an empty string to a NULL text value.
+func stringToNullableText(s string) pgtype.Text {
+	if s == "" {
+		return pgtype.Text{Valid: false}
+	}
+	return pgtype.Text{String: s, Valid: true}
+}
+
 func (db *PgCategoryDB) UpdateCategorySortOrder(id, userID string, sortOrder int) error {
 	cid := stringToUUID(id)
 	uid := stringToUUID(userID)
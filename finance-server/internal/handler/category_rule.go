@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sentinel errors for category rule operations.
+var (
+	ErrCategoryRuleNotFound = errors.New("category rule not found")
+	ErrInvalidRuleCategory  = errors.New("invalid category rule category")
+)
+
+// Category rule match types.
+const (
+	RuleMatchContains = "contains"
+	RuleMatchRegex    = "regex"
+)
+
+// MockCategoryRule is the auto-categorization rule representation used by the CategoryRuleDB interface.
+// A contains rule matches when Pattern occurs in an expense note, a regex
+// rule when Pattern matches it, both ignoring case. An empty contains
+// pattern matches every note. When set, the expense amount must also lie
+// within MinAmountCents and MaxAmountCents, inclusive.
+// Tag, if set, is attached to the expenses the rule categorizes.
+type MockCategoryRule struct {
+	ID             string
+	CategoryID     string
+	MatchType      string
+	Pattern        string
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	Tag            string
+	Priority       int32
+	CreatedAt      time.Time
+}
+
+// NewCategoryRule holds the fields of a category rule to create.
+type NewCategoryRule struct {
+	CategoryID     string
+	MatchType      string
+	Pattern        string
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	Tag            string
+	Priority       int32
+}
+
+// CategoryRuleDB abstracts database operations for category rules.
+// This allows testing with mock implementations.
+type CategoryRuleDB interface {
+	CreateCategoryRule(userID string, rule NewCategoryRule) (MockCategoryRule, error)
+	// GetCategoryRulesByUser returns rules ordered by priority, highest first.
+	GetCategoryRulesByUser(userID string) ([]MockCategoryRule, error)
+	DeleteCategoryRule(id, userID string) error
+}
+
+// CategoryRuleHandler handles category rule HTTP requests.
+type CategoryRuleHandler struct {
+	db CategoryRuleDB
+}
+
+// NewCategoryRuleHandler creates a CategoryRuleHandler with the given database.
+func NewCategoryRuleHandler(db CategoryRuleDB) *CategoryRuleHandler {
+	return &CategoryRuleHandler{db: db}
+}
+
+type createCategoryRuleRequest struct {
+	CategoryID     string `json:"category_id"`
+	MatchType      string `json:"match_type"`
+	Pattern        string `json:"pattern"`
+	MinAmountCents *int64 `json:"min_amount_cents"`
+	MaxAmountCents *int64 `json:"max_amount_cents"`
+	Tag            string `json:"tag"`
+	Priority       int32  `json:"priority"`
+}
+
+// Create handles POST /api/v1/category-rules.
+// match_type is contains (the default) or regex. A rule needs a pattern,
+// an amount range, or both.
+func (h *CategoryRuleHandler) Create(c *gin.Context) {
+	var req createCategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.CategoryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+		return
+	}
+
+	matchType := req.MatchType
+	if matchType == "" {
+		matchType = RuleMatchContains
+	}
+	if matchType != RuleMatchContains && matchType != RuleMatchRegex {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "match_type must be contains or regex"})
+		return
+	}
+
+	pattern := strings.TrimSpace(req.Pattern)
+	if matchType == RuleMatchRegex {
+		if pattern == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pattern is required for regex rules"})
+			return
+		}
+		if _, err := compileRulePattern(pattern); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pattern is not a valid regular expression"})
+			return
+		}
+	}
+
+	if req.MinAmountCents != nil && *req.MinAmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_amount_cents must be greater than 0"})
+		return
+	}
+	if req.MaxAmountCents != nil && *req.MaxAmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_amount_cents must be greater than 0"})
+		return
+	}
+	if req.MinAmountCents != nil && req.MaxAmountCents != nil && *req.MinAmountCents > *req.MaxAmountCents {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_amount_cents must not exceed max_amount_cents"})
+		return
+	}
+	if pattern == "" && req.MinAmountCents == nil && req.MaxAmountCents == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pattern or an amount range is required"})
+		return
+	}
+
+	// The tag is stored as it will be attached to expenses.
+	var tag string
+	if tags := normalizeTags([]string{req.Tag}); len(tags) > 0 {
+		if err := checkTags(tags); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		tag = tags[0]
+	}
+
+	userID := c.GetString("user_id")
+	rule, err := h.db.CreateCategoryRule(userID, NewCategoryRule{
+		CategoryID:     req.CategoryID,
+		MatchType:      matchType,
+		Pattern:        pattern,
+		MinAmountCents: req.MinAmountCents,
+		MaxAmountCents: req.MaxAmountCents,
+		Tag:            tag,
+		Priority:       req.Priority,
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidRuleCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, categoryRuleResponse(rule))
+}
+
+// List handles GET /api/v1/category-rules.
+func (h *CategoryRuleHandler) List(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	rules, err := h.db.GetCategoryRulesByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(rules))
+	for i, rule := range rules {
+		result[i] = categoryRuleResponse(rule)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Delete handles DELETE /api/v1/category-rules/:id.
+func (h *CategoryRuleHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("user_id")
+
+	if err := h.db.DeleteCategoryRule(id, userID); err != nil {
+		if errors.Is(err, ErrCategoryRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category rule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func categoryRuleResponse(rule MockCategoryRule) gin.H {
+	var tag any
+	if rule.Tag != "" {
+		tag = rule.Tag
+	}
+	return gin.H{
+		"id":               rule.ID,
+		"category_id":      rule.CategoryID,
+		"match_type":       rule.MatchType,
+		"pattern":          rule.Pattern,
+		"min_amount_cents": rule.MinAmountCents,
+		"max_amount_cents": rule.MaxAmountCents,
+		"tag":              tag,
+		"priority":         rule.Priority,
+		"created_at":       rule.CreatedAt,
+	}
+}
+
+// compileRulePattern compiles a regex rule pattern so that it ignores case
+// like contains rules do.
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// categoryRules is a user's rules prepared for matching many expenses.
+type categoryRules struct {
+	rules   []MockCategoryRule
+	regexps []*regexp.Regexp
+}
+
+// newCategoryRules compiles the regex rules among rules, which must already
+// be ordered by priority. A stored pattern that no longer compiles never
+// matches.
+func newCategoryRules(rules []MockCategoryRule) categoryRules {
+	regexps := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		if rule.MatchType == RuleMatchRegex {
+			regexps[i], _ = compileRulePattern(rule.Pattern)
+		}
+	}
+	return categoryRules{rules: rules, regexps: regexps}
+}
+
+// match returns the first rule matching an expense with the given note and
+// amount.
+func (r categoryRules) match(note string, amountCents int64) (MockCategoryRule, bool) {
+	lower := strings.ToLower(note)
+	for i, rule := range r.rules {
+		if rule.MinAmountCents != nil && amountCents < *rule.MinAmountCents {
+			continue
+		}
+		if rule.MaxAmountCents != nil && amountCents > *rule.MaxAmountCents {
+			continue
+		}
+		if rule.MatchType == RuleMatchRegex {
+			if r.regexps[i] == nil || !r.regexps[i].MatchString(note) {
+				continue
+			}
+		} else if !strings.Contains(lower, strings.ToLower(rule.Pattern)) {
+			continue
+		}
+		return rule, true
+	}
+	return MockCategoryRule{}, false
+}
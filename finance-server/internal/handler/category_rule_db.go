@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// PgCategoryRuleDB implements CategoryRuleDB using sqlc-generated queries against PostgreSQL.
+type PgCategoryRuleDB struct {
+	queries *sqlc.Queries
+}
+
+// NewPgCategoryRuleDB creates a PgCategoryRuleDB wrapping sqlc.Queries.
+func NewPgCategoryRuleDB(queries *sqlc.Queries) *PgCategoryRuleDB {
+	return &PgCategoryRuleDB{queries: queries}
+}
+
+func (db *PgCategoryRuleDB) CreateCategoryRule(userID string, rule NewCategoryRule) (MockCategoryRule, error) {
+	uid := stringToUUID(userID)
+	cid := stringToUUID(rule.CategoryID)
+
+	cat, err := db.queries.GetCategoryByID(context.Background(), sqlc.GetCategoryByIDParams{
+		ID:     cid,
+		UserID: uid,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockCategoryRule{}, ErrInvalidRuleCategory
+		}
+		return MockCategoryRule{}, err
+	}
+	if cat.Kind != CategoryKindExpense || cat.ArchivedAt.Valid {
+		return MockCategoryRule{}, ErrInvalidRuleCategory
+	}
+
+	row, err := db.queries.CreateCategoryRule(context.Background(), sqlc.CreateCategoryRuleParams{
+		UserID:         uid,
+		CategoryID:     cid,
+		Pattern:        rule.Pattern,
+		Priority:       rule.Priority,
+		MatchType:      rule.MatchType,
+		MinAmountCents: int64ToPgInt8(rule.MinAmountCents),
+		MaxAmountCents: int64ToPgInt8(rule.MaxAmountCents),
+		Tag:            stringToNullableText(rule.Tag),
+	})
+	if err != nil {
+		return MockCategoryRule{}, err
+	}
+
+	return categoryRuleFromRow(row), nil
+}
+
+func (db *PgCategoryRuleDB) GetCategoryRulesByUser(userID string) ([]MockCategoryRule, error) {
+	rows, err := db.queries.GetCategoryRulesByUser(context.Background(), stringToUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]MockCategoryRule, len(rows))
+	for i, row := range rows {
+		rules[i] = categoryRuleFromRow(row)
+	}
+	return rules, nil
+}
+
+func (db *PgCategoryRuleDB) DeleteCategoryRule(id, userID string) error {
+	rowsAffected, err := db.queries.DeleteCategoryRule(context.Background(), sqlc.DeleteCategoryRuleParams{
+		ID:     stringToUUID(id),
+		UserID: stringToUUID(userID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCategoryRuleNotFound
+	}
+	return nil
+}
+
+func categoryRuleFromRow(row sqlc.CategoryRule) MockCategoryRule {
+	rule := MockCategoryRule{
+		ID:         uuidToString(row.ID),
+		CategoryID: uuidToString(row.CategoryID),
+		MatchType:  row.MatchType,
+		Pattern:    row.Pattern,
+		Tag:        row.Tag.String,
+		Priority:   row.Priority,
+		CreatedAt:  row.CreatedAt.Time,
+	}
+	if row.MinAmountCents.Valid {
+		rule.MinAmountCents = &row.MinAmountCents.Int64
+	}
+	if row.MaxAmountCents.Valid {
+		rule.MaxAmountCents = &row.MaxAmountCents.Int64
+	}
+	return rule
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nnc/finance-tracker/server/internal/handler"
@@ -14,36 +15,45 @@ import (
 const testUserID = "550e8400-e29b-41d4-a716-446655440000"
 
 // mockCategoryDB implements handler.CategoryDB for testing.
+// usage holds the records that still reference each category.
 type mockCategoryDB struct {
 	categories []handler.MockCategory
 	nextID     int
+	usage      map[string]handler.CategoryUsage
 }
 
 func newMockCategoryDB() *mockCategoryDB {
 	return &mockCategoryDB{
 		categories: make([]handler.MockCategory, 0),
 		nextID:     1,
+		usage:      make(map[string]handler.CategoryUsage),
 	}
 }
 
-func (m *mockCategoryDB) CreateCategory(userID, name, icon, color string) (handler.MockCategory, error) {
+func (m *mockCategoryDB) CreateCategory(userID, name, icon, color, kind, defaultVisibility, parentID string) (handler.MockCategory, error) {
 	cat := handler.MockCategory{
-		ID:        idForIndex(m.nextID),
-		UserID:    userID,
-		Name:      name,
-		Icon:      icon,
-		Color:     color,
-		SortOrder: len(m.categories),
+		ID:                idForIndex(m.nextID),
+		UserID:            userID,
+		Name:              name,
+		Icon:              icon,
+		Color:             color,
+		Kind:              kind,
+		SortOrder:         len(m.categories),
+		DefaultVisibility: defaultVisibility,
+		ParentID:          parentID,
 	}
 	m.nextID++
 	m.categories = append(m.categories, cat)
 	return cat, nil
 }
 
-func (m *mockCategoryDB) GetCategoriesByUser(userID string) ([]handler.MockCategory, error) {
+func (m *mockCategoryDB) GetCategoriesByUser(userID, kind string, includeArchived bool) ([]handler.MockCategory, error) {
 	var result []handler.MockCategory
 	for _, cat := range m.categories {
-		if cat.UserID == userID {
+		if cat.ArchivedAt != nil && !includeArchived {
+			continue
+		}
+		if cat.UserID == userID && (kind == "" || cat.Kind == kind) {
 			result = append(result, cat)
 		}
 	}
@@ -59,22 +69,53 @@ func (m *mockCategoryDB) GetCategoryByID(id, userID string) (handler.MockCategor
 	return handler.MockCategory{}, handler.ErrCategoryNotFound
 }
 
-func (m *mockCategoryDB) UpdateCategory(id, userID, name, icon, color string) error {
+func (m *mockCategoryDB) UpdateCategory(id, userID, name, icon, color, defaultVisibility string, parentID *string) error {
 	for i, cat := range m.categories {
 		if cat.ID == id && cat.UserID == userID {
 			m.categories[i].Name = name
 			m.categories[i].Icon = icon
 			m.categories[i].Color = color
+			if defaultVisibility != "" {
+				m.categories[i].DefaultVisibility = defaultVisibility
+			}
+			if parentID != nil {
+				m.categories[i].ParentID = *parentID
+			}
 			return nil
 		}
 	}
 	return handler.ErrCategoryNotFound
 }
 
-func (m *mockCategoryDB) DeleteCategory(id, userID string) error {
+func (m *mockCategoryDB) DeleteCategory(id, userID string) (handler.CategoryUsage, error) {
 	for i, cat := range m.categories {
 		if cat.ID == id && cat.UserID == userID {
+			if usage := m.usage[id]; usage != (handler.CategoryUsage{}) {
+				return usage, handler.ErrCategoryInUse
+			}
 			m.categories = append(m.categories[:i], m.categories[i+1:]...)
+			return handler.CategoryUsage{}, nil
+		}
+	}
+	return handler.CategoryUsage{}, handler.ErrCategoryNotFound
+}
+
+func (m *mockCategoryDB) ReassignCategory(id, userID, targetID string) error {
+	if usage, ok := m.usage[id]; ok {
+		m.usage[targetID] = usage
+		delete(m.usage, id)
+	}
+	_, err := m.DeleteCategory(id, userID)
+	return err
+}
+
+func (m *mockCategoryDB) ArchiveCategory(id, userID string) error {
+	for i, cat := range m.categories {
+		if cat.ID == id && cat.UserID == userID {
+			if cat.ArchivedAt == nil {
+				now := time.Now()
+				m.categories[i].ArchivedAt = &now
+			}
 			return nil
 		}
 	}
@@ -96,9 +137,13 @@ func idForIndex(i int) string {
 }
 
 func setupCategoryRouter(db handler.CategoryDB) *gin.Engine {
+	return setupCategoryRouterWithActivity(db, newMockActivityDB())
+}
+
+func setupCategoryRouterWithActivity(db handler.CategoryDB, activity handler.ActivityDB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	h := handler.NewCategoryHandler(db)
+	h := handler.NewCategoryHandler(db, activity)
 
 	cats := r.Group("/api/v1/categories")
 	cats.Use(func(c *gin.Context) {
@@ -200,6 +245,114 @@ func TestListCategories_Success(t *testing.T) {
 	}
 }
 
+func TestCreateCategory_DefaultsToExpenseKind(t *testing.T) {
+	db := newMockCategoryDB()
+	r := setupCategoryRouter(db)
+
+	body, _ := json.Marshal(map[string]string{
+		"name": "Food", "icon": "restaurant", "color": "#FF7043",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["kind"] != "expense" {
+		t.Fatalf("expected kind expense, got %v", resp["kind"])
+	}
+}
+
+func TestCreateCategory_InvalidKind(t *testing.T) {
+	db := newMockCategoryDB()
+	r := setupCategoryRouter(db)
+
+	body, _ := json.Marshal(map[string]string{
+		"name": "Salary", "icon": "work", "color": "#66BB6A", "kind": "transfer",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreateCategory_DefaultVisibility(t *testing.T) {
+	tests := []struct {
+		visibility string
+		want       int
+		wantVis    string
+	}{
+		{"", http.StatusCreated, handler.VisibilityFamily},
+		{"private", http.StatusCreated, handler.VisibilityPrivate},
+		{"hidden", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.visibility, func(t *testing.T) {
+			r := setupCategoryRouter(newMockCategoryDB())
+
+			body, _ := json.Marshal(map[string]string{
+				"name": "Gifts", "icon": "gift", "color": "#AB47BC", "default_visibility": tt.visibility,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			var resp map[string]any
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if tt.wantVis != "" && resp["default_visibility"] != tt.wantVis {
+				t.Fatalf("expected default_visibility %s, got %v", tt.wantVis, resp["default_visibility"])
+			}
+		})
+	}
+}
+
+func TestListCategories_FilterByKind(t *testing.T) {
+	db := newMockCategoryDB()
+	r := setupCategoryRouter(db)
+
+	for _, cat := range []map[string]string{
+		{"name": "Food", "icon": "restaurant", "color": "#000"},
+		{"name": "Salary", "icon": "work", "color": "#000", "kind": "income"},
+	} {
+		body, _ := json.Marshal(cat)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/categories?kind=income", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if len(resp) != 1 {
+		t.Fatalf("expected 1 income category, got %d", len(resp))
+	}
+	if resp[0]["name"] != "Salary" {
+		t.Fatalf("expected Salary, got %v", resp[0]["name"])
+	}
+}
+
 func TestUpdateCategory_Success(t *testing.T) {
 	db := newMockCategoryDB()
 	r := setupCategoryRouter(db)
@@ -288,6 +441,137 @@ func TestDeleteCategory_NotFound(t *testing.T) {
 	}
 }
 
+func TestDeleteCategory_InUse(t *testing.T) {
+	db := newMockCategoryDB()
+	food, _ := db.CreateCategory(testUserID, "Food", "restaurant", "#FF7043", "expense", "family", "")
+	db.usage[food.ID] = handler.CategoryUsage{Expenses: 3}
+	r := setupCategoryRouter(db)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/categories/"+food.ID, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := db.GetCategoryByID(food.ID, testUserID); err != nil {
+		t.Fatal("category in use should not be deleted")
+	}
+}
+
+func TestDeleteCategory_InUseByBudgetsAndRules(t *testing.T) {
+	db := newMockCategoryDB()
+	food, _ := db.CreateCategory(testUserID, "Food", "restaurant", "#FF7043", "expense", "family", "")
+	db.usage[food.ID] = handler.CategoryUsage{Budgets: 1, CategoryRules: 2}
+	r := setupCategoryRouter(db)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/categories/"+food.ID, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Usage map[string]int64 `json:"usage"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Usage["budgets"] != 1 || resp.Usage["category_rules"] != 2 || resp.Usage["expenses"] != 0 {
+		t.Fatalf("expected budget and rule counts, got %v", resp.Usage)
+	}
+	if _, err := db.GetCategoryByID(food.ID, testUserID); err != nil {
+		t.Fatal("category with budgets and rules should not be deleted")
+	}
+}
+
+func TestDeleteCategory_Reassign(t *testing.T) {
+	db := newMockCategoryDB()
+	food, _ := db.CreateCategory(testUserID, "Food", "restaurant", "#FF7043", "expense", "family", "")
+	groceries, _ := db.CreateCategory(testUserID, "Groceries", "cart", "#66BB6A", "expense", "family", "")
+	db.usage[food.ID] = handler.CategoryUsage{Expenses: 3}
+	r := setupCategoryRouter(db)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/categories/"+food.ID+"?reassign_to="+groceries.ID, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, err := db.GetCategoryByID(food.ID, testUserID); err == nil {
+		t.Fatal("expected reassigned category to be deleted")
+	}
+	if db.usage[groceries.ID].Expenses != 3 {
+		t.Fatal("expected records to move to the reassign_to category")
+	}
+}
+
+func TestDeleteCategory_ReassignConflicts(t *testing.T) {
+	db := newMockCategoryDB()
+	food, _ := db.CreateCategory(testUserID, "Food", "restaurant", "#FF7043", "expense", "family", "")
+	salary, _ := db.CreateCategory(testUserID, "Salary", "work", "#42A5F5", "income", "family", "")
+	old, _ := db.CreateCategory(testUserID, "Old", "history", "#9E9E9E", "expense", "family", "")
+	db.ArchiveCategory(old.ID, testUserID)
+	db.usage[food.ID] = handler.CategoryUsage{Expenses: 3}
+	r := setupCategoryRouter(db)
+
+	for _, target := range []string{food.ID, salary.ID, old.ID, "nonexistent-id"} {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/categories/"+food.ID+"?reassign_to="+target, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Fatalf("reassign_to=%s: expected 409, got %d: %s", target, w.Code, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/categories/"+food.ID+"?reassign_to="+old.ID+"&archive=true", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for reassign_to with archive, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDeleteCategory_Archive(t *testing.T) {
+	db := newMockCategoryDB()
+	food, _ := db.CreateCategory(testUserID, "Food", "restaurant", "#FF7043", "expense", "family", "")
+	db.CreateCategory(testUserID, "Transport", "directions_car", "#42A5F5", "expense", "family", "")
+	db.usage[food.ID] = handler.CategoryUsage{Expenses: 3}
+	r := setupCategoryRouter(db)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/categories/"+food.ID+"?archive=true", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/categories", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var listed []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	if len(listed) != 1 || listed[0]["name"] != "Transport" {
+		t.Fatalf("expected archived category hidden from list, got %v", listed)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/categories?include_archived=true", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	if len(listed) != 2 {
+		t.Fatalf("expected 2 categories with include_archived, got %d", len(listed))
+	}
+	if listed[0]["archived_at"] == nil {
+		t.Fatalf("expected archived_at on archived category, got %v", listed[0])
+	}
+}
+
 func TestReorderCategories_Success(t *testing.T) {
 	db := newMockCategoryDB()
 	r := setupCategoryRouter(db)
@@ -350,3 +634,126 @@ func TestBulkCreateCategories_Success(t *testing.T) {
 		t.Fatalf("expected 3 categories, got %d", len(resp))
 	}
 }
+
+func TestListCategories_Tree(t *testing.T) {
+	db := newMockCategoryDB()
+	r := setupCategoryRouter(db)
+
+	create := func(body map[string]string) map[string]any {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var created map[string]any
+		json.Unmarshal(w.Body.Bytes(), &created)
+		return created
+	}
+
+	food := create(map[string]string{"name": "Food", "icon": "restaurant", "color": "#FF7043"})
+	create(map[string]string{"name": "Transport", "icon": "directions_car", "color": "#42A5F5"})
+	groceries := create(map[string]string{"name": "Groceries", "icon": "cart", "color": "#66BB6A", "parent_id": food["id"].(string)})
+	if groceries["parent_id"] != food["id"] {
+		t.Fatalf("expected parent_id %v, got %v", food["id"], groceries["parent_id"])
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/categories", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var tree []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &tree)
+	if len(tree) != 2 {
+		t.Fatalf("expected 2 top-level categories, got %d", len(tree))
+	}
+	children := tree[0]["children"].([]any)
+	if tree[0]["name"] != "Food" || len(children) != 1 || children[0].(map[string]any)["name"] != "Groceries" {
+		t.Fatalf("expected Groceries nested under Food, got %v", tree[0])
+	}
+	if len(tree[1]["children"].([]any)) != 0 {
+		t.Fatalf("expected Transport without children, got %v", tree[1]["children"])
+	}
+}
+
+func TestCreateCategory_InvalidParent(t *testing.T) {
+	db := newMockCategoryDB()
+	food, _ := db.CreateCategory(testUserID, "Food", "restaurant", "#FF7043", "expense", "family", "")
+	groceries, _ := db.CreateCategory(testUserID, "Groceries", "cart", "#66BB6A", "expense", "family", food.ID)
+	salary, _ := db.CreateCategory(testUserID, "Salary", "work", "#42A5F5", "income", "family", "")
+	r := setupCategoryRouter(db)
+
+	for _, parentID := range []string{groceries.ID, salary.ID, "nonexistent-id"} {
+		body, _ := json.Marshal(map[string]string{
+			"name": "Snacks", "icon": "cookie", "color": "#FFCA28", "parent_id": parentID,
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/categories", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("parent_id=%s: expected 400, got %d: %s", parentID, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestUpdateCategory_Parent(t *testing.T) {
+	db := newMockCategoryDB()
+	food, _ := db.CreateCategory(testUserID, "Food", "restaurant", "#FF7043", "expense", "family", "")
+	groceries, _ := db.CreateCategory(testUserID, "Groceries", "cart", "#66BB6A", "expense", "family", food.ID)
+	transport, _ := db.CreateCategory(testUserID, "Transport", "directions_car", "#42A5F5", "expense", "family", "")
+	r := setupCategoryRouter(db)
+
+	update := func(id string, body map[string]any) int {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/categories/"+id, bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// A category with subcategories cannot be nested itself.
+	if code := update(food.ID, map[string]any{"name": "Food", "icon": "restaurant", "color": "#FF7043", "parent_id": transport.ID}); code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", code)
+	}
+
+	// Omitting parent_id keeps the parent.
+	if code := update(groceries.ID, map[string]any{"name": "Groceries", "icon": "cart", "color": "#66BB6A"}); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if cat, _ := db.GetCategoryByID(groceries.ID, testUserID); cat.ParentID != food.ID {
+		t.Fatalf("expected parent kept, got %q", cat.ParentID)
+	}
+
+	// An empty parent_id moves it to the top level.
+	if code := update(groceries.ID, map[string]any{"name": "Groceries", "icon": "cart", "color": "#66BB6A", "parent_id": ""}); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if cat, _ := db.GetCategoryByID(groceries.ID, testUserID); cat.ParentID != "" {
+		t.Fatalf("expected top-level category, got parent %q", cat.ParentID)
+	}
+}
+
+func TestReorderCategories_MixedParents(t *testing.T) {
+	db := newMockCategoryDB()
+	food, _ := db.CreateCategory(testUserID, "Food", "restaurant", "#FF7043", "expense", "family", "")
+	groceries, _ := db.CreateCategory(testUserID, "Groceries", "cart", "#66BB6A", "expense", "family", food.ID)
+	r := setupCategoryRouter(db)
+
+	body, _ := json.Marshal([]map[string]any{
+		{"id": food.ID, "sort_order": 1},
+		{"id": groceries.ID, "sort_order": 0},
+	})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/categories/reorder", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sentinel errors for currency operations.
+var (
+	ErrInvalidCurrency = errors.New("invalid currency")
+)
+
+// MockCurrency is the currency representation used by the CurrencyDB interface.
+// USDRate and RateDate describe the currency's most recent exchange rate.
+type MockCurrency struct {
+	Code     string
+	Name     string
+	USDRate  float64
+	RateDate time.Time
+}
+
+// MockExchangeRate is a stored rate: one unit of Currency is worth USDRate
+// US dollars from RateDate until the next stored rate.
+type MockExchangeRate struct {
+	Currency string
+	RateDate time.Time
+	USDRate  float64
+}
+
+// CurrencyDB abstracts database operations for currencies, exchange rates
+// and user and family settings. Unknown currency codes return ErrInvalidCurrency.
+// This allows testing with mock implementations.
+type CurrencyDB interface {
+	ListCurrencies() ([]MockCurrency, error)
+	UpsertExchangeRate(currency string, rateDate time.Time, usdRate float64) (MockExchangeRate, error)
+	GetUserBaseCurrency(userID string) (string, error)
+	SetUserBaseCurrency(userID, currency string) error
+	SetFamilyBaseCurrency(familyID, currency string) error
+	SetFamilyPrivateExpenses(familyID, mode string) error
+}
+
+// CurrencyHandler handles currency and base currency settings HTTP requests.
+type CurrencyHandler struct {
+	db       CurrencyDB
+	familyDB FamilyDB
+}
+
+// NewCurrencyHandler creates a CurrencyHandler with the given databases.
+func NewCurrencyHandler(db CurrencyDB, familyDB FamilyDB) *CurrencyHandler {
+	return &CurrencyHandler{db: db, familyDB: familyDB}
+}
+
+// parseCurrency normalizes a currency code to upper case and reports
+// whether it has the shape of an ISO 4217 code. An empty code is valid and
+// means "use the default".
+func parseCurrency(s string) (string, bool) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if code == "" {
+		return "", true
+	}
+	if len(code) != 3 {
+		return "", false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", false
+		}
+	}
+	return code, true
+}
+
+// List handles GET /api/v1/currencies.
+func (h *CurrencyHandler) List(c *gin.Context) {
+	currencies, err := h.db.ListCurrencies()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(currencies))
+	for i, cur := range currencies {
+		result[i] = gin.H{
+			"code":      cur.Code,
+			"name":      cur.Name,
+			"usd_rate":  cur.USDRate,
+			"rate_date": cur.RateDate.Format("2006-01-02"),
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type setRateRequest struct {
+	RateDate string  `json:"rate_date"`
+	USDRate  float64 `json:"usd_rate"`
+}
+
+// SetRate handles PUT /api/v1/currencies/:code/rates on the operator
+// listener; rates are shared by all users, so it is not a user route.
+// It stores the value of one unit of the currency in USD for a date,
+// replacing any rate already stored for that date. Expenses keep the rate
+// date they were recorded with, so only new or edited expenses pick up
+// rates for newer dates.
+func (h *CurrencyHandler) SetRate(c *gin.Context) {
+	code, ok := parseCurrency(c.Param("code"))
+	if !ok || code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be a 3-letter ISO 4217 code"})
+		return
+	}
+
+	var req setRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.USDRate <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "usd_rate must be greater than 0"})
+		return
+	}
+
+	rateDate := time.Now()
+	if req.RateDate != "" {
+		var err error
+		rateDate, err = time.Parse("2006-01-02", req.RateDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rate_date must be in YYYY-MM-DD format"})
+			return
+		}
+	}
+
+	rate, err := h.db.UpsertExchangeRate(code, rateDate, req.USDRate)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"currency":  rate.Currency,
+		"rate_date": rate.RateDate.Format("2006-01-02"),
+		"usd_rate":  rate.USDRate,
+	})
+}
+
+// GetSettings handles GET /api/v1/users/me/settings.
+func (h *CurrencyHandler) GetSettings(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	baseCurrency, err := h.db.GetUserBaseCurrency(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"base_currency": baseCurrency})
+}
+
+type updateSettingsRequest struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+// UpdateSettings handles PUT /api/v1/users/me/settings.
+// The base currency is the currency personal summaries are reported in.
+func (h *CurrencyHandler) UpdateSettings(c *gin.Context) {
+	var req updateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	code, ok := parseCurrency(req.BaseCurrency)
+	if !ok || code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base_currency must be a 3-letter ISO 4217 code"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if err := h.db.SetUserBaseCurrency(userID, code); err != nil {
+		if errors.Is(err, ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"base_currency": code})
+}
+
+type updateFamilySettingsRequest struct {
+	BaseCurrency    string `json:"base_currency"`
+	PrivateExpenses string `json:"private_expenses"`
+}
+
+// UpdateFamilySettings handles PUT /api/v1/families/:familyId/settings.
+// Only family admins can change the family's base currency and how members'
+// private expenses are treated; settings left out are kept.
+func (h *CurrencyHandler) UpdateFamilySettings(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermManageFamily)
+	if !ok {
+		return
+	}
+
+	var req updateFamilySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	code, ok := parseCurrency(req.BaseCurrency)
+	if !ok || (code == "" && req.PrivateExpenses == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base_currency must be a 3-letter ISO 4217 code"})
+		return
+	}
+
+	if req.PrivateExpenses != "" && req.PrivateExpenses != PrivateExpensesExclude && req.PrivateExpenses != PrivateExpensesAggregate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "private_expenses must be exclude or aggregate"})
+		return
+	}
+
+	if code != "" {
+		if err := h.db.SetFamilyBaseCurrency(family.ID, code); err != nil {
+			if errors.Is(err, ErrInvalidCurrency) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		family.BaseCurrency = code
+	}
+
+	if req.PrivateExpenses != "" {
+		if err := h.db.SetFamilyPrivateExpenses(family.ID, req.PrivateExpenses); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		family.PrivateExpenses = req.PrivateExpenses
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"base_currency":    family.BaseCurrency,
+		"private_expenses": family.PrivateExpenses,
+	})
+}
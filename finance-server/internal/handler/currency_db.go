@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// PgCurrencyDB implements CurrencyDB using sqlc-generated queries against PostgreSQL.
+type PgCurrencyDB struct {
+	queries *sqlc.Queries
+}
+
+// NewPgCurrencyDB creates a PgCurrencyDB wrapping sqlc.Queries.
+func NewPgCurrencyDB(queries *sqlc.Queries) *PgCurrencyDB {
+	return &PgCurrencyDB{queries: queries}
+}
+
+// currencyError maps constraint violations caused by an unknown currency
+// code to ErrInvalidCurrency. For expenses and incomes the NOT NULL check on
+// rate_date fires first, because no rate can be found for an unknown code.
+func currencyError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if (pgErr.Code == "23503" && strings.Contains(pgErr.ConstraintName, "currency")) ||
+			(pgErr.Code == "23502" && pgErr.ColumnName == "rate_date") {
+			return ErrInvalidCurrency
+		}
+	}
+	return err
+}
+
+func numericToFloat(n pgtype.Numeric) float64 {
+	f, err := n.Float64Value()
+	if err != nil {
+		return 0
+	}
+	return f.Float64
+}
+
+func (db *PgCurrencyDB) ListCurrencies() ([]MockCurrency, error) {
+	rows, err := db.queries.ListCurrencies(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := make([]MockCurrency, len(rows))
+	for i, row := range rows {
+		currencies[i] = MockCurrency{
+			Code:     row.Code,
+			Name:     row.Name,
+			USDRate:  numericToFloat(row.UsdRate),
+			RateDate: row.RateDate.Time,
+		}
+	}
+	return currencies, nil
+}
+
+func (db *PgCurrencyDB) UpsertExchangeRate(currency string, rateDate time.Time, usdRate float64) (MockExchangeRate, error) {
+	var rate pgtype.Numeric
+	if err := rate.Scan(strconv.FormatFloat(usdRate, 'f', -1, 64)); err != nil {
+		return MockExchangeRate{}, err
+	}
+
+	row, err := db.queries.UpsertExchangeRate(context.Background(), sqlc.UpsertExchangeRateParams{
+		Currency: currency,
+		RateDate: pgtype.Date{Time: rateDate, Valid: true},
+		UsdRate:  rate,
+	})
+	if err != nil {
+		return MockExchangeRate{}, currencyError(err)
+	}
+
+	return MockExchangeRate{
+		Currency: row.Currency,
+		RateDate: row.RateDate.Time,
+		USDRate:  numericToFloat(row.UsdRate),
+	}, nil
+}
+
+func (db *PgCurrencyDB) GetUserBaseCurrency(userID string) (string, error) {
+	return db.queries.GetUserBaseCurrency(context.Background(), stringToUUID(userID))
+}
+
+func (db *PgCurrencyDB) SetUserBaseCurrency(userID, currency string) error {
+	_, err := db.queries.SetUserBaseCurrency(context.Background(), sqlc.SetUserBaseCurrencyParams{
+		ID:           stringToUUID(userID),
+		BaseCurrency: currency,
+	})
+	return currencyError(err)
+}
+
+func (db *PgCurrencyDB) SetFamilyBaseCurrency(familyID, currency string) error {
+	_, err := db.queries.SetFamilyBaseCurrency(context.Background(), sqlc.SetFamilyBaseCurrencyParams{
+		ID:           stringToUUID(familyID),
+		BaseCurrency: currency,
+	})
+	return currencyError(err)
+}
+
+func (db *PgCurrencyDB) SetFamilyPrivateExpenses(familyID, mode string) error {
+	_, err := db.queries.SetFamilyPrivateExpenses(context.Background(), sqlc.SetFamilyPrivateExpensesParams{
+		ID:              stringToUUID(familyID),
+		PrivateExpenses: mode,
+	})
+	return err
+}
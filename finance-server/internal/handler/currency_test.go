@@ -0,0 +1,292 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+// mockCurrencies are the currencies seeded by the currencies migration.
+var mockCurrencies = []string{"EUR", "UAH", "USD"}
+
+func mockCurrencyKnown(code string) bool {
+	for _, c := range mockCurrencies {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// mockCurrencyDB implements handler.CurrencyDB for testing.
+type mockCurrencyDB struct {
+	rates                 map[string]handler.MockExchangeRate // currency -> latest rate
+	userCurrency          map[string]string
+	familyCurrency        map[string]string
+	familyPrivateExpenses map[string]string
+}
+
+func newMockCurrencyDB() *mockCurrencyDB {
+	return &mockCurrencyDB{
+		rates: map[string]handler.MockExchangeRate{
+			"USD": {Currency: "USD", RateDate: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), USDRate: 1},
+			"UAH": {Currency: "UAH", RateDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), USDRate: 0.024},
+			"EUR": {Currency: "EUR", RateDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), USDRate: 1.08},
+		},
+		userCurrency:          make(map[string]string),
+		familyCurrency:        make(map[string]string),
+		familyPrivateExpenses: make(map[string]string),
+	}
+}
+
+func (m *mockCurrencyDB) ListCurrencies() ([]handler.MockCurrency, error) {
+	result := make([]handler.MockCurrency, len(mockCurrencies))
+	for i, code := range mockCurrencies {
+		rate := m.rates[code]
+		result[i] = handler.MockCurrency{Code: code, Name: code, USDRate: rate.USDRate, RateDate: rate.RateDate}
+	}
+	return result, nil
+}
+
+func (m *mockCurrencyDB) UpsertExchangeRate(currency string, rateDate time.Time, usdRate float64) (handler.MockExchangeRate, error) {
+	if !mockCurrencyKnown(currency) {
+		return handler.MockExchangeRate{}, handler.ErrInvalidCurrency
+	}
+	rate := handler.MockExchangeRate{Currency: currency, RateDate: rateDate, USDRate: usdRate}
+	m.rates[currency] = rate
+	return rate, nil
+}
+
+func (m *mockCurrencyDB) GetUserBaseCurrency(userID string) (string, error) {
+	if c, ok := m.userCurrency[userID]; ok {
+		return c, nil
+	}
+	return "UAH", nil
+}
+
+func (m *mockCurrencyDB) SetUserBaseCurrency(userID, currency string) error {
+	if !mockCurrencyKnown(currency) {
+		return handler.ErrInvalidCurrency
+	}
+	m.userCurrency[userID] = currency
+	return nil
+}
+
+func (m *mockCurrencyDB) SetFamilyBaseCurrency(familyID, currency string) error {
+	if !mockCurrencyKnown(currency) {
+		return handler.ErrInvalidCurrency
+	}
+	m.familyCurrency[familyID] = currency
+	return nil
+}
+
+func (m *mockCurrencyDB) SetFamilyPrivateExpenses(familyID, mode string) error {
+	m.familyPrivateExpenses[familyID] = mode
+	return nil
+}
+
+func setupCurrencyRouter(db handler.CurrencyDB, familyDB handler.FamilyDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewCurrencyHandler(db, familyDB)
+
+	api := r.Group("/api/v1", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User-ID"))
+		c.Next()
+	})
+	{
+		api.GET("/currencies", h.List)
+		api.PUT("/currencies/:code/rates", h.SetRate)
+		api.GET("/users/me/settings", h.GetSettings)
+		api.PUT("/users/me/settings", h.UpdateSettings)
+		api.PUT("/families/me/settings", h.UpdateFamilySettings)
+	}
+	return r
+}
+
+func putJSON(r *gin.Engine, path, userID string, payload map[string]any) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestListCurrencies(t *testing.T) {
+	r := setupCurrencyRouter(newMockCurrencyDB(), newMockFamilyDB())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/currencies", nil)
+	req.Header.Set("X-User-ID", testUserID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var list []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 3 {
+		t.Fatalf("expected 3 currencies, got %d", len(list))
+	}
+	if list[0]["code"] != "EUR" || list[0]["usd_rate"] != 1.08 || list[0]["rate_date"] != "2026-01-01" {
+		t.Fatalf("unexpected first currency: %v", list[0])
+	}
+}
+
+func TestSetExchangeRate(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db := newMockCurrencyDB()
+		r := setupCurrencyRouter(db, newMockFamilyDB())
+
+		w := putJSON(r, "/api/v1/currencies/eur/rates", testUserID, map[string]any{"rate_date": "2026-03-01", "usd_rate": 1.1})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := db.rates["EUR"]; got.USDRate != 1.1 || got.RateDate.Format("2006-01-02") != "2026-03-01" {
+			t.Fatalf("unexpected stored rate: %+v", got)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		payload map[string]any
+	}{
+		{"invalid code", "/api/v1/currencies/EURO/rates", map[string]any{"usd_rate": 1.1}},
+		{"unknown currency", "/api/v1/currencies/GBP/rates", map[string]any{"usd_rate": 1.27}},
+		{"zero rate", "/api/v1/currencies/EUR/rates", map[string]any{"usd_rate": 0}},
+		{"negative rate", "/api/v1/currencies/EUR/rates", map[string]any{"usd_rate": -1}},
+		{"invalid date", "/api/v1/currencies/EUR/rates", map[string]any{"usd_rate": 1.1, "rate_date": "03/01/2026"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupCurrencyRouter(newMockCurrencyDB(), newMockFamilyDB())
+			w := putJSON(r, tt.path, testUserID, tt.payload)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUserSettings(t *testing.T) {
+	t.Run("defaults and update", func(t *testing.T) {
+		db := newMockCurrencyDB()
+		r := setupCurrencyRouter(db, newMockFamilyDB())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me/settings", nil)
+		req.Header.Set("X-User-ID", testUserID)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["base_currency"] != "UAH" {
+			t.Fatalf("expected base_currency UAH, got %v", resp["base_currency"])
+		}
+
+		w = putJSON(r, "/api/v1/users/me/settings", testUserID, map[string]any{"base_currency": "usd"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if db.userCurrency[testUserID] != "USD" {
+			t.Fatalf("expected USD to be stored, got %q", db.userCurrency[testUserID])
+		}
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		r := setupCurrencyRouter(newMockCurrencyDB(), newMockFamilyDB())
+		w := putJSON(r, "/api/v1/users/me/settings", testUserID, map[string]any{"base_currency": "dollars"})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("unknown currency", func(t *testing.T) {
+		r := setupCurrencyRouter(newMockCurrencyDB(), newMockFamilyDB())
+		w := putJSON(r, "/api/v1/users/me/settings", testUserID, map[string]any{"base_currency": "GBP"})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestFamilySettings(t *testing.T) {
+	newFamily := func() *mockFamilyDB {
+		fdb := newMockFamilyDB()
+		fdb.families["family-1"] = &handler.MockFamily{ID: "family-1", Name: "Smith Family", AdminUserID: "user-1", BaseCurrency: "UAH"}
+		fdb.userFamily["user-1"] = "family-1"
+		fdb.userFamily["user-2"] = "family-1"
+		return fdb
+	}
+
+	t.Run("admin can change base currency", func(t *testing.T) {
+		db := newMockCurrencyDB()
+		r := setupCurrencyRouter(db, newFamily())
+
+		w := putJSON(r, "/api/v1/families/me/settings", "user-1", map[string]any{"base_currency": "EUR"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if db.familyCurrency["family-1"] != "EUR" {
+			t.Fatalf("expected EUR to be stored, got %q", db.familyCurrency["family-1"])
+		}
+	})
+
+	t.Run("member cannot change base currency", func(t *testing.T) {
+		r := setupCurrencyRouter(newMockCurrencyDB(), newFamily())
+		w := putJSON(r, "/api/v1/families/me/settings", "user-2", map[string]any{"base_currency": "EUR"})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("no family", func(t *testing.T) {
+		r := setupCurrencyRouter(newMockCurrencyDB(), newMockFamilyDB())
+		w := putJSON(r, "/api/v1/families/me/settings", "user-3", map[string]any{"base_currency": "EUR"})
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestFamilySettings_PrivateExpenses(t *testing.T) {
+	fdb := newMockFamilyDB()
+	fdb.families["family-1"] = &handler.MockFamily{ID: "family-1", Name: "Smith Family", AdminUserID: "user-1", BaseCurrency: "UAH", PrivateExpenses: handler.PrivateExpensesExclude}
+	fdb.userFamily["user-1"] = "family-1"
+
+	tests := []struct {
+		name    string
+		payload map[string]any
+		want    int
+	}{
+		{"aggregate", map[string]any{"private_expenses": "aggregate"}, http.StatusOK},
+		{"unknown mode", map[string]any{"private_expenses": "show"}, http.StatusBadRequest},
+		{"nothing to change", map[string]any{}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newMockCurrencyDB()
+			r := setupCurrencyRouter(db, fdb)
+			w := putJSON(r, "/api/v1/families/me/settings", "user-1", tt.payload)
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var resp map[string]any
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp["base_currency"] != "UAH" || resp["private_expenses"] != "aggregate" || db.familyPrivateExpenses["family-1"] != "aggregate" {
+				t.Fatalf("unexpected settings: %v", resp)
+			}
+		})
+	}
+}
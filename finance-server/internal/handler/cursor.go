@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response headers used by paginated listings. The router's CORS middleware
+// exposes them to browser clients. Search (q) results get no next cursor: they
+// are ordered by relevance, which the date-based keyset of a cursor cannot
+// continue, so they are paged by offset instead.
+const (
+	HeaderNextCursor = "X-Next-Cursor"
+	HeaderTotalCount = "X-Total-Count"
+	HeaderTotalCents = "X-Total-Cents"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// Encode returns the opaque form of the cursor handed out to clients.
+func (ec ExpenseCursor) Encode() string {
+	raw := ec.ExpenseDate.Format("2006-01-02") + "|" + ec.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + ec.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeExpenseCursor parses a cursor produced by ExpenseCursor.Encode.
+func decodeExpenseCursor(s string) (*ExpenseCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[2] == "" {
+		return nil, errInvalidCursor
+	}
+	expenseDate, err := time.Parse("2006-01-02", parts[0])
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &ExpenseCursor{ExpenseDate: expenseDate, CreatedAt: createdAt, ID: parts[2]}, nil
+}
+
+// parseListQuery reads the filter and cursor shared by expense listings.
+// The tag parameter may be repeated to require several tags.
+// Malformed dates are ignored; malformed amounts or cursors write a 400
+// response and return ok=false. Search results are ranked by relevance, so a
+// cursor cannot be combined with q.
+func parseListQuery(c *gin.Context) (filter ExpenseFilter, before *ExpenseCursor, ok bool) {
+	if df := c.Query("date_from"); df != "" {
+		if t, err := time.Parse("2006-01-02", df); err == nil {
+			filter.DateFrom = &t
+		}
+	}
+	if dt := c.Query("date_to"); dt != "" {
+		if t, err := time.Parse("2006-01-02", dt); err == nil {
+			filter.DateTo = &t
+		}
+	}
+	filter.CategoryID = c.Query("category_id")
+	filter.Query = strings.TrimSpace(c.Query("q"))
+	if tags := normalizeTags(c.QueryArray("tag")); len(tags) > 0 {
+		filter.Tags = tags
+	}
+
+	if filter.MinAmountCents, ok = parseAmountQuery(c, "min_amount"); !ok {
+		return filter, nil, false
+	}
+	if filter.MaxAmountCents, ok = parseAmountQuery(c, "max_amount"); !ok {
+		return filter, nil, false
+	}
+	if filter.MinAmountCents != nil && filter.MaxAmountCents != nil && *filter.MaxAmountCents < *filter.MinAmountCents {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_amount must not be less than min_amount"})
+		return filter, nil, false
+	}
+
+	if cur := c.Query("cursor"); cur != "" {
+		if filter.Query != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cursor cannot be combined with q"})
+			return filter, nil, false
+		}
+		var err error
+		before, err = decodeExpenseCursor(cur)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return filter, nil, false
+		}
+	}
+
+	return filter, before, true
+}
+
+// parseAmountQuery reads an optional non-negative amount in cents, writing a
+// 400 response and returning ok=false when it is malformed.
+func parseAmountQuery(c *gin.Context, name string) (*int64, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, true
+	}
+	amount, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || amount < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a non-negative amount in cents"})
+		return nil, false
+	}
+	return &amount, true
+}
+
+// wantTotals reports whether the client asked for include_totals.
+func wantTotals(c *gin.Context) bool {
+	v, _ := strconv.ParseBool(c.Query("include_totals"))
+	return v
+}
+
+func setTotalHeaders(c *gin.Context, totals ExpenseTotals) {
+	c.Header(HeaderTotalCount, strconv.FormatInt(totals.Count, 10))
+	c.Header(HeaderTotalCents, strconv.FormatInt(totals.TotalCents, 10))
+}
+
+// Encode returns the opaque form of the cursor handed out to clients.
+func (ac ActivityCursor) Encode() string {
+	raw := ac.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + ac.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeActivityCursor parses a cursor produced by ActivityCursor.Encode.
+func decodeActivityCursor(s string) (*ActivityCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, errInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return &ActivityCursor{CreatedAt: t, ID: id}, nil
+}
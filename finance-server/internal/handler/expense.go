@@ -12,49 +12,185 @@ import (
 
 // Sentinel errors for expense operations.
 var (
-	ErrExpenseNotFound = errors.New("expense not found")
+	ErrExpenseNotFound        = errors.New("expense not found")
+	ErrInvalidExpenseCategory = errors.New("invalid expense category")
 )
 
+// Expense visibilities. Private expenses are kept out of the owner's
+// families, or only counted in their totals, depending on each family's
+// PrivateExpenses setting.
+const (
+	VisibilityFamily  = "family"
+	VisibilityPrivate = "private"
+)
+
+// validVisibility reports whether v is empty or a known visibility.
+func validVisibility(v string) bool {
+	return v == "" || v == VisibilityFamily || v == VisibilityPrivate
+}
+
 // MockExpense is the expense representation used by the ExpenseDB interface.
+// RecurringID is set when the expense was posted from a recurring template.
+// RateDate is the date of the exchange rate used to convert the expense.
+// Tags are sorted by name.
 type MockExpense struct {
 	ID          string
 	UserID      string
 	CategoryID  string
 	AmountCents int64
+	Currency    string
 	Note        string
 	ExpenseDate time.Time
+	RateDate    time.Time
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	RecurringID string
+	Visibility  string
+	Tags        []string
+}
+
+// NewExpense holds the fields for one expense in a batch insert.
+// An empty Currency means the user's base currency; the visibility is
+// always the category's default.
+type NewExpense struct {
+	CategoryID  string
+	AmountCents int64
+	Currency    string
+	Note        string
+	ExpenseDate time.Time
+	Tags        []string
+}
+
+// ExpenseFingerprint identifies an existing expense for duplicate detection.
+type ExpenseFingerprint struct {
+	ExpenseDate time.Time
+	AmountCents int64
+	Note        string
+}
+
+// ExportExpense is an expense joined with its category for exports.
+type ExportExpense struct {
+	ID           string
+	CategoryID   string
+	CategoryName string
+	CategoryIcon string
+	AmountCents  int64
+	Currency     string
+	Note         string
+	ExpenseDate  time.Time
+	CreatedAt    time.Time
+}
+
+// ExpenseFilter narrows the rows included in a listing or export.
+// Nil bounds and empty strings are not applied. Query is a full-text search
+// over notes and category names; amounts compare against AmountCents in the
+// expense's own currency. Tags keeps the expenses carrying all of them.
+type ExpenseFilter struct {
+	DateFrom       *time.Time
+	DateTo         *time.Time
+	CategoryID     string
+	Query          string
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	Tags           []string
+}
+
+// CategorySuggestion is a category proposed for a note, backed by the past
+// expenses with similar notes that were filed under it.
+type CategorySuggestion struct {
+	CategoryID    string
+	CategoryName  string
+	CategoryIcon  string
+	CategoryColor string
+	MatchCount    int64
+	LastUsed      time.Time
+}
+
+// ExpenseCursor is the position of a row in a keyset-paginated query.
+// Listings are ordered by (ExpenseDate, CreatedAt, ID) descending and
+// exports ascending; a cursor selects the rows strictly past it.
+type ExpenseCursor struct {
+	ExpenseDate time.Time
+	CreatedAt   time.Time
+	ID          string
+}
+
+// ExpensePage selects one page of a newest-first listing. When Before is
+// set the page starts right after that row and Offset is left at zero.
+type ExpensePage struct {
+	Limit  int
+	Offset int
+	Before *ExpenseCursor
+}
+
+// ExpenseTotals is the count and sum of the expenses matching a filter,
+// converted to the owner's base currency.
+type ExpenseTotals struct {
+	Count      int64
+	TotalCents int64
 }
 
 // ExpenseDB abstracts database operations for expenses.
 // This allows testing with mock implementations.
+// An empty currency means the user's base currency on create and the
+// current currency on update; unknown codes return ErrInvalidCurrency.
+// Likewise an empty visibility means the category's default on create and
+// the current visibility on update, and nil tags keep the current tags on
+// update. Tags are created for the user as needed. The category must be an
+// expense category of the user's own or shared by one of their families,
+// and not archived unless an updated expense is already filed under it;
+// others return ErrInvalidExpenseCategory.
 type ExpenseDB interface {
-	CreateExpense(userID, categoryID string, amountCents int64, note string, expenseDate time.Time) (MockExpense, error)
+	CreateExpense(userID, categoryID string, amountCents int64, currency, visibility, note string, expenseDate time.Time, tags []string) (MockExpense, error)
+	// GetExpense returns ErrExpenseNotFound unless the expense belongs to userID.
+	GetExpense(id, userID string) (MockExpense, error)
 	GetExpensesByUser(userID string, limit, offset int) ([]MockExpense, error)
-	GetExpensesByUserFiltered(userID string, limit, offset int, dateFrom, dateTo *time.Time, categoryID string) ([]MockExpense, error)
-	UpdateExpense(id, userID, categoryID string, amountCents int64, note string, expenseDate time.Time) (MockExpense, error)
+	GetExpensesByUserFiltered(userID string, filter ExpenseFilter, page ExpensePage) ([]MockExpense, error)
+	GetExpenseTotals(userID string, filter ExpenseFilter) (ExpenseTotals, error)
+	UpdateExpense(id, userID, categoryID string, amountCents int64, currency, visibility, note string, expenseDate time.Time, tags []string) (MockExpense, error)
 	DeleteExpense(id, userID string) error
+	// CreateExpenses inserts all items in a single transaction.
+	CreateExpenses(userID string, items []NewExpense) ([]MockExpense, error)
+	GetExpenseFingerprints(userID string, dateFrom, dateTo time.Time) ([]ExpenseFingerprint, error)
+	// GetExpensesForExport returns up to limit rows after the cursor (nil for the first batch).
+	GetExpensesForExport(userID string, filter ExpenseFilter, after *ExpenseCursor, limit int) ([]ExportExpense, error)
+	// SuggestCategories returns up to limit categories of past expenses whose
+	// notes share words with note, best match first. Archived categories are
+	// never suggested.
+	SuggestCategories(userID, note string, limit int) ([]CategorySuggestion, error)
 }
 
+// maxCategorySuggestions bounds the categories returned by SuggestCategory.
+const maxCategorySuggestions = 3
+
 // ExpenseHandler handles expense HTTP requests.
+// Changes are recorded in the activity log of the families that share the
+// user's expenses. Expenses created without a category are categorized by
+// the user's category rules.
 type ExpenseHandler struct {
-	db ExpenseDB
+	db       ExpenseDB
+	ruleDB   CategoryRuleDB
+	activity ActivityDB
 }
 
-// NewExpenseHandler creates an ExpenseHandler with the given database.
-func NewExpenseHandler(db ExpenseDB) *ExpenseHandler {
-	return &ExpenseHandler{db: db}
+// NewExpenseHandler creates an ExpenseHandler with the given databases.
+func NewExpenseHandler(db ExpenseDB, ruleDB CategoryRuleDB, activity ActivityDB) *ExpenseHandler {
+	return &ExpenseHandler{db: db, ruleDB: ruleDB, activity: activity}
 }
 
 type createExpenseRequest struct {
-	CategoryID  string `json:"category_id"`
-	AmountCents int64  `json:"amount_cents"`
-	Note        string `json:"note"`
-	ExpenseDate string `json:"expense_date"`
+	CategoryID  string   `json:"category_id"`
+	AmountCents int64    `json:"amount_cents"`
+	Currency    string   `json:"currency"`
+	Visibility  string   `json:"visibility"`
+	Note        string   `json:"note"`
+	ExpenseDate string   `json:"expense_date"`
+	Tags        []string `json:"tags"`
 }
 
 // Create handles POST /api/v1/expenses.
+// Without a category_id the first matching category rule picks the
+// category and adds its tag, and rule_id in the response names it.
 func (h *ExpenseHandler) Create(c *gin.Context) {
 	var req createExpenseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -62,13 +198,19 @@ func (h *ExpenseHandler) Create(c *gin.Context) {
 		return
 	}
 
-	if req.CategoryID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+	if req.AmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount_cents must be greater than 0"})
 		return
 	}
 
-	if req.AmountCents <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "amount_cents must be greater than 0"})
+	currency, ok := parseCurrency(req.Currency)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be a 3-letter ISO 4217 code"})
+		return
+	}
+
+	if !validVisibility(req.Visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visibility must be family or private"})
 		return
 	}
 
@@ -84,10 +226,39 @@ func (h *ExpenseHandler) Create(c *gin.Context) {
 		}
 	}
 
+	tags := normalizeTags(req.Tags)
 	userID := c.GetString("user_id")
-	exp, err := h.db.CreateExpense(userID, req.CategoryID, req.AmountCents, req.Note, expenseDate)
+	categoryID := req.CategoryID
+	var ruleID any
+	if categoryID == "" {
+		rules, err := h.ruleDB.GetCategoryRulesByUser(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		rule, ok := newCategoryRules(rules).match(req.Note, req.AmountCents)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+			return
+		}
+		categoryID = rule.CategoryID
+		ruleID = rule.ID
+		if rule.Tag != "" {
+			tags = normalizeTags(append(tags, rule.Tag))
+		}
+	}
+	if err := checkTags(tags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exp, err := h.db.CreateExpense(userID, categoryID, req.AmountCents, currency, req.Visibility, req.Note, expenseDate, tags)
 	if err != nil {
-		if strings.Contains(err.Error(), "foreign key") || strings.Contains(err.Error(), "violates") {
+		if errors.Is(err, ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency"})
+			return
+		}
+		if errors.Is(err, ErrInvalidExpenseCategory) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
 			return
 		}
@@ -95,25 +266,73 @@ func (h *ExpenseHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if exp.Visibility != VisibilityPrivate {
+		recordMemberEvent(c, h.activity, ActionExpenseCreated, exp.ID, nil, expenseSnapshot(exp))
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"id":           exp.ID,
 		"user_id":      exp.UserID,
 		"category_id":  exp.CategoryID,
 		"amount_cents": exp.AmountCents,
+		"currency":     exp.Currency,
+		"visibility":   exp.Visibility,
 		"note":         exp.Note,
 		"expense_date": exp.ExpenseDate.Format("2006-01-02"),
+		"rate_date":    exp.RateDate.Format("2006-01-02"),
+		"tags":         tagsJSON(exp.Tags),
+		"rule_id":      ruleID,
 		"created_at":   exp.CreatedAt,
 	})
 }
 
+// SuggestCategory handles GET /api/v1/expenses/suggest-category.
+// It proposes categories for the note query parameter based on how the
+// user filed past expenses with similar notes.
+func (h *ExpenseHandler) SuggestCategory(c *gin.Context) {
+	note := strings.TrimSpace(c.Query("note"))
+	if note == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note query parameter is required"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	suggestions, err := h.db.SuggestCategories(userID, note, maxCategorySuggestions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(suggestions))
+	for i, s := range suggestions {
+		result[i] = gin.H{
+			"category_id":    s.CategoryID,
+			"category_name":  s.CategoryName,
+			"category_icon":  s.CategoryIcon,
+			"category_color": s.CategoryColor,
+			"match_count":    s.MatchCount,
+			"last_used":      s.LastUsed.Format("2006-01-02"),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"note":        note,
+		"suggestions": result,
+	})
+}
+
 type updateExpenseRequest struct {
-	CategoryID  string `json:"category_id"`
-	AmountCents int64  `json:"amount_cents"`
-	Note        string `json:"note"`
-	ExpenseDate string `json:"expense_date"`
+	CategoryID  string   `json:"category_id"`
+	AmountCents int64    `json:"amount_cents"`
+	Currency    string   `json:"currency"`
+	Visibility  string   `json:"visibility"`
+	Note        string   `json:"note"`
+	ExpenseDate string   `json:"expense_date"`
+	Tags        []string `json:"tags"`
 }
 
 // Update handles PUT /api/v1/expenses/:id.
+// Omitting tags keeps the current ones; an empty list removes them.
 func (h *ExpenseHandler) Update(c *gin.Context) {
 	id := c.Param("id")
 	userID := c.GetString("user_id")
@@ -134,6 +353,17 @@ func (h *ExpenseHandler) Update(c *gin.Context) {
 		return
 	}
 
+	currency, ok := parseCurrency(req.Currency)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be a 3-letter ISO 4217 code"})
+		return
+	}
+
+	if !validVisibility(req.Visibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visibility must be family or private"})
+		return
+	}
+
 	var expenseDate time.Time
 	if req.ExpenseDate == "" {
 		expenseDate = time.Now()
@@ -146,13 +376,33 @@ func (h *ExpenseHandler) Update(c *gin.Context) {
 		}
 	}
 
-	exp, err := h.db.UpdateExpense(id, userID, req.CategoryID, req.AmountCents, req.Note, expenseDate)
+	tags := normalizeTags(req.Tags)
+	if err := checkTags(tags); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before, err := h.db.GetExpense(id, userID)
 	if err != nil {
 		if errors.Is(err, ErrExpenseNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
 			return
 		}
-		if strings.Contains(err.Error(), "foreign key") || strings.Contains(err.Error(), "violates") {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	exp, err := h.db.UpdateExpense(id, userID, req.CategoryID, req.AmountCents, currency, req.Visibility, req.Note, expenseDate, tags)
+	if err != nil {
+		if errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		if errors.Is(err, ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency"})
+			return
+		}
+		if errors.Is(err, ErrInvalidExpenseCategory) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
 			return
 		}
@@ -160,13 +410,21 @@ func (h *ExpenseHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if before.Visibility != VisibilityPrivate || exp.Visibility != VisibilityPrivate {
+		recordMemberEvent(c, h.activity, ActionExpenseUpdated, exp.ID, expenseSnapshot(before), expenseSnapshot(exp))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"id":           exp.ID,
 		"user_id":      exp.UserID,
 		"category_id":  exp.CategoryID,
 		"amount_cents": exp.AmountCents,
+		"currency":     exp.Currency,
+		"visibility":   exp.Visibility,
 		"note":         exp.Note,
 		"expense_date": exp.ExpenseDate.Format("2006-01-02"),
+		"rate_date":    exp.RateDate.Format("2006-01-02"),
+		"tags":         tagsJSON(exp.Tags),
 		"created_at":   exp.CreatedAt,
 		"updated_at":   exp.UpdatedAt,
 	})
@@ -177,7 +435,7 @@ func (h *ExpenseHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	userID := c.GetString("user_id")
 
-	err := h.db.DeleteExpense(id, userID)
+	before, err := h.db.GetExpense(id, userID)
 	if err != nil {
 		if errors.Is(err, ErrExpenseNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
@@ -187,10 +445,33 @@ func (h *ExpenseHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if err := h.db.DeleteExpense(id, userID); err != nil {
+		if errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	if before.Visibility != VisibilityPrivate {
+		recordMemberEvent(c, h.activity, ActionExpenseDeleted, id, expenseSnapshot(before), nil)
+	}
+
 	c.Status(http.StatusNoContent)
 }
 
 // List handles GET /api/v1/expenses.
+// Optional filters: date_from, date_to, category_id, min_amount and
+// max_amount (in cents), tag (repeatable; expenses must carry every given
+// tag), and q, a full-text search over notes and category names whose
+// matches are returned most relevant first.
+// Pages can be requested by offset or, to stay stable while expenses are
+// added, by passing the X-Next-Cursor of the previous page as cursor. Search
+// results are ranked by relevance rather than by date, so they carry no
+// cursor and are paged by offset only.
+// With include_totals=true the count and sum of all matching expenses are
+// returned in the X-Total-Count and X-Total-Cents headers.
 func (h *ExpenseHandler) List(c *gin.Context) {
 	userID := c.GetString("user_id")
 
@@ -208,34 +489,56 @@ func (h *ExpenseHandler) List(c *gin.Context) {
 		}
 	}
 
-	var dateFrom, dateTo *time.Time
-	if df := c.Query("date_from"); df != "" {
-		if t, err := time.Parse("2006-01-02", df); err == nil {
-			dateFrom = &t
-		}
+	filter, before, ok := parseListQuery(c)
+	if !ok {
+		return
 	}
-	if dt := c.Query("date_to"); dt != "" {
-		if t, err := time.Parse("2006-01-02", dt); err == nil {
-			dateTo = &t
-		}
+	if before != nil {
+		offset = 0
 	}
-	categoryID := c.Query("category_id")
 
-	expenses, err := h.db.GetExpensesByUserFiltered(userID, limit, offset, dateFrom, dateTo, categoryID)
+	// One extra row tells whether there is a next page.
+	expenses, err := h.db.GetExpensesByUserFiltered(userID, filter, ExpensePage{Limit: limit + 1, Offset: offset, Before: before})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
+	if len(expenses) > limit {
+		expenses = expenses[:limit]
+		last := expenses[limit-1]
+		// Relevance-ranked search results cannot be continued by a keyset cursor.
+		if filter.Query == "" {
+			c.Header(HeaderNextCursor, ExpenseCursor{ExpenseDate: last.ExpenseDate, CreatedAt: last.CreatedAt, ID: last.ID}.Encode())
+		}
+	}
+
+	if wantTotals(c) {
+		totals, err := h.db.GetExpenseTotals(userID, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		setTotalHeaders(c, totals)
+	}
 
 	result := make([]gin.H, len(expenses))
 	for i, exp := range expenses {
+		var recurringID any
+		if exp.RecurringID != "" {
+			recurringID = exp.RecurringID
+		}
 		result[i] = gin.H{
 			"id":           exp.ID,
 			"user_id":      exp.UserID,
 			"category_id":  exp.CategoryID,
 			"amount_cents": exp.AmountCents,
+			"currency":     exp.Currency,
+			"visibility":   exp.Visibility,
 			"note":         exp.Note,
 			"expense_date": exp.ExpenseDate.Format("2006-01-02"),
+			"rate_date":    exp.RateDate.Format("2006-01-02"),
+			"recurring_id": recurringID,
+			"tags":         tagsJSON(exp.Tags),
 			"created_at":   exp.CreatedAt,
 		}
 	}
@@ -2,24 +2,52 @@ package handler
 
 import (
 	"context"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
 )
 
 // PgExpenseDB implements ExpenseDB using sqlc-generated queries against PostgreSQL.
 type PgExpenseDB struct {
 	queries *sqlc.Queries
+	pool    *pgxpool.Pool
 }
 
 // NewPgExpenseDB creates a PgExpenseDB wrapping sqlc.Queries.
-func NewPgExpenseDB(queries *sqlc.Queries) *PgExpenseDB {
-	return &PgExpenseDB{queries: queries}
+// The pool is used for operations that need a transaction.
+func NewPgExpenseDB(queries *sqlc.Queries, pool *pgxpool.Pool) *PgExpenseDB {
+	return &PgExpenseDB{queries: queries, pool: pool}
 }
 
-func (db *PgExpenseDB) CreateExpense(userID, categoryID string, amountCents int64, note string, expenseDate time.Time) (MockExpense, error) {
+// checkExpenseCategory verifies that the user may file expenses under the
+// category and that it is an expense category. An archived category is only
+// accepted for expenseID when the expense is already filed under it; pass an
+// invalid expenseID for new expenses.
+func checkExpenseCategory(ctx context.Context, q *sqlc.Queries, userID, categoryID, expenseID pgtype.UUID) error {
+	kind, err := q.GetExpenseCategoryKind(ctx, sqlc.GetExpenseCategoryKindParams{
+		ID:        categoryID,
+		UserID:    userID,
+		ExpenseID: expenseID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrInvalidExpenseCategory
+		}
+		return err
+	}
+	if kind != CategoryKindExpense {
+		return ErrInvalidExpenseCategory
+	}
+	return nil
+}
+
+func (db *PgExpenseDB) CreateExpense(userID, categoryID string, amountCents int64, currency, visibility, note string, expenseDate time.Time, tags []string) (MockExpense, error) {
+	ctx := context.Background()
 	uid := stringToUUID(userID)
 	cid := stringToUUID(categoryID)
 
@@ -28,14 +56,32 @@ func (db *PgExpenseDB) CreateExpense(userID, categoryID string, amountCents int6
 		Valid: true,
 	}
 
-	row, err := db.queries.CreateExpense(context.Background(), sqlc.CreateExpenseParams{
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return MockExpense{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	if err := checkExpenseCategory(ctx, qtx, uid, cid, pgtype.UUID{}); err != nil {
+		return MockExpense{}, err
+	}
+	row, err := qtx.CreateExpense(ctx, sqlc.CreateExpenseParams{
 		UserID:      uid,
 		CategoryID:  cid,
 		AmountCents: amountCents,
 		Note:        note,
 		ExpenseDate: dateVal,
+		Currency:    stringToNullableText(currency),
+		Visibility:  stringToNullableText(visibility),
 	})
 	if err != nil {
+		return MockExpense{}, currencyError(err)
+	}
+	if err := addExpenseTags(ctx, qtx, uid, row.ID, tags); err != nil {
+		return MockExpense{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
 		return MockExpense{}, err
 	}
 
@@ -48,9 +94,48 @@ func (db *PgExpenseDB) CreateExpense(userID, categoryID string, amountCents int6
 		ExpenseDate: row.ExpenseDate.Time,
 		CreatedAt:   row.CreatedAt.Time,
 		UpdatedAt:   row.UpdatedAt.Time,
+		RecurringID: uuidToString(row.RecurringID),
+		Currency:    row.Currency,
+		RateDate:    row.RateDate.Time,
+		Visibility:  row.Visibility,
+		Tags:        tags,
 	}, nil
 }
 
+func (db *PgExpenseDB) GetExpense(id, userID string) (MockExpense, error) {
+	row, err := db.queries.GetExpense(context.Background(), sqlc.GetExpenseParams{
+		ID:     stringToUUID(id),
+		UserID: stringToUUID(userID),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockExpense{}, ErrExpenseNotFound
+		}
+		return MockExpense{}, err
+	}
+
+	exp := MockExpense{
+		ID:          uuidToString(row.ID),
+		UserID:      uuidToString(row.UserID),
+		CategoryID:  uuidToString(row.CategoryID),
+		AmountCents: row.AmountCents,
+		Note:        row.Note,
+		ExpenseDate: row.ExpenseDate.Time,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+		RecurringID: uuidToString(row.RecurringID),
+		Currency:    row.Currency,
+		RateDate:    row.RateDate.Time,
+		Visibility:  row.Visibility,
+	}
+	tags, err := expenseTags(context.Background(), db.queries, []string{exp.ID})
+	if err != nil {
+		return MockExpense{}, err
+	}
+	exp.Tags = tags[exp.ID]
+	return exp, nil
+}
+
 func (db *PgExpenseDB) GetExpensesByUser(userID string, limit, offset int) ([]MockExpense, error) {
 	uid := stringToUUID(userID)
 
@@ -74,12 +159,20 @@ func (db *PgExpenseDB) GetExpensesByUser(userID string, limit, offset int) ([]Mo
 			ExpenseDate: row.ExpenseDate.Time,
 			CreatedAt:   row.CreatedAt.Time,
 			UpdatedAt:   row.UpdatedAt.Time,
+			RecurringID: uuidToString(row.RecurringID),
+			Currency:    row.Currency,
+			RateDate:    row.RateDate.Time,
+			Visibility:  row.Visibility,
 		}
 	}
+	if err := attachExpenseTags(context.Background(), db.queries, expenses); err != nil {
+		return nil, err
+	}
 	return expenses, nil
 }
 
-func (db *PgExpenseDB) UpdateExpense(id, userID, categoryID string, amountCents int64, note string, expenseDate time.Time) (MockExpense, error) {
+func (db *PgExpenseDB) UpdateExpense(id, userID, categoryID string, amountCents int64, currency, visibility, note string, expenseDate time.Time, tags []string) (MockExpense, error) {
+	ctx := context.Background()
 	uid := stringToUUID(id)
 	uidUser := stringToUUID(userID)
 	cid := stringToUUID(categoryID)
@@ -89,22 +182,39 @@ func (db *PgExpenseDB) UpdateExpense(id, userID, categoryID string, amountCents
 		Valid: true,
 	}
 
-	row, err := db.queries.UpdateExpense(context.Background(), sqlc.UpdateExpenseParams{
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return MockExpense{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	if err := checkExpenseCategory(ctx, qtx, uidUser, cid, uid); err != nil {
+		return MockExpense{}, err
+	}
+	row, err := qtx.UpdateExpense(ctx, sqlc.UpdateExpenseParams{
 		ID:          uid,
 		UserID:      uidUser,
 		CategoryID:  cid,
 		AmountCents: amountCents,
 		Note:        note,
 		ExpenseDate: dateVal,
+		Currency:    stringToNullableText(currency),
+		Visibility:  stringToNullableText(visibility),
 	})
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return MockExpense{}, ErrExpenseNotFound
 		}
-		return MockExpense{}, err
+		return MockExpense{}, currencyError(err)
+	}
+	if tags != nil {
+		if err := replaceExpenseTags(ctx, qtx, uidUser, row.ID, tags); err != nil {
+			return MockExpense{}, err
+		}
 	}
 
-	return MockExpense{
+	exp := MockExpense{
 		ID:          uuidToString(row.ID),
 		UserID:      uuidToString(row.UserID),
 		CategoryID:  uuidToString(row.CategoryID),
@@ -113,7 +223,21 @@ func (db *PgExpenseDB) UpdateExpense(id, userID, categoryID string, amountCents
 		ExpenseDate: row.ExpenseDate.Time,
 		CreatedAt:   row.CreatedAt.Time,
 		UpdatedAt:   row.UpdatedAt.Time,
-	}, nil
+		RecurringID: uuidToString(row.RecurringID),
+		Currency:    row.Currency,
+		RateDate:    row.RateDate.Time,
+		Visibility:  row.Visibility,
+	}
+	current, err := expenseTags(ctx, qtx, []string{exp.ID})
+	if err != nil {
+		return MockExpense{}, err
+	}
+	exp.Tags = current[exp.ID]
+
+	if err := tx.Commit(ctx); err != nil {
+		return MockExpense{}, err
+	}
+	return exp, nil
 }
 
 func dateToPgDate(t *time.Time) pgtype.Date {
@@ -123,6 +247,13 @@ func dateToPgDate(t *time.Time) pgtype.Date {
 	return pgtype.Date{Time: *t, Valid: true}
 }
 
+func int64ToPgInt8(v *int64) pgtype.Int8 {
+	if v == nil {
+		return pgtype.Int8{Valid: false}
+	}
+	return pgtype.Int8{Int64: *v, Valid: true}
+}
+
 func stringToNullableUUID(s string) pgtype.UUID {
 	if s == "" {
 		return pgtype.UUID{Valid: false}
@@ -130,16 +261,23 @@ func stringToNullableUUID(s string) pgtype.UUID {
 	return stringToUUID(s)
 }
 
-func (db *PgExpenseDB) GetExpensesByUserFiltered(userID string, limit, offset int, dateFrom, dateTo *time.Time, categoryID string) ([]MockExpense, error) {
-	uid := stringToUUID(userID)
+func (db *PgExpenseDB) GetExpensesByUserFiltered(userID string, filter ExpenseFilter, page ExpensePage) ([]MockExpense, error) {
+	beforeID, beforeDate, beforeCreatedAt := cursorParams(page.Before)
 
 	rows, err := db.queries.GetExpensesByUserFiltered(context.Background(), sqlc.GetExpensesByUserFilteredParams{
-		UserID:     uid,
-		Limit:      int32(limit),
-		Offset:     int32(offset),
-		DateFrom:   dateToPgDate(dateFrom),
-		DateTo:     dateToPgDate(dateTo),
-		CategoryID: stringToNullableUUID(categoryID),
+		UserID:          stringToUUID(userID),
+		Limit:           int32(page.Limit),
+		Offset:          int32(page.Offset),
+		DateFrom:        dateToPgDate(filter.DateFrom),
+		DateTo:          dateToPgDate(filter.DateTo),
+		CategoryID:      stringToNullableUUID(filter.CategoryID),
+		Query:           stringToNullableText(filter.Query),
+		MinAmount:       int64ToPgInt8(filter.MinAmountCents),
+		MaxAmount:       int64ToPgInt8(filter.MaxAmountCents),
+		Tags:            filter.Tags,
+		BeforeID:        beforeID,
+		BeforeDate:      beforeDate,
+		BeforeCreatedAt: beforeCreatedAt,
 	})
 	if err != nil {
 		return nil, err
@@ -156,11 +294,35 @@ func (db *PgExpenseDB) GetExpensesByUserFiltered(userID string, limit, offset in
 			ExpenseDate: row.ExpenseDate.Time,
 			CreatedAt:   row.CreatedAt.Time,
 			UpdatedAt:   row.UpdatedAt.Time,
+			RecurringID: uuidToString(row.RecurringID),
+			Currency:    row.Currency,
+			RateDate:    row.RateDate.Time,
+			Visibility:  row.Visibility,
 		}
 	}
+	if err := attachExpenseTags(context.Background(), db.queries, expenses); err != nil {
+		return nil, err
+	}
 	return expenses, nil
 }
 
+func (db *PgExpenseDB) GetExpenseTotals(userID string, filter ExpenseFilter) (ExpenseTotals, error) {
+	row, err := db.queries.GetExpenseTotals(context.Background(), sqlc.GetExpenseTotalsParams{
+		UserID:     stringToUUID(userID),
+		DateFrom:   dateToPgDate(filter.DateFrom),
+		DateTo:     dateToPgDate(filter.DateTo),
+		CategoryID: stringToNullableUUID(filter.CategoryID),
+		Query:      stringToNullableText(filter.Query),
+		MinAmount:  int64ToPgInt8(filter.MinAmountCents),
+		MaxAmount:  int64ToPgInt8(filter.MaxAmountCents),
+		Tags:       filter.Tags,
+	})
+	if err != nil {
+		return ExpenseTotals{}, err
+	}
+	return ExpenseTotals{Count: row.ExpenseCount, TotalCents: row.TotalCents}, nil
+}
+
 func (db *PgExpenseDB) DeleteExpense(id, userID string) error {
 	uid := stringToUUID(id)
 	uidUser := stringToUUID(userID)
@@ -177,3 +339,161 @@ func (db *PgExpenseDB) DeleteExpense(id, userID string) error {
 	}
 	return nil
 }
+
+func (db *PgExpenseDB) CreateExpenses(userID string, items []NewExpense) ([]MockExpense, error) {
+	ctx := context.Background()
+	uid := stringToUUID(userID)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	expenses := make([]MockExpense, len(items))
+	for i, item := range items {
+		cid := stringToUUID(item.CategoryID)
+		if err := checkExpenseCategory(ctx, qtx, uid, cid, pgtype.UUID{}); err != nil {
+			return nil, err
+		}
+		row, err := qtx.CreateExpense(ctx, sqlc.CreateExpenseParams{
+			UserID:      uid,
+			CategoryID:  cid,
+			AmountCents: item.AmountCents,
+			Note:        item.Note,
+			ExpenseDate: pgtype.Date{Time: item.ExpenseDate, Valid: true},
+			Currency:    stringToNullableText(item.Currency),
+		})
+		if err != nil {
+			return nil, currencyError(err)
+		}
+		if err := addExpenseTags(ctx, qtx, uid, row.ID, item.Tags); err != nil {
+			return nil, err
+		}
+		expenses[i] = MockExpense{
+			ID:          uuidToString(row.ID),
+			UserID:      uuidToString(row.UserID),
+			CategoryID:  uuidToString(row.CategoryID),
+			AmountCents: row.AmountCents,
+			Note:        row.Note,
+			ExpenseDate: row.ExpenseDate.Time,
+			CreatedAt:   row.CreatedAt.Time,
+			UpdatedAt:   row.UpdatedAt.Time,
+			Currency:    row.Currency,
+			RateDate:    row.RateDate.Time,
+			Visibility:  row.Visibility,
+			Tags:        item.Tags,
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+func (db *PgExpenseDB) GetExpenseFingerprints(userID string, dateFrom, dateTo time.Time) ([]ExpenseFingerprint, error) {
+	rows, err := db.queries.GetExpenseFingerprints(context.Background(), sqlc.GetExpenseFingerprintsParams{
+		UserID:   stringToUUID(userID),
+		DateFrom: pgtype.Date{Time: dateFrom, Valid: true},
+		DateTo:   pgtype.Date{Time: dateTo, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make([]ExpenseFingerprint, len(rows))
+	for i, row := range rows {
+		fingerprints[i] = ExpenseFingerprint{
+			ExpenseDate: row.ExpenseDate.Time,
+			AmountCents: row.AmountCents,
+			Note:        row.Note,
+		}
+	}
+	return fingerprints, nil
+}
+
+// cursorParams converts a cursor into the nullable keyset parameters
+// shared by the paginated expense queries.
+func cursorParams(cursor *ExpenseCursor) (pgtype.UUID, pgtype.Date, pgtype.Timestamptz) {
+	if cursor == nil {
+		return pgtype.UUID{}, pgtype.Date{}, pgtype.Timestamptz{}
+	}
+	return stringToUUID(cursor.ID),
+		pgtype.Date{Time: cursor.ExpenseDate, Valid: true},
+		pgtype.Timestamptz{Time: cursor.CreatedAt, Valid: true}
+}
+
+func (db *PgExpenseDB) GetExpensesForExport(userID string, filter ExpenseFilter, after *ExpenseCursor, limit int) ([]ExportExpense, error) {
+	afterID, afterDate, afterCreatedAt := cursorParams(after)
+
+	rows, err := db.queries.GetExpensesForExport(context.Background(), sqlc.GetExpensesForExportParams{
+		UserID:         stringToUUID(userID),
+		Limit:          int32(limit),
+		DateFrom:       dateToPgDate(filter.DateFrom),
+		DateTo:         dateToPgDate(filter.DateTo),
+		CategoryID:     stringToNullableUUID(filter.CategoryID),
+		AfterID:        afterID,
+		AfterDate:      afterDate,
+		AfterCreatedAt: afterCreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expenses := make([]ExportExpense, len(rows))
+	for i, row := range rows {
+		expenses[i] = ExportExpense{
+			ID:           uuidToString(row.ID),
+			CategoryID:   uuidToString(row.CategoryID),
+			CategoryName: row.CategoryName,
+			CategoryIcon: row.CategoryIcon,
+			AmountCents:  row.AmountCents,
+			Currency:     row.Currency,
+			Note:         row.Note,
+			ExpenseDate:  row.ExpenseDate.Time,
+			CreatedAt:    row.CreatedAt.Time,
+		}
+	}
+	return expenses, nil
+}
+
+func (db *PgExpenseDB) SuggestCategories(userID, note string, limit int) ([]CategorySuggestion, error) {
+	query := anyWordQuery(note)
+	if query == "" {
+		return []CategorySuggestion{}, nil
+	}
+
+	rows, err := db.queries.GetCategorySuggestions(context.Background(), sqlc.GetCategorySuggestionsParams{
+		UserID: stringToUUID(userID),
+		Limit:  int32(limit),
+		Query:  query,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]CategorySuggestion, len(rows))
+	for i, row := range rows {
+		suggestions[i] = CategorySuggestion{
+			CategoryID:    uuidToString(row.CategoryID),
+			CategoryName:  row.CategoryName,
+			CategoryIcon:  row.CategoryIcon,
+			CategoryColor: row.CategoryColor,
+			MatchCount:    row.MatchCount,
+			LastUsed:      row.LastUsed.Time,
+		}
+	}
+	return suggestions, nil
+}
+
+// anyWordQuery turns free text into a web search query matching any of its
+// words, so past notes sharing only some words with it still match. Quotes
+// and other search syntax in the text are dropped.
+func anyWordQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " or ")
+}
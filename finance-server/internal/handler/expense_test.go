@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,6 +28,13 @@ type mockExpenseDB struct {
 	lastFilterDateFrom *time.Time
 	lastFilterDateTo   *time.Time
 	lastFilterCatID    string
+	lastFilter         handler.ExpenseFilter
+	lastPage           handler.ExpensePage
+	exportCalls        int
+	// privateCategories default new expenses to private.
+	privateCategories map[string]bool
+	// archivedCategories only accept expenses already filed under them.
+	archivedCategories map[string]bool
 }
 
 func newMockExpenseDB() *mockExpenseDB {
@@ -34,17 +44,36 @@ func newMockExpenseDB() *mockExpenseDB {
 	}
 }
 
-func (m *mockExpenseDB) CreateExpense(userID, categoryID string, amountCents int64, note string, expenseDate time.Time) (handler.MockExpense, error) {
+func (m *mockExpenseDB) CreateExpense(userID, categoryID string, amountCents int64, currency, visibility, note string, expenseDate time.Time, tags []string) (handler.MockExpense, error) {
 	if m.createErr != nil {
 		return handler.MockExpense{}, m.createErr
 	}
+	if currency == "" {
+		currency = "UAH"
+	}
+	if visibility == "" {
+		visibility = handler.VisibilityFamily
+		if m.privateCategories[categoryID] {
+			visibility = handler.VisibilityPrivate
+		}
+	}
+	if !mockCurrencyKnown(currency) {
+		return handler.MockExpense{}, handler.ErrInvalidCurrency
+	}
+	if categoryID == testIncomeCategoryID || m.archivedCategories[categoryID] {
+		return handler.MockExpense{}, handler.ErrInvalidExpenseCategory
+	}
 	exp := handler.MockExpense{
 		ID:          expIDForIndex(m.nextID),
 		UserID:      userID,
 		CategoryID:  categoryID,
 		AmountCents: amountCents,
+		Currency:    currency,
+		Visibility:  visibility,
 		Note:        note,
 		ExpenseDate: expenseDate,
+		RateDate:    expenseDate,
+		Tags:        tags,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -54,41 +83,150 @@ func (m *mockExpenseDB) CreateExpense(userID, categoryID string, amountCents int
 }
 
 func (m *mockExpenseDB) GetExpensesByUser(userID string, limit, offset int) ([]handler.MockExpense, error) {
-	return m.GetExpensesByUserFiltered(userID, limit, offset, nil, nil, "")
+	return m.GetExpensesByUserFiltered(userID, handler.ExpenseFilter{}, handler.ExpensePage{Limit: limit, Offset: offset})
 }
 
-func (m *mockExpenseDB) GetExpensesByUserFiltered(userID string, limit, offset int, dateFrom, dateTo *time.Time, categoryID string) ([]handler.MockExpense, error) {
-	m.lastFilterDateFrom = dateFrom
-	m.lastFilterDateTo = dateTo
-	m.lastFilterCatID = categoryID
+// expenseBefore reports whether a sorts before b in a newest-first listing.
+func expenseBefore(a, b handler.ExpenseCursor) bool {
+	if !a.ExpenseDate.Equal(b.ExpenseDate) {
+		return a.ExpenseDate.After(b.ExpenseDate)
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
+
+func expenseCursor(exp handler.MockExpense) handler.ExpenseCursor {
+	return handler.ExpenseCursor{ExpenseDate: exp.ExpenseDate, CreatedAt: exp.CreatedAt, ID: exp.ID}
+}
 
+func (m *mockExpenseDB) matching(userID string, filter handler.ExpenseFilter) []handler.MockExpense {
 	var result []handler.MockExpense
 	for _, exp := range m.expenses {
-		if exp.UserID == userID {
-			result = append(result, exp)
+		if exp.UserID != userID {
+			continue
+		}
+		if filter.DateFrom != nil && exp.ExpenseDate.Before(*filter.DateFrom) {
+			continue
+		}
+		if filter.DateTo != nil && exp.ExpenseDate.After(*filter.DateTo) {
+			continue
+		}
+		if filter.CategoryID != "" && exp.CategoryID != filter.CategoryID {
+			continue
+		}
+		if filter.MinAmountCents != nil && exp.AmountCents < *filter.MinAmountCents {
+			continue
+		}
+		if filter.MaxAmountCents != nil && exp.AmountCents > *filter.MaxAmountCents {
+			continue
+		}
+		// Full-text search is approximated by a case-insensitive substring match.
+		if filter.Query != "" && !strings.Contains(strings.ToLower(exp.Note), strings.ToLower(filter.Query)) {
+			continue
+		}
+		if !hasAllTags(exp.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, exp)
+	}
+	return result
+}
+
+// hasAllTags reports whether tags contains every one of want.
+func hasAllTags(tags, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, t := range tags {
+			if t == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
 		}
 	}
+	return true
+}
+
+func (m *mockExpenseDB) GetExpensesByUserFiltered(userID string, filter handler.ExpenseFilter, page handler.ExpensePage) ([]handler.MockExpense, error) {
+	m.lastFilterDateFrom = filter.DateFrom
+	m.lastFilterDateTo = filter.DateTo
+	m.lastFilterCatID = filter.CategoryID
+	m.lastFilter = filter
+	m.lastPage = page
+
+	result := m.matching(userID, filter)
+	sort.SliceStable(result, func(i, j int) bool {
+		return expenseBefore(expenseCursor(result[i]), expenseCursor(result[j]))
+	})
+	if page.Before != nil {
+		start := 0
+		for start < len(result) && !expenseBefore(*page.Before, expenseCursor(result[start])) {
+			start++
+		}
+		result = result[start:]
+	}
 	// Apply offset and limit
-	if offset >= len(result) {
+	if page.Offset >= len(result) {
 		return []handler.MockExpense{}, nil
 	}
-	result = result[offset:]
-	if limit < len(result) {
-		result = result[:limit]
+	result = result[page.Offset:]
+	if page.Limit < len(result) {
+		result = result[:page.Limit]
 	}
 	return result, nil
 }
 
-func (m *mockExpenseDB) UpdateExpense(id, userID, categoryID string, amountCents int64, note string, expenseDate time.Time) (handler.MockExpense, error) {
+func (m *mockExpenseDB) GetExpenseTotals(userID string, filter handler.ExpenseFilter) (handler.ExpenseTotals, error) {
+	var totals handler.ExpenseTotals
+	for _, exp := range m.matching(userID, filter) {
+		totals.Count++
+		totals.TotalCents += exp.AmountCents
+	}
+	return totals, nil
+}
+
+func (m *mockExpenseDB) GetExpense(id, userID string) (handler.MockExpense, error) {
+	for _, exp := range m.expenses {
+		if exp.ID == id && exp.UserID == userID {
+			return exp, nil
+		}
+	}
+	return handler.MockExpense{}, handler.ErrExpenseNotFound
+}
+
+func (m *mockExpenseDB) UpdateExpense(id, userID, categoryID string, amountCents int64, currency, visibility, note string, expenseDate time.Time, tags []string) (handler.MockExpense, error) {
 	if m.updateErr != nil {
 		return handler.MockExpense{}, m.updateErr
 	}
+	if currency != "" && !mockCurrencyKnown(currency) {
+		return handler.MockExpense{}, handler.ErrInvalidCurrency
+	}
+	if categoryID == testIncomeCategoryID {
+		return handler.MockExpense{}, handler.ErrInvalidExpenseCategory
+	}
 	for i, exp := range m.expenses {
 		if exp.ID == id && exp.UserID == userID {
+			if m.archivedCategories[categoryID] && exp.CategoryID != categoryID {
+				return handler.MockExpense{}, handler.ErrInvalidExpenseCategory
+			}
 			m.expenses[i].CategoryID = categoryID
 			m.expenses[i].AmountCents = amountCents
+			if currency != "" {
+				m.expenses[i].Currency = currency
+			}
+			if visibility != "" {
+				m.expenses[i].Visibility = visibility
+			}
 			m.expenses[i].Note = note
 			m.expenses[i].ExpenseDate = expenseDate
+			m.expenses[i].RateDate = expenseDate
+			if tags != nil {
+				m.expenses[i].Tags = tags
+			}
 			m.expenses[i].UpdatedAt = time.Now()
 			return m.expenses[i], nil
 		}
@@ -109,14 +247,138 @@ func (m *mockExpenseDB) DeleteExpense(id, userID string) error {
 	return handler.ErrExpenseNotFound
 }
 
+func (m *mockExpenseDB) CreateExpenses(userID string, items []handler.NewExpense) ([]handler.MockExpense, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	for _, item := range items {
+		if item.CategoryID == testIncomeCategoryID || m.archivedCategories[item.CategoryID] {
+			return nil, handler.ErrInvalidExpenseCategory
+		}
+	}
+	created := make([]handler.MockExpense, len(items))
+	for i, item := range items {
+		created[i], _ = m.CreateExpense(userID, item.CategoryID, item.AmountCents, item.Currency, "", item.Note, item.ExpenseDate, item.Tags)
+	}
+	return created, nil
+}
+
+func (m *mockExpenseDB) GetExpenseFingerprints(userID string, dateFrom, dateTo time.Time) ([]handler.ExpenseFingerprint, error) {
+	var result []handler.ExpenseFingerprint
+	for _, exp := range m.expenses {
+		if exp.UserID == userID && !exp.ExpenseDate.Before(dateFrom) && !exp.ExpenseDate.After(dateTo) {
+			result = append(result, handler.ExpenseFingerprint{
+				ExpenseDate: exp.ExpenseDate,
+				AmountCents: exp.AmountCents,
+				Note:        exp.Note,
+			})
+		}
+	}
+	return result, nil
+}
+
+func (m *mockExpenseDB) SuggestCategories(userID, note string, limit int) ([]handler.CategorySuggestion, error) {
+	words := strings.Fields(strings.ToLower(note))
+	byCategory := make(map[string]*handler.CategorySuggestion)
+	var result []*handler.CategorySuggestion
+	for _, exp := range m.expenses {
+		if exp.UserID != userID {
+			continue
+		}
+		matched := false
+		for _, w := range strings.Fields(strings.ToLower(exp.Note)) {
+			for _, nw := range words {
+				matched = matched || w == nw
+			}
+		}
+		if !matched {
+			continue
+		}
+		s, ok := byCategory[exp.CategoryID]
+		if !ok {
+			s = &handler.CategorySuggestion{CategoryID: exp.CategoryID}
+			byCategory[exp.CategoryID] = s
+			result = append(result, s)
+		}
+		s.MatchCount++
+		if exp.ExpenseDate.After(s.LastUsed) {
+			s.LastUsed = exp.ExpenseDate
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].MatchCount > result[j].MatchCount })
+
+	suggestions := []handler.CategorySuggestion{}
+	for i := 0; i < len(result) && i < limit; i++ {
+		suggestions = append(suggestions, *result[i])
+	}
+	return suggestions, nil
+}
+
+func (m *mockExpenseDB) GetExpensesForExport(userID string, filter handler.ExpenseFilter, after *handler.ExpenseCursor, limit int) ([]handler.ExportExpense, error) {
+	m.exportCalls++
+	var matching []handler.MockExpense
+	for _, exp := range m.expenses {
+		if exp.UserID != userID {
+			continue
+		}
+		if filter.DateFrom != nil && exp.ExpenseDate.Before(*filter.DateFrom) {
+			continue
+		}
+		if filter.DateTo != nil && exp.ExpenseDate.After(*filter.DateTo) {
+			continue
+		}
+		if filter.CategoryID != "" && exp.CategoryID != filter.CategoryID {
+			continue
+		}
+		matching = append(matching, exp)
+	}
+
+	// Expenses are kept in insertion order, so the cursor is resolved by ID.
+	start := 0
+	if after != nil {
+		for i, exp := range matching {
+			if exp.ID == after.ID {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	result := []handler.ExportExpense{}
+	for _, exp := range matching[start:] {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, handler.ExportExpense{
+			ID:           exp.ID,
+			CategoryID:   exp.CategoryID,
+			CategoryName: "Food",
+			AmountCents:  exp.AmountCents,
+			Currency:     exp.Currency,
+			Note:         exp.Note,
+			ExpenseDate:  exp.ExpenseDate,
+			CreatedAt:    exp.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
 func expIDForIndex(i int) string {
 	return "exp-" + string(rune('0'+i))
 }
 
 func setupExpenseRouter(db handler.ExpenseDB) *gin.Engine {
+	return setupExpenseRouterWithActivity(db, newMockActivityDB())
+}
+
+func setupExpenseRouterWithActivity(db handler.ExpenseDB, activity handler.ActivityDB) *gin.Engine {
+	return setupExpenseRouterWithRules(db, newMockCategoryRuleDB(), activity)
+}
+
+func setupExpenseRouterWithRules(db handler.ExpenseDB, ruleDB handler.CategoryRuleDB, activity handler.ActivityDB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	h := handler.NewExpenseHandler(db)
+	h := handler.NewExpenseHandler(db, ruleDB, activity)
 
 	expenses := r.Group("/api/v1/expenses")
 	expenses.Use(func(c *gin.Context) {
@@ -126,6 +388,7 @@ func setupExpenseRouter(db handler.ExpenseDB) *gin.Engine {
 	{
 		expenses.POST("", h.Create)
 		expenses.GET("", h.List)
+		expenses.GET("/suggest-category", h.SuggestCategory)
 		expenses.PUT("/:id", h.Update)
 		expenses.DELETE("/:id", h.Delete)
 	}
@@ -189,6 +452,185 @@ func TestCreateExpense_MissingCategoryID(t *testing.T) {
 	}
 }
 
+func TestCreateExpense_IncomeCategory(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testIncomeCategoryID,
+		"amount_cents": 1500,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(db.expenses) != 0 {
+		t.Fatalf("expected no expense to be created, got %d", len(db.expenses))
+	}
+}
+
+func TestCreateExpense_ArchivedCategory(t *testing.T) {
+	db := newMockExpenseDB()
+	db.archivedCategories = map[string]bool{testTransportCategoryID: true}
+	r := setupExpenseRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testTransportCategoryID,
+		"amount_cents": 1500,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(db.expenses) != 0 {
+		t.Fatalf("expected no expense to be created, got %d", len(db.expenses))
+	}
+}
+
+func TestCreateExpense_CategorizedByRule(t *testing.T) {
+	db := newMockExpenseDB()
+	ruleDB := newMockCategoryRuleDB()
+	ruleDB.CreateCategoryRule(testUserID, handler.NewCategoryRule{CategoryID: testTransportCategoryID, Pattern: "taxi"})
+	r := setupExpenseRouterWithRules(db, ruleDB, newMockActivityDB())
+
+	body, _ := json.Marshal(map[string]any{
+		"amount_cents": 1500,
+		"note":         "Taxi to the airport",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["category_id"] != testTransportCategoryID || resp["rule_id"] != "rule-1" {
+		t.Fatalf("expected expense categorized by rule-1, got %v", resp)
+	}
+
+	body, _ = json.Marshal(map[string]any{
+		"amount_cents": 1500,
+		"note":         "Groceries",
+	})
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 when no rule matches, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreateExpense_Tags(t *testing.T) {
+	db := newMockExpenseDB()
+	ruleDB := newMockCategoryRuleDB()
+	ruleDB.CreateCategoryRule(testUserID, handler.NewCategoryRule{CategoryID: testTransportCategoryID, Pattern: "taxi", Tag: "travel"})
+	r := setupExpenseRouterWithRules(db, ruleDB, newMockActivityDB())
+
+	body, _ := json.Marshal(map[string]any{
+		"amount_cents": 1500,
+		"note":         "Taxi to the airport",
+		"tags":         []string{" Vacation-2026 ", "kids", "vacation-2026", ""},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Tags []string `json:"tags"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	want := []string{"kids", "travel", "vacation-2026"}
+	if strings.Join(resp.Tags, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected tags %v, got %v", want, resp.Tags)
+	}
+	if strings.Join(db.expenses[0].Tags, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected stored tags %v, got %v", want, db.expenses[0].Tags)
+	}
+}
+
+func TestCreateExpense_InvalidTags(t *testing.T) {
+	tooMany := make([]string, 21)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag-%d", i)
+	}
+	tests := []struct {
+		name string
+		tags []string
+	}{
+		{"too many", tooMany},
+		{"too long", []string{strings.Repeat("x", 51)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupExpenseRouter(newMockExpenseDB())
+			body, _ := json.Marshal(map[string]any{
+				"category_id":  testFoodCategoryID,
+				"amount_cents": 500,
+				"tags":         tt.tags,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSuggestCategory(t *testing.T) {
+	db := newMockExpenseDB()
+	date := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	db.CreateExpense(testUserID, testTransportCategoryID, 900, "", "", "Uber to office", date, nil)
+	db.CreateExpense(testUserID, testTransportCategoryID, 1100, "", "", "uber home", date.AddDate(0, 0, 3), nil)
+	db.CreateExpense(testUserID, testFoodCategoryID, 2500, "", "", "Dinner at office", date, nil)
+	r := setupExpenseRouter(db)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/suggest-category?note=uber+office", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Suggestions []map[string]any `json:"suggestions"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %s", w.Body.String())
+	}
+	first := resp.Suggestions[0]
+	if first["category_id"] != testTransportCategoryID || first["match_count"] != float64(2) || first["last_used"] != "2026-03-04" {
+		t.Fatalf("unexpected top suggestion: %v", first)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/expenses/suggest-category", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without note, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestCreateExpense_InvalidAmount_Zero(t *testing.T) {
 	db := newMockExpenseDB()
 	r := setupExpenseRouter(db)
@@ -290,6 +732,125 @@ func TestCreateExpense_EmptyNote(t *testing.T) {
 	}
 }
 
+func TestCreateExpense_Currency(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+		"amount_cents": 1200,
+		"currency":     "eur",
+		"expense_date": "2026-03-15",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["currency"] != "EUR" {
+		t.Fatalf("expected currency EUR, got %v", resp["currency"])
+	}
+	if resp["rate_date"] != "2026-03-15" {
+		t.Fatalf("expected rate_date 2026-03-15, got %v", resp["rate_date"])
+	}
+}
+
+func TestCreateExpense_DefaultCurrency(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+		"amount_cents": 1200,
+		"expense_date": "2026-03-15",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["currency"] != "UAH" {
+		t.Fatalf("expected base currency UAH, got %v", resp["currency"])
+	}
+}
+
+func TestCreateExpense_InvalidCurrency(t *testing.T) {
+	for _, currency := range []string{"EURO", "E1R", "GBP"} {
+		t.Run(currency, func(t *testing.T) {
+			db := newMockExpenseDB()
+			r := setupExpenseRouter(db)
+
+			body, _ := json.Marshal(map[string]any{
+				"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+				"amount_cents": 1200,
+				"currency":     currency,
+				"expense_date": "2026-03-15",
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateExpense_Visibility(t *testing.T) {
+	const privateCategoryID = "550e8400-e29b-41d4-a716-446655440004"
+
+	tests := []struct {
+		name       string
+		categoryID string
+		visibility string
+		want       int
+		wantVis    string
+	}{
+		{"defaults to family", "550e8400-e29b-41d4-a716-446655440001", "", http.StatusCreated, handler.VisibilityFamily},
+		{"category default", privateCategoryID, "", http.StatusCreated, handler.VisibilityPrivate},
+		{"explicit overrides category", privateCategoryID, "family", http.StatusCreated, handler.VisibilityFamily},
+		{"explicit private", "550e8400-e29b-41d4-a716-446655440001", "private", http.StatusCreated, handler.VisibilityPrivate},
+		{"unknown", "550e8400-e29b-41d4-a716-446655440001", "secret", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newMockExpenseDB()
+			db.privateCategories = map[string]bool{privateCategoryID: true}
+			r := setupExpenseRouter(db)
+
+			body, _ := json.Marshal(map[string]any{
+				"category_id":  tt.categoryID,
+				"amount_cents": 1200,
+				"visibility":   tt.visibility,
+				"expense_date": "2026-03-15",
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			var resp map[string]any
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if tt.wantVis != "" && resp["visibility"] != tt.wantVis {
+				t.Fatalf("expected visibility %s, got %v", tt.wantVis, resp["visibility"])
+			}
+		})
+	}
+}
+
 func TestCreateExpense_AmountCentsIsInteger(t *testing.T) {
 	db := newMockExpenseDB()
 	r := setupExpenseRouter(db)
@@ -495,6 +1056,58 @@ func TestUpdateExpense_MissingCategoryID(t *testing.T) {
 	}
 }
 
+func TestUpdateExpense_ArchivedCategory(t *testing.T) {
+	const foodCategoryID = "550e8400-e29b-41d4-a716-446655440001"
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+	id := createTestExpense(t, r)
+	db.archivedCategories = map[string]bool{foodCategoryID: true, testTransportCategoryID: true}
+
+	update := func(categoryID string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(map[string]any{
+			"category_id":  categoryID,
+			"amount_cents": 2500,
+			"expense_date": "2026-03-16",
+		})
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/expenses/"+id, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// An expense already in an archived category can still be edited.
+	if w := update(foodCategoryID); w.Code != http.StatusOK {
+		t.Fatalf("same category: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := update(testTransportCategoryID); w.Code != http.StatusBadRequest {
+		t.Fatalf("moved into archived category: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdateExpense_IncomeCategory(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+	id := createTestExpense(t, r)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testIncomeCategoryID,
+		"amount_cents": 2500,
+		"expense_date": "2026-03-16",
+	})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/expenses/"+id, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if db.expenses[0].CategoryID == testIncomeCategoryID {
+		t.Fatal("expense should keep its expense category")
+	}
+}
+
 func TestUpdateExpense_InvalidAmount_Zero(t *testing.T) {
 	db := newMockExpenseDB()
 	r := setupExpenseRouter(db)
@@ -668,6 +1281,241 @@ func TestListExpenses_NoFilters(t *testing.T) {
 	}
 }
 
+// --- Cursor Pagination Tests ---
+
+func createExpenseOn(t *testing.T, r *gin.Engine, date string, amountCents int) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+		"amount_cents": amountCents,
+		"expense_date": date,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListExpenses_Cursor(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+
+	for i, d := range []string{"2026-03-01", "2026-03-02", "2026-03-03", "2026-03-04", "2026-03-05"} {
+		createExpenseOn(t, r, d, 1000+i)
+	}
+
+	var dates []string
+	path := "/api/v1/expenses?limit=2"
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp []map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		for _, e := range resp {
+			dates = append(dates, e["expense_date"].(string))
+		}
+
+		if page == 0 {
+			// An expense added while scrolling must not shift later pages.
+			createExpenseOn(t, r, "2026-03-06", 5000)
+		}
+
+		next := w.Header().Get(handler.HeaderNextCursor)
+		if next == "" {
+			break
+		}
+		path = "/api/v1/expenses?limit=2&cursor=" + next
+	}
+
+	want := []string{"2026-03-05", "2026-03-04", "2026-03-03", "2026-03-02", "2026-03-01"}
+	if len(dates) != len(want) {
+		t.Fatalf("expected %v, got %v", want, dates)
+	}
+	for i := range want {
+		if dates[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, dates)
+		}
+	}
+	if db.lastPage.Offset != 0 || db.lastPage.Before == nil {
+		t.Fatalf("expected keyset page without offset, got %+v", db.lastPage)
+	}
+}
+
+func TestListExpenses_NoNextCursorOnLastPage(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+	createExpenseOn(t, r, "2026-03-01", 1000)
+	createExpenseOn(t, r, "2026-03-02", 1000)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses?limit=2", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if next := w.Header().Get(handler.HeaderNextCursor); next != "" {
+		t.Fatalf("expected no next cursor, got %q", next)
+	}
+}
+
+func TestListExpenses_InvalidCursor(t *testing.T) {
+	r := setupExpenseRouter(newMockExpenseDB())
+
+	for _, cursor := range []string{"not-base64!", "Zm9v"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses?cursor="+cursor, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("cursor %q: expected 400, got %d: %s", cursor, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestListExpenses_Totals(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+	createExpenseOn(t, r, "2026-03-01", 1000)
+	createExpenseOn(t, r, "2026-03-02", 2000)
+	createExpenseOn(t, r, "2026-04-01", 4000)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses?limit=1&date_to=2026-03-31&include_totals=true", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Header().Get(handler.HeaderTotalCount) != "2" || w.Header().Get(handler.HeaderTotalCents) != "3000" {
+		t.Fatalf("unexpected totals: count=%q cents=%q", w.Header().Get(handler.HeaderTotalCount), w.Header().Get(handler.HeaderTotalCents))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/expenses", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Header().Get(handler.HeaderTotalCount) != "" {
+		t.Fatal("totals should only be returned when requested")
+	}
+}
+
+// --- Search and Amount Filter Tests ---
+
+func TestListExpenses_Search(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+	db.expenses = []handler.MockExpense{
+		{ID: "exp-1", UserID: testUserID, CategoryID: "cat-1", AmountCents: 1500, Note: "Coffee with Anna", ExpenseDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "exp-2", UserID: testUserID, CategoryID: "cat-1", AmountCents: 90000, Note: "Groceries", ExpenseDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: "exp-3", UserID: testUserID, CategoryID: "cat-1", AmountCents: 2500, Note: "coffee beans", ExpenseDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses?q=%20coffee%20&limit=1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if db.lastFilter.Query != "coffee" {
+		t.Fatalf("expected trimmed query, got %q", db.lastFilter.Query)
+	}
+	var resp []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp) != 1 {
+		t.Fatalf("expected 1 expense, got %d", len(resp))
+	}
+	// Ranked results are paged by offset only.
+	if next := w.Header().Get(handler.HeaderNextCursor); next != "" {
+		t.Fatalf("expected no cursor for search results, got %q", next)
+	}
+}
+
+func TestListExpenses_SearchWithCursor(t *testing.T) {
+	r := setupExpenseRouter(newMockExpenseDB())
+	cursor := handler.ExpenseCursor{ExpenseDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), ID: "exp-1"}.Encode()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses?q=coffee&cursor="+cursor, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListExpenses_AmountRange(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+	db.expenses = []handler.MockExpense{
+		{ID: "exp-1", UserID: testUserID, CategoryID: "cat-1", AmountCents: 500, ExpenseDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "exp-2", UserID: testUserID, CategoryID: "cat-1", AmountCents: 1500, ExpenseDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: "exp-3", UserID: testUserID, CategoryID: "cat-1", AmountCents: 2500, ExpenseDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses?min_amount=1000&max_amount=2000", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp) != 1 || resp[0]["id"] != "exp-2" {
+		t.Fatalf("expected only exp-2, got %v", resp)
+	}
+	if *db.lastFilter.MinAmountCents != 1000 || *db.lastFilter.MaxAmountCents != 2000 {
+		t.Fatalf("unexpected amount filter: %+v", db.lastFilter)
+	}
+}
+
+func TestListExpenses_InvalidAmountRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"non-numeric min", "min_amount=abc"},
+		{"negative max", "max_amount=-1"},
+		{"max below min", "min_amount=2000&max_amount=1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupExpenseRouter(newMockExpenseDB())
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestListExpenses_TagFilter(t *testing.T) {
+	db := newMockExpenseDB()
+	r := setupExpenseRouter(db)
+	db.expenses = []handler.MockExpense{
+		{ID: "exp-1", UserID: testUserID, CategoryID: "cat-1", AmountCents: 500, Tags: []string{"kids"}, ExpenseDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "exp-2", UserID: testUserID, CategoryID: "cat-1", AmountCents: 1500, Tags: []string{"kids", "vacation-2026"}, ExpenseDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: "exp-3", UserID: testUserID, CategoryID: "cat-1", AmountCents: 2500, ExpenseDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses?tag=Kids&tag=vacation-2026", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp) != 1 || resp[0]["id"] != "exp-2" {
+		t.Fatalf("expected only exp-2, got %v", resp)
+	}
+	if strings.Join(db.lastFilter.Tags, ",") != "kids,vacation-2026" {
+		t.Fatalf("unexpected tag filter: %v", db.lastFilter.Tags)
+	}
+}
+
 func TestDeleteExpense_NotFound(t *testing.T) {
 	db := newMockExpenseDB()
 	r := setupExpenseRouter(db)
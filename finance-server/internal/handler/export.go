@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/export"
+)
+
+// exportBatchSize is the number of rows fetched per query while streaming.
+const exportBatchSize = 500
+
+// ExportHandler handles expense export HTTP requests.
+// Rows are read in keyset-paginated batches and written to the response as
+// they arrive, so large exports never sit in memory as a whole.
+type ExportHandler struct {
+	expenseDB ExpenseDB
+	familyDB  FamilyDB
+	viewDB    FamilyViewDB
+}
+
+// NewExportHandler creates an ExportHandler with the given databases.
+func NewExportHandler(expenseDB ExpenseDB, familyDB FamilyDB, viewDB FamilyViewDB) *ExportHandler {
+	return &ExportHandler{expenseDB: expenseDB, familyDB: familyDB, viewDB: viewDB}
+}
+
+// exportFetcher returns the next batch of records after the cursor.
+type exportFetcher func(after *ExpenseCursor) ([]export.Record, error)
+
+// Export handles GET /api/v1/expenses/export.
+// Query parameters: format (csv, json, xlsx; default csv), date_from,
+// date_to and category_id.
+func (h *ExportHandler) Export(c *gin.Context) {
+	format, filter, ok := parseExportQuery(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("user_id")
+	fetch := func(after *ExpenseCursor) ([]export.Record, error) {
+		rows, err := h.expenseDB.GetExpensesForExport(userID, filter, after, exportBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		records := make([]export.Record, len(rows))
+		for i, e := range rows {
+			records[i] = export.Record{
+				ID:           e.ID,
+				ExpenseDate:  e.ExpenseDate,
+				AmountCents:  e.AmountCents,
+				Currency:     e.Currency,
+				Note:         e.Note,
+				CategoryID:   e.CategoryID,
+				CategoryName: e.CategoryName,
+				CategoryIcon: e.CategoryIcon,
+				CreatedAt:    e.CreatedAt,
+			}
+		}
+		return records, nil
+	}
+
+	streamExport(c, format, "expenses", false, fetch)
+}
+
+// FamilyExport handles GET /api/v1/families/:familyId/expenses/export.
+// It accepts the same query parameters as Export and adds the member column.
+func (h *ExportHandler) FamilyExport(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
+		return
+	}
+
+	format, filter, ok := parseExportQuery(c)
+	if !ok {
+		return
+	}
+
+	fetch := func(after *ExpenseCursor) ([]export.Record, error) {
+		rows, err := h.viewDB.GetFamilyExpensesForExport(family.ID, filter, after, exportBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		records := make([]export.Record, len(rows))
+		for i, e := range rows {
+			records[i] = export.Record{
+				ID:           e.ID,
+				ExpenseDate:  e.ExpenseDate,
+				AmountCents:  e.AmountCents,
+				Currency:     e.Currency,
+				Note:         e.Note,
+				CategoryID:   e.CategoryID,
+				CategoryName: e.CategoryName,
+				CategoryIcon: e.CategoryIcon,
+				MemberEmail:  e.UserEmail,
+				CreatedAt:    e.CreatedAt,
+			}
+		}
+		return records, nil
+	}
+
+	streamExport(c, format, "family-expenses", true, fetch)
+}
+
+// parseExportQuery validates the export query parameters, writing a 400
+// response and returning ok=false when they are invalid.
+func parseExportQuery(c *gin.Context) (format string, filter ExpenseFilter, ok bool) {
+	format = strings.ToLower(c.DefaultQuery("format", export.FormatCSV))
+	if !export.ValidFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of: csv, json, xlsx"})
+		return "", filter, false
+	}
+
+	if df := c.Query("date_from"); df != "" {
+		t, err := time.Parse("2006-01-02", df)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date_from must be in YYYY-MM-DD format"})
+			return "", filter, false
+		}
+		filter.DateFrom = &t
+	}
+	if dt := c.Query("date_to"); dt != "" {
+		t, err := time.Parse("2006-01-02", dt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "date_to must be in YYYY-MM-DD format"})
+			return "", filter, false
+		}
+		filter.DateTo = &t
+	}
+	if filter.DateFrom != nil && filter.DateTo != nil && filter.DateTo.Before(*filter.DateFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_to must not be before date_from"})
+		return "", filter, false
+	}
+	filter.CategoryID = c.Query("category_id")
+
+	return format, filter, true
+}
+
+// streamExport writes all batches returned by fetch to the response.
+// The first batch is fetched before any header is written so a database
+// error can still be reported as a 500; later failures can only abort the
+// stream, leaving a truncated download.
+func streamExport(c *gin.Context, format, name string, includeMember bool, fetch exportFetcher) {
+	batch, err := fetch(nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	filename := name + "-" + time.Now().UTC().Format("2006-01-02") + "." + format
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w, err := export.NewWriter(format, c.Writer, includeMember)
+	if err != nil {
+		log.Printf("Export: %v", err)
+		return
+	}
+
+	for len(batch) > 0 {
+		for _, record := range batch {
+			if err := w.Write(record); err != nil {
+				log.Printf("Export: %v", err)
+				return
+			}
+		}
+		c.Writer.Flush()
+
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		batch, err = fetch(&ExpenseCursor{ExpenseDate: last.ExpenseDate, CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			log.Printf("Export: %v", err)
+			return
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		log.Printf("Export: %v", err)
+	}
+}
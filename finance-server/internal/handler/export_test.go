@@ -0,0 +1,163 @@
+package handler_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+func setupExportRouter(expenseDB handler.ExpenseDB, familyDB handler.FamilyDB, viewDB handler.FamilyViewDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewExportHandler(expenseDB, familyDB, viewDB)
+
+	api := r.Group("/api/v1")
+	api.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User-ID"))
+		c.Next()
+	})
+	{
+		api.GET("/expenses/export", h.Export)
+		api.GET("/families/me/expenses/export", h.FamilyExport)
+	}
+	return r
+}
+
+func TestExport_CSVStreamsAllBatches(t *testing.T) {
+	expenseDB := newMockExpenseDB()
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 1200; i++ {
+		expenseDB.CreateExpense(testUserID, testFoodCategoryID, int64(100+i), "", "", "item", day, nil)
+	}
+	r := setupExportRouter(expenseDB, newMockFamilyDB(), &mockFamilyViewDB{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/export", nil)
+	req.Header.Set("X-User-ID", testUserID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("expected csv content type, got %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "attachment") || !strings.Contains(cd, ".csv") {
+		t.Fatalf("unexpected Content-Disposition: %q", cd)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	if len(rows) != 1201 {
+		t.Fatalf("expected header + 1200 rows, got %d", len(rows))
+	}
+	if rows[1][1] != "1.00" || rows[1][2] != "UAH" || rows[1][3] != "Food" {
+		t.Fatalf("unexpected first row: %v", rows[1])
+	}
+	if expenseDB.exportCalls != 3 {
+		t.Fatalf("expected 3 batched queries, got %d", expenseDB.exportCalls)
+	}
+}
+
+func TestExport_JSONFiltered(t *testing.T) {
+	expenseDB := newMockExpenseDB()
+	expenseDB.CreateExpense(testUserID, testFoodCategoryID, 500, "", "", "lunch", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), nil)
+	expenseDB.CreateExpense(testUserID, testTransportCategoryID, 700, "", "", "bus", time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), nil)
+	expenseDB.CreateExpense(testUserID, testFoodCategoryID, 900, "", "", "dinner", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), nil)
+	r := setupExportRouter(expenseDB, newMockFamilyDB(), &mockFamilyViewDB{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/export?format=json&date_to=2026-03-31&category_id="+testFoodCategoryID, nil)
+	req.Header.Set("X-User-ID", testUserID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(got) != 1 || got[0]["note"] != "lunch" || got[0]["amount_cents"] != float64(500) {
+		t.Fatalf("unexpected export: %s", w.Body.String())
+	}
+}
+
+func TestExport_Validation(t *testing.T) {
+	r := setupExportRouter(newMockExpenseDB(), newMockFamilyDB(), &mockFamilyViewDB{})
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown format", "?format=pdf"},
+		{"bad date_from", "?date_from=03/01/2026"},
+		{"bad date_to", "?date_to=2026-13-01"},
+		{"inverted range", "?date_from=2026-03-02&date_to=2026-03-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/export"+tt.query, nil)
+			req.Header.Set("X-User-ID", testUserID)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFamilyExport(t *testing.T) {
+	fdb := newMockFamilyDB()
+	fdb.families["family-1"] = &handler.MockFamily{ID: "family-1", Name: "Smith Family", AdminUserID: "user-1"}
+	fdb.userFamily["user-1"] = "family-1"
+	viewDB := &mockFamilyViewDB{
+		expenses: []handler.FamilyExpense{
+			{
+				ID:           "exp-1",
+				UserID:       "user-1",
+				UserEmail:    "user1@test.com",
+				CategoryID:   "cat-1",
+				CategoryName: "Food",
+				AmountCents:  2500,
+				Note:         "Lunch",
+				ExpenseDate:  time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	r := setupExportRouter(newMockExpenseDB(), fdb, viewDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses/export?format=xlsx", nil)
+	req.Header.Set("X-User-ID", "user-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "spreadsheetml") {
+		t.Fatalf("expected xlsx content type, got %q", ct)
+	}
+	if !strings.HasPrefix(w.Body.String(), "PK") {
+		t.Fatal("expected a zip archive")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses/export", nil)
+	req.Header.Set("X-User-ID", "user-9")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without family, got %d", w.Code)
+	}
+}
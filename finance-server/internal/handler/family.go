@@ -5,27 +5,50 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/mailer"
 )
 
 // Sentinel errors for family operations.
 var (
-	ErrFamilyNotFound     = errors.New("family not found")
-	ErrInvitationNotFound = errors.New("invitation not found")
-	ErrAlreadyInFamily    = errors.New("user already in a family")
-	ErrFamilyFull         = errors.New("family is full")
+	ErrFamilyNotFound       = errors.New("family not found")
+	ErrFamilyMemberNotFound = errors.New("family member not found")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrAlreadyInFamily      = errors.New("user already in this family")
+	ErrFamilyFull           = errors.New("family is full")
+)
+
+// maxFamilyMembers bounds the number of members in a family.
+const maxFamilyMembers = 10
+
+// Family settings for members' private expenses. Excluded private expenses
+// are left out of every family view; aggregated ones are counted in the
+// family's totals without their category or note.
+const (
+	PrivateExpensesExclude   = "exclude"
+	PrivateExpensesAggregate = "aggregate"
 )
 
 // MockFamily is the family representation used by the FamilyDB interface.
 type MockFamily struct {
-	ID          string
-	Name        string
-	AdminUserID string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID           string
+	Name         string
+	AdminUserID  string
+	BaseCurrency string
+	// PrivateExpenses is PrivateExpensesExclude or PrivateExpensesAggregate.
+	PrivateExpenses string
+	// Role is the requesting user's role, set by the membership lookups.
+	Role      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 // MockFamilyMember is the member representation used by the FamilyDB interface.
@@ -39,10 +62,13 @@ type MockFamilyMember struct {
 }
 
 // MockInvitation is the invitation representation used by the FamilyDB interface.
+// Email is empty for invitations created before they were addressed to an
+// email; anyone holding the token can accept those.
 type MockInvitation struct {
 	ID            string
 	FamilyID      string
 	InviterUserID string
+	Email         string
 	TokenHash     string
 	Status        string
 	FamilyName    string
@@ -51,10 +77,14 @@ type MockInvitation struct {
 }
 
 // MockPendingInvitation is the pending invitation representation.
+// FamilyName and InviterEmail are only set by GetInvitationsForEmail.
 type MockPendingInvitation struct {
 	ID            string
 	FamilyID      string
+	FamilyName    string
 	InviterUserID string
+	InviterEmail  string
+	Email         string
 	Status        string
 	ExpiresAt     time.Time
 	CreatedAt     time.Time
@@ -62,29 +92,92 @@ type MockPendingInvitation struct {
 
 // FamilyDB abstracts database operations for families.
 // This allows testing with mock implementations.
+// A user can belong to several families; lookups for a user return
+// ErrFamilyNotFound when they are not a member.
 type FamilyDB interface {
+	// CreateFamily creates the family with userID as its owner in one
+	// transaction.
 	CreateFamily(userID, name string) (MockFamily, error)
-	GetFamilyByUserID(userID string) (MockFamily, error)
+	// GetActiveFamily returns the family the user last selected, falling back
+	// to the earliest one they joined.
+	GetActiveFamily(userID string) (MockFamily, error)
+	GetFamilyForMember(familyID, userID string) (MockFamily, error)
+	GetUserFamilies(userID string) ([]MockFamily, error)
+	SetActiveFamily(userID, familyID string) error
 	GetFamilyMembers(familyID string) ([]MockFamilyMember, error)
-	AddFamilyMember(familyID, userID, role string) error
 	RemoveFamilyMember(familyID, userID string) (int64, error)
+	// SetMemberRole returns ErrFamilyMemberNotFound when userID is not a member.
+	SetMemberRole(familyID, userID, role string) error
+	// TransferOwnership makes toUserID the owner and fromUserID, the current
+	// owner, an admin in one transaction. It returns ErrFamilyMemberNotFound
+	// when toUserID is not a member.
+	TransferOwnership(familyID, fromUserID, toUserID string) error
+	// TransferOwnershipAndLeave is TransferOwnership followed by removing
+	// fromUserID from the family, in the same transaction.
+	TransferOwnershipAndLeave(familyID, fromUserID, toUserID string) error
 	DeleteFamily(familyID, adminUserID string) (int64, error)
 	GetFamilyMemberCount(familyID string) (int64, error)
-	CreateInvitation(familyID, inviterUserID, tokenHash string, expiresAt time.Time) (MockInvitation, error)
+	CreateInvitation(familyID, inviterUserID, email, tokenHash string, expiresAt time.Time) (MockInvitation, error)
+	// GetInvitationByTokenHash and GetInvitationByID return
+	// ErrInvitationNotFound unless the invitation is pending and unexpired.
 	GetInvitationByTokenHash(tokenHash string) (MockInvitation, error)
-	AcceptInvitation(invitationID string) (int64, error)
+	GetInvitationByID(invitationID string) (MockInvitation, error)
+	// AcceptInvitation marks the invitation accepted and adds userID to the
+	// family in one transaction. It returns ErrInvitationNotFound unless the
+	// invitation is still pending and unexpired, and ErrFamilyFull when the
+	// family already has maxFamilyMembers members.
+	AcceptInvitation(invitationID, familyID, userID, role string) error
 	RevokeInvitation(invitationID, familyID string) (int64, error)
 	GetPendingInvitations(familyID string) ([]MockPendingInvitation, error)
+	// GetInvitationsForEmail returns pending invitations addressed to email,
+	// matched case-insensitively.
+	GetInvitationsForEmail(email string) ([]MockPendingInvitation, error)
+	GetUserEmail(userID string) (string, error)
+}
+
+// activeFamilyAlias is accepted in place of a family ID to address the
+// user's active family, which keeps /families/me routes working.
+const activeFamilyAlias = "me"
+
+// familyFromPath resolves the :familyId route parameter to a family the
+// current user belongs to, writing a 404 response and returning ok=false
+// when there is none. "me", or a route without the parameter, selects the
+// active family.
+func familyFromPath(c *gin.Context, db FamilyDB) (MockFamily, bool) {
+	userID := c.GetString("user_id")
+	familyID := c.Param("familyId")
+
+	var family MockFamily
+	var err error
+	if familyID == "" || familyID == activeFamilyAlias {
+		family, err = db.GetActiveFamily(userID)
+	} else {
+		family, err = db.GetFamilyForMember(familyID, userID)
+	}
+	if err != nil {
+		if errors.Is(err, ErrFamilyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
+			return MockFamily{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return MockFamily{}, false
+	}
+	return family, true
 }
 
 // FamilyHandler handles family HTTP requests.
+// Membership and invitation changes are recorded in the family's activity log.
 type FamilyHandler struct {
-	db FamilyDB
+	db       FamilyDB
+	activity ActivityDB
+	mailer   mailer.Mailer
+	appURL   string
 }
 
-// NewFamilyHandler creates a FamilyHandler with the given database.
-func NewFamilyHandler(db FamilyDB) *FamilyHandler {
-	return &FamilyHandler{db: db}
+// NewFamilyHandler creates a FamilyHandler with the given databases.
+// Invitations are delivered through m, with links pointing at appURL.
+func NewFamilyHandler(db FamilyDB, activity ActivityDB, m mailer.Mailer, appURL string) *FamilyHandler {
+	return &FamilyHandler{db: db, activity: activity, mailer: m, appURL: strings.TrimRight(appURL, "/")}
 }
 
 type createFamilyRequest struct {
@@ -92,6 +185,7 @@ type createFamilyRequest struct {
 }
 
 // CreateFamily handles POST /api/v1/families.
+// The new family becomes the creator's active family.
 func (h *FamilyHandler) CreateFamily(c *gin.Context) {
 	var req createFamilyRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -106,30 +200,21 @@ func (h *FamilyHandler) CreateFamily(c *gin.Context) {
 
 	userID := c.GetString("user_id")
 
-	// Check user not already in a family
-	_, err := h.db.GetFamilyByUserID(userID)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "You are already in a family"})
-		return
-	}
-	if !errors.Is(err, ErrFamilyNotFound) {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		return
-	}
-
-	// Create family
+	// Create family with the creator as owner
 	family, err := h.db.CreateFamily(userID, req.Name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	// Add creator as admin member
-	if err := h.db.AddFamilyMember(family.ID, userID, "admin"); err != nil {
+	// Switch to the new family; the creator is about to set it up.
+	if err := h.db.SetActiveFamily(userID, family.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	recordFamilyEvent(c, h.activity, family.ID, ActionFamilyCreated, family.ID, nil, gin.H{"name": family.Name})
+
 	c.JSON(http.StatusCreated, gin.H{
 		"id":            family.ID,
 		"name":          family.Name,
@@ -137,20 +222,80 @@ func (h *FamilyHandler) CreateFamily(c *gin.Context) {
 	})
 }
 
-// GetMyFamily handles GET /api/v1/families/me.
-func (h *FamilyHandler) GetMyFamily(c *gin.Context) {
+// ListFamilies handles GET /api/v1/families.
+// It returns every family the user belongs to, flagging the active one.
+func (h *FamilyHandler) ListFamilies(c *gin.Context) {
 	userID := c.GetString("user_id")
 
-	family, err := h.db.GetFamilyByUserID(userID)
+	families, err := h.db.GetUserFamilies(userID)
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	activeID := ""
+	if len(families) > 0 {
+		active, err := h.db.GetActiveFamily(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		activeID = active.ID
+	}
+
+	result := make([]gin.H, len(families))
+	for i, f := range families {
+		result[i] = gin.H{
+			"id":               f.ID,
+			"name":             f.Name,
+			"admin_user_id":    f.AdminUserID,
+			"base_currency":    f.BaseCurrency,
+			"private_expenses": f.PrivateExpenses,
+			"role":             f.Role,
+			"is_active":        f.ID == activeID,
+			"created_at":       f.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+type setActiveFamilyRequest struct {
+	FamilyID string `json:"family_id"`
+}
+
+// SetActiveFamily handles PUT /api/v1/families/active.
+// The active family is the one /families/me routes resolve to.
+func (h *FamilyHandler) SetActiveFamily(c *gin.Context) {
+	var req setActiveFamilyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.FamilyID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "family_id is required"})
+		return
+	}
+
+	if err := h.db.SetActiveFamily(c.GetString("user_id"), req.FamilyID); err != nil {
 		if errors.Is(err, ErrFamilyNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
+			c.JSON(http.StatusNotFound, gin.H{"error": "Family not found"})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"family_id": req.FamilyID})
+}
+
+// GetMyFamily handles GET /api/v1/families/:familyId.
+func (h *FamilyHandler) GetMyFamily(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.db, PermViewFamily)
+	if !ok {
+		return
+	}
+
 	members, err := h.db.GetFamilyMembers(family.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
@@ -170,16 +315,18 @@ func (h *FamilyHandler) GetMyFamily(c *gin.Context) {
 
 	result := gin.H{
 		"family": gin.H{
-			"id":            family.ID,
-			"name":          family.Name,
-			"admin_user_id": family.AdminUserID,
-			"created_at":    family.CreatedAt,
+			"id":               family.ID,
+			"name":             family.Name,
+			"admin_user_id":    family.AdminUserID,
+			"base_currency":    family.BaseCurrency,
+			"private_expenses": family.PrivateExpenses,
+			"created_at":       family.CreatedAt,
 		},
 		"members": memberList,
 	}
 
-	// Include invitations for admin only
-	if family.AdminUserID == userID {
+	// Include invitations for those who can manage them
+	if HasPermission(family.Role, PermManageFamily) {
 		invitations, err := h.db.GetPendingInvitations(family.ID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
@@ -189,6 +336,7 @@ func (h *FamilyHandler) GetMyFamily(c *gin.Context) {
 		for i, inv := range invitations {
 			invList[i] = gin.H{
 				"id":         inv.ID,
+				"email":      inv.Email,
 				"status":     inv.Status,
 				"expires_at": inv.ExpiresAt,
 				"created_at": inv.CreatedAt,
@@ -200,22 +348,12 @@ func (h *FamilyHandler) GetMyFamily(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// DeleteMyFamily handles DELETE /api/v1/families/me.
+// DeleteMyFamily handles DELETE /api/v1/families/:familyId.
 func (h *FamilyHandler) DeleteMyFamily(c *gin.Context) {
 	userID := c.GetString("user_id")
 
-	family, err := h.db.GetFamilyByUserID(userID)
-	if err != nil {
-		if errors.Is(err, ErrFamilyNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		return
-	}
-
-	if family.AdminUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only admin can delete the family"})
+	family, ok := authorizeFamily(c, h.db, PermOwnFamily)
+	if !ok {
 		return
 	}
 
@@ -232,23 +370,23 @@ func (h *FamilyHandler) DeleteMyFamily(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Family deleted"})
 }
 
-// RemoveMember handles DELETE /api/v1/families/me/members/:userId.
+// RemoveMember handles DELETE /api/v1/families/:familyId/members/:userId.
 func (h *FamilyHandler) RemoveMember(c *gin.Context) {
-	userID := c.GetString("user_id")
 	targetUserID := c.Param("userId")
 
-	family, err := h.db.GetFamilyByUserID(userID)
-	if err != nil {
-		if errors.Is(err, ErrFamilyNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	family, ok := authorizeFamily(c, h.db, PermManageFamily)
+	if !ok {
+		return
+	}
+
+	target, ok := h.member(c, family.ID, targetUserID)
+	if !ok {
 		return
 	}
 
-	if family.AdminUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only admin can remove members"})
+	// Admins manage members and viewers; only the owner can remove an admin.
+	if target.Role == RoleOwner || (target.Role == RoleAdmin && family.Role != RoleOwner) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role in this family does not allow this"})
 		return
 	}
 
@@ -258,57 +396,216 @@ func (h *FamilyHandler) RemoveMember(c *gin.Context) {
 		return
 	}
 	if rows == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found or is admin"})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
 		return
 	}
 
+	recordFamilyEvent(c, h.activity, family.ID, ActionMemberRemoved, targetUserID, memberSnapshot(target), nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
 }
 
-// LeaveFamily handles POST /api/v1/families/me/leave.
+type leaveFamilyRequest struct {
+	SuccessorUserID string `json:"successor_user_id"`
+}
+
+// LeaveFamily handles POST /api/v1/families/:familyId/leave.
+// The owner must name a successor_user_id, who becomes the new owner before
+// the owner leaves.
 func (h *FamilyHandler) LeaveFamily(c *gin.Context) {
 	userID := c.GetString("user_id")
 
-	family, err := h.db.GetFamilyByUserID(userID)
-	if err != nil {
-		if errors.Is(err, ErrFamilyNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
+	var req leaveFamilyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	family, ok := authorizeFamily(c, h.db, PermViewFamily)
+	if !ok {
+		return
+	}
+
+	if family.Role == RoleOwner {
+		if req.SuccessorUserID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Owner must name a successor_user_id to leave, or delete the family instead"})
+			return
+		}
+		if !h.transferOwnership(c, family, req.SuccessorUserID, true) {
 			return
 		}
+	} else if _, err := h.db.RemoveFamilyMember(family.ID, userID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	if family.AdminUserID == userID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Admin cannot leave. Delete the family instead."})
+	recordFamilyEvent(c, h.activity, family.ID, ActionMemberLeft, userID, nil, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left family"})
+}
+
+// PromoteMember handles POST /api/v1/families/:familyId/members/:userId/promote.
+// It makes a member or viewer an admin.
+func (h *FamilyHandler) PromoteMember(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.db, PermManageFamily)
+	if !ok {
+		return
+	}
+
+	target, ok := h.member(c, family.ID, c.Param("userId"))
+	if !ok {
+		return
+	}
+	if target.Role == RoleAdmin || target.Role == RoleOwner {
+		c.JSON(http.StatusConflict, gin.H{"error": "Member is already an admin"})
+		return
+	}
+
+	h.setMemberRole(c, family.ID, target, RoleAdmin)
+}
+
+// DemoteMember handles POST /api/v1/families/:familyId/members/:userId/demote.
+// It makes an admin a regular member; only the owner can demote admins.
+func (h *FamilyHandler) DemoteMember(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.db, PermOwnFamily)
+	if !ok {
+		return
+	}
+
+	target, ok := h.member(c, family.ID, c.Param("userId"))
+	if !ok {
+		return
+	}
+	if target.Role != RoleAdmin {
+		c.JSON(http.StatusConflict, gin.H{"error": "Member is not an admin"})
 		return
 	}
 
-	_, err = h.db.RemoveFamilyMember(family.ID, userID)
+	h.setMemberRole(c, family.ID, target, RoleMember)
+}
+
+type transferOwnershipRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// TransferOwnership handles POST /api/v1/families/:familyId/transfer-ownership.
+// The new owner must already be a member; the previous owner stays on as an
+// admin.
+func (h *FamilyHandler) TransferOwnership(c *gin.Context) {
+	var req transferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.UserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		return
+	}
+
+	family, ok := authorizeFamily(c, h.db, PermOwnFamily)
+	if !ok {
+		return
+	}
+
+	if !h.transferOwnership(c, family, req.UserID, false) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"admin_user_id": req.UserID})
+}
+
+// member looks up userID among the family's members, writing an error
+// response and returning ok=false when they are not one.
+func (h *FamilyHandler) member(c *gin.Context, familyID, userID string) (MockFamilyMember, bool) {
+	members, err := h.db.GetFamilyMembers(familyID)
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return MockFamilyMember{}, false
+	}
+	for _, m := range members {
+		if m.UserID == userID {
+			return m, true
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+	return MockFamilyMember{}, false
+}
+
+func (h *FamilyHandler) setMemberRole(c *gin.Context, familyID string, target MockFamilyMember, role string) {
+	if err := h.db.SetMemberRole(familyID, target.UserID, role); err != nil {
+		if errors.Is(err, ErrFamilyMemberNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Left family"})
+	recordFamilyEvent(c, h.activity, familyID, ActionMemberRoleChanged, target.UserID,
+		gin.H{"role": target.Role}, gin.H{"role": role})
+
+	c.JSON(http.StatusOK, gin.H{"user_id": target.UserID, "role": role})
 }
 
-// CreateInvitation handles POST /api/v1/families/me/invitations.
-func (h *FamilyHandler) CreateInvitation(c *gin.Context) {
+// transferOwnership hands the family from the current user to toUserID,
+// writing an error response and returning false when it cannot. With leave
+// set the current user is also removed from the family.
+func (h *FamilyHandler) transferOwnership(c *gin.Context, family MockFamily, toUserID string, leave bool) bool {
 	userID := c.GetString("user_id")
+	if toUserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You already own this family"})
+		return false
+	}
 
-	family, err := h.db.GetFamilyByUserID(userID)
-	if err != nil {
+	transfer := h.db.TransferOwnership
+	if leave {
+		transfer = h.db.TransferOwnershipAndLeave
+	}
+	if err := transfer(family.ID, userID, toUserID); err != nil {
+		if errors.Is(err, ErrFamilyMemberNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+			return false
+		}
 		if errors.Is(err, ErrFamilyNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
-			return
+			// Ownership changed hands since the family was loaded.
+			c.JSON(http.StatusConflict, gin.H{"error": "Family ownership has changed"})
+			return false
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return false
+	}
+
+	recordFamilyEvent(c, h.activity, family.ID, ActionOwnershipTransferred, family.ID,
+		gin.H{"admin_user_id": userID}, gin.H{"admin_user_id": toUserID})
+	return true
+}
+
+type createInvitationRequest struct {
+	Email string `json:"email"`
+}
+
+// CreateInvitation handles POST /api/v1/families/:familyId/invitations.
+// The invitation is addressed to an email and mailed to it; only the account
+// registered with that email can accept it.
+func (h *FamilyHandler) CreateInvitation(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req createInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil || addr.Address != strings.TrimSpace(req.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a valid email is required"})
 		return
 	}
+	email := strings.ToLower(addr.Address)
 
-	if family.AdminUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only admin can create invitations"})
+	family, ok := authorizeFamily(c, h.db, PermManageFamily)
+	if !ok {
 		return
 	}
 
@@ -318,11 +615,41 @@ func (h *FamilyHandler) CreateInvitation(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
-	if count >= 10 {
+	if count >= maxFamilyMembers {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Family is full (max 10 members)"})
 		return
 	}
 
+	// Reject emails that already belong to a member or have an invitation
+	members, err := h.db.GetFamilyMembers(family.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	for _, m := range members {
+		if strings.EqualFold(m.Email, email) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This email already belongs to a family member"})
+			return
+		}
+	}
+	pending, err := h.db.GetPendingInvitations(family.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	for _, inv := range pending {
+		if strings.EqualFold(inv.Email, email) {
+			c.JSON(http.StatusConflict, gin.H{"error": "This email already has a pending invitation"})
+			return
+		}
+	}
+
+	inviterEmail, err := h.db.GetUserEmail(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
 	// Generate token: 32 random bytes -> hex
 	tokenBytes := make([]byte, 32)
 	if _, err := rand.Read(tokenBytes); err != nil {
@@ -337,35 +664,51 @@ func (h *FamilyHandler) CreateInvitation(c *gin.Context) {
 
 	expiresAt := time.Now().Add(7 * 24 * time.Hour)
 
-	inv, err := h.db.CreateInvitation(family.ID, userID, tokenHash, expiresAt)
+	inv, err := h.db.CreateInvitation(family.ID, userID, email, tokenHash, expiresAt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	if err := h.mailer.Send(c.Request.Context(), h.invitationMessage(inv, family.Name, inviterEmail, rawToken)); err != nil {
+		// An invitation nobody was told about would only block a resend.
+		log.Printf("Invitation %s: send mail: %v", inv.ID, err)
+		if _, err := h.db.RevokeInvitation(inv.ID, family.ID); err != nil {
+			log.Printf("Invitation %s: revoke after failed send: %v", inv.ID, err)
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Could not send invitation email"})
+		return
+	}
+
+	recordFamilyEvent(c, h.activity, family.ID, ActionInvitationCreated, inv.ID, nil,
+		gin.H{"email": inv.Email, "expires_at": inv.ExpiresAt})
+
 	c.JSON(http.StatusCreated, gin.H{
-		"token":      rawToken,
+		"id":         inv.ID,
+		"email":      inv.Email,
 		"expires_at": inv.ExpiresAt,
 	})
 }
 
-// RevokeInvitation handles DELETE /api/v1/families/me/invitations/:id.
+// invitationMessage builds the email telling the invitee how to join.
+func (h *FamilyHandler) invitationMessage(inv MockInvitation, familyName, inviterEmail, rawToken string) mailer.Message {
+	link := h.appURL + "/invitations/" + rawToken
+	return mailer.Message{
+		To:      inv.Email,
+		Subject: fmt.Sprintf("You're invited to join %s", familyName),
+		Body: fmt.Sprintf("%s invited you to join the family %q on Finance Tracker.\n\n"+
+			"Sign in with this email address and open the link below to accept:\n%s\n\n"+
+			"The invitation expires on %s.\n",
+			inviterEmail, familyName, link, inv.ExpiresAt.UTC().Format("2 January 2006")),
+	}
+}
+
+// RevokeInvitation handles DELETE /api/v1/families/:familyId/invitations/:id.
 func (h *FamilyHandler) RevokeInvitation(c *gin.Context) {
-	userID := c.GetString("user_id")
 	invitationID := c.Param("id")
 
-	family, err := h.db.GetFamilyByUserID(userID)
-	if err != nil {
-		if errors.Is(err, ErrFamilyNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		return
-	}
-
-	if family.AdminUserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only admin can revoke invitations"})
+	family, ok := authorizeFamily(c, h.db, PermManageFamily)
+	if !ok {
 		return
 	}
 
@@ -379,6 +722,8 @@ func (h *FamilyHandler) RevokeInvitation(c *gin.Context) {
 		return
 	}
 
+	recordFamilyEvent(c, h.activity, family.ID, ActionInvitationRevoked, invitationID, nil, nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
 }
 
@@ -405,11 +750,44 @@ func (h *FamilyHandler) GetInvitationInfo(c *gin.Context) {
 	})
 }
 
+// ListInvitations handles GET /api/v1/invitations.
+// It lists pending invitations addressed to the user's email.
+func (h *FamilyHandler) ListInvitations(c *gin.Context) {
+	email, err := h.db.GetUserEmail(c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	invitations, err := h.db.GetInvitationsForEmail(email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(invitations))
+	for i, inv := range invitations {
+		result[i] = gin.H{
+			"id":            inv.ID,
+			"family_id":     inv.FamilyID,
+			"family_name":   inv.FamilyName,
+			"inviter_email": inv.InviterEmail,
+			"expires_at":    inv.ExpiresAt,
+			"created_at":    inv.CreatedAt,
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 type acceptInvitationRequest struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	InvitationID string `json:"invitation_id"`
 }
 
 // AcceptInvitation handles POST /api/v1/invitations/accept.
+// The invitation is identified by the token from the email or, for
+// invitations listed by GET /invitations, by invitation_id. Invitations
+// addressed to an email can only be accepted by that email's account.
 func (h *FamilyHandler) AcceptInvitation(c *gin.Context) {
 	var req acceptInvitationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -417,29 +795,26 @@ func (h *FamilyHandler) AcceptInvitation(c *gin.Context) {
 		return
 	}
 
-	if req.Token == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+	if req.Token == "" && req.InvitationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token or invitation_id is required"})
 		return
 	}
 
 	userID := c.GetString("user_id")
 
-	// Check user not already in a family
-	_, err := h.db.GetFamilyByUserID(userID)
-	if err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "You are already in a family"})
-		return
-	}
-	if !errors.Is(err, ErrFamilyNotFound) {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		return
+	var inv MockInvitation
+	var err error
+	if req.Token != "" {
+		// Hash token and look up
+		hash := sha256.Sum256([]byte(req.Token))
+		inv, err = h.db.GetInvitationByTokenHash(hex.EncodeToString(hash[:]))
+	} else {
+		inv, err = h.db.GetInvitationByID(req.InvitationID)
+		if err == nil && inv.Email == "" {
+			// Without an email the token is the only proof of invitation.
+			err = ErrInvitationNotFound
+		}
 	}
-
-	// Hash token and look up
-	hash := sha256.Sum256([]byte(req.Token))
-	tokenHash := hex.EncodeToString(hash[:])
-
-	inv, err := h.db.GetInvitationByTokenHash(tokenHash)
 	if err != nil {
 		if errors.Is(err, ErrInvitationNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found or expired"})
@@ -449,30 +824,46 @@ func (h *FamilyHandler) AcceptInvitation(c *gin.Context) {
 		return
 	}
 
-	// Check member count
-	count, err := h.db.GetFamilyMemberCount(inv.FamilyID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	// Check user not already in this family
+	_, err = h.db.GetFamilyForMember(inv.FamilyID, userID)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "You are already in this family"})
 		return
 	}
-	if count >= 10 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Family is full (max 10 members)"})
+	if !errors.Is(err, ErrFamilyNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	// Add member
-	if err := h.db.AddFamilyMember(inv.FamilyID, userID, "member"); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		return
+	if inv.Email != "" {
+		email, err := h.db.GetUserEmail(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		if !strings.EqualFold(email, inv.Email) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This invitation was sent to a different email address"})
+			return
+		}
 	}
 
-	// Mark invitation accepted
-	_, err = h.db.AcceptInvitation(inv.ID)
-	if err != nil {
+	// Accept and join in one step, so a concurrent accept can neither
+	// overfill the family nor reuse a revoked or expired invitation.
+	if err := h.db.AcceptInvitation(inv.ID, inv.FamilyID, userID, RoleMember); err != nil {
+		if errors.Is(err, ErrInvitationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found or expired"})
+			return
+		}
+		if errors.Is(err, ErrFamilyFull) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Family is full (max 10 members)"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	recordFamilyEvent(c, h.activity, inv.FamilyID, ActionMemberJoined, userID, nil, gin.H{"role": RoleMember})
+
 	c.JSON(http.StatusOK, gin.H{
 		"family_id":   inv.FamilyID,
 		"family_name": inv.FamilyName,
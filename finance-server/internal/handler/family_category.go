@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sentinel errors for family category operations.
+var (
+	ErrCategoryMappingNotFound = errors.New("category mapping not found")
+)
+
+// MockCategoryMapping maps a member's personal category onto a family
+// category, so family summaries count its expenses under the family one.
+type MockCategoryMapping struct {
+	CategoryID       string
+	FamilyCategoryID string
+}
+
+// FamilyCategoryDB abstracts database operations for family categories and
+// the mappings of personal categories onto them.
+// This allows testing with mock implementations.
+type FamilyCategoryDB interface {
+	CreateFamilyCategory(familyID, name, icon, color, kind string) (MockCategory, error)
+	GetFamilyCategories(familyID, kind string) ([]MockCategory, error)
+	GetFamilyCategory(id, familyID string) (MockCategory, error)
+	UpdateFamilyCategory(id, familyID, name, icon, color string) error
+	// DeleteFamilyCategory returns ErrCategoryInUse while expenses, incomes
+	// or recurring expenses still use the category.
+	DeleteFamilyCategory(id, familyID string) error
+	GetCategoryMappings(familyID, userID string) ([]MockCategoryMapping, error)
+	// SetCategoryMapping returns ErrCategoryNotFound unless categoryID is one
+	// of userID's categories and familyCategoryID a family category of the
+	// same kind.
+	SetCategoryMapping(familyID, userID, categoryID, familyCategoryID string) error
+	DeleteCategoryMapping(familyID, userID, categoryID string) error
+}
+
+// FamilyCategoryHandler handles family category HTTP requests.
+// Family categories can be used by every member for their own expenses;
+// changes to them are recorded in the family's activity log.
+type FamilyCategoryHandler struct {
+	db       FamilyCategoryDB
+	familyDB FamilyDB
+	activity ActivityDB
+}
+
+// NewFamilyCategoryHandler creates a FamilyCategoryHandler with the given databases.
+func NewFamilyCategoryHandler(db FamilyCategoryDB, familyDB FamilyDB, activity ActivityDB) *FamilyCategoryHandler {
+	return &FamilyCategoryHandler{db: db, familyDB: familyDB, activity: activity}
+}
+
+func familyCategoryJSON(cat MockCategory) gin.H {
+	return gin.H{
+		"id":         cat.ID,
+		"family_id":  cat.FamilyID,
+		"name":       cat.Name,
+		"icon":       cat.Icon,
+		"color":      cat.Color,
+		"kind":       cat.Kind,
+		"sort_order": cat.SortOrder,
+	}
+}
+
+// List handles GET /api/v1/families/:familyId/categories.
+// An optional kind query parameter restricts the list to expense or income categories.
+func (h *FamilyCategoryHandler) List(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
+		return
+	}
+
+	kind := c.Query("kind")
+	if kind != "" && kind != CategoryKindExpense && kind != CategoryKindIncome {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be expense or income"})
+		return
+	}
+
+	cats, err := h.db.GetFamilyCategories(family.ID, kind)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(cats))
+	for i, cat := range cats {
+		result[i] = familyCategoryJSON(cat)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Create handles POST /api/v1/families/:familyId/categories.
+func (h *FamilyCategoryHandler) Create(c *gin.Context) {
+	var req createCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.Name == "" || req.Icon == "" || req.Color == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name, icon, and color are required"})
+		return
+	}
+
+	kind, ok := normalizeCategoryKind(req.Kind)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be expense or income"})
+		return
+	}
+
+	family, ok := authorizeFamily(c, h.familyDB, PermManageFamily)
+	if !ok {
+		return
+	}
+
+	cat, err := h.db.CreateFamilyCategory(family.ID, req.Name, req.Icon, req.Color, kind)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	recordFamilyEvent(c, h.activity, family.ID, ActionCategoryCreated, cat.ID, nil, categorySnapshot(cat))
+
+	c.JSON(http.StatusCreated, familyCategoryJSON(cat))
+}
+
+// Update handles PUT /api/v1/families/:familyId/categories/:id.
+func (h *FamilyCategoryHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+
+	var req updateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.Name == "" || req.Icon == "" || req.Color == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name, icon, and color are required"})
+		return
+	}
+
+	family, ok := authorizeFamily(c, h.familyDB, PermManageFamily)
+	if !ok {
+		return
+	}
+
+	before, ok := h.category(c, id, family.ID)
+	if !ok {
+		return
+	}
+
+	if err := h.db.UpdateFamilyCategory(id, family.ID, req.Name, req.Icon, req.Color); err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	after := before
+	after.Name, after.Icon, after.Color = req.Name, req.Icon, req.Color
+	recordFamilyEvent(c, h.activity, family.ID, ActionCategoryUpdated, id, categorySnapshot(before), categorySnapshot(after))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category updated"})
+}
+
+// Delete handles DELETE /api/v1/families/:familyId/categories/:id.
+// Categories still used by members' expenses or incomes cannot be deleted.
+func (h *FamilyCategoryHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+
+	family, ok := authorizeFamily(c, h.familyDB, PermManageFamily)
+	if !ok {
+		return
+	}
+
+	before, ok := h.category(c, id, family.ID)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteFamilyCategory(id, family.ID); err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		if errors.Is(err, ErrCategoryInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Category is in use"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	recordFamilyEvent(c, h.activity, family.ID, ActionCategoryDeleted, id, categorySnapshot(before), nil)
+
+	c.Status(http.StatusNoContent)
+}
+
+// category looks up a family category, writing an error response and
+// returning ok=false when there is none.
+func (h *FamilyCategoryHandler) category(c *gin.Context, id, familyID string) (MockCategory, bool) {
+	cat, err := h.db.GetFamilyCategory(id, familyID)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return MockCategory{}, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return MockCategory{}, false
+	}
+	return cat, true
+}
+
+// ListMappings handles GET /api/v1/families/:familyId/category-mappings.
+// It returns the current user's mappings in the family.
+func (h *FamilyCategoryHandler) ListMappings(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermContribute)
+	if !ok {
+		return
+	}
+
+	mappings, err := h.db.GetCategoryMappings(family.ID, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(mappings))
+	for i, m := range mappings {
+		result[i] = gin.H{
+			"category_id":        m.CategoryID,
+			"family_category_id": m.FamilyCategoryID,
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type setCategoryMappingRequest struct {
+	FamilyCategoryID string `json:"family_category_id"`
+}
+
+// SetMapping handles PUT /api/v1/families/:familyId/category-mappings/:categoryId.
+// The personal category must be the user's own and of the same kind as the
+// family category; an existing mapping is replaced.
+func (h *FamilyCategoryHandler) SetMapping(c *gin.Context) {
+	categoryID := c.Param("categoryId")
+
+	var req setCategoryMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.FamilyCategoryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "family_category_id is required"})
+		return
+	}
+
+	family, ok := authorizeFamily(c, h.familyDB, PermContribute)
+	if !ok {
+		return
+	}
+
+	if err := h.db.SetCategoryMapping(family.ID, c.GetString("user_id"), categoryID, req.FamilyCategoryID); err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"category_id":        categoryID,
+		"family_category_id": req.FamilyCategoryID,
+	})
+}
+
+// DeleteMapping handles DELETE /api/v1/families/:familyId/category-mappings/:categoryId.
+func (h *FamilyCategoryHandler) DeleteMapping(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermContribute)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteCategoryMapping(family.ID, c.GetString("user_id"), c.Param("categoryId")); err != nil {
+		if errors.Is(err, ErrCategoryMappingNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Mapping not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// PgFamilyCategoryDB implements FamilyCategoryDB using sqlc-generated queries against PostgreSQL.
+type PgFamilyCategoryDB struct {
+	queries *sqlc.Queries
+}
+
+// NewPgFamilyCategoryDB creates a PgFamilyCategoryDB wrapping sqlc.Queries.
+func NewPgFamilyCategoryDB(queries *sqlc.Queries) *PgFamilyCategoryDB {
+	return &PgFamilyCategoryDB{queries: queries}
+}
+
+func familyCategoryFromRow(row sqlc.Category) MockCategory {
+	return MockCategory{
+		ID:        uuidToString(row.ID),
+		FamilyID:  uuidToString(row.FamilyID),
+		Name:      row.Name,
+		Icon:      row.Icon,
+		Color:     row.Color,
+		Kind:      row.Kind,
+		SortOrder: int(row.SortOrder),
+	}
+}
+
+func (db *PgFamilyCategoryDB) CreateFamilyCategory(familyID, name, icon, color, kind string) (MockCategory, error) {
+	row, err := db.queries.CreateFamilyCategory(context.Background(), sqlc.CreateFamilyCategoryParams{
+		FamilyID: stringToUUID(familyID),
+		Name:     name,
+		Icon:     icon,
+		Color:    color,
+		Kind:     kind,
+	})
+	if err != nil {
+		return MockCategory{}, err
+	}
+	return familyCategoryFromRow(row), nil
+}
+
+func (db *PgFamilyCategoryDB) GetFamilyCategories(familyID, kind string) ([]MockCategory, error) {
+	rows, err := db.queries.GetFamilyCategories(context.Background(), sqlc.GetFamilyCategoriesParams{
+		FamilyID: stringToUUID(familyID),
+		Kind:     stringToNullableText(kind),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cats := make([]MockCategory, len(rows))
+	for i, row := range rows {
+		cats[i] = familyCategoryFromRow(row)
+	}
+	return cats, nil
+}
+
+func (db *PgFamilyCategoryDB) GetFamilyCategory(id, familyID string) (MockCategory, error) {
+	row, err := db.queries.GetFamilyCategoryByID(context.Background(), sqlc.GetFamilyCategoryByIDParams{
+		ID:       stringToUUID(id),
+		FamilyID: stringToUUID(familyID),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockCategory{}, ErrCategoryNotFound
+		}
+		return MockCategory{}, err
+	}
+	return familyCategoryFromRow(row), nil
+}
+
+func (db *PgFamilyCategoryDB) UpdateFamilyCategory(id, familyID, name, icon, color string) error {
+	rowsAffected, err := db.queries.UpdateFamilyCategory(context.Background(), sqlc.UpdateFamilyCategoryParams{
+		ID:       stringToUUID(id),
+		FamilyID: stringToUUID(familyID),
+		Name:     name,
+		Icon:     icon,
+		Color:    color,
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (db *PgFamilyCategoryDB) DeleteFamilyCategory(id, familyID string) error {
+	rowsAffected, err := db.queries.DeleteFamilyCategory(context.Background(), sqlc.DeleteFamilyCategoryParams{
+		ID:       stringToUUID(id),
+		FamilyID: stringToUUID(familyID),
+	})
+	if err != nil {
+		return categoryInUseError(err)
+	}
+	if rowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (db *PgFamilyCategoryDB) GetCategoryMappings(familyID, userID string) ([]MockCategoryMapping, error) {
+	rows, err := db.queries.GetCategoryMappings(context.Background(), sqlc.GetCategoryMappingsParams{
+		FamilyID: stringToUUID(familyID),
+		UserID:   stringToUUID(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make([]MockCategoryMapping, len(rows))
+	for i, row := range rows {
+		mappings[i] = MockCategoryMapping{
+			CategoryID:       uuidToString(row.CategoryID),
+			FamilyCategoryID: uuidToString(row.FamilyCategoryID),
+		}
+	}
+	return mappings, nil
+}
+
+func (db *PgFamilyCategoryDB) SetCategoryMapping(familyID, userID, categoryID, familyCategoryID string) error {
+	rowsAffected, err := db.queries.UpsertCategoryMapping(context.Background(), sqlc.UpsertCategoryMappingParams{
+		CategoryID:       stringToUUID(categoryID),
+		UserID:           stringToUUID(userID),
+		FamilyCategoryID: stringToUUID(familyCategoryID),
+		FamilyID:         stringToUUID(familyID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (db *PgFamilyCategoryDB) DeleteCategoryMapping(familyID, userID, categoryID string) error {
+	rowsAffected, err := db.queries.DeleteCategoryMapping(context.Background(), sqlc.DeleteCategoryMappingParams{
+		FamilyID:   stringToUUID(familyID),
+		CategoryID: stringToUUID(categoryID),
+		UserID:     stringToUUID(userID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrCategoryMappingNotFound
+	}
+	return nil
+}
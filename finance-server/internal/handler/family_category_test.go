@@ -0,0 +1,224 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+// mockFamilyCategoryDB implements handler.FamilyCategoryDB for testing.
+type mockFamilyCategoryDB struct {
+	categories []handler.MockCategory
+	// personal maps a personal category ID to its owner.
+	personal map[string]string
+	mappings map[string]handler.MockCategoryMapping // familyID/categoryID -> mapping
+	inUse    map[string]bool
+}
+
+func newMockFamilyCategoryDB() *mockFamilyCategoryDB {
+	return &mockFamilyCategoryDB{
+		personal: map[string]string{"cat-groceries": "user-3"},
+		mappings: make(map[string]handler.MockCategoryMapping),
+		inUse:    make(map[string]bool),
+	}
+}
+
+func (m *mockFamilyCategoryDB) CreateFamilyCategory(familyID, name, icon, color, kind string) (handler.MockCategory, error) {
+	cat := handler.MockCategory{
+		ID:        fmt.Sprintf("fcat-%d", len(m.categories)+1),
+		FamilyID:  familyID,
+		Name:      name,
+		Icon:      icon,
+		Color:     color,
+		Kind:      kind,
+		SortOrder: len(m.categories),
+	}
+	m.categories = append(m.categories, cat)
+	return cat, nil
+}
+
+func (m *mockFamilyCategoryDB) GetFamilyCategories(familyID, kind string) ([]handler.MockCategory, error) {
+	result := []handler.MockCategory{}
+	for _, cat := range m.categories {
+		if cat.FamilyID == familyID && (kind == "" || cat.Kind == kind) {
+			result = append(result, cat)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockFamilyCategoryDB) GetFamilyCategory(id, familyID string) (handler.MockCategory, error) {
+	for _, cat := range m.categories {
+		if cat.ID == id && cat.FamilyID == familyID {
+			return cat, nil
+		}
+	}
+	return handler.MockCategory{}, handler.ErrCategoryNotFound
+}
+
+func (m *mockFamilyCategoryDB) UpdateFamilyCategory(id, familyID, name, icon, color string) error {
+	for i, cat := range m.categories {
+		if cat.ID == id && cat.FamilyID == familyID {
+			m.categories[i].Name, m.categories[i].Icon, m.categories[i].Color = name, icon, color
+			return nil
+		}
+	}
+	return handler.ErrCategoryNotFound
+}
+
+func (m *mockFamilyCategoryDB) DeleteFamilyCategory(id, familyID string) error {
+	if m.inUse[id] {
+		return handler.ErrCategoryInUse
+	}
+	for i, cat := range m.categories {
+		if cat.ID == id && cat.FamilyID == familyID {
+			m.categories = append(m.categories[:i], m.categories[i+1:]...)
+			return nil
+		}
+	}
+	return handler.ErrCategoryNotFound
+}
+
+func (m *mockFamilyCategoryDB) GetCategoryMappings(familyID, userID string) ([]handler.MockCategoryMapping, error) {
+	result := []handler.MockCategoryMapping{}
+	for key, mapping := range m.mappings {
+		if key == familyID+"/"+mapping.CategoryID && m.personal[mapping.CategoryID] == userID {
+			result = append(result, mapping)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockFamilyCategoryDB) SetCategoryMapping(familyID, userID, categoryID, familyCategoryID string) error {
+	if m.personal[categoryID] != userID {
+		return handler.ErrCategoryNotFound
+	}
+	if _, err := m.GetFamilyCategory(familyCategoryID, familyID); err != nil {
+		return err
+	}
+	m.mappings[familyID+"/"+categoryID] = handler.MockCategoryMapping{CategoryID: categoryID, FamilyCategoryID: familyCategoryID}
+	return nil
+}
+
+func (m *mockFamilyCategoryDB) DeleteCategoryMapping(familyID, userID, categoryID string) error {
+	key := familyID + "/" + categoryID
+	if _, ok := m.mappings[key]; !ok || m.personal[categoryID] != userID {
+		return handler.ErrCategoryMappingNotFound
+	}
+	delete(m.mappings, key)
+	return nil
+}
+
+func setupFamilyCategoryRouter(db handler.FamilyCategoryDB, activity handler.ActivityDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewFamilyCategoryHandler(db, newRolesFamily(), activity)
+
+	families := r.Group("/api/v1/families", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User-ID"))
+		c.Next()
+	})
+	{
+		families.GET("/:familyId/categories", h.List)
+		families.POST("/:familyId/categories", h.Create)
+		families.PUT("/:familyId/categories/:id", h.Update)
+		families.DELETE("/:familyId/categories/:id", h.Delete)
+		families.GET("/:familyId/category-mappings", h.ListMappings)
+		families.PUT("/:familyId/category-mappings/:categoryId", h.SetMapping)
+		families.DELETE("/:familyId/category-mappings/:categoryId", h.DeleteMapping)
+	}
+	return r
+}
+
+func TestFamilyCategories(t *testing.T) {
+	db := newMockFamilyCategoryDB()
+	activity := newMockActivityDB()
+	r := setupFamilyCategoryRouter(db, activity)
+	groceries := map[string]string{"name": "Groceries", "icon": "cart", "color": "#4CAF50"}
+
+	t.Run("member cannot create", func(t *testing.T) {
+		w := postFamilyJSON(r, "/api/v1/families/family-1/categories", "user-3", groceries)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d", w.Code)
+		}
+	})
+
+	t.Run("admin creates", func(t *testing.T) {
+		w := postFamilyJSON(r, "/api/v1/families/family-1/categories", "user-2", groceries)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["family_id"] != "family-1" || resp["kind"] != handler.CategoryKindExpense {
+			t.Fatalf("unexpected category: %v", resp)
+		}
+	})
+
+	t.Run("viewer lists", func(t *testing.T) {
+		w := familyRequest(r, http.MethodGet, "/api/v1/families/me/categories", "user-4")
+		var cats []map[string]any
+		json.Unmarshal(w.Body.Bytes(), &cats)
+		if w.Code != http.StatusOK || len(cats) != 1 || cats[0]["name"] != "Groceries" {
+			t.Fatalf("unexpected list: %d %v", w.Code, cats)
+		}
+	})
+
+	t.Run("category in use", func(t *testing.T) {
+		db.inUse["fcat-1"] = true
+		defer delete(db.inUse, "fcat-1")
+		w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1/categories/fcat-1", "user-2")
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected 409, got %d", w.Code)
+		}
+	})
+
+	if got := activity.actions("family-1"); len(got) != 1 || got[0] != handler.ActionCategoryCreated {
+		t.Fatalf("expected one category.created event, got %v", got)
+	}
+}
+
+func TestCategoryMappings(t *testing.T) {
+	db := newMockFamilyCategoryDB()
+	db.CreateFamilyCategory("family-1", "Groceries", "cart", "#4CAF50", handler.CategoryKindExpense)
+	r := setupFamilyCategoryRouter(db, newMockActivityDB())
+
+	tests := []struct {
+		name       string
+		userID     string
+		categoryID string
+		target     string
+		want       int
+	}{
+		{"viewer cannot map", "user-4", "cat-groceries", "fcat-1", http.StatusForbidden},
+		{"someone else's category", "user-2", "cat-groceries", "fcat-1", http.StatusNotFound},
+		{"unknown family category", "user-3", "cat-groceries", "fcat-9", http.StatusNotFound},
+		{"maps own category", "user-3", "cat-groceries", "fcat-1", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := putJSON(r, "/api/v1/families/family-1/category-mappings/"+tt.categoryID, tt.userID, map[string]any{"family_category_id": tt.target})
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := familyRequest(r, http.MethodGet, "/api/v1/families/family-1/category-mappings", "user-3")
+	var mappings []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &mappings)
+	if len(mappings) != 1 || mappings[0]["family_category_id"] != "fcat-1" {
+		t.Fatalf("unexpected mappings: %v", mappings)
+	}
+
+	if w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1/category-mappings/cat-groceries", "user-3"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1/category-mappings/cat-groceries", "user-3"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a removed mapping, got %d", w.Code)
+	}
+}
@@ -4,51 +4,119 @@ import (
 	"context"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
 )
 
 // PgFamilyDB implements FamilyDB using sqlc-generated queries against PostgreSQL.
 type PgFamilyDB struct {
 	queries *sqlc.Queries
+	pool    *pgxpool.Pool
 }
 
 // NewPgFamilyDB creates a PgFamilyDB wrapping sqlc.Queries.
-func NewPgFamilyDB(queries *sqlc.Queries) *PgFamilyDB {
-	return &PgFamilyDB{queries: queries}
+// The pool is used to transfer ownership in a transaction.
+func NewPgFamilyDB(queries *sqlc.Queries, pool *pgxpool.Pool) *PgFamilyDB {
+	return &PgFamilyDB{queries: queries, pool: pool}
 }
 
 func (db *PgFamilyDB) CreateFamily(userID, name string) (MockFamily, error) {
+	ctx := context.Background()
 	uid := stringToUUID(userID)
-	row, err := db.queries.CreateFamily(context.Background(), sqlc.CreateFamilyParams{
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return MockFamily{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	row, err := qtx.CreateFamily(ctx, sqlc.CreateFamilyParams{
 		Name:        name,
 		AdminUserID: uid,
 	})
 	if err != nil {
 		return MockFamily{}, err
 	}
+	_, err = qtx.AddFamilyMember(ctx, sqlc.AddFamilyMemberParams{
+		FamilyID: row.ID,
+		UserID:   uid,
+		Role:     RoleOwner,
+	})
+	if err != nil {
+		return MockFamily{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return MockFamily{}, err
+	}
+
 	return MockFamily{
-		ID:          uuidToString(row.ID),
-		Name:        row.Name,
-		AdminUserID: uuidToString(row.AdminUserID),
-		CreatedAt:   row.CreatedAt.Time,
-		UpdatedAt:   row.UpdatedAt.Time,
+		ID:              uuidToString(row.ID),
+		Name:            row.Name,
+		AdminUserID:     uuidToString(row.AdminUserID),
+		BaseCurrency:    row.BaseCurrency,
+		PrivateExpenses: row.PrivateExpenses,
+		CreatedAt:       row.CreatedAt.Time,
+		UpdatedAt:       row.UpdatedAt.Time,
 	}, nil
 }
 
-func (db *PgFamilyDB) GetFamilyByUserID(userID string) (MockFamily, error) {
-	uid := stringToUUID(userID)
-	row, err := db.queries.GetFamilyByUserID(context.Background(), uid)
+func (db *PgFamilyDB) GetActiveFamily(userID string) (MockFamily, error) {
+	row, err := db.queries.GetActiveFamily(context.Background(), stringToUUID(userID))
 	if err != nil {
-		return MockFamily{}, ErrFamilyNotFound
+		if err == pgx.ErrNoRows {
+			return MockFamily{}, ErrFamilyNotFound
+		}
+		return MockFamily{}, err
 	}
-	return MockFamily{
-		ID:          uuidToString(row.ID),
-		Name:        row.Name,
-		AdminUserID: uuidToString(row.AdminUserID),
-		CreatedAt:   row.CreatedAt.Time,
-		UpdatedAt:   row.UpdatedAt.Time,
-	}, nil
+	family := familyFromRow(row.Family)
+	family.Role = row.Role
+	return family, nil
+}
+
+func (db *PgFamilyDB) GetFamilyForMember(familyID, userID string) (MockFamily, error) {
+	row, err := db.queries.GetFamilyForMember(context.Background(), sqlc.GetFamilyForMemberParams{
+		ID:     stringToUUID(familyID),
+		UserID: stringToUUID(userID),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockFamily{}, ErrFamilyNotFound
+		}
+		return MockFamily{}, err
+	}
+	family := familyFromRow(row.Family)
+	family.Role = row.Role
+	return family, nil
+}
+
+func (db *PgFamilyDB) GetUserFamilies(userID string) ([]MockFamily, error) {
+	rows, err := db.queries.GetUserFamilies(context.Background(), stringToUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+	families := make([]MockFamily, len(rows))
+	for i, row := range rows {
+		families[i] = familyFromRow(row.Family)
+		families[i].Role = row.Role
+	}
+	return families, nil
+}
+
+func (db *PgFamilyDB) SetActiveFamily(userID, familyID string) error {
+	rowsAffected, err := db.queries.SetActiveFamily(context.Background(), sqlc.SetActiveFamilyParams{
+		ID:             stringToUUID(userID),
+		ActiveFamilyID: stringToUUID(familyID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrFamilyNotFound
+	}
+	return nil
 }
 
 func (db *PgFamilyDB) GetFamilyMembers(familyID string) ([]MockFamilyMember, error) {
@@ -70,24 +138,77 @@ func (db *PgFamilyDB) GetFamilyMembers(familyID string) ([]MockFamilyMember, err
 	return members, nil
 }
 
-func (db *PgFamilyDB) AddFamilyMember(familyID, userID, role string) error {
+func (db *PgFamilyDB) RemoveFamilyMember(familyID, userID string) (int64, error) {
 	fid := stringToUUID(familyID)
 	uid := stringToUUID(userID)
-	_, err := db.queries.AddFamilyMember(context.Background(), sqlc.AddFamilyMemberParams{
+	return db.queries.RemoveFamilyMember(context.Background(), sqlc.RemoveFamilyMemberParams{
 		FamilyID: fid,
 		UserID:   uid,
+	})
+}
+
+func (db *PgFamilyDB) SetMemberRole(familyID, userID, role string) error {
+	rowsAffected, err := db.queries.SetFamilyMemberRole(context.Background(), sqlc.SetFamilyMemberRoleParams{
+		FamilyID: stringToUUID(familyID),
+		UserID:   stringToUUID(userID),
 		Role:     role,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrFamilyMemberNotFound
+	}
+	return nil
 }
 
-func (db *PgFamilyDB) RemoveFamilyMember(familyID, userID string) (int64, error) {
+func (db *PgFamilyDB) TransferOwnership(familyID, fromUserID, toUserID string) error {
+	return db.transferOwnership(familyID, fromUserID, toUserID, false)
+}
+
+func (db *PgFamilyDB) TransferOwnershipAndLeave(familyID, fromUserID, toUserID string) error {
+	return db.transferOwnership(familyID, fromUserID, toUserID, true)
+}
+
+// transferOwnership makes toUserID the owner and fromUserID an admin, then
+// removes fromUserID when leave is set, all in one transaction.
+func (db *PgFamilyDB) transferOwnership(familyID, fromUserID, toUserID string, leave bool) error {
+	ctx := context.Background()
 	fid := stringToUUID(familyID)
-	uid := stringToUUID(userID)
-	return db.queries.RemoveFamilyMember(context.Background(), sqlc.RemoveFamilyMemberParams{
-		FamilyID: fid,
-		UserID:   uid,
-	})
+	from := stringToUUID(fromUserID)
+	to := stringToUUID(toUserID)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	rowsAffected, err := qtx.SetFamilyOwner(ctx, sqlc.SetFamilyOwnerParams{ID: fid, AdminUserID: from, NewOwnerID: to})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrFamilyNotFound
+	}
+	rowsAffected, err = qtx.SetFamilyMemberRole(ctx, sqlc.SetFamilyMemberRoleParams{FamilyID: fid, UserID: to, Role: RoleOwner})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrFamilyMemberNotFound
+	}
+	if _, err := qtx.SetFamilyMemberRole(ctx, sqlc.SetFamilyMemberRoleParams{FamilyID: fid, UserID: from, Role: RoleAdmin}); err != nil {
+		return err
+	}
+	if leave {
+		if _, err := qtx.RemoveFamilyMember(ctx, sqlc.RemoveFamilyMemberParams{FamilyID: fid, UserID: from}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (db *PgFamilyDB) DeleteFamily(familyID, adminUserID string) (int64, error) {
@@ -104,7 +225,7 @@ func (db *PgFamilyDB) GetFamilyMemberCount(familyID string) (int64, error) {
 	return db.queries.GetFamilyMemberCount(context.Background(), fid)
 }
 
-func (db *PgFamilyDB) CreateInvitation(familyID, inviterUserID, tokenHash string, expiresAt time.Time) (MockInvitation, error) {
+func (db *PgFamilyDB) CreateInvitation(familyID, inviterUserID, email, tokenHash string, expiresAt time.Time) (MockInvitation, error) {
 	fid := stringToUUID(familyID)
 	uid := stringToUUID(inviterUserID)
 	row, err := db.queries.CreateInvitation(context.Background(), sqlc.CreateInvitationParams{
@@ -112,6 +233,7 @@ func (db *PgFamilyDB) CreateInvitation(familyID, inviterUserID, tokenHash string
 		InviterUserID: uid,
 		TokenHash:     tokenHash,
 		ExpiresAt:     pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		Email:         stringToNullableText(email),
 	})
 	if err != nil {
 		return MockInvitation{}, err
@@ -120,6 +242,7 @@ func (db *PgFamilyDB) CreateInvitation(familyID, inviterUserID, tokenHash string
 		ID:            uuidToString(row.ID),
 		FamilyID:      uuidToString(row.FamilyID),
 		InviterUserID: uuidToString(row.InviterUserID),
+		Email:         row.Email.String,
 		TokenHash:     row.TokenHash,
 		Status:        row.Status,
 		ExpiresAt:     row.ExpiresAt.Time,
@@ -136,6 +259,7 @@ func (db *PgFamilyDB) GetInvitationByTokenHash(tokenHash string) (MockInvitation
 		ID:            uuidToString(row.ID),
 		FamilyID:      uuidToString(row.FamilyID),
 		InviterUserID: uuidToString(row.InviterUserID),
+		Email:         row.Email.String,
 		TokenHash:     row.TokenHash,
 		Status:        row.Status,
 		FamilyName:    row.FamilyName,
@@ -144,9 +268,65 @@ func (db *PgFamilyDB) GetInvitationByTokenHash(tokenHash string) (MockInvitation
 	}, nil
 }
 
-func (db *PgFamilyDB) AcceptInvitation(invitationID string) (int64, error) {
-	iid := stringToUUID(invitationID)
-	return db.queries.AcceptInvitation(context.Background(), iid)
+func (db *PgFamilyDB) GetInvitationByID(invitationID string) (MockInvitation, error) {
+	row, err := db.queries.GetInvitationByID(context.Background(), stringToUUID(invitationID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockInvitation{}, ErrInvitationNotFound
+		}
+		return MockInvitation{}, err
+	}
+	return MockInvitation{
+		ID:            uuidToString(row.ID),
+		FamilyID:      uuidToString(row.FamilyID),
+		InviterUserID: uuidToString(row.InviterUserID),
+		Email:         row.Email.String,
+		TokenHash:     row.TokenHash,
+		Status:        row.Status,
+		FamilyName:    row.FamilyName,
+		ExpiresAt:     row.ExpiresAt.Time,
+		CreatedAt:     row.CreatedAt.Time,
+	}, nil
+}
+
+func (db *PgFamilyDB) AcceptInvitation(invitationID, familyID, userID, role string) error {
+	ctx := context.Background()
+	fid := stringToUUID(familyID)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	if err := qtx.LockFamily(ctx, fid); err != nil {
+		return err
+	}
+	rowsAffected, err := qtx.AcceptInvitation(ctx, stringToUUID(invitationID))
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrInvitationNotFound
+	}
+	count, err := qtx.GetFamilyMemberCount(ctx, fid)
+	if err != nil {
+		return err
+	}
+	if count >= maxFamilyMembers {
+		return ErrFamilyFull
+	}
+	_, err = qtx.AddFamilyMember(ctx, sqlc.AddFamilyMemberParams{
+		FamilyID: fid,
+		UserID:   stringToUUID(userID),
+		Role:     role,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (db *PgFamilyDB) RevokeInvitation(invitationID, familyID string) (int64, error) {
@@ -170,6 +350,7 @@ func (db *PgFamilyDB) GetPendingInvitations(familyID string) ([]MockPendingInvit
 			ID:            uuidToString(row.ID),
 			FamilyID:      uuidToString(row.FamilyID),
 			InviterUserID: uuidToString(row.InviterUserID),
+			Email:         row.Email.String,
 			Status:        row.Status,
 			ExpiresAt:     row.ExpiresAt.Time,
 			CreatedAt:     row.CreatedAt.Time,
@@ -177,3 +358,40 @@ func (db *PgFamilyDB) GetPendingInvitations(familyID string) ([]MockPendingInvit
 	}
 	return invitations, nil
 }
+
+func (db *PgFamilyDB) GetInvitationsForEmail(email string) ([]MockPendingInvitation, error) {
+	rows, err := db.queries.GetPendingInvitationsForEmail(context.Background(), email)
+	if err != nil {
+		return nil, err
+	}
+	invitations := make([]MockPendingInvitation, len(rows))
+	for i, row := range rows {
+		invitations[i] = MockPendingInvitation{
+			ID:           uuidToString(row.ID),
+			FamilyID:     uuidToString(row.FamilyID),
+			FamilyName:   row.FamilyName,
+			InviterEmail: row.InviterEmail,
+			Email:        email,
+			Status:       "pending",
+			ExpiresAt:    row.ExpiresAt.Time,
+			CreatedAt:    row.CreatedAt.Time,
+		}
+	}
+	return invitations, nil
+}
+
+func (db *PgFamilyDB) GetUserEmail(userID string) (string, error) {
+	return db.queries.GetUserEmail(context.Background(), stringToUUID(userID))
+}
+
+func familyFromRow(row sqlc.Family) MockFamily {
+	return MockFamily{
+		ID:              uuidToString(row.ID),
+		Name:            row.Name,
+		AdminUserID:     uuidToString(row.AdminUserID),
+		BaseCurrency:    row.BaseCurrency,
+		PrivateExpenses: row.PrivateExpenses,
+		CreatedAt:       row.CreatedAt.Time,
+		UpdatedAt:       row.UpdatedAt.Time,
+	}
+}
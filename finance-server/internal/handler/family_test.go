@@ -2,14 +2,23 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nnc/finance-tracker/server/internal/handler"
+	"github.com/nnc/finance-tracker/server/internal/mailer"
 )
 
 // mockFamilyDB implements handler.FamilyDB for testing.
@@ -18,8 +27,7 @@ type mockFamilyDB struct {
 	members     map[string][]handler.MockFamilyMember // familyID -> members
 	memberCount map[string]int64
 	invitations map[string]*handler.MockInvitation // tokenHash -> invitation
-	pending     map[string][]handler.MockPendingInvitation
-	// Track user -> familyID mapping
+	// Track user -> active familyID mapping
 	userFamily map[string]string
 }
 
@@ -29,33 +37,90 @@ func newMockFamilyDB() *mockFamilyDB {
 		members:     make(map[string][]handler.MockFamilyMember),
 		memberCount: make(map[string]int64),
 		invitations: make(map[string]*handler.MockInvitation),
-		pending:     make(map[string][]handler.MockPendingInvitation),
 		userFamily:  make(map[string]string),
 	}
 }
 
 func (m *mockFamilyDB) CreateFamily(userID, name string) (handler.MockFamily, error) {
 	f := handler.MockFamily{
-		ID:          "family-1",
-		Name:        name,
-		AdminUserID: userID,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:           fmt.Sprintf("family-%d", len(m.families)+1),
+		Name:         name,
+		AdminUserID:  userID,
+		BaseCurrency: "UAH",
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
 	}
 	m.families[f.ID] = &f
+	m.AddFamilyMember(f.ID, userID, handler.RoleOwner)
 	return f, nil
 }
 
-func (m *mockFamilyDB) GetFamilyByUserID(userID string) (handler.MockFamily, error) {
-	fid, ok := m.userFamily[userID]
-	if !ok {
+// isMember reports whether userID belongs to familyID, either through the
+// active-family mapping or the member list.
+func (m *mockFamilyDB) isMember(familyID, userID string) bool {
+	if m.userFamily[userID] == familyID {
+		return true
+	}
+	for _, mem := range m.members[familyID] {
+		if mem.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockFamilyDB) GetActiveFamily(userID string) (handler.MockFamily, error) {
+	if fid, ok := m.userFamily[userID]; ok {
+		return m.GetFamilyForMember(fid, userID)
+	}
+	families, _ := m.GetUserFamilies(userID)
+	if len(families) == 0 {
 		return handler.MockFamily{}, handler.ErrFamilyNotFound
 	}
-	f, ok := m.families[fid]
-	if !ok {
+	return families[0], nil
+}
+
+func (m *mockFamilyDB) GetFamilyForMember(familyID, userID string) (handler.MockFamily, error) {
+	f, ok := m.families[familyID]
+	if !ok || !m.isMember(familyID, userID) {
 		return handler.MockFamily{}, handler.ErrFamilyNotFound
 	}
-	return *f, nil
+	family := *f
+	family.Role = m.roleOf(familyID, userID)
+	return family, nil
+}
+
+// roleOf returns the user's role from the member list; users without one are
+// the owner if they administer the family and members otherwise.
+func (m *mockFamilyDB) roleOf(familyID, userID string) string {
+	for _, mem := range m.members[familyID] {
+		if mem.UserID == userID && mem.Role != "" {
+			return mem.Role
+		}
+	}
+	if f, ok := m.families[familyID]; ok && f.AdminUserID == userID {
+		return handler.RoleOwner
+	}
+	return handler.RoleMember
+}
+
+func (m *mockFamilyDB) GetUserFamilies(userID string) ([]handler.MockFamily, error) {
+	var result []handler.MockFamily
+	for id := range m.families {
+		if f, err := m.GetFamilyForMember(id, userID); err == nil {
+			result = append(result, f)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (m *mockFamilyDB) SetActiveFamily(userID, familyID string) error {
+	if _, err := m.GetFamilyForMember(familyID, userID); err != nil {
+		return err
+	}
+	m.userFamily[userID] = familyID
+	return nil
 }
 
 func (m *mockFamilyDB) GetFamilyMembers(familyID string) ([]handler.MockFamilyMember, error) {
@@ -71,7 +136,6 @@ func (m *mockFamilyDB) AddFamilyMember(familyID, userID, role string) error {
 		Role:     role,
 		JoinedAt: time.Now(),
 	})
-	m.userFamily[userID] = familyID
 	m.memberCount[familyID]++
 	return nil
 }
@@ -79,9 +143,11 @@ func (m *mockFamilyDB) AddFamilyMember(familyID, userID, role string) error {
 func (m *mockFamilyDB) RemoveFamilyMember(familyID, userID string) (int64, error) {
 	members := m.members[familyID]
 	for i, mem := range members {
-		if mem.UserID == userID && mem.Role != "admin" {
+		if mem.UserID == userID && mem.Role != handler.RoleOwner {
 			m.members[familyID] = append(members[:i], members[i+1:]...)
-			delete(m.userFamily, userID)
+			if m.userFamily[userID] == familyID {
+				delete(m.userFamily, userID)
+			}
 			m.memberCount[familyID]--
 			return 1, nil
 		}
@@ -89,6 +155,37 @@ func (m *mockFamilyDB) RemoveFamilyMember(familyID, userID string) (int64, error
 	return 0, nil
 }
 
+func (m *mockFamilyDB) SetMemberRole(familyID, userID, role string) error {
+	for i, mem := range m.members[familyID] {
+		if mem.UserID == userID {
+			m.members[familyID][i].Role = role
+			return nil
+		}
+	}
+	return handler.ErrFamilyMemberNotFound
+}
+
+func (m *mockFamilyDB) TransferOwnership(familyID, fromUserID, toUserID string) error {
+	f, ok := m.families[familyID]
+	if !ok || f.AdminUserID != fromUserID {
+		return handler.ErrFamilyNotFound
+	}
+	if err := m.SetMemberRole(familyID, toUserID, handler.RoleOwner); err != nil {
+		return err
+	}
+	m.SetMemberRole(familyID, fromUserID, handler.RoleAdmin)
+	f.AdminUserID = toUserID
+	return nil
+}
+
+func (m *mockFamilyDB) TransferOwnershipAndLeave(familyID, fromUserID, toUserID string) error {
+	if err := m.TransferOwnership(familyID, fromUserID, toUserID); err != nil {
+		return err
+	}
+	m.RemoveFamilyMember(familyID, fromUserID)
+	return nil
+}
+
 func (m *mockFamilyDB) DeleteFamily(familyID, adminUserID string) (int64, error) {
 	f, ok := m.families[familyID]
 	if !ok || f.AdminUserID != adminUserID {
@@ -96,7 +193,9 @@ func (m *mockFamilyDB) DeleteFamily(familyID, adminUserID string) (int64, error)
 	}
 	// Clean up members
 	for _, mem := range m.members[familyID] {
-		delete(m.userFamily, mem.UserID)
+		if m.userFamily[mem.UserID] == familyID {
+			delete(m.userFamily, mem.UserID)
+		}
 	}
 	delete(m.families, familyID)
 	delete(m.members, familyID)
@@ -108,16 +207,20 @@ func (m *mockFamilyDB) GetFamilyMemberCount(familyID string) (int64, error) {
 	return m.memberCount[familyID], nil
 }
 
-func (m *mockFamilyDB) CreateInvitation(familyID, inviterUserID, tokenHash string, expiresAt time.Time) (handler.MockInvitation, error) {
+func (m *mockFamilyDB) CreateInvitation(familyID, inviterUserID, email, tokenHash string, expiresAt time.Time) (handler.MockInvitation, error) {
 	inv := handler.MockInvitation{
-		ID:            "inv-1",
+		ID:            fmt.Sprintf("inv-%d", len(m.invitations)+1),
 		FamilyID:      familyID,
 		InviterUserID: inviterUserID,
+		Email:         email,
 		TokenHash:     tokenHash,
 		Status:        "pending",
 		ExpiresAt:     expiresAt,
 		CreatedAt:     time.Now(),
 	}
+	if f, ok := m.families[familyID]; ok {
+		inv.FamilyName = f.Name
+	}
 	m.invitations[tokenHash] = &inv
 	return inv, nil
 }
@@ -130,8 +233,27 @@ func (m *mockFamilyDB) GetInvitationByTokenHash(tokenHash string) (handler.MockI
 	return *inv, nil
 }
 
-func (m *mockFamilyDB) AcceptInvitation(invitationID string) (int64, error) {
-	return 1, nil
+func (m *mockFamilyDB) GetInvitationByID(invitationID string) (handler.MockInvitation, error) {
+	for _, inv := range m.invitations {
+		if inv.ID == invitationID {
+			return *inv, nil
+		}
+	}
+	return handler.MockInvitation{}, handler.ErrInvitationNotFound
+}
+
+func (m *mockFamilyDB) AcceptInvitation(invitationID, familyID, userID, role string) error {
+	for hash, inv := range m.invitations {
+		if inv.ID == invitationID && inv.FamilyID == familyID {
+			if m.memberCount[familyID] >= 10 {
+				return handler.ErrFamilyFull
+			}
+			m.AddFamilyMember(familyID, userID, role)
+			delete(m.invitations, hash)
+			return nil
+		}
+	}
+	return handler.ErrInvitationNotFound
 }
 
 func (m *mockFamilyDB) RevokeInvitation(invitationID, familyID string) (int64, error) {
@@ -146,13 +268,87 @@ func (m *mockFamilyDB) RevokeInvitation(invitationID, familyID string) (int64, e
 }
 
 func (m *mockFamilyDB) GetPendingInvitations(familyID string) ([]handler.MockPendingInvitation, error) {
-	return m.pending[familyID], nil
+	var result []handler.MockPendingInvitation
+	for _, inv := range m.invitations {
+		if inv.FamilyID == familyID {
+			result = append(result, pendingInvitation(inv))
+		}
+	}
+	return result, nil
+}
+
+func (m *mockFamilyDB) GetInvitationsForEmail(email string) ([]handler.MockPendingInvitation, error) {
+	var result []handler.MockPendingInvitation
+	for _, inv := range m.invitations {
+		if inv.Email != "" && strings.EqualFold(inv.Email, email) {
+			p := pendingInvitation(inv)
+			p.FamilyName = inv.FamilyName
+			p.InviterEmail = inv.InviterUserID + "@test.com"
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func pendingInvitation(inv *handler.MockInvitation) handler.MockPendingInvitation {
+	return handler.MockPendingInvitation{
+		ID:            inv.ID,
+		FamilyID:      inv.FamilyID,
+		InviterUserID: inv.InviterUserID,
+		Email:         inv.Email,
+		Status:        inv.Status,
+		ExpiresAt:     inv.ExpiresAt,
+		CreatedAt:     inv.CreatedAt,
+	}
+}
+
+// GetUserEmail matches the emails AddFamilyMember gives members.
+func (m *mockFamilyDB) GetUserEmail(userID string) (string, error) {
+	return userID + "@test.com", nil
+}
+
+// mockMailer implements mailer.Mailer, recording sent messages.
+type mockMailer struct {
+	sent []mailer.Message
+	err  error
+}
+
+func (m *mockMailer) Send(ctx context.Context, msg mailer.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// mailedToken returns the invitation token from the link in the last mail
+// m sent.
+func mailedToken(t *testing.T, m *mockMailer) string {
+	t.Helper()
+	if len(m.sent) == 0 {
+		t.Fatal("no invitation mail was sent")
+	}
+	body := m.sent[len(m.sent)-1].Body
+	i := strings.Index(body, "/invitations/")
+	if i < 0 {
+		t.Fatalf("mail has no invitation link: %q", body)
+	}
+	return strings.Fields(body[i+len("/invitations/"):])[0]
 }
 
 func setupFamilyRouter(db handler.FamilyDB) *gin.Engine {
+	return setupFamilyRouterWith(db, newMockActivityDB(), &mockMailer{})
+}
+
+func setupFamilyRouterWithMailer(db handler.FamilyDB, m mailer.Mailer) *gin.Engine {
+	return setupFamilyRouterWith(db, newMockActivityDB(), m)
+}
+
+func setupFamilyRouterWith(db handler.FamilyDB, activity handler.ActivityDB, m mailer.Mailer) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	h := handler.NewFamilyHandler(db)
+	h := handler.NewFamilyHandler(db, activity, m, "https://app.example.com/")
 
 	// Simulate auth middleware by setting user_id
 	families := r.Group("/api/v1/families", func(c *gin.Context) {
@@ -161,18 +357,24 @@ func setupFamilyRouter(db handler.FamilyDB) *gin.Engine {
 	})
 	{
 		families.POST("", h.CreateFamily)
-		families.GET("/me", h.GetMyFamily)
-		families.DELETE("/me", h.DeleteMyFamily)
-		families.DELETE("/me/members/:userId", h.RemoveMember)
-		families.POST("/me/leave", h.LeaveFamily)
-		families.POST("/me/invitations", h.CreateInvitation)
-		families.DELETE("/me/invitations/:id", h.RevokeInvitation)
+		families.GET("", h.ListFamilies)
+		families.PUT("/active", h.SetActiveFamily)
+		families.GET("/:familyId", h.GetMyFamily)
+		families.DELETE("/:familyId", h.DeleteMyFamily)
+		families.DELETE("/:familyId/members/:userId", h.RemoveMember)
+		families.POST("/:familyId/members/:userId/promote", h.PromoteMember)
+		families.POST("/:familyId/members/:userId/demote", h.DemoteMember)
+		families.POST("/:familyId/transfer-ownership", h.TransferOwnership)
+		families.POST("/:familyId/leave", h.LeaveFamily)
+		families.POST("/:familyId/invitations", h.CreateInvitation)
+		families.DELETE("/:familyId/invitations/:id", h.RevokeInvitation)
 	}
 	invitations := r.Group("/api/v1/invitations", func(c *gin.Context) {
 		c.Set("user_id", c.GetHeader("X-User-ID"))
 		c.Next()
 	})
 	{
+		invitations.GET("", h.ListInvitations)
 		invitations.GET("/:token", h.GetInvitationInfo)
 		invitations.POST("/accept", h.AcceptInvitation)
 	}
@@ -202,7 +404,7 @@ func TestCreateFamily(t *testing.T) {
 		}
 	})
 
-	t.Run("already in family", func(t *testing.T) {
+	t.Run("second family becomes active", func(t *testing.T) {
 		db := newMockFamilyDB()
 		r := setupFamilyRouter(db)
 
@@ -214,7 +416,7 @@ func TestCreateFamily(t *testing.T) {
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
-		// Try to create second family
+		// Create second family
 		body, _ = json.Marshal(map[string]string{"name": "Family 2"})
 		req = httptest.NewRequest(http.MethodPost, "/api/v1/families", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
@@ -222,8 +424,11 @@ func TestCreateFamily(t *testing.T) {
 		w = httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
-		if w.Code != http.StatusConflict {
-			t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		if db.userFamily["user-1"] != "family-2" {
+			t.Fatalf("expected family-2 to be active, got %q", db.userFamily["user-1"])
 		}
 	})
 }
@@ -328,9 +533,10 @@ func TestDeleteMyFamily(t *testing.T) {
 }
 
 func TestCreateInvitation(t *testing.T) {
-	t.Run("success returns raw token", func(t *testing.T) {
+	t.Run("success mails the token without returning it", func(t *testing.T) {
 		db := newMockFamilyDB()
-		r := setupFamilyRouter(db)
+		m := &mockMailer{}
+		r := setupFamilyRouterWithMailer(db, m)
 
 		// Create family
 		body, _ := json.Marshal(map[string]string{"name": "Smith Family"})
@@ -341,7 +547,7 @@ func TestCreateInvitation(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		// Create invitation
-		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", nil)
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", inviteBody("user-2@test.com"))
 		req.Header.Set("X-User-ID", "user-1")
 		w = httptest.NewRecorder()
 		r.ServeHTTP(w, req)
@@ -352,10 +558,10 @@ func TestCreateInvitation(t *testing.T) {
 
 		var resp map[string]any
 		json.Unmarshal(w.Body.Bytes(), &resp)
-		token, ok := resp["token"].(string)
-		if !ok || token == "" {
-			t.Fatal("response should include raw token")
+		if _, ok := resp["token"]; ok {
+			t.Fatal("response must not include the invitation token")
 		}
+		token := mailedToken(t, m)
 		// Token should be 64 hex chars (32 bytes)
 		if len(token) != 64 {
 			t.Fatalf("expected token length 64, got %d", len(token))
@@ -378,7 +584,7 @@ func TestCreateInvitation(t *testing.T) {
 		db.memberCount["family-1"] = 10
 
 		// Try to create invitation
-		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", nil)
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", inviteBody("user-2@test.com"))
 		req.Header.Set("X-User-ID", "user-1")
 		w = httptest.NewRecorder()
 		r.ServeHTTP(w, req)
@@ -392,7 +598,8 @@ func TestCreateInvitation(t *testing.T) {
 func TestAcceptInvitation(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		db := newMockFamilyDB()
-		r := setupFamilyRouter(db)
+		m := &mockMailer{}
+		r := setupFamilyRouterWithMailer(db, m)
 
 		// Create family as user-1
 		body, _ := json.Marshal(map[string]string{"name": "Smith Family"})
@@ -403,14 +610,12 @@ func TestAcceptInvitation(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		// Create invitation
-		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", nil)
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", inviteBody("user-2@test.com"))
 		req.Header.Set("X-User-ID", "user-1")
 		w = httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
-		var invResp map[string]any
-		json.Unmarshal(w.Body.Bytes(), &invResp)
-		token := invResp["token"].(string)
+		token := mailedToken(t, m)
 
 		// Accept invitation as user-2
 		body, _ = json.Marshal(map[string]string{"token": token})
@@ -442,9 +647,51 @@ func TestAcceptInvitation(t *testing.T) {
 		}
 	})
 
+	t.Run("family filled after invitation", func(t *testing.T) {
+		db := newMockFamilyDB()
+		m := &mockMailer{}
+		r := setupFamilyRouterWithMailer(db, m)
+
+		// Create family as user-1
+		body, _ := json.Marshal(map[string]string{"name": "Smith Family"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/families", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "user-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		// Create invitation
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", inviteBody("user-2@test.com"))
+		req.Header.Set("X-User-ID", "user-1")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		token := mailedToken(t, m)
+		db.memberCount["family-1"] = 10
+
+		// Accept invitation as user-2
+		body, _ = json.Marshal(map[string]string{"token": token})
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/invitations/accept", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "user-2")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+		if db.isMember("family-1", "user-2") {
+			t.Fatal("expected user-2 not to be added to a full family")
+		}
+		if len(db.invitations) != 1 {
+			t.Fatalf("expected invitation to stay pending, got %d", len(db.invitations))
+		}
+	})
+
 	t.Run("user already in family", func(t *testing.T) {
 		db := newMockFamilyDB()
-		r := setupFamilyRouter(db)
+		m := &mockMailer{}
+		r := setupFamilyRouterWithMailer(db, m)
 
 		// Create family as user-1
 		body, _ := json.Marshal(map[string]string{"name": "Smith Family"})
@@ -455,14 +702,12 @@ func TestAcceptInvitation(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		// Create invitation
-		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", nil)
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", inviteBody("user-2@test.com"))
 		req.Header.Set("X-User-ID", "user-1")
 		w = httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
-		var invResp map[string]any
-		json.Unmarshal(w.Body.Bytes(), &invResp)
-		token := invResp["token"].(string)
+		token := mailedToken(t, m)
 
 		// Try to accept as user-1 (already in family)
 		body, _ = json.Marshal(map[string]string{"token": token})
@@ -543,7 +788,7 @@ func TestRevokeInvitation(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		// Create invitation
-		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", nil)
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/families/me/invitations", inviteBody("user-2@test.com"))
 		req.Header.Set("X-User-ID", "user-1")
 		w = httptest.NewRecorder()
 		r.ServeHTTP(w, req)
@@ -638,3 +883,381 @@ func TestRemoveMember(t *testing.T) {
 		}
 	})
 }
+
+func TestMultipleFamilies(t *testing.T) {
+	// user-1 creates family-1 and family-2; user-2 only belongs to family-2.
+	newFamilies := func() (*mockFamilyDB, *gin.Engine) {
+		db := newMockFamilyDB()
+		r := setupFamilyRouter(db)
+		for _, name := range []string{"Home", "Parents"} {
+			body, _ := json.Marshal(map[string]string{"name": name})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/families", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-User-ID", "user-1")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		db.AddFamilyMember("family-2", "user-2", "member")
+		return db, r
+	}
+
+	t.Run("address family by ID", func(t *testing.T) {
+		_, r := newFamilies()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/families/family-1", nil)
+		req.Header.Set("X-User-ID", "user-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["family"]["name"] != "Home" {
+			t.Fatalf("expected Home, got %v", resp["family"]["name"])
+		}
+	})
+
+	t.Run("non-member gets 404", func(t *testing.T) {
+		_, r := newFamilies()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/families/family-1", nil)
+		req.Header.Set("X-User-ID", "user-2")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("list flags active family and role", func(t *testing.T) {
+		_, r := newFamilies()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/families", nil)
+		req.Header.Set("X-User-ID", "user-2")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp []map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp) != 1 || resp[0]["id"] != "family-2" || resp[0]["role"] != "member" || resp[0]["is_active"] != true {
+			t.Fatalf("unexpected families for user-2: %v", resp)
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/families", nil)
+		req.Header.Set("X-User-ID", "user-1")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp) != 2 || resp[0]["is_active"] != false || resp[1]["is_active"] != true || resp[1]["role"] != "owner" {
+			t.Fatalf("unexpected families for user-1: %v", resp)
+		}
+	})
+
+	t.Run("switch active family", func(t *testing.T) {
+		_, r := newFamilies()
+
+		body, _ := json.Marshal(map[string]string{"family_id": "family-1"})
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/families/active", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "user-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/families/me", nil)
+		req.Header.Set("X-User-ID", "user-1")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp map[string]map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["family"]["id"] != "family-1" {
+			t.Fatalf("expected /me to resolve to family-1, got %v", resp["family"]["id"])
+		}
+	})
+
+	t.Run("cannot switch to a family you are not in", func(t *testing.T) {
+		_, r := newFamilies()
+
+		body, _ := json.Marshal(map[string]string{"family_id": "family-1"})
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/families/active", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "user-2")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("join a second family by invitation", func(t *testing.T) {
+		db, _ := newFamilies()
+		m := &mockMailer{}
+		r := setupFamilyRouterWithMailer(db, m)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/families/family-1/invitations", inviteBody("user-2@test.com"))
+		req.Header.Set("X-User-ID", "user-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		token := mailedToken(t, m)
+
+		body, _ := json.Marshal(map[string]string{"token": token})
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/invitations/accept", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "user-2")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if families, _ := db.GetUserFamilies("user-2"); len(families) != 2 {
+			t.Fatalf("expected user-2 in 2 families, got %d", len(families))
+		}
+	})
+}
+
+func inviteBody(email string) io.Reader {
+	body, _ := json.Marshal(map[string]string{"email": email})
+	return bytes.NewReader(body)
+}
+
+func postFamilyJSON(r *gin.Engine, path, userID string, payload map[string]string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestPromoteAndDemoteMember(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   string
+		userID   string
+		target   string
+		want     int
+		wantRole string
+	}{
+		{"admin promotes member", "promote", "user-2", "user-3", http.StatusOK, handler.RoleAdmin},
+		{"admin promotes viewer", "promote", "user-2", "user-4", http.StatusOK, handler.RoleAdmin},
+		{"member cannot promote", "promote", "user-3", "user-4", http.StatusForbidden, handler.RoleViewer},
+		{"already admin", "promote", "user-1", "user-2", http.StatusConflict, handler.RoleAdmin},
+		{"unknown member", "promote", "user-1", "user-9", http.StatusNotFound, ""},
+		{"owner demotes admin", "demote", "user-1", "user-2", http.StatusOK, handler.RoleMember},
+		{"admin cannot demote", "demote", "user-2", "user-2", http.StatusForbidden, handler.RoleAdmin},
+		{"cannot demote a non-admin", "demote", "user-1", "user-3", http.StatusConflict, handler.RoleMember},
+		{"cannot demote the owner", "demote", "user-1", "user-1", http.StatusConflict, handler.RoleOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newRolesFamily()
+			r := setupFamilyRouter(db)
+
+			w := familyRequest(r, http.MethodPost, "/api/v1/families/family-1/members/"+tt.target+"/"+tt.action, tt.userID)
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+			if tt.wantRole != "" {
+				if role := db.roleOf("family-1", tt.target); role != tt.wantRole {
+					t.Fatalf("expected role %q, got %q", tt.wantRole, role)
+				}
+			}
+		})
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	t.Run("owner hands over to a member", func(t *testing.T) {
+		db := newRolesFamily()
+		r := setupFamilyRouter(db)
+
+		w := postFamilyJSON(r, "/api/v1/families/family-1/transfer-ownership", "user-1", map[string]string{"user_id": "user-3"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if db.families["family-1"].AdminUserID != "user-3" {
+			t.Fatalf("expected user-3 to own the family, got %s", db.families["family-1"].AdminUserID)
+		}
+		if db.roleOf("family-1", "user-3") != handler.RoleOwner || db.roleOf("family-1", "user-1") != handler.RoleAdmin {
+			t.Fatalf("unexpected roles: user-1=%s user-3=%s", db.roleOf("family-1", "user-1"), db.roleOf("family-1", "user-3"))
+		}
+	})
+
+	t.Run("admin cannot transfer", func(t *testing.T) {
+		r := setupFamilyRouter(newRolesFamily())
+		w := postFamilyJSON(r, "/api/v1/families/family-1/transfer-ownership", "user-2", map[string]string{"user_id": "user-3"})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("invalid targets", func(t *testing.T) {
+		for target, want := range map[string]int{
+			"":       http.StatusBadRequest,
+			"user-1": http.StatusBadRequest,
+			"user-9": http.StatusNotFound,
+		} {
+			r := setupFamilyRouter(newRolesFamily())
+			w := postFamilyJSON(r, "/api/v1/families/family-1/transfer-ownership", "user-1", map[string]string{"user_id": target})
+			if w.Code != want {
+				t.Fatalf("target %q: expected %d, got %d: %s", target, want, w.Code, w.Body.String())
+			}
+		}
+	})
+}
+
+func TestLeaveFamily_OwnerSuccessor(t *testing.T) {
+	t.Run("owner leaves with a successor", func(t *testing.T) {
+		db := newRolesFamily()
+		r := setupFamilyRouter(db)
+
+		w := postFamilyJSON(r, "/api/v1/families/family-1/leave", "user-1", map[string]string{"successor_user_id": "user-2"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if db.families["family-1"].AdminUserID != "user-2" {
+			t.Fatalf("expected user-2 to own the family, got %s", db.families["family-1"].AdminUserID)
+		}
+		if db.isMember("family-1", "user-1") {
+			t.Fatal("previous owner should have left")
+		}
+	})
+
+	t.Run("unknown successor", func(t *testing.T) {
+		db := newRolesFamily()
+		r := setupFamilyRouter(db)
+
+		w := postFamilyJSON(r, "/api/v1/families/family-1/leave", "user-1", map[string]string{"successor_user_id": "user-9"})
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+		if !db.isMember("family-1", "user-1") || db.families["family-1"].AdminUserID != "user-1" {
+			t.Fatal("owner should still own the family")
+		}
+	})
+}
+
+func TestEmailInvitations(t *testing.T) {
+	newInviteFamily := func(m *mockMailer) (*mockFamilyDB, *gin.Engine) {
+		db := newMockFamilyDB()
+		r := setupFamilyRouterWithMailer(db, m)
+		postFamilyJSON(r, "/api/v1/families", "user-1", map[string]string{"name": "Smith Family"})
+		return db, r
+	}
+	invite := func(r *gin.Engine, email string) *httptest.ResponseRecorder {
+		return postFamilyJSON(r, "/api/v1/families/me/invitations", "user-1", map[string]string{"email": email})
+	}
+
+	t.Run("mails a link to the invitee", func(t *testing.T) {
+		m := &mockMailer{}
+		_, r := newInviteFamily(m)
+
+		w := invite(r, "User-2@Test.com")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["email"] != "user-2@test.com" {
+			t.Fatalf("expected normalized email, got %v", resp["email"])
+		}
+		if len(m.sent) != 1 || m.sent[0].To != "user-2@test.com" {
+			t.Fatalf("expected one mail to user-2@test.com, got %+v", m.sent)
+		}
+		if _, ok := resp["token"]; ok {
+			t.Fatal("response must not include the invitation token")
+		}
+		link := "https://app.example.com/invitations/" + mailedToken(t, m)
+		if !strings.Contains(m.sent[0].Body, link) {
+			t.Fatalf("mail body should contain %s: %q", link, m.sent[0].Body)
+		}
+	})
+
+	t.Run("rejects bad and duplicate emails", func(t *testing.T) {
+		_, r := newInviteFamily(&mockMailer{})
+
+		if w := invite(r, "not-an-email"); w.Code != http.StatusBadRequest {
+			t.Fatalf("invalid email: expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := invite(r, "user-1@test.com"); w.Code != http.StatusConflict {
+			t.Fatalf("member email: expected 409, got %d: %s", w.Code, w.Body.String())
+		}
+		invite(r, "user-2@test.com")
+		if w := invite(r, "USER-2@test.com"); w.Code != http.StatusConflict {
+			t.Fatalf("pending email: expected 409, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("failed delivery revokes the invitation", func(t *testing.T) {
+		db, r := newInviteFamily(&mockMailer{err: errors.New("connection refused")})
+
+		if w := invite(r, "user-2@test.com"); w.Code != http.StatusBadGateway {
+			t.Fatalf("expected 502, got %d: %s", w.Code, w.Body.String())
+		}
+		if len(db.invitations) != 0 {
+			t.Fatalf("expected invitation to be revoked, got %d", len(db.invitations))
+		}
+	})
+
+	t.Run("only the invited email can accept", func(t *testing.T) {
+		m := &mockMailer{}
+		_, r := newInviteFamily(m)
+		invite(r, "user-2@test.com")
+
+		w := postFamilyJSON(r, "/api/v1/invitations/accept", "user-3", map[string]string{"token": mailedToken(t, m)})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("invitee lists and accepts by id", func(t *testing.T) {
+		_, r := newInviteFamily(&mockMailer{})
+		invite(r, "user-2@test.com")
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/invitations", nil)
+		req.Header.Set("X-User-ID", "user-2")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var list []map[string]any
+		json.Unmarshal(w.Body.Bytes(), &list)
+		if len(list) != 1 || list[0]["family_name"] != "Smith Family" || list[0]["inviter_email"] != "user-1@test.com" {
+			t.Fatalf("unexpected invitations: %s", w.Body.String())
+		}
+
+		w = postFamilyJSON(r, "/api/v1/invitations/accept", "user-3", map[string]string{"invitation_id": list[0]["id"].(string)})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("other user: expected 403, got %d: %s", w.Code, w.Body.String())
+		}
+		w = postFamilyJSON(r, "/api/v1/invitations/accept", "user-2", map[string]string{"invitation_id": list[0]["id"].(string)})
+		if w.Code != http.StatusOK {
+			t.Fatalf("invitee: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("legacy invitation without email", func(t *testing.T) {
+		db, r := newInviteFamily(&mockMailer{})
+		hash := sha256.Sum256([]byte("legacy-token"))
+		db.CreateInvitation("family-1", "user-1", "", hex.EncodeToString(hash[:]), time.Now().Add(time.Hour))
+
+		w := postFamilyJSON(r, "/api/v1/invitations/accept", "user-3", map[string]string{"invitation_id": "inv-1"})
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("by id: expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+		w = postFamilyJSON(r, "/api/v1/invitations/accept", "user-3", map[string]string{"token": "legacy-token"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("by token: expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +9,7 @@ import (
 )
 
 // FamilyExpense represents a single expense in the family feed.
+// Private expenses have no category or tags and an empty note.
 type FamilyExpense struct {
 	ID            string
 	UserID        string
@@ -19,9 +19,13 @@ type FamilyExpense struct {
 	CategoryColor string
 	CategoryIcon  string
 	AmountCents   int64
+	Currency      string
 	Note          string
 	ExpenseDate   time.Time
 	CreatedAt     time.Time
+	IsSplit       bool
+	IsPrivate     bool
+	Tags          []string
 }
 
 // FamilyMemberTotal represents per-user expense totals.
@@ -33,54 +37,86 @@ type FamilyMemberTotal struct {
 }
 
 // FamilyCategoryTotal represents per-category expense totals for a family.
+// Aggregated private expenses are totalled under an empty CategoryID.
+// ParentID is empty for top-level categories.
 type FamilyCategoryTotal struct {
 	CategoryID    string
 	CategoryName  string
 	CategoryColor string
 	CategoryIcon  string
+	ParentID      string
 	TotalCents    int64
 	Count         int
 }
 
 // FamilyViewDB abstracts database operations for family expense views.
+// Expense totals are converted to the family's base currency. Members'
+// private expenses are left out or aggregated according to the family's
+// PrivateExpenses setting. Rollup totals are per top-level category and
+// include the expenses of its subcategories.
 type FamilyViewDB interface {
-	GetFamilyExpenses(familyID string, limit, offset int) ([]FamilyExpense, error)
+	GetFamilyExpenses(familyID string, filter ExpenseFilter, page ExpensePage) ([]FamilyExpense, error)
+	GetFamilyExpenseTotals(familyID string, filter ExpenseFilter) (ExpenseTotals, error)
 	GetFamilyMemberTotals(familyID string, dateFrom, dateTo time.Time) ([]FamilyMemberTotal, error)
 	GetFamilyCategoryTotals(familyID string, dateFrom, dateTo time.Time) ([]FamilyCategoryTotal, error)
+	GetFamilyCategoryRollupTotals(familyID string, dateFrom, dateTo time.Time) ([]FamilyCategoryTotal, error)
+	GetFamilyIncomeTotal(familyID string, dateFrom, dateTo time.Time) (int64, error)
+	GetFamilyExpensesForExport(familyID string, filter ExpenseFilter, after *ExpenseCursor, limit int) ([]FamilyExpense, error)
 }
 
 // FamilyViewHandler handles family view HTTP requests.
 type FamilyViewHandler struct {
 	familyDB FamilyDB
 	viewDB   FamilyViewDB
+	budgetDB BudgetDB
 }
 
 // NewFamilyViewHandler creates a FamilyViewHandler with the given databases.
-func NewFamilyViewHandler(familyDB FamilyDB, viewDB FamilyViewDB) *FamilyViewHandler {
-	return &FamilyViewHandler{familyDB: familyDB, viewDB: viewDB}
+func NewFamilyViewHandler(familyDB FamilyDB, viewDB FamilyViewDB, budgetDB BudgetDB) *FamilyViewHandler {
+	return &FamilyViewHandler{familyDB: familyDB, viewDB: viewDB, budgetDB: budgetDB}
 }
 
-// FamilyFeed handles GET /api/v1/families/me/expenses.
+// FamilyFeed handles GET /api/v1/families/:familyId/expenses.
+// It accepts the same filter, cursor and include_totals parameters as
+// ExpenseHandler.List; totals are in the family's base currency.
 func (h *FamilyViewHandler) FamilyFeed(c *gin.Context) {
-	userID := c.GetString("user_id")
-
-	family, err := h.familyDB.GetFamilyByUserID(userID)
-	if err != nil {
-		if errors.Is(err, ErrFamilyNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
 		return
 	}
 
 	limit, offset := parsePagination(c)
 
-	expenses, err := h.viewDB.GetFamilyExpenses(family.ID, limit, offset)
+	filter, before, ok := parseListQuery(c)
+	if !ok {
+		return
+	}
+	if before != nil {
+		offset = 0
+	}
+
+	expenses, err := h.viewDB.GetFamilyExpenses(family.ID, filter, ExpensePage{Limit: limit + 1, Offset: offset, Before: before})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
+	if len(expenses) > limit {
+		expenses = expenses[:limit]
+		last := expenses[limit-1]
+		// Relevance-ranked search results cannot be continued by a keyset cursor.
+		if filter.Query == "" {
+			c.Header(HeaderNextCursor, ExpenseCursor{ExpenseDate: last.ExpenseDate, CreatedAt: last.CreatedAt, ID: last.ID}.Encode())
+		}
+	}
+
+	if wantTotals(c) {
+		totals, err := h.viewDB.GetFamilyExpenseTotals(family.ID, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		setTotalHeaders(c, totals)
+	}
 
 	result := make([]gin.H, len(expenses))
 	for i, e := range expenses {
@@ -93,55 +129,73 @@ func (h *FamilyViewHandler) FamilyFeed(c *gin.Context) {
 			"category_color": e.CategoryColor,
 			"category_icon":  e.CategoryIcon,
 			"amount_cents":   e.AmountCents,
+			"currency":       e.Currency,
 			"note":           e.Note,
 			"expense_date":   e.ExpenseDate.Format("2006-01-02"),
+			"is_split":       e.IsSplit,
+			"is_private":     e.IsPrivate,
+			"tags":           tagsJSON(e.Tags),
 		}
 	}
 
 	c.JSON(http.StatusOK, result)
 }
 
-// FamilySummary handles GET /api/v1/families/me/summary.
+// FamilySummary handles GET /api/v1/families/:familyId/summary.
 func (h *FamilyViewHandler) FamilySummary(c *gin.Context) {
-	userID := c.GetString("user_id")
-
-	family, err := h.familyDB.GetFamilyByUserID(userID)
-	if err != nil {
-		if errors.Is(err, ErrFamilyNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "no family"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
 		return
 	}
 
-	month := c.Query("month")
-	if month == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "month query parameter is required (format: YYYY-MM)"})
+	period, compare, ok := parseSummaryPeriod(c)
+	if !ok {
 		return
 	}
+	dateFrom, dateTo := period.From, period.To
 
-	parsed, err := time.Parse("2006-01", month)
+	memberTotals, err := h.viewDB.GetFamilyMemberTotals(family.ID, dateFrom, dateTo)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "month query parameter is required (format: YYYY-MM)"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	dateFrom := parsed
-	dateTo := time.Date(parsed.Year(), parsed.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	categoryTotals, err := h.viewDB.GetFamilyCategoryTotals(family.ID, dateFrom, dateTo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
 
-	memberTotals, err := h.viewDB.GetFamilyMemberTotals(family.ID, dateFrom, dateTo)
+	rollupTotals, err := h.viewDB.GetFamilyCategoryRollupTotals(family.ID, dateFrom, dateTo)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	categoryTotals, err := h.viewDB.GetFamilyCategoryTotals(family.ID, dateFrom, dateTo)
+	incomeCents, err := h.viewDB.GetFamilyIncomeTotal(family.ID, dateFrom, dateTo)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	var previousTotals []FamilyCategoryTotal
+	if compare != nil {
+		previousTotals, err = h.viewDB.GetFamilyCategoryTotals(family.ID, compare.From, compare.To)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+	}
+
+	var budgets []MockBudget
+	if period.Kind == PeriodMonth {
+		budgets, err = h.budgetDB.GetFamilyBudgets(family.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+	}
+
 	var totalCents int64
 	for _, mt := range memberTotals {
 		totalCents += mt.TotalCents
@@ -159,21 +213,74 @@ func (h *FamilyViewHandler) FamilySummary(c *gin.Context) {
 
 	byCategory := make([]gin.H, len(categoryTotals))
 	for i, ct := range categoryTotals {
+		var categoryID, parentID any
+		if ct.CategoryID != "" {
+			categoryID = ct.CategoryID
+		}
+		if ct.ParentID != "" {
+			parentID = ct.ParentID
+		}
 		byCategory[i] = gin.H{
-			"category_id":    ct.CategoryID,
+			"category_id":    categoryID,
 			"category_name":  ct.CategoryName,
 			"category_color": ct.CategoryColor,
 			"category_icon":  ct.CategoryIcon,
+			"parent_id":      parentID,
 			"total_cents":    ct.TotalCents,
 			"expense_count":  ct.Count,
+			"is_private":     ct.CategoryID == "",
+		}
+	}
+
+	byParentCategory := make([]gin.H, len(rollupTotals))
+	for i, rt := range rollupTotals {
+		var categoryID any
+		if rt.CategoryID != "" {
+			categoryID = rt.CategoryID
+		}
+		byParentCategory[i] = gin.H{
+			"category_id":    categoryID,
+			"category_name":  rt.CategoryName,
+			"category_color": rt.CategoryColor,
+			"category_icon":  rt.CategoryIcon,
+			"total_cents":    rt.TotalCents,
+			"expense_count":  rt.Count,
+			"is_private":     rt.CategoryID == "",
 		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"total_cents": totalCents,
-		"by_person":   byPerson,
-		"by_category": byCategory,
-	})
+	resp := gin.H{
+		"period":             period.JSON(),
+		"currency":           family.BaseCurrency,
+		"total_cents":        totalCents,
+		"expense_cents":      totalCents,
+		"income_cents":       incomeCents,
+		"net_cents":          incomeCents - totalCents,
+		"by_person":          byPerson,
+		"by_category":        byCategory,
+		"by_parent_category": byParentCategory,
+		"budgets":            nil,
+	}
+	if period.Kind == PeriodMonth {
+		resp["month"] = period.From.Format("2006-01")
+		spent := spentByCategory(familyTotalsAsCategoryTotals(categoryTotals), familyTotalsAsCategoryTotals(rollupTotals))
+		resp["budgets"] = budgetStatus(budgets, spent, totalCents)
+	}
+	if compare != nil {
+		resp["comparison"] = periodComparison(*compare, familyTotalsAsCategoryTotals(categoryTotals), familyTotalsAsCategoryTotals(previousTotals))
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// familyTotalsAsCategoryTotals converts family category totals for use with
+// the shared summary helpers.
+func familyTotalsAsCategoryTotals(totals []FamilyCategoryTotal) []CategoryTotal {
+	out := make([]CategoryTotal, len(totals))
+	for i, ft := range totals {
+		out[i] = CategoryTotal(ft)
+	}
+	return out
 }
 
 // parsePagination extracts limit and offset from query params with defaults.
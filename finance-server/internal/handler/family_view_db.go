@@ -18,13 +18,23 @@ func NewPgFamilyViewDB(queries *sqlc.Queries) *PgFamilyViewDB {
 	return &PgFamilyViewDB{queries: queries}
 }
 
-func (db *PgFamilyViewDB) GetFamilyExpenses(familyID string, limit, offset int) ([]FamilyExpense, error) {
-	fid := stringToUUID(familyID)
+func (db *PgFamilyViewDB) GetFamilyExpenses(familyID string, filter ExpenseFilter, page ExpensePage) ([]FamilyExpense, error) {
+	beforeID, beforeDate, beforeCreatedAt := cursorParams(page.Before)
 
 	rows, err := db.queries.GetFamilyExpenses(context.Background(), sqlc.GetFamilyExpensesParams{
-		FamilyID: fid,
-		Limit:    int32(limit),
-		Offset:   int32(offset),
+		FamilyID:        stringToUUID(familyID),
+		Limit:           int32(page.Limit),
+		Offset:          int32(page.Offset),
+		DateFrom:        dateToPgDate(filter.DateFrom),
+		DateTo:          dateToPgDate(filter.DateTo),
+		CategoryID:      stringToNullableUUID(filter.CategoryID),
+		Query:           stringToNullableText(filter.Query),
+		MinAmount:       int64ToPgInt8(filter.MinAmountCents),
+		MaxAmount:       int64ToPgInt8(filter.MaxAmountCents),
+		Tags:            filter.Tags,
+		BeforeID:        beforeID,
+		BeforeDate:      beforeDate,
+		BeforeCreatedAt: beforeCreatedAt,
 	})
 	if err != nil {
 		return nil, err
@@ -41,14 +51,49 @@ func (db *PgFamilyViewDB) GetFamilyExpenses(familyID string, limit, offset int)
 			CategoryColor: row.CategoryColor,
 			CategoryIcon:  row.CategoryIcon,
 			AmountCents:   row.AmountCents,
+			Currency:      row.Currency,
 			Note:          row.Note,
 			ExpenseDate:   row.ExpenseDate.Time,
 			CreatedAt:     row.CreatedAt.Time,
+			IsSplit:       row.IsSplit,
+			IsPrivate:     row.IsPrivate,
 		}
 	}
+
+	// Tags are not shown for private expenses, like their category and note.
+	var ids []string
+	for _, e := range expenses {
+		if !e.IsPrivate {
+			ids = append(ids, e.ID)
+		}
+	}
+	tags, err := expenseTags(context.Background(), db.queries, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range expenses {
+		expenses[i].Tags = tags[expenses[i].ID]
+	}
 	return expenses, nil
 }
 
+func (db *PgFamilyViewDB) GetFamilyExpenseTotals(familyID string, filter ExpenseFilter) (ExpenseTotals, error) {
+	row, err := db.queries.GetFamilyExpenseTotals(context.Background(), sqlc.GetFamilyExpenseTotalsParams{
+		FamilyID:   stringToUUID(familyID),
+		DateFrom:   dateToPgDate(filter.DateFrom),
+		DateTo:     dateToPgDate(filter.DateTo),
+		CategoryID: stringToNullableUUID(filter.CategoryID),
+		Query:      stringToNullableText(filter.Query),
+		MinAmount:  int64ToPgInt8(filter.MinAmountCents),
+		MaxAmount:  int64ToPgInt8(filter.MaxAmountCents),
+		Tags:       filter.Tags,
+	})
+	if err != nil {
+		return ExpenseTotals{}, err
+	}
+	return ExpenseTotals{Count: row.ExpenseCount, TotalCents: row.TotalCents}, nil
+}
+
 func (db *PgFamilyViewDB) GetFamilyMemberTotals(familyID string, dateFrom, dateTo time.Time) ([]FamilyMemberTotal, error) {
 	fid := stringToUUID(familyID)
 
@@ -92,9 +137,83 @@ func (db *PgFamilyViewDB) GetFamilyCategoryTotals(familyID string, dateFrom, dat
 			CategoryName:  row.CategoryName,
 			CategoryColor: row.CategoryColor,
 			CategoryIcon:  row.CategoryIcon,
+			ParentID:      uuidToString(row.ParentID),
 			TotalCents:    row.TotalCents,
 			Count:         int(row.ExpenseCount),
 		}
 	}
 	return totals, nil
 }
+
+func (db *PgFamilyViewDB) GetFamilyCategoryRollupTotals(familyID string, dateFrom, dateTo time.Time) ([]FamilyCategoryTotal, error) {
+	fid := stringToUUID(familyID)
+
+	rows, err := db.queries.GetFamilyCategoryRollupTotals(context.Background(), sqlc.GetFamilyCategoryRollupTotalsParams{
+		FamilyID:      fid,
+		ExpenseDate:   pgtype.Date{Time: dateFrom, Valid: true},
+		ExpenseDate_2: pgtype.Date{Time: dateTo, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]FamilyCategoryTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = FamilyCategoryTotal{
+			CategoryID:    uuidToString(row.CategoryID),
+			CategoryName:  row.CategoryName,
+			CategoryColor: row.CategoryColor,
+			CategoryIcon:  row.CategoryIcon,
+			TotalCents:    row.TotalCents,
+			Count:         int(row.ExpenseCount),
+		}
+	}
+	return totals, nil
+}
+
+func (db *PgFamilyViewDB) GetFamilyIncomeTotal(familyID string, dateFrom, dateTo time.Time) (int64, error) {
+	fid := stringToUUID(familyID)
+
+	return db.queries.GetFamilyIncomeTotal(context.Background(), sqlc.GetFamilyIncomeTotalParams{
+		FamilyID:     fid,
+		IncomeDate:   pgtype.Date{Time: dateFrom, Valid: true},
+		IncomeDate_2: pgtype.Date{Time: dateTo, Valid: true},
+	})
+}
+
+func (db *PgFamilyViewDB) GetFamilyExpensesForExport(familyID string, filter ExpenseFilter, after *ExpenseCursor, limit int) ([]FamilyExpense, error) {
+	afterID, afterDate, afterCreatedAt := cursorParams(after)
+
+	rows, err := db.queries.GetFamilyExpensesForExport(context.Background(), sqlc.GetFamilyExpensesForExportParams{
+		FamilyID:       stringToUUID(familyID),
+		Limit:          int32(limit),
+		DateFrom:       dateToPgDate(filter.DateFrom),
+		DateTo:         dateToPgDate(filter.DateTo),
+		CategoryID:     stringToNullableUUID(filter.CategoryID),
+		AfterID:        afterID,
+		AfterDate:      afterDate,
+		AfterCreatedAt: afterCreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expenses := make([]FamilyExpense, len(rows))
+	for i, row := range rows {
+		expenses[i] = FamilyExpense{
+			ID:            uuidToString(row.ID),
+			UserID:        uuidToString(row.UserID),
+			UserEmail:     row.UserEmail,
+			CategoryID:    uuidToString(row.CategoryID),
+			CategoryName:  row.CategoryName,
+			CategoryColor: row.CategoryColor,
+			CategoryIcon:  row.CategoryIcon,
+			AmountCents:   row.AmountCents,
+			Currency:      row.Currency,
+			Note:          row.Note,
+			ExpenseDate:   row.ExpenseDate.Time,
+			CreatedAt:     row.CreatedAt.Time,
+		}
+	}
+	return expenses, nil
+}
@@ -16,13 +16,43 @@ type mockFamilyViewDB struct {
 	expenses       []handler.FamilyExpense
 	memberTotals   []handler.FamilyMemberTotal
 	categoryTotals []handler.FamilyCategoryTotal
+	rollupTotals   []handler.FamilyCategoryTotal
+	incomeTotal    int64
+	lastPage       handler.ExpensePage
+
+	categoryTotalsFrom map[string][]handler.FamilyCategoryTotal
 }
 
-func (m *mockFamilyViewDB) GetFamilyExpenses(familyID string, limit, offset int) ([]handler.FamilyExpense, error) {
+func (m *mockFamilyViewDB) GetFamilyExpenses(familyID string, filter handler.ExpenseFilter, page handler.ExpensePage) ([]handler.FamilyExpense, error) {
+	m.lastPage = page
 	if m.expenses == nil {
 		return []handler.FamilyExpense{}, nil
 	}
-	return m.expenses, nil
+	result := m.expenses
+	if page.Before != nil {
+		for i, e := range result {
+			if e.ID == page.Before.ID {
+				result = result[i+1:]
+				break
+			}
+		}
+	}
+	if page.Offset >= len(result) {
+		return []handler.FamilyExpense{}, nil
+	}
+	result = result[page.Offset:]
+	if page.Limit < len(result) {
+		result = result[:page.Limit]
+	}
+	return result, nil
+}
+
+func (m *mockFamilyViewDB) GetFamilyExpenseTotals(familyID string, filter handler.ExpenseFilter) (handler.ExpenseTotals, error) {
+	totals := handler.ExpenseTotals{Count: int64(len(m.expenses))}
+	for _, e := range m.expenses {
+		totals.TotalCents += e.AmountCents
+	}
+	return totals, nil
 }
 
 func (m *mockFamilyViewDB) GetFamilyMemberTotals(familyID string, dateFrom, dateTo time.Time) ([]handler.FamilyMemberTotal, error) {
@@ -33,16 +63,34 @@ func (m *mockFamilyViewDB) GetFamilyMemberTotals(familyID string, dateFrom, date
 }
 
 func (m *mockFamilyViewDB) GetFamilyCategoryTotals(familyID string, dateFrom, dateTo time.Time) ([]handler.FamilyCategoryTotal, error) {
+	if totals, ok := m.categoryTotalsFrom[dateFrom.Format("2006-01-02")]; ok {
+		return totals, nil
+	}
 	if m.categoryTotals == nil {
 		return []handler.FamilyCategoryTotal{}, nil
 	}
 	return m.categoryTotals, nil
 }
 
-func setupFamilyViewRouter(familyDB handler.FamilyDB, viewDB handler.FamilyViewDB) *gin.Engine {
+func (m *mockFamilyViewDB) GetFamilyCategoryRollupTotals(familyID string, dateFrom, dateTo time.Time) ([]handler.FamilyCategoryTotal, error) {
+	return m.rollupTotals, nil
+}
+
+func (m *mockFamilyViewDB) GetFamilyIncomeTotal(familyID string, dateFrom, dateTo time.Time) (int64, error) {
+	return m.incomeTotal, nil
+}
+
+func (m *mockFamilyViewDB) GetFamilyExpensesForExport(familyID string, filter handler.ExpenseFilter, after *handler.ExpenseCursor, limit int) ([]handler.FamilyExpense, error) {
+	if after != nil || m.expenses == nil {
+		return []handler.FamilyExpense{}, nil
+	}
+	return m.expenses, nil
+}
+
+func setupFamilyViewRouter(familyDB handler.FamilyDB, viewDB handler.FamilyViewDB, budgetDB handler.BudgetDB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	h := handler.NewFamilyViewHandler(familyDB, viewDB)
+	h := handler.NewFamilyViewHandler(familyDB, viewDB, budgetDB)
 
 	families := r.Group("/api/v1/families", func(c *gin.Context) {
 		c.Set("user_id", c.GetHeader("X-User-ID"))
@@ -95,7 +143,7 @@ func TestFamilyFeed_Success(t *testing.T) {
 		},
 	}
 
-	r := setupFamilyViewRouter(fdb, viewDB)
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses", nil)
 	req.Header.Set("X-User-ID", "user-1")
@@ -131,7 +179,7 @@ func TestFamilyFeedNoFamily(t *testing.T) {
 	fdb := newMockFamilyDB()
 	viewDB := &mockFamilyViewDB{}
 
-	r := setupFamilyViewRouter(fdb, viewDB)
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses", nil)
 	req.Header.Set("X-User-ID", "user-1")
@@ -160,7 +208,7 @@ func TestFamilyFeed_Empty(t *testing.T) {
 
 	viewDB := &mockFamilyViewDB{}
 
-	r := setupFamilyViewRouter(fdb, viewDB)
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses", nil)
 	req.Header.Set("X-User-ID", "user-1")
@@ -201,7 +249,7 @@ func TestFamilySummary_Success(t *testing.T) {
 		},
 	}
 
-	r := setupFamilyViewRouter(fdb, viewDB)
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/summary?month=2026-03", nil)
 	req.Header.Set("X-User-ID", "user-1")
@@ -236,6 +284,47 @@ func TestFamilySummary_Success(t *testing.T) {
 	}
 }
 
+func TestFamilySummary_IncomeAndNet(t *testing.T) {
+	fdb := newMockFamilyDB()
+	fdb.families["family-1"] = &handler.MockFamily{
+		ID:          "family-1",
+		Name:        "Smith Family",
+		AdminUserID: "user-1",
+	}
+	fdb.userFamily["user-1"] = "family-1"
+
+	viewDB := &mockFamilyViewDB{
+		memberTotals: []handler.FamilyMemberTotal{
+			{UserID: "user-1", UserEmail: "user1@test.com", TotalCents: 45000, Count: 12},
+		},
+		incomeTotal: 300000,
+	}
+
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/summary?month=2026-03", nil)
+	req.Header.Set("X-User-ID", "user-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp["expense_cents"] != float64(45000) {
+		t.Fatalf("expected expense_cents 45000, got %v", resp["expense_cents"])
+	}
+	if resp["income_cents"] != float64(300000) {
+		t.Fatalf("expected income_cents 300000, got %v", resp["income_cents"])
+	}
+	if resp["net_cents"] != float64(255000) {
+		t.Fatalf("expected net_cents 255000, got %v", resp["net_cents"])
+	}
+}
+
 func TestFamilySummary_MissingMonth(t *testing.T) {
 	fdb := newMockFamilyDB()
 	fdb.families["family-1"] = &handler.MockFamily{
@@ -247,7 +336,7 @@ func TestFamilySummary_MissingMonth(t *testing.T) {
 
 	viewDB := &mockFamilyViewDB{}
 
-	r := setupFamilyViewRouter(fdb, viewDB)
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/summary", nil)
 	req.Header.Set("X-User-ID", "user-1")
@@ -270,7 +359,7 @@ func TestFamilySummary_NoFamily(t *testing.T) {
 	fdb := newMockFamilyDB()
 	viewDB := &mockFamilyViewDB{}
 
-	r := setupFamilyViewRouter(fdb, viewDB)
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/summary?month=2026-03", nil)
 	req.Header.Set("X-User-ID", "user-1")
@@ -293,7 +382,7 @@ func TestFamilySummary_Empty(t *testing.T) {
 
 	viewDB := &mockFamilyViewDB{}
 
-	r := setupFamilyViewRouter(fdb, viewDB)
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/summary?month=2026-03", nil)
 	req.Header.Set("X-User-ID", "user-1")
@@ -327,3 +416,162 @@ func TestFamilySummary_Empty(t *testing.T) {
 		t.Fatalf("expected empty by_category, got %d", len(byCategory))
 	}
 }
+
+func TestFamilyFeed_CursorAndTotals(t *testing.T) {
+	fdb := newMockFamilyDB()
+	fdb.families["family-1"] = &handler.MockFamily{ID: "family-1", Name: "Smith Family", AdminUserID: "user-1"}
+	fdb.userFamily["user-1"] = "family-1"
+
+	viewDB := &mockFamilyViewDB{}
+	for i, id := range []string{"exp-3", "exp-2", "exp-1"} {
+		viewDB.expenses = append(viewDB.expenses, handler.FamilyExpense{
+			ID:          id,
+			UserID:      "user-1",
+			AmountCents: 1000,
+			ExpenseDate: time.Date(2026, 3, 3-i, 0, 0, 0, 0, time.UTC),
+			CreatedAt:   time.Date(2026, 3, 3-i, 12, 0, 0, 0, time.UTC),
+		})
+	}
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses?limit=2&include_totals=true", nil)
+	req.Header.Set("X-User-ID", "user-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp) != 2 {
+		t.Fatalf("expected 2 expenses, got %d", len(resp))
+	}
+	if w.Header().Get(handler.HeaderTotalCount) != "3" || w.Header().Get(handler.HeaderTotalCents) != "3000" {
+		t.Fatalf("unexpected totals: count=%q cents=%q", w.Header().Get(handler.HeaderTotalCount), w.Header().Get(handler.HeaderTotalCents))
+	}
+	next := w.Header().Get(handler.HeaderNextCursor)
+	if next == "" {
+		t.Fatal("expected a next cursor")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses?limit=2&offset=5&cursor="+next, nil)
+	req.Header.Set("X-User-ID", "user-1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp) != 1 || resp[0]["id"] != "exp-1" {
+		t.Fatalf("expected only exp-1 on the second page, got %v", resp)
+	}
+	if viewDB.lastPage.Before == nil || viewDB.lastPage.Before.ID != "exp-2" || viewDB.lastPage.Offset != 0 {
+		t.Fatalf("unexpected page: %+v", viewDB.lastPage)
+	}
+	if w.Header().Get(handler.HeaderNextCursor) != "" {
+		t.Fatal("expected no next cursor on the last page")
+	}
+}
+
+func TestFamilyView_PrivateExpenses(t *testing.T) {
+	fdb := newMockFamilyDB()
+	fdb.families["family-1"] = &handler.MockFamily{
+		ID:              "family-1",
+		Name:            "Smith Family",
+		AdminUserID:     "user-1",
+		PrivateExpenses: handler.PrivateExpensesAggregate,
+	}
+	fdb.userFamily["user-1"] = "family-1"
+
+	viewDB := &mockFamilyViewDB{
+		expenses: []handler.FamilyExpense{
+			{ID: "exp-1", UserID: "user-2", AmountCents: 4000, ExpenseDate: time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), IsPrivate: true},
+		},
+		memberTotals: []handler.FamilyMemberTotal{
+			{UserID: "user-2", UserEmail: "user2@test.com", TotalCents: 4000, Count: 1},
+		},
+		categoryTotals: []handler.FamilyCategoryTotal{
+			{TotalCents: 4000, Count: 1},
+		},
+	}
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
+
+	t.Run("feed", func(t *testing.T) {
+		w := familyRequest(r, http.MethodGet, "/api/v1/families/me/expenses", "user-1")
+		var resp []map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp) != 1 || resp[0]["is_private"] != true || resp[0]["note"] != "" {
+			t.Fatalf("unexpected feed: %v", resp)
+		}
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		w := familyRequest(r, http.MethodGet, "/api/v1/families/me/summary?month=2026-03", "user-1")
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["total_cents"] != float64(4000) {
+			t.Fatalf("expected private expenses in the total, got %v", resp["total_cents"])
+		}
+		byCategory := resp["by_category"].([]any)
+		row := byCategory[0].(map[string]any)
+		if row["category_id"] != nil || row["is_private"] != true {
+			t.Fatalf("expected an uncategorized private row, got %v", row)
+		}
+	})
+}
+
+func TestFamilySummary_PeriodComparison(t *testing.T) {
+	fdb := newMockFamilyDB()
+	fdb.families["family-1"] = &handler.MockFamily{
+		ID:          "family-1",
+		Name:        "Smith Family",
+		AdminUserID: "user-1",
+	}
+	fdb.userFamily["user-1"] = "family-1"
+
+	viewDB := &mockFamilyViewDB{
+		memberTotals: []handler.FamilyMemberTotal{
+			{UserID: "user-1", UserEmail: "user1@test.com", TotalCents: 9000, Count: 3},
+		},
+		categoryTotals: []handler.FamilyCategoryTotal{
+			{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 6000, Count: 2},
+			{CategoryName: "Private", TotalCents: 3000, Count: 1},
+		},
+		categoryTotalsFrom: map[string][]handler.FamilyCategoryTotal{
+			"2026-03-09": {
+				{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 8000, Count: 3},
+			},
+		},
+	}
+
+	r := setupFamilyViewRouter(fdb, viewDB, newMockBudgetDB())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/summary?period=week&date=2026-03-18&compare=previous", nil)
+	req.Header.Set("X-User-ID", "user-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	period := resp["period"].(map[string]any)
+	if period["kind"] != "week" || period["from"] != "2026-03-16" || period["to"] != "2026-03-22" {
+		t.Fatalf("unexpected period: %v", period)
+	}
+	if resp["budgets"] != nil {
+		t.Fatalf("budgets should only be reported for month periods, got %v", resp["budgets"])
+	}
+
+	comparison := resp["comparison"].(map[string]any)
+	if comparison["total_cents"] != float64(8000) || comparison["delta_cents"] != float64(1000) {
+		t.Fatalf("unexpected comparison totals: %v", comparison)
+	}
+	byCategory := comparison["by_category"].([]any)
+	if len(byCategory) != 2 {
+		t.Fatalf("expected 2 comparison rows, got %d", len(byCategory))
+	}
+	private := byCategory[1].(map[string]any)
+	if private["category_id"] != nil || private["delta_cents"] != float64(3000) {
+		t.Fatalf("expected private row with nil category_id, got %v", private)
+	}
+}
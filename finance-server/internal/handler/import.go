@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/importer"
+)
+
+const (
+	// maxImportFileSize bounds uploaded statement files.
+	maxImportFileSize = 5 << 20
+	// maxImportRows bounds the number of rows accepted in one commit.
+	maxImportRows = 5000
+)
+
+// ImportHandler handles bank statement import HTTP requests.
+// Imports are two-step: Preview parses an uploaded file without writing
+// anything, and Commit inserts the reviewed rows in one transaction.
+type ImportHandler struct {
+	expenseDB ExpenseDB
+	ruleDB    CategoryRuleDB
+}
+
+// NewImportHandler creates an ImportHandler with the given databases.
+func NewImportHandler(expenseDB ExpenseDB, ruleDB CategoryRuleDB) *ImportHandler {
+	return &ImportHandler{expenseDB: expenseDB, ruleDB: ruleDB}
+}
+
+// Preview handles POST /api/v1/expenses/import/preview.
+// It expects a multipart form with a "file" field plus optional "format"
+// (csv, ofx, qif; detected from the file name when omitted), CSV column
+// mapping fields and a "default_category_id" for rows no rule matches.
+func (h *ImportHandler) Preview(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	format := strings.ToLower(c.PostForm("format"))
+	if format == "" {
+		format = importer.DetectFormat(fileHeader.Filename)
+	}
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of: csv, ofx, qif"})
+		return
+	}
+
+	opts, err := importOptionsFromForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	defer file.Close()
+
+	parsed, err := importer.Parse(format, file, opts)
+	if err != nil {
+		if errors.Is(err, importer.ErrUnsupportedFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of: csv, ofx, qif"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not parse file: " + err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	ruleList, err := h.ruleDB.GetCategoryRulesByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	rules := newCategoryRules(ruleList)
+
+	items := make([]NewExpense, len(parsed.Rows))
+	for i, row := range parsed.Rows {
+		items[i] = NewExpense{AmountCents: row.AmountCents, Note: row.Note, ExpenseDate: row.Date}
+	}
+	duplicates, err := h.findDuplicates(userID, items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	defaultCategoryID := c.PostForm("default_category_id")
+	duplicateCount := 0
+	rows := make([]gin.H, len(parsed.Rows))
+	for i, row := range parsed.Rows {
+		var categoryID, ruleID, tag any
+		if rule, ok := rules.match(row.Note, row.AmountCents); ok {
+			categoryID = rule.CategoryID
+			ruleID = rule.ID
+			if rule.Tag != "" {
+				tag = rule.Tag
+			}
+		} else if defaultCategoryID != "" {
+			categoryID = defaultCategoryID
+		}
+		if duplicates[i] {
+			duplicateCount++
+		}
+
+		rows[i] = gin.H{
+			"line":         row.Line,
+			"expense_date": row.Date.Format("2006-01-02"),
+			"amount_cents": row.AmountCents,
+			"note":         row.Note,
+			"category_id":  categoryID,
+			"rule_id":      ruleID,
+			"tag":          tag,
+			"duplicate":    duplicates[i],
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"format":     format,
+		"rows":       rows,
+		"total":      len(rows),
+		"duplicates": duplicateCount,
+		"skipped":    parsed.Skipped,
+	})
+}
+
+type importRow struct {
+	CategoryID  string   `json:"category_id"`
+	AmountCents int64    `json:"amount_cents"`
+	Note        string   `json:"note"`
+	ExpenseDate string   `json:"expense_date"`
+	Tags        []string `json:"tags"`
+}
+
+type commitImportRequest struct {
+	Rows []importRow `json:"rows"`
+	// SkipDuplicates defaults to true.
+	SkipDuplicates *bool `json:"skip_duplicates"`
+}
+
+// Commit handles POST /api/v1/expenses/import.
+// Rows without a category_id are categorized by the user's rules, which
+// also add their tag. All rows are inserted in one transaction, so either
+// everything is imported or nothing is.
+func (h *ImportHandler) Commit(c *gin.Context) {
+	var req commitImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if len(req.Rows) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rows must not be empty"})
+		return
+	}
+	if len(req.Rows) > maxImportRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d rows can be imported at once", maxImportRows)})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	ruleList, err := h.ruleDB.GetCategoryRulesByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	rules := newCategoryRules(ruleList)
+
+	items := make([]NewExpense, len(req.Rows))
+	for i, row := range req.Rows {
+		if row.AmountCents <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rows[%d]: amount_cents must be greater than 0", i)})
+			return
+		}
+		expenseDate, err := time.Parse("2006-01-02", row.ExpenseDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rows[%d]: expense_date must be in YYYY-MM-DD format", i)})
+			return
+		}
+		categoryID := row.CategoryID
+		tags := normalizeTags(row.Tags)
+		if categoryID == "" {
+			if rule, ok := rules.match(row.Note, row.AmountCents); ok {
+				categoryID = rule.CategoryID
+				if rule.Tag != "" {
+					tags = normalizeTags(append(tags, rule.Tag))
+				}
+			}
+		}
+		if categoryID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rows[%d]: category_id is required", i)})
+			return
+		}
+		if err := checkTags(tags); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rows[%d]: %s", i, err)})
+			return
+		}
+		items[i] = NewExpense{
+			CategoryID:  categoryID,
+			AmountCents: row.AmountCents,
+			Note:        strings.TrimSpace(row.Note),
+			ExpenseDate: expenseDate,
+			Tags:        tags,
+		}
+	}
+
+	skipped := 0
+	if req.SkipDuplicates == nil || *req.SkipDuplicates {
+		duplicates, err := h.findDuplicates(userID, items)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		kept := items[:0]
+		for i, item := range items {
+			if duplicates[i] {
+				skipped++
+				continue
+			}
+			kept = append(kept, item)
+		}
+		items = kept
+	}
+
+	created := []MockExpense{}
+	if len(items) > 0 {
+		created, err = h.expenseDB.CreateExpenses(userID, items)
+		if err != nil {
+			if errors.Is(err, ErrInvalidExpenseCategory) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+	}
+
+	result := make([]gin.H, len(created))
+	for i, exp := range created {
+		result[i] = gin.H{
+			"id":           exp.ID,
+			"category_id":  exp.CategoryID,
+			"amount_cents": exp.AmountCents,
+			"note":         exp.Note,
+			"expense_date": exp.ExpenseDate.Format("2006-01-02"),
+			"tags":         tagsJSON(exp.Tags),
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"imported":           len(created),
+		"duplicates_skipped": skipped,
+		"expenses":           result,
+	})
+}
+
+// findDuplicates reports, for each item, whether it matches an existing
+// expense by date, amount and note (case- and whitespace-insensitive).
+// Each existing expense absorbs at most one item, so two identical
+// purchases on the same day are only flagged if both already exist.
+func (h *ImportHandler) findDuplicates(userID string, items []NewExpense) ([]bool, error) {
+	duplicates := make([]bool, len(items))
+	if len(items) == 0 {
+		return duplicates, nil
+	}
+
+	dateFrom, dateTo := items[0].ExpenseDate, items[0].ExpenseDate
+	for _, item := range items[1:] {
+		if item.ExpenseDate.Before(dateFrom) {
+			dateFrom = item.ExpenseDate
+		}
+		if item.ExpenseDate.After(dateTo) {
+			dateTo = item.ExpenseDate
+		}
+	}
+
+	existing, err := h.expenseDB.GetExpenseFingerprints(userID, dateFrom, dateTo)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(existing))
+	for _, fp := range existing {
+		counts[fingerprintKey(fp.ExpenseDate, fp.AmountCents, fp.Note)]++
+	}
+
+	for i, item := range items {
+		key := fingerprintKey(item.ExpenseDate, item.AmountCents, item.Note)
+		if counts[key] > 0 {
+			counts[key]--
+			duplicates[i] = true
+		}
+	}
+	return duplicates, nil
+}
+
+func fingerprintKey(date time.Time, amountCents int64, note string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(note)), " ")
+	return date.Format("2006-01-02") + "|" + strconv.FormatInt(amountCents, 10) + "|" + normalized
+}
+
+// importOptionsFromForm reads the CSV mapping and date format fields.
+func importOptionsFromForm(c *gin.Context) (importer.Options, error) {
+	mapping := importer.CSVMapping{
+		DateColumn:       c.DefaultPostForm("date_column", "date"),
+		AmountColumn:     c.DefaultPostForm("amount_column", "amount"),
+		NoteColumn:       c.PostForm("note_column"),
+		DateFormat:       c.PostForm("date_format"),
+		NoHeader:         c.PostForm("no_header") == "true",
+		ExpensesPositive: c.PostForm("expenses_positive") == "true",
+	}
+
+	switch d := c.PostForm("delimiter"); d {
+	case "":
+	case "tab", "\\t", "\t":
+		mapping.Delimiter = '\t'
+	case ",", ";", "|":
+		mapping.Delimiter = rune(d[0])
+	default:
+		return importer.Options{}, errors.New("delimiter must be one of: , ; | tab")
+	}
+
+	switch sep := c.PostForm("decimal_separator"); sep {
+	case "":
+	case ".", ",":
+		mapping.DecimalSeparator = sep[0]
+	default:
+		return importer.Options{}, errors.New("decimal_separator must be . or ,")
+	}
+
+	return importer.Options{Mapping: mapping, DateFormat: c.PostForm("date_format")}, nil
+}
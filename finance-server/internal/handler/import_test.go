@@ -0,0 +1,375 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+const (
+	testFoodCategoryID      = "550e8400-e29b-41d4-a716-446655440001"
+	testTransportCategoryID = "550e8400-e29b-41d4-a716-446655440002"
+)
+
+// mockCategoryRuleDB implements handler.CategoryRuleDB for testing.
+type mockCategoryRuleDB struct {
+	rules  []handler.MockCategoryRule
+	nextID int
+}
+
+func newMockCategoryRuleDB() *mockCategoryRuleDB {
+	return &mockCategoryRuleDB{
+		rules:  make([]handler.MockCategoryRule, 0),
+		nextID: 1,
+	}
+}
+
+func (m *mockCategoryRuleDB) CreateCategoryRule(userID string, nr handler.NewCategoryRule) (handler.MockCategoryRule, error) {
+	if nr.CategoryID == testIncomeCategoryID {
+		return handler.MockCategoryRule{}, handler.ErrInvalidRuleCategory
+	}
+	if nr.MatchType == "" {
+		nr.MatchType = handler.RuleMatchContains
+	}
+	rule := handler.MockCategoryRule{
+		ID:             "rule-" + string(rune('0'+m.nextID)),
+		CategoryID:     nr.CategoryID,
+		MatchType:      nr.MatchType,
+		Pattern:        nr.Pattern,
+		MinAmountCents: nr.MinAmountCents,
+		MaxAmountCents: nr.MaxAmountCents,
+		Tag:            nr.Tag,
+		Priority:       nr.Priority,
+		CreatedAt:      time.Now(),
+	}
+	m.nextID++
+	// Keep rules ordered by priority like the real query.
+	i := 0
+	for i < len(m.rules) && m.rules[i].Priority >= nr.Priority {
+		i++
+	}
+	m.rules = append(m.rules[:i], append([]handler.MockCategoryRule{rule}, m.rules[i:]...)...)
+	return rule, nil
+}
+
+func (m *mockCategoryRuleDB) GetCategoryRulesByUser(userID string) ([]handler.MockCategoryRule, error) {
+	return m.rules, nil
+}
+
+func (m *mockCategoryRuleDB) DeleteCategoryRule(id, userID string) error {
+	for i, rule := range m.rules {
+		if rule.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return nil
+		}
+	}
+	return handler.ErrCategoryRuleNotFound
+}
+
+func setupImportRouter(expenseDB handler.ExpenseDB, ruleDB handler.CategoryRuleDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewImportHandler(expenseDB, ruleDB)
+	rh := handler.NewCategoryRuleHandler(ruleDB)
+
+	api := r.Group("/api/v1")
+	api.Use(func(c *gin.Context) {
+		c.Set("user_id", testUserID)
+		c.Next()
+	})
+	{
+		api.POST("/expenses/import/preview", h.Preview)
+		api.POST("/expenses/import", h.Commit)
+		api.POST("/category-rules", rh.Create)
+		api.GET("/category-rules", rh.List)
+		api.DELETE("/category-rules/:id", rh.Delete)
+	}
+	return r
+}
+
+func previewRequest(t *testing.T, filename, content string, fields map[string]string) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(content))
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses/import/preview", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestImportPreview_CSV(t *testing.T) {
+	expenseDB := newMockExpenseDB()
+	expenseDB.CreateExpense(testUserID, testFoodCategoryID, 350, "", "", "Coffee  shop", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), nil)
+	ruleDB := newMockCategoryRuleDB()
+	ruleDB.CreateCategoryRule(testUserID, handler.NewCategoryRule{CategoryID: testTransportCategoryID, Pattern: "uber"})
+	r := setupImportRouter(expenseDB, ruleDB)
+
+	csv := "Date,Description,Amount\n" +
+		"2026-03-01,COFFEE SHOP,-3.50\n" +
+		"2026-03-02,Uber Trip,-18.20\n" +
+		"2026-03-03,Refund,5.00\n" +
+		"2026-03-04,Bookstore,-12.00\n"
+	req := previewRequest(t, "statement.csv", csv, map[string]string{
+		"note_column":         "Description",
+		"default_category_id": testFoodCategoryID,
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Format     string           `json:"format"`
+		Rows       []map[string]any `json:"rows"`
+		Duplicates int              `json:"duplicates"`
+		Skipped    int              `json:"skipped"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp.Format != "csv" || len(resp.Rows) != 3 || resp.Skipped != 1 {
+		t.Fatalf("unexpected preview: %s", w.Body.String())
+	}
+	if resp.Duplicates != 1 || resp.Rows[0]["duplicate"] != true {
+		t.Fatalf("expected first row flagged as duplicate: %s", w.Body.String())
+	}
+	if resp.Rows[1]["category_id"] != testTransportCategoryID || resp.Rows[1]["rule_id"] != "rule-1" {
+		t.Fatalf("expected rule to categorize Uber row: %v", resp.Rows[1])
+	}
+	if resp.Rows[2]["category_id"] != testFoodCategoryID || resp.Rows[2]["rule_id"] != nil {
+		t.Fatalf("expected default category for unmatched row: %v", resp.Rows[2])
+	}
+	if len(expenseDB.expenses) != 1 {
+		t.Fatalf("preview must not write expenses, got %d", len(expenseDB.expenses))
+	}
+}
+
+func TestImportPreview_OFXDetectedFromFilename(t *testing.T) {
+	r := setupImportRouter(newMockExpenseDB(), newMockCategoryRuleDB())
+
+	ofx := "<OFX><STMTTRN><DTPOSTED>20260305<TRNAMT>-9.99<NAME>Music</STMTTRN></OFX>"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, previewRequest(t, "bank.ofx", ofx, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["format"] != "ofx" || resp["total"] != float64(1) {
+		t.Fatalf("unexpected preview: %s", w.Body.String())
+	}
+}
+
+func TestImportPreview_BadInput(t *testing.T) {
+	r := setupImportRouter(newMockExpenseDB(), newMockCategoryRuleDB())
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		fields   map[string]string
+	}{
+		{"unknown format", "statement.txt", "x", nil},
+		{"unparseable csv", "statement.csv", "date,amount\nyesterday,-1\n", nil},
+		{"bad delimiter", "statement.csv", "date,amount\n", map[string]string{"delimiter": "#"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, previewRequest(t, tt.filename, tt.content, tt.fields))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportCommit(t *testing.T) {
+	expenseDB := newMockExpenseDB()
+	expenseDB.CreateExpense(testUserID, testFoodCategoryID, 350, "", "", "Coffee shop", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), nil)
+	ruleDB := newMockCategoryRuleDB()
+	ruleDB.CreateCategoryRule(testUserID, handler.NewCategoryRule{CategoryID: testTransportCategoryID, Pattern: "uber"})
+	r := setupImportRouter(expenseDB, ruleDB)
+
+	body, _ := json.Marshal(map[string]any{
+		"rows": []map[string]any{
+			{"expense_date": "2026-03-01", "amount_cents": 350, "note": "coffee shop", "category_id": testFoodCategoryID},
+			{"expense_date": "2026-03-02", "amount_cents": 1820, "note": "Uber trip"},
+			{"expense_date": "2026-03-04", "amount_cents": 1200, "note": "Bookstore", "category_id": testFoodCategoryID},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["imported"] != float64(2) || resp["duplicates_skipped"] != float64(1) {
+		t.Fatalf("unexpected commit result: %s", w.Body.String())
+	}
+	if len(expenseDB.expenses) != 3 {
+		t.Fatalf("expected 3 expenses after import, got %d", len(expenseDB.expenses))
+	}
+	if expenseDB.expenses[1].CategoryID != testTransportCategoryID {
+		t.Fatalf("expected rule category on imported row, got %s", expenseDB.expenses[1].CategoryID)
+	}
+}
+
+func TestImportCommit_Validation(t *testing.T) {
+	r := setupImportRouter(newMockExpenseDB(), newMockCategoryRuleDB())
+
+	tests := []struct {
+		name string
+		rows []map[string]any
+	}{
+		{"empty", []map[string]any{}},
+		{"missing category", []map[string]any{{"expense_date": "2026-03-01", "amount_cents": 100, "note": "x"}}},
+		{"invalid amount", []map[string]any{{"expense_date": "2026-03-01", "amount_cents": 0, "category_id": testFoodCategoryID}}},
+		{"invalid date", []map[string]any{{"expense_date": "01.03.2026", "amount_cents": 100, "category_id": testFoodCategoryID}}},
+		{"income category", []map[string]any{{"expense_date": "2026-03-01", "amount_cents": 100, "category_id": testIncomeCategoryID}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]any{"rows": tt.rows})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/expenses/import", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCategoryRules(t *testing.T) {
+	ruleDB := newMockCategoryRuleDB()
+	r := setupImportRouter(newMockExpenseDB(), ruleDB)
+
+	body, _ := json.Marshal(map[string]any{"category_id": testTransportCategoryID, "pattern": "  taxi ", "tag": " Rides "})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/category-rules", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if ruleDB.rules[0].Pattern != "taxi" {
+		t.Fatalf("expected trimmed pattern, got %q", ruleDB.rules[0].Pattern)
+	}
+	if ruleDB.rules[0].Tag != "rides" {
+		t.Fatalf("expected normalized tag, got %q", ruleDB.rules[0].Tag)
+	}
+
+	body, _ = json.Marshal(map[string]any{"category_id": testTransportCategoryID, "pattern": " "})
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/category-rules", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/category-rules/rule-1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCategoryRules_Validation(t *testing.T) {
+	r := setupImportRouter(newMockExpenseDB(), newMockCategoryRuleDB())
+
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"unknown match type", map[string]any{"category_id": testTransportCategoryID, "pattern": "taxi", "match_type": "glob"}},
+		{"invalid regex", map[string]any{"category_id": testTransportCategoryID, "pattern": "taxi(", "match_type": "regex"}},
+		{"empty regex", map[string]any{"category_id": testTransportCategoryID, "match_type": "regex", "min_amount_cents": 100}},
+		{"non-positive bound", map[string]any{"category_id": testTransportCategoryID, "min_amount_cents": 0}},
+		{"inverted range", map[string]any{"category_id": testTransportCategoryID, "min_amount_cents": 500, "max_amount_cents": 100}},
+		{"tag too long", map[string]any{"category_id": testTransportCategoryID, "pattern": "taxi", "tag": strings.Repeat("a", 51)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/category-rules", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestImportPreview_RegexAndAmountRules(t *testing.T) {
+	ruleDB := newMockCategoryRuleDB()
+	minAmount := int64(10000)
+	ruleDB.CreateCategoryRule(testUserID, handler.NewCategoryRule{
+		CategoryID: testTransportCategoryID,
+		MatchType:  handler.RuleMatchRegex,
+		Pattern:    `^(uber|bolt)\b`,
+		Tag:        "rides",
+		Priority:   1,
+	})
+	ruleDB.CreateCategoryRule(testUserID, handler.NewCategoryRule{
+		CategoryID:     testFoodCategoryID,
+		MinAmountCents: &minAmount,
+	})
+	r := setupImportRouter(newMockExpenseDB(), ruleDB)
+
+	csv := "Date,Description,Amount\n" +
+		"2026-03-02,BOLT ride,-7.00\n" +
+		"2026-03-03,Paid to Uber,-9.00\n" +
+		"2026-03-04,Supermarket,-120.00\n"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, previewRequest(t, "statement.csv", csv, map[string]string{"note_column": "Description"}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Rows []map[string]any `json:"rows"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp.Rows[0]["category_id"] != testTransportCategoryID || resp.Rows[0]["tag"] != "rides" {
+		t.Fatalf("expected regex rule to categorize and tag Bolt row: %v", resp.Rows[0])
+	}
+	if resp.Rows[1]["category_id"] != nil {
+		t.Fatalf("expected anchored regex not to match: %v", resp.Rows[1])
+	}
+	if resp.Rows[2]["category_id"] != testFoodCategoryID || resp.Rows[2]["tag"] != nil {
+		t.Fatalf("expected amount rule to categorize large row: %v", resp.Rows[2])
+	}
+}
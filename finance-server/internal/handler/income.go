@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sentinel errors for income operations.
+var (
+	ErrIncomeNotFound        = errors.New("income not found")
+	ErrInvalidIncomeCategory = errors.New("category is not an income category")
+)
+
+// MockIncome is the income representation used by the IncomeDB interface.
+// RateDate is the date of the exchange rate used to convert the income.
+type MockIncome struct {
+	ID          string
+	UserID      string
+	CategoryID  string
+	AmountCents int64
+	Currency    string
+	Note        string
+	IncomeDate  time.Time
+	RateDate    time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// IncomeDB abstracts database operations for incomes.
+// This allows testing with mock implementations.
+//
+// An empty currency means the user's base currency on create and the
+// current currency on update; unknown codes return ErrInvalidCurrency. The
+// category must be an income category of the user's own or shared by one of
+// their families; others return ErrInvalidIncomeCategory.
+type IncomeDB interface {
+	CreateIncome(userID, categoryID string, amountCents int64, currency, note string, incomeDate time.Time) (MockIncome, error)
+	GetIncomesByUserFiltered(userID string, limit, offset int, dateFrom, dateTo *time.Time, categoryID string) ([]MockIncome, error)
+	UpdateIncome(id, userID, categoryID string, amountCents int64, currency, note string, incomeDate time.Time) (MockIncome, error)
+	DeleteIncome(id, userID string) error
+}
+
+// IncomeHandler handles income HTTP requests.
+type IncomeHandler struct {
+	db IncomeDB
+}
+
+// NewIncomeHandler creates an IncomeHandler with the given database.
+func NewIncomeHandler(db IncomeDB) *IncomeHandler {
+	return &IncomeHandler{db: db}
+}
+
+type incomeRequest struct {
+	CategoryID  string `json:"category_id"`
+	AmountCents int64  `json:"amount_cents"`
+	Currency    string `json:"currency"`
+	Note        string `json:"note"`
+	IncomeDate  string `json:"income_date"`
+}
+
+// bindIncomeRequest parses and validates an income request body, writing a
+// 400 response and returning false when it is invalid.
+func bindIncomeRequest(c *gin.Context) (incomeRequest, time.Time, bool) {
+	var req incomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return req, time.Time{}, false
+	}
+
+	if req.CategoryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+		return req, time.Time{}, false
+	}
+
+	if req.AmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount_cents must be greater than 0"})
+		return req, time.Time{}, false
+	}
+
+	currency, ok := parseCurrency(req.Currency)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be a 3-letter ISO 4217 code"})
+		return req, time.Time{}, false
+	}
+	req.Currency = currency
+
+	if req.IncomeDate == "" {
+		return req, time.Now(), true
+	}
+	incomeDate, err := time.Parse("2006-01-02", req.IncomeDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "income_date must be in YYYY-MM-DD format"})
+		return req, time.Time{}, false
+	}
+	return req, incomeDate, true
+}
+
+// Create handles POST /api/v1/incomes.
+func (h *IncomeHandler) Create(c *gin.Context) {
+	req, incomeDate, ok := bindIncomeRequest(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("user_id")
+	inc, err := h.db.CreateIncome(userID, req.CategoryID, req.AmountCents, req.Currency, req.Note, incomeDate)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency"})
+			return
+		}
+		if isInvalidCategoryErr(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, incomeResponse(inc))
+}
+
+// Update handles PUT /api/v1/incomes/:id.
+func (h *IncomeHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("user_id")
+
+	req, incomeDate, ok := bindIncomeRequest(c)
+	if !ok {
+		return
+	}
+
+	inc, err := h.db.UpdateIncome(id, userID, req.CategoryID, req.AmountCents, req.Currency, req.Note, incomeDate)
+	if err != nil {
+		if errors.Is(err, ErrIncomeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Income not found"})
+			return
+		}
+		if errors.Is(err, ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency"})
+			return
+		}
+		if isInvalidCategoryErr(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	resp := incomeResponse(inc)
+	resp["updated_at"] = inc.UpdatedAt
+	c.JSON(http.StatusOK, resp)
+}
+
+// Delete handles DELETE /api/v1/incomes/:id.
+func (h *IncomeHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("user_id")
+
+	err := h.db.DeleteIncome(id, userID)
+	if err != nil {
+		if errors.Is(err, ErrIncomeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Income not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// List handles GET /api/v1/incomes.
+func (h *IncomeHandler) List(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	limit := 50
+	offset := 0
+
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	var dateFrom, dateTo *time.Time
+	if df := c.Query("date_from"); df != "" {
+		if t, err := time.Parse("2006-01-02", df); err == nil {
+			dateFrom = &t
+		}
+	}
+	if dt := c.Query("date_to"); dt != "" {
+		if t, err := time.Parse("2006-01-02", dt); err == nil {
+			dateTo = &t
+		}
+	}
+	categoryID := c.Query("category_id")
+
+	incomes, err := h.db.GetIncomesByUserFiltered(userID, limit, offset, dateFrom, dateTo, categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(incomes))
+	for i, inc := range incomes {
+		result[i] = incomeResponse(inc)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func incomeResponse(inc MockIncome) gin.H {
+	return gin.H{
+		"id":           inc.ID,
+		"user_id":      inc.UserID,
+		"category_id":  inc.CategoryID,
+		"amount_cents": inc.AmountCents,
+		"currency":     inc.Currency,
+		"note":         inc.Note,
+		"income_date":  inc.IncomeDate.Format("2006-01-02"),
+		"created_at":   inc.CreatedAt,
+	}
+}
+
+// isInvalidCategoryErr reports whether err means the referenced category
+// does not exist or cannot be used for this record.
+func isInvalidCategoryErr(err error) bool {
+	if errors.Is(err, ErrInvalidIncomeCategory) {
+		return true
+	}
+	return strings.Contains(err.Error(), "foreign key") || strings.Contains(err.Error(), "violates")
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// PgIncomeDB implements IncomeDB using sqlc-generated queries against PostgreSQL.
+type PgIncomeDB struct {
+	queries *sqlc.Queries
+}
+
+// NewPgIncomeDB creates a PgIncomeDB wrapping sqlc.Queries.
+func NewPgIncomeDB(queries *sqlc.Queries) *PgIncomeDB {
+	return &PgIncomeDB{queries: queries}
+}
+
+// checkIncomeCategory verifies that the user may file incomes under the
+// category and that it is an income category. An archived category is only
+// accepted for incomeID when the income is already filed under it; pass an
+// invalid incomeID for new incomes.
+func (db *PgIncomeDB) checkIncomeCategory(userID, categoryID, incomeID pgtype.UUID) error {
+	kind, err := db.queries.GetIncomeCategoryKind(context.Background(), sqlc.GetIncomeCategoryKindParams{
+		ID:       categoryID,
+		UserID:   userID,
+		IncomeID: incomeID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrInvalidIncomeCategory
+		}
+		return err
+	}
+	if kind != CategoryKindIncome {
+		return ErrInvalidIncomeCategory
+	}
+	return nil
+}
+
+func (db *PgIncomeDB) CreateIncome(userID, categoryID string, amountCents int64, currency, note string, incomeDate time.Time) (MockIncome, error) {
+	uid := stringToUUID(userID)
+	cid := stringToUUID(categoryID)
+
+	if err := db.checkIncomeCategory(uid, cid, pgtype.UUID{}); err != nil {
+		return MockIncome{}, err
+	}
+
+	row, err := db.queries.CreateIncome(context.Background(), sqlc.CreateIncomeParams{
+		UserID:      uid,
+		CategoryID:  cid,
+		AmountCents: amountCents,
+		Note:        note,
+		IncomeDate:  pgtype.Date{Time: incomeDate, Valid: true},
+		Currency:    stringToNullableText(currency),
+	})
+	if err != nil {
+		return MockIncome{}, currencyError(err)
+	}
+
+	return incomeFromRow(row), nil
+}
+
+func (db *PgIncomeDB) GetIncomesByUserFiltered(userID string, limit, offset int, dateFrom, dateTo *time.Time, categoryID string) ([]MockIncome, error) {
+	uid := stringToUUID(userID)
+
+	rows, err := db.queries.GetIncomesByUserFiltered(context.Background(), sqlc.GetIncomesByUserFilteredParams{
+		UserID:     uid,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+		DateFrom:   dateToPgDate(dateFrom),
+		DateTo:     dateToPgDate(dateTo),
+		CategoryID: stringToNullableUUID(categoryID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	incomes := make([]MockIncome, len(rows))
+	for i, row := range rows {
+		incomes[i] = incomeFromRow(row)
+	}
+	return incomes, nil
+}
+
+func (db *PgIncomeDB) UpdateIncome(id, userID, categoryID string, amountCents int64, currency, note string, incomeDate time.Time) (MockIncome, error) {
+	iid := stringToUUID(id)
+	uid := stringToUUID(userID)
+	cid := stringToUUID(categoryID)
+
+	if err := db.checkIncomeCategory(uid, cid, iid); err != nil {
+		return MockIncome{}, err
+	}
+
+	row, err := db.queries.UpdateIncome(context.Background(), sqlc.UpdateIncomeParams{
+		ID:          iid,
+		UserID:      uid,
+		CategoryID:  cid,
+		AmountCents: amountCents,
+		Note:        note,
+		IncomeDate:  pgtype.Date{Time: incomeDate, Valid: true},
+		Currency:    stringToNullableText(currency),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockIncome{}, ErrIncomeNotFound
+		}
+		return MockIncome{}, currencyError(err)
+	}
+
+	return incomeFromRow(row), nil
+}
+
+func (db *PgIncomeDB) DeleteIncome(id, userID string) error {
+	rowsAffected, err := db.queries.DeleteIncome(context.Background(), sqlc.DeleteIncomeParams{
+		ID:     stringToUUID(id),
+		UserID: stringToUUID(userID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrIncomeNotFound
+	}
+	return nil
+}
+
+func incomeFromRow(row sqlc.Income) MockIncome {
+	return MockIncome{
+		ID:          uuidToString(row.ID),
+		UserID:      uuidToString(row.UserID),
+		CategoryID:  uuidToString(row.CategoryID),
+		AmountCents: row.AmountCents,
+		Currency:    row.Currency,
+		Note:        row.Note,
+		IncomeDate:  row.IncomeDate.Time,
+		RateDate:    row.RateDate.Time,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,353 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+const testIncomeCategoryID = "550e8400-e29b-41d4-a716-446655440009"
+
+// mockIncomeDB implements handler.IncomeDB for testing.
+type mockIncomeDB struct {
+	incomes []handler.MockIncome
+	nextID  int
+}
+
+func newMockIncomeDB() *mockIncomeDB {
+	return &mockIncomeDB{
+		incomes: make([]handler.MockIncome, 0),
+		nextID:  1,
+	}
+}
+
+func (m *mockIncomeDB) CreateIncome(userID, categoryID string, amountCents int64, currency, note string, incomeDate time.Time) (handler.MockIncome, error) {
+	if categoryID != testIncomeCategoryID {
+		return handler.MockIncome{}, handler.ErrInvalidIncomeCategory
+	}
+	if currency == "" {
+		currency = "UAH"
+	}
+	if !mockCurrencyKnown(currency) {
+		return handler.MockIncome{}, handler.ErrInvalidCurrency
+	}
+	inc := handler.MockIncome{
+		ID:          "inc-" + string(rune('0'+m.nextID)),
+		UserID:      userID,
+		CategoryID:  categoryID,
+		AmountCents: amountCents,
+		Currency:    currency,
+		Note:        note,
+		IncomeDate:  incomeDate,
+		RateDate:    incomeDate,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	m.nextID++
+	m.incomes = append(m.incomes, inc)
+	return inc, nil
+}
+
+func (m *mockIncomeDB) GetIncomesByUserFiltered(userID string, limit, offset int, dateFrom, dateTo *time.Time, categoryID string) ([]handler.MockIncome, error) {
+	var result []handler.MockIncome
+	for _, inc := range m.incomes {
+		if inc.UserID == userID {
+			result = append(result, inc)
+		}
+	}
+	if offset >= len(result) {
+		return []handler.MockIncome{}, nil
+	}
+	result = result[offset:]
+	if limit < len(result) {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockIncomeDB) UpdateIncome(id, userID, categoryID string, amountCents int64, currency, note string, incomeDate time.Time) (handler.MockIncome, error) {
+	if categoryID != testIncomeCategoryID {
+		return handler.MockIncome{}, handler.ErrInvalidIncomeCategory
+	}
+	if currency != "" && !mockCurrencyKnown(currency) {
+		return handler.MockIncome{}, handler.ErrInvalidCurrency
+	}
+	for i, inc := range m.incomes {
+		if inc.ID == id && inc.UserID == userID {
+			m.incomes[i].CategoryID = categoryID
+			m.incomes[i].AmountCents = amountCents
+			if currency != "" {
+				m.incomes[i].Currency = currency
+			}
+			m.incomes[i].Note = note
+			m.incomes[i].IncomeDate = incomeDate
+			m.incomes[i].UpdatedAt = time.Now()
+			return m.incomes[i], nil
+		}
+	}
+	return handler.MockIncome{}, handler.ErrIncomeNotFound
+}
+
+func (m *mockIncomeDB) DeleteIncome(id, userID string) error {
+	for i, inc := range m.incomes {
+		if inc.ID == id && inc.UserID == userID {
+			m.incomes = append(m.incomes[:i], m.incomes[i+1:]...)
+			return nil
+		}
+	}
+	return handler.ErrIncomeNotFound
+}
+
+func setupIncomeRouter(db handler.IncomeDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewIncomeHandler(db)
+
+	incomes := r.Group("/api/v1/incomes")
+	incomes.Use(func(c *gin.Context) {
+		c.Set("user_id", testUserID)
+		c.Next()
+	})
+	{
+		incomes.POST("", h.Create)
+		incomes.GET("", h.List)
+		incomes.PUT("/:id", h.Update)
+		incomes.DELETE("/:id", h.Delete)
+	}
+	return r
+}
+
+func createTestIncome(t *testing.T, r *gin.Engine) string {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testIncomeCategoryID,
+		"amount_cents": 250000,
+		"note":         "March salary",
+		"income_date":  "2026-03-01",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/incomes", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp["id"].(string)
+}
+
+func TestCreateIncome_Success(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testIncomeCategoryID,
+		"amount_cents": 250000,
+		"note":         "March salary",
+		"income_date":  "2026-03-01",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/incomes", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp["amount_cents"] != float64(250000) {
+		t.Fatalf("expected amount_cents 250000, got %v", resp["amount_cents"])
+	}
+	if resp["income_date"] != "2026-03-01" {
+		t.Fatalf("expected income_date 2026-03-01, got %v", resp["income_date"])
+	}
+}
+
+func TestCreateIncome_InvalidAmount(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testIncomeCategoryID,
+		"amount_cents": 0,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/incomes", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreateIncome_ExpenseCategoryRejected(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+		"amount_cents": 1000,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/incomes", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreateIncome_Currency(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testIncomeCategoryID,
+		"amount_cents": 100000,
+		"currency":     "usd",
+		"income_date":  "2026-03-01",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/incomes", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["currency"] != "USD" {
+		t.Fatalf("expected currency USD, got %v", resp["currency"])
+	}
+}
+
+func TestCreateIncome_DefaultCurrency(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+	createTestIncome(t, r)
+
+	if db.incomes[0].Currency != "UAH" {
+		t.Fatalf("expected base currency UAH, got %q", db.incomes[0].Currency)
+	}
+}
+
+func TestCreateIncome_InvalidCurrency(t *testing.T) {
+	for _, currency := range []string{"EURO", "E1R", "GBP"} {
+		t.Run(currency, func(t *testing.T) {
+			db := newMockIncomeDB()
+			r := setupIncomeRouter(db)
+
+			body, _ := json.Marshal(map[string]any{
+				"category_id":  testIncomeCategoryID,
+				"amount_cents": 1000,
+				"currency":     currency,
+			})
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/incomes", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestListIncomes_Success(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+
+	createTestIncome(t, r)
+	createTestIncome(t, r)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/incomes", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp) != 2 {
+		t.Fatalf("expected 2 incomes, got %d", len(resp))
+	}
+}
+
+func TestUpdateIncome_Success(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+	id := createTestIncome(t, r)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testIncomeCategoryID,
+		"amount_cents": 260000,
+		"note":         "March salary + bonus",
+		"income_date":  "2026-03-02",
+	})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/incomes/"+id, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["amount_cents"] != float64(260000) {
+		t.Fatalf("expected amount_cents 260000, got %v", resp["amount_cents"])
+	}
+}
+
+func TestUpdateIncome_NotFound(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  testIncomeCategoryID,
+		"amount_cents": 1000,
+	})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/incomes/nonexistent", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDeleteIncome_Success(t *testing.T) {
+	db := newMockIncomeDB()
+	r := setupIncomeRouter(db)
+	id := createTestIncome(t, r)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/incomes/"+id, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(db.incomes) != 0 {
+		t.Fatalf("expected income to be deleted, %d remain", len(db.incomes))
+	}
+}
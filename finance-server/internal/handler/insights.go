@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// insightsHistoryMonths is how many completed months insights look back
+// over for averages and medians.
+const insightsHistoryMonths = 6
+
+// anomalyFactor flags a category once its spend this month reaches this
+// multiple of its historical median.
+const anomalyFactor = 2
+
+// minRunRateDays is how many days of the current month must have passed
+// before the projection trusts this month's run rate over history.
+const minRunRateDays = 7
+
+// InsightsHandler handles spending insights HTTP requests.
+type InsightsHandler struct {
+	db SummaryDB
+}
+
+// NewInsightsHandler creates an InsightsHandler with the given database.
+func NewInsightsHandler(db SummaryDB) *InsightsHandler {
+	return &InsightsHandler{db: db}
+}
+
+// categoryHistory accumulates one category's spend across the history window.
+type categoryHistory struct {
+	total   CategoryTotal
+	spent   int64
+	monthly []int64
+}
+
+// Insights handles GET /api/v1/expenses/insights.
+//
+// It projects month-end spend for the month containing date (default
+// today), and reports per-category averages and medians over the previous
+// six months, flagging categories that already spent twice their median.
+// month_start selects salary-day months as in the summary endpoint.
+func (h *InsightsHandler) Insights(c *gin.Context) {
+	monthStart, ok := parseMonthStart(c)
+	if !ok {
+		return
+	}
+	asOf, ok := parseAnchorDate(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("user_id")
+	period := newPeriod(PeriodMonth, asOf, time.Monday, monthStart)
+
+	baseCurrency, err := h.db.GetUserBaseCurrency(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	current, err := h.db.GetCategoryTotals(userID, period.From, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	dailyTotals, err := h.db.GetDailyTotals(userID, period.From, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	var order []string
+	byCategory := make(map[string]*categoryHistory)
+	track := func(ct CategoryTotal) *categoryHistory {
+		hist, ok := byCategory[ct.CategoryID]
+		if !ok {
+			hist = &categoryHistory{total: ct, monthly: make([]int64, insightsHistoryMonths)}
+			byCategory[ct.CategoryID] = hist
+			order = append(order, ct.CategoryID)
+		}
+		return hist
+	}
+
+	for _, ct := range current {
+		track(ct).spent = ct.TotalCents
+	}
+
+	var spentCents int64
+	for _, dt := range dailyTotals {
+		spentCents += dt.TotalCents
+	}
+
+	// Months without any expenses are left out of averages and medians, so
+	// months before the user started tracking don't drag them down.
+	historyMonths := 0
+	active := make([]bool, insightsHistoryMonths)
+	var historyCents int64
+	month := period
+	for i := 0; i < insightsHistoryMonths; i++ {
+		month = month.Previous()
+		totals, err := h.db.GetCategoryTotals(userID, month.From, month.To)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+		if len(totals) == 0 {
+			continue
+		}
+		historyMonths++
+		active[i] = true
+		for _, ct := range totals {
+			track(ct).monthly[i] = ct.TotalCents
+			historyCents += ct.TotalCents
+		}
+	}
+
+	daysInPeriod := int(period.To.Sub(period.From).Hours()/24) + 1
+	daysElapsed := int(asOf.Sub(period.From).Hours()/24) + 1
+
+	var averageMonthly int64
+	if historyMonths > 0 {
+		averageMonthly = historyCents / int64(historyMonths)
+	}
+
+	projected := spentCents
+	if remaining := daysInPeriod - daysElapsed; remaining > 0 {
+		dailyRate := float64(spentCents) / float64(daysElapsed)
+		if daysElapsed < minRunRateDays && historyMonths > 0 {
+			dailyRate = float64(averageMonthly) / float64(daysInPeriod)
+		}
+		projected += int64(dailyRate * float64(remaining))
+	}
+
+	categories := make([]gin.H, 0, len(order))
+	anomalies := 0
+	for _, id := range order {
+		hist := byCategory[id]
+		months := make([]int64, 0, historyMonths)
+		for i, v := range hist.monthly {
+			if active[i] {
+				months = append(months, v)
+			}
+		}
+
+		var sum int64
+		for _, v := range months {
+			sum += v
+		}
+		var average, median int64
+		if len(months) > 0 {
+			average = sum / int64(len(months))
+			median = medianCents(months)
+		}
+
+		anomaly := median > 0 && hist.spent >= anomalyFactor*median
+		if anomaly {
+			anomalies++
+		}
+
+		categories = append(categories, gin.H{
+			"category_id":    hist.total.CategoryID,
+			"category_name":  hist.total.CategoryName,
+			"category_color": hist.total.CategoryColor,
+			"category_icon":  hist.total.CategoryIcon,
+			"spent_cents":    hist.spent,
+			"average_cents":  average,
+			"median_cents":   median,
+			"anomaly":        anomaly,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"period":                period.JSON(),
+		"as_of":                 asOf.Format("2006-01-02"),
+		"currency":              baseCurrency,
+		"days_elapsed":          daysElapsed,
+		"days_in_period":        daysInPeriod,
+		"spent_cents":           spentCents,
+		"projected_cents":       projected,
+		"average_monthly_cents": averageMonthly,
+		"history_months":        historyMonths,
+		"anomaly_count":         anomalies,
+		"by_category":           categories,
+	})
+}
+
+// medianCents returns the median of values without modifying them.
+func medianCents(values []int64) int64 {
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,180 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+func setupInsightsRouter(db handler.SummaryDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewInsightsHandler(db)
+
+	expenses := r.Group("/api/v1/expenses")
+	expenses.Use(func(c *gin.Context) {
+		c.Set("user_id", testUserID)
+		c.Next()
+	})
+	{
+		expenses.GET("/insights", h.Insights)
+	}
+	return r
+}
+
+// newInsightsDB returns a mock with March 2026 spending and three months of
+// history; October to December 2025 are left empty.
+func newInsightsDB() *mockSummaryDB {
+	return &mockSummaryDB{
+		dailyTotals: []handler.DateTotal{
+			{Date: "2026-03-02", TotalCents: 20000},
+			{Date: "2026-03-09", TotalCents: 15000},
+		},
+		categoryTotalsFrom: map[string][]handler.CategoryTotal{
+			"2026-03-01": {
+				{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 30000, Count: 3},
+				{CategoryID: "cat-2", CategoryName: "Transport", TotalCents: 5000, Count: 1},
+			},
+			"2026-02-01": {
+				{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 10000, Count: 2},
+				{CategoryID: "cat-2", CategoryName: "Transport", TotalCents: 8000, Count: 2},
+			},
+			"2026-01-01": {
+				{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 12000, Count: 2},
+				{CategoryID: "cat-2", CategoryName: "Transport", TotalCents: 6000, Count: 1},
+			},
+			"2025-12-01": {
+				{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 8000, Count: 1},
+			},
+		},
+	}
+}
+
+func TestInsights_ProjectionAndAnomalies(t *testing.T) {
+	db := newInsightsDB()
+	r := setupInsightsRouter(db)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/insights?date=2026-03-10", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp["spent_cents"] != float64(35000) {
+		t.Fatalf("expected spent_cents 35000, got %v", resp["spent_cents"])
+	}
+	// 10 days in at 3500/day, 21 days left.
+	if resp["projected_cents"] != float64(108500) {
+		t.Fatalf("expected projected_cents 108500, got %v", resp["projected_cents"])
+	}
+	if resp["history_months"] != float64(3) {
+		t.Fatalf("expected 3 history months, got %v", resp["history_months"])
+	}
+	if resp["average_monthly_cents"] != float64(14666) {
+		t.Fatalf("expected average_monthly_cents 14666, got %v", resp["average_monthly_cents"])
+	}
+	if resp["anomaly_count"] != float64(1) {
+		t.Fatalf("expected 1 anomaly, got %v", resp["anomaly_count"])
+	}
+
+	byCategory := resp["by_category"].([]any)
+	if len(byCategory) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(byCategory))
+	}
+	food := byCategory[0].(map[string]any)
+	if food["median_cents"] != float64(10000) || food["average_cents"] != float64(10000) || food["anomaly"] != true {
+		t.Fatalf("expected Food flagged against a 10000 median, got %v", food)
+	}
+	transport := byCategory[1].(map[string]any)
+	if transport["median_cents"] != float64(6000) || transport["anomaly"] != false {
+		t.Fatalf("expected Transport within range, got %v", transport)
+	}
+}
+
+func TestInsights_EarlyMonthUsesHistory(t *testing.T) {
+	db := newInsightsDB()
+	db.dailyTotals = []handler.DateTotal{{Date: "2026-03-02", TotalCents: 3000}}
+	r := setupInsightsRouter(db)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/insights?date=2026-03-03", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	// 3000 so far plus 28 days at the historical 14666/31 per day.
+	if resp["projected_cents"] != float64(16246) {
+		t.Fatalf("expected projected_cents 16246, got %v", resp["projected_cents"])
+	}
+}
+
+func TestInsights_SalaryMonth(t *testing.T) {
+	db := &mockSummaryDB{}
+	r := setupInsightsRouter(db)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/insights?date=2026-03-10&month_start=25", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	period := resp["period"].(map[string]any)
+	if period["from"] != "2026-02-25" || period["to"] != "2026-03-24" {
+		t.Fatalf("unexpected period: %v", period)
+	}
+	if resp["days_elapsed"] != float64(14) || resp["days_in_period"] != float64(28) {
+		t.Fatalf("unexpected day counts: %v/%v", resp["days_elapsed"], resp["days_in_period"])
+	}
+	if len(db.ranges) != 1+6 {
+		t.Fatalf("expected current month plus 6 months of history, got %v", db.ranges)
+	}
+	if last := db.ranges[len(db.ranges)-1]; last != [2]string{"2025-08-25", "2025-09-24"} {
+		t.Fatalf("unexpected oldest history month: %v", last)
+	}
+}
+
+func TestInsights_InvalidParams(t *testing.T) {
+	for _, q := range []string{"month_start=0", "date=10-03-2026"} {
+		r := setupInsightsRouter(&mockSummaryDB{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/insights?"+q, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", q, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestInsights_DBError(t *testing.T) {
+	r := setupInsightsRouter(&mockSummaryDB{err: errors.New("database connection failed")})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/insights", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Summary period kinds accepted by the period query parameter.
+const (
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+	PeriodCustom  = "custom"
+)
+
+// Comparison modes accepted by the compare query parameter.
+const (
+	ComparePrevious     = "previous"
+	CompareSameLastYear = "same_last_year"
+)
+
+// maxMonthStart is the latest day a salary-day month may start on, so that
+// every month has that day.
+const maxMonthStart = 28
+
+// Period is an inclusive date range a summary is computed over.
+type Period struct {
+	Kind string
+	From time.Time
+	To   time.Time
+}
+
+// months returns how many months a month-based period spans, or 0 for
+// week and custom periods.
+func (p Period) months() int {
+	switch p.Kind {
+	case PeriodMonth:
+		return 1
+	case PeriodQuarter:
+		return 3
+	case PeriodYear:
+		return 12
+	}
+	return 0
+}
+
+// Previous returns the period of the same kind and length immediately
+// before p.
+func (p Period) Previous() Period {
+	prev := Period{Kind: p.Kind, To: p.From.AddDate(0, 0, -1)}
+	switch {
+	case p.Kind == PeriodWeek:
+		prev.From = p.From.AddDate(0, 0, -7)
+	case p.months() > 0:
+		prev.From = p.From.AddDate(0, -p.months(), 0)
+	default:
+		prev.From = prev.To.Add(-p.To.Sub(p.From))
+	}
+	return prev
+}
+
+// SameLastYear returns the period of the same kind one year before p.
+// Weeks move back 52 weeks so they keep starting on the same weekday.
+func (p Period) SameLastYear() Period {
+	switch {
+	case p.Kind == PeriodWeek:
+		return Period{Kind: p.Kind, From: p.From.AddDate(0, 0, -364), To: p.To.AddDate(0, 0, -364)}
+	case p.months() > 0:
+		from := p.From.AddDate(-1, 0, 0)
+		return Period{Kind: p.Kind, From: from, To: from.AddDate(0, p.months(), -1)}
+	}
+	return Period{Kind: p.Kind, From: yearEarlier(p.From), To: yearEarlier(p.To)}
+}
+
+// JSON returns the period as it is reported in summary responses.
+func (p Period) JSON() gin.H {
+	return gin.H{
+		"kind": p.Kind,
+		"from": p.From.Format("2006-01-02"),
+		"to":   p.To.Format("2006-01-02"),
+	}
+}
+
+// yearEarlier returns the same calendar day one year before t, clamping
+// 29 February to the 28th.
+func yearEarlier(t time.Time) time.Time {
+	day := t.Day()
+	if last := time.Date(t.Year()-1, t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day(); day > last {
+		day = last
+	}
+	return time.Date(t.Year()-1, t.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// monthPeriodStart returns the start of the salary-day month containing d
+// when months begin on day start.
+func monthPeriodStart(d time.Time, start int) time.Time {
+	if d.Day() >= start {
+		return time.Date(d.Year(), d.Month(), start, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(d.Year(), d.Month()-1, start, 0, 0, 0, 0, time.UTC)
+}
+
+// newPeriod returns the period of the given kind containing anchor.
+func newPeriod(kind string, anchor time.Time, weekStart time.Weekday, monthStart int) Period {
+	p := Period{Kind: kind}
+	switch kind {
+	case PeriodWeek:
+		offset := (int(anchor.Weekday()) - int(weekStart) + 7) % 7
+		p.From = anchor.AddDate(0, 0, -offset)
+		p.To = p.From.AddDate(0, 0, 6)
+		return p
+	case PeriodMonth:
+		p.From = monthPeriodStart(anchor, monthStart)
+	case PeriodQuarter:
+		m := monthPeriodStart(anchor, monthStart)
+		p.From = time.Date(m.Year(), (m.Month()-1)/3*3+1, monthStart, 0, 0, 0, 0, time.UTC)
+	case PeriodYear:
+		m := monthPeriodStart(anchor, monthStart)
+		p.From = time.Date(m.Year(), time.January, monthStart, 0, 0, 0, 0, time.UTC)
+	}
+	p.To = p.From.AddDate(0, p.months(), -1)
+	return p
+}
+
+// parseWeekday parses a weekday name such as "monday" or "sun".
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseMonthStart reads the month_start query parameter, the day of the
+// month salary-day months begin on. It defaults to 1. On failure it writes
+// a 400 response and returns ok=false.
+func parseMonthStart(c *gin.Context) (int, bool) {
+	ms := c.Query("month_start")
+	if ms == "" {
+		return 1, true
+	}
+	v, err := strconv.Atoi(ms)
+	if err != nil || v < 1 || v > maxMonthStart {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "month_start must be between 1 and 28"})
+		return 0, false
+	}
+	return v, true
+}
+
+// parseAnchorDate reads the date query parameter, defaulting to today. On
+// failure it writes a 400 response and returns ok=false.
+func parseAnchorDate(c *gin.Context) (time.Time, bool) {
+	d := c.Query("date")
+	if d == "" {
+		return time.Now().UTC().Truncate(24 * time.Hour), true
+	}
+	t, err := time.Parse("2006-01-02", d)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseSummaryPeriod reads the summary period from the query string.
+//
+// The period is either the legacy month=YYYY-MM or period=week|month|
+// quarter|year anchored on date (default today), or period=custom with an
+// explicit from/to range. week_start (default monday) and month_start
+// (1-28, default 1) control where weeks and months begin. When compare is
+// set, the period to compare against is returned as well. On failure it
+// writes a 400 response and returns ok=false.
+func parseSummaryPeriod(c *gin.Context) (period Period, compare *Period, ok bool) {
+	weekStart := time.Monday
+	if ws := c.Query("week_start"); ws != "" {
+		if weekStart, ok = parseWeekday(ws); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "week_start must be a weekday name"})
+			return period, nil, false
+		}
+	}
+
+	monthStart, ok := parseMonthStart(c)
+	if !ok {
+		return period, nil, false
+	}
+
+	kind := c.Query("period")
+	switch kind {
+	case "":
+		month := c.Query("month")
+		parsed, err := time.Parse("2006-01", month)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "month query parameter is required (format: YYYY-MM)"})
+			return period, nil, false
+		}
+		period = newPeriod(PeriodMonth, parsed.AddDate(0, 0, monthStart-1), weekStart, monthStart)
+
+	case PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear:
+		anchor, ok := parseAnchorDate(c)
+		if !ok {
+			return period, nil, false
+		}
+		period = newPeriod(kind, anchor, weekStart, monthStart)
+
+	case PeriodCustom:
+		from, errFrom := time.Parse("2006-01-02", c.Query("from"))
+		to, errTo := time.Parse("2006-01-02", c.Query("to"))
+		if errFrom != nil || errTo != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to are required for a custom period (format: YYYY-MM-DD)"})
+			return period, nil, false
+		}
+		if to.Before(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+			return period, nil, false
+		}
+		period = Period{Kind: PeriodCustom, From: from, To: to}
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be week, month, quarter, year or custom"})
+		return period, nil, false
+	}
+
+	switch c.Query("compare") {
+	case "":
+	case ComparePrevious:
+		prev := period.Previous()
+		compare = &prev
+	case CompareSameLastYear:
+		prev := period.SameLastYear()
+		compare = &prev
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "compare must be previous or same_last_year"})
+		return period, nil, false
+	}
+
+	return period, compare, true
+}
+
+// periodComparison reports how spending in the current period differs from
+// the compared period, for the total and for every category that appears
+// in either of them.
+func periodComparison(compare Period, current, previous []CategoryTotal) gin.H {
+	var currentCents, previousCents int64
+	previousByCategory := make(map[string]CategoryTotal, len(previous))
+	for _, ct := range previous {
+		previousCents += ct.TotalCents
+		previousByCategory[ct.CategoryID] = ct
+	}
+
+	byCategory := make([]gin.H, 0, len(current)+len(previous))
+	seen := make(map[string]bool, len(current))
+	for _, ct := range current {
+		currentCents += ct.TotalCents
+		seen[ct.CategoryID] = true
+		byCategory = append(byCategory, categoryDelta(ct, ct.TotalCents, previousByCategory[ct.CategoryID].TotalCents))
+	}
+	for _, ct := range previous {
+		if !seen[ct.CategoryID] {
+			byCategory = append(byCategory, categoryDelta(ct, 0, ct.TotalCents))
+		}
+	}
+
+	return gin.H{
+		"period":      compare.JSON(),
+		"total_cents": previousCents,
+		"delta_cents": currentCents - previousCents,
+		"by_category": byCategory,
+	}
+}
+
+// categoryDelta builds one by_category entry of a period comparison. An
+// empty category ID stands for private expenses in family summaries.
+func categoryDelta(ct CategoryTotal, currentCents, previousCents int64) gin.H {
+	var categoryID any
+	if ct.CategoryID != "" {
+		categoryID = ct.CategoryID
+	}
+	return gin.H{
+		"category_id":    categoryID,
+		"category_name":  ct.CategoryName,
+		"category_color": ct.CategoryColor,
+		"category_icon":  ct.CategoryIcon,
+		"total_cents":    currentCents,
+		"previous_cents": previousCents,
+		"delta_cents":    currentCents - previousCents,
+	}
+}
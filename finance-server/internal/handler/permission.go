@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Family member roles, from least to most privileged.
+const (
+	RoleViewer = "viewer"
+	RoleMember = "member"
+	RoleAdmin  = "admin"
+	RoleOwner  = "owner"
+)
+
+// roleRanks orders the roles; a role holds every permission of the roles
+// ranked below it.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// FamilyPermission is an action within a family that some roles may take.
+type FamilyPermission int
+
+const (
+	// PermViewFamily allows reading the family, its feed, summary, budgets
+	// and balances.
+	PermViewFamily FamilyPermission = iota
+	// PermContribute allows adding expenses to the family and splitting or
+	// settling them.
+	PermContribute
+	// PermManageFamily allows managing invitations, members, shared budgets
+	// and settings.
+	PermManageFamily
+	// PermOwnFamily allows deleting the family and transferring ownership.
+	PermOwnFamily
+)
+
+// permissionRoles is the least privileged role granted each permission.
+var permissionRoles = map[FamilyPermission]string{
+	PermViewFamily:   RoleViewer,
+	PermContribute:   RoleMember,
+	PermManageFamily: RoleAdmin,
+	PermOwnFamily:    RoleOwner,
+}
+
+// ValidRole reports whether role is a known family role.
+func ValidRole(role string) bool {
+	return roleRanks[role] > 0
+}
+
+// HasPermission reports whether a member with the given role may take perm.
+// Unknown roles have no permissions.
+func HasPermission(role string, perm FamilyPermission) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[permissionRoles[perm]]
+}
+
+// authorizeFamily resolves the family addressed by the request like
+// familyFromPath and checks that the current user's role grants perm. It
+// writes a 404 or 403 response and returns ok=false otherwise. Every family
+// handler goes through it so permissions are checked in one place.
+func authorizeFamily(c *gin.Context, db FamilyDB, perm FamilyPermission) (MockFamily, bool) {
+	family, ok := familyFromPath(c, db)
+	if !ok {
+		return MockFamily{}, false
+	}
+	if !HasPermission(family.Role, perm) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role in this family does not allow this"})
+		return MockFamily{}, false
+	}
+	return family, true
+}
@@ -0,0 +1,187 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		role string
+		perm handler.FamilyPermission
+		want bool
+	}{
+		{handler.RoleViewer, handler.PermViewFamily, true},
+		{handler.RoleViewer, handler.PermContribute, false},
+		{handler.RoleMember, handler.PermContribute, true},
+		{handler.RoleMember, handler.PermManageFamily, false},
+		{handler.RoleAdmin, handler.PermManageFamily, true},
+		{handler.RoleAdmin, handler.PermOwnFamily, false},
+		{handler.RoleOwner, handler.PermOwnFamily, true},
+		{"", handler.PermViewFamily, false},
+		{"superuser", handler.PermViewFamily, false},
+	}
+	for _, tt := range tests {
+		if got := handler.HasPermission(tt.role, tt.perm); got != tt.want {
+			t.Errorf("HasPermission(%q, %d) = %v, want %v", tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
+
+// newRolesFamily returns family-1 owned by user-1 with an admin (user-2),
+// a member (user-3) and a viewer (user-4).
+func newRolesFamily() *mockFamilyDB {
+	fdb := newMockFamilyDB()
+	fdb.families["family-1"] = &handler.MockFamily{ID: "family-1", Name: "Smith Family", AdminUserID: "user-1", BaseCurrency: "UAH"}
+	roles := []string{handler.RoleOwner, handler.RoleAdmin, handler.RoleMember, handler.RoleViewer}
+	for i, role := range roles {
+		fdb.AddFamilyMember("family-1", "user-"+string(rune('1'+i)), role)
+	}
+	return fdb
+}
+
+func familyRequest(r http.Handler, method, path, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestFamilyPermissions_View(t *testing.T) {
+	r := setupFamilyRouter(newRolesFamily())
+
+	t.Run("viewer sees family without invitations", func(t *testing.T) {
+		w := familyRequest(r, http.MethodGet, "/api/v1/families/family-1", "user-4")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if _, ok := resp["invitations"]; ok {
+			t.Fatal("viewer should not see invitations")
+		}
+	})
+
+	t.Run("admin sees invitations", func(t *testing.T) {
+		w := familyRequest(r, http.MethodGet, "/api/v1/families/family-1", "user-2")
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if _, ok := resp["invitations"]; !ok {
+			t.Fatal("admin should see invitations")
+		}
+	})
+}
+
+func TestFamilyPermissions_Invitations(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		want   int
+	}{
+		{"viewer", "user-4", http.StatusForbidden},
+		{"member", "user-3", http.StatusForbidden},
+		{"admin", "user-2", http.StatusCreated},
+		{"owner", "user-1", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupFamilyRouter(newRolesFamily())
+			w := postFamilyJSON(r, "/api/v1/families/family-1/invitations", tt.userID, map[string]string{"email": "user-9@test.com"})
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFamilyPermissions_RemoveMember(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		target string
+		want   int
+	}{
+		{"member cannot remove viewer", "user-3", "user-4", http.StatusForbidden},
+		{"admin removes viewer", "user-2", "user-4", http.StatusOK},
+		{"admin cannot remove owner", "user-2", "user-1", http.StatusForbidden},
+		{"owner removes admin", "user-1", "user-2", http.StatusOK},
+		{"unknown member", "user-1", "user-9", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupFamilyRouter(newRolesFamily())
+			w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1/members/"+tt.target, tt.userID)
+			if w.Code != tt.want {
+				t.Fatalf("expected %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFamilyPermissions_DeleteAndLeave(t *testing.T) {
+	t.Run("admin cannot delete family", func(t *testing.T) {
+		r := setupFamilyRouter(newRolesFamily())
+		w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1", "user-2")
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("owner deletes family", func(t *testing.T) {
+		r := setupFamilyRouter(newRolesFamily())
+		w := familyRequest(r, http.MethodDelete, "/api/v1/families/family-1", "user-1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("owner cannot leave", func(t *testing.T) {
+		r := setupFamilyRouter(newRolesFamily())
+		w := familyRequest(r, http.MethodPost, "/api/v1/families/family-1/leave", "user-1")
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("viewer can leave", func(t *testing.T) {
+		r := setupFamilyRouter(newRolesFamily())
+		w := familyRequest(r, http.MethodPost, "/api/v1/families/family-1/leave", "user-4")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestFamilyPermissions_Budgets(t *testing.T) {
+	r := setupBudgetRouter(newMockBudgetDB(), newRolesFamily())
+
+	if w := familyRequest(r, http.MethodGet, "/api/v1/families/me/budgets", "user-4"); w.Code != http.StatusOK {
+		t.Fatalf("viewer list: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := putBudget(r, "/api/v1/families/me/budgets", "user-3", map[string]any{"amount_cents": 1000}); w.Code != http.StatusForbidden {
+		t.Fatalf("member set: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := putBudget(r, "/api/v1/families/me/budgets", "user-2", map[string]any{"amount_cents": 1000}); w.Code != http.StatusOK {
+		t.Fatalf("admin set: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestFamilyPermissions_Settlements(t *testing.T) {
+	r := setupSplitRouter(newMockSplitDB(), newRolesFamily())
+
+	if w := familyRequest(r, http.MethodGet, "/api/v1/families/me/balances", "user-4"); w.Code != http.StatusOK {
+		t.Fatalf("viewer balances: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	payload := map[string]any{"to_user_id": "user-1", "amount_cents": 1000}
+	if w := postSettlement(r, "user-4", payload); w.Code != http.StatusForbidden {
+		t.Fatalf("viewer settle: expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := postSettlement(r, "user-3", payload); w.Code != http.StatusCreated {
+		t.Fatalf("member settle: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
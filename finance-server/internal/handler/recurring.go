@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/recurring"
+)
+
+// Sentinel errors for recurring expense operations.
+var (
+	ErrRecurringExpenseNotFound = errors.New("recurring expense not found")
+	ErrInvalidRecurringCategory = errors.New("invalid recurring expense category")
+)
+
+// MockRecurringExpense is the recurring expense template representation used by the RecurringDB interface.
+// A nil EndDate means the template repeats indefinitely.
+type MockRecurringExpense struct {
+	ID          string
+	UserID      string
+	CategoryID  string
+	AmountCents int64
+	Note        string
+	Frequency   string
+	StartDate   time.Time
+	EndDate     *time.Time
+	NextDate    time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RecurringDB abstracts database operations for recurring expense templates.
+// This allows testing with mock implementations.
+type RecurringDB interface {
+	CreateRecurringExpense(userID, categoryID string, amountCents int64, note, frequency string, startDate time.Time, endDate *time.Time) (MockRecurringExpense, error)
+	GetRecurringExpensesByUser(userID string) ([]MockRecurringExpense, error)
+	UpdateRecurringExpense(id, userID, categoryID string, amountCents int64, note, frequency string, startDate time.Time, endDate *time.Time) (MockRecurringExpense, error)
+	DeleteRecurringExpense(id, userID string) error
+}
+
+// RecurringHandler handles recurring expense HTTP requests.
+type RecurringHandler struct {
+	db RecurringDB
+}
+
+// NewRecurringHandler creates a RecurringHandler with the given database.
+func NewRecurringHandler(db RecurringDB) *RecurringHandler {
+	return &RecurringHandler{db: db}
+}
+
+type recurringExpenseRequest struct {
+	CategoryID  string `json:"category_id"`
+	AmountCents int64  `json:"amount_cents"`
+	Note        string `json:"note"`
+	Frequency   string `json:"frequency"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+}
+
+// bindRecurringRequest parses and validates a create/update body.
+// It writes a 400 response and returns false on invalid input.
+func bindRecurringRequest(c *gin.Context) (recurringExpenseRequest, time.Time, *time.Time, bool) {
+	var req recurringExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return req, time.Time{}, nil, false
+	}
+
+	if req.CategoryID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category_id is required"})
+		return req, time.Time{}, nil, false
+	}
+
+	if req.AmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount_cents must be greater than 0"})
+		return req, time.Time{}, nil, false
+	}
+
+	if !recurring.ValidFrequency(req.Frequency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "frequency must be one of: daily, weekly, monthly, yearly"})
+		return req, time.Time{}, nil, false
+	}
+
+	var startDate time.Time
+	if req.StartDate == "" {
+		now := time.Now()
+		startDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	} else {
+		var err error
+		startDate, err = time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must be in YYYY-MM-DD format"})
+			return req, time.Time{}, nil, false
+		}
+	}
+
+	var endDate *time.Time
+	if req.EndDate != "" {
+		t, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be in YYYY-MM-DD format"})
+			return req, time.Time{}, nil, false
+		}
+		if t.Before(startDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must not be before start_date"})
+			return req, time.Time{}, nil, false
+		}
+		endDate = &t
+	}
+
+	return req, startDate, endDate, true
+}
+
+// Create handles POST /api/v1/recurring-expenses.
+func (h *RecurringHandler) Create(c *gin.Context) {
+	req, startDate, endDate, ok := bindRecurringRequest(c)
+	if !ok {
+		return
+	}
+
+	userID := c.GetString("user_id")
+	r, err := h.db.CreateRecurringExpense(userID, req.CategoryID, req.AmountCents, req.Note, req.Frequency, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRecurringCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, recurringResponse(r))
+}
+
+// List handles GET /api/v1/recurring-expenses.
+func (h *RecurringHandler) List(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	items, err := h.db.GetRecurringExpensesByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(items))
+	for i, r := range items {
+		result[i] = recurringResponse(r)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Update handles PUT /api/v1/recurring-expenses/:id.
+// Occurrences that were already posted are kept; the new schedule applies from
+// the template's next pending date onward.
+func (h *RecurringHandler) Update(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("user_id")
+
+	req, startDate, endDate, ok := bindRecurringRequest(c)
+	if !ok {
+		return
+	}
+
+	r, err := h.db.UpdateRecurringExpense(id, userID, req.CategoryID, req.AmountCents, req.Note, req.Frequency, startDate, endDate)
+	if err != nil {
+		if errors.Is(err, ErrRecurringExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recurring expense not found"})
+			return
+		}
+		if errors.Is(err, ErrInvalidRecurringCategory) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, recurringResponse(r))
+}
+
+// Delete handles DELETE /api/v1/recurring-expenses/:id.
+// Expenses already posted from the template are kept.
+func (h *RecurringHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("user_id")
+
+	if err := h.db.DeleteRecurringExpense(id, userID); err != nil {
+		if errors.Is(err, ErrRecurringExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recurring expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func recurringResponse(r MockRecurringExpense) gin.H {
+	var endDate any
+	if r.EndDate != nil {
+		endDate = r.EndDate.Format("2006-01-02")
+	}
+	return gin.H{
+		"id":           r.ID,
+		"user_id":      r.UserID,
+		"category_id":  r.CategoryID,
+		"amount_cents": r.AmountCents,
+		"note":         r.Note,
+		"frequency":    r.Frequency,
+		"start_date":   r.StartDate.Format("2006-01-02"),
+		"end_date":     endDate,
+		"next_date":    r.NextDate.Format("2006-01-02"),
+		"created_at":   r.CreatedAt,
+		"updated_at":   r.UpdatedAt,
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+	"github.com/nnc/finance-tracker/server/internal/recurring"
+)
+
+// PgRecurringDB implements RecurringDB using sqlc-generated queries against PostgreSQL.
+type PgRecurringDB struct {
+	queries *sqlc.Queries
+}
+
+// NewPgRecurringDB creates a PgRecurringDB wrapping sqlc.Queries.
+func NewPgRecurringDB(queries *sqlc.Queries) *PgRecurringDB {
+	return &PgRecurringDB{queries: queries}
+}
+
+// checkRecurringCategory verifies that the category belongs to the user and is
+// an expense category that is not archived.
+func (db *PgRecurringDB) checkRecurringCategory(userID, categoryID pgtype.UUID) error {
+	cat, err := db.queries.GetCategoryByID(context.Background(), sqlc.GetCategoryByIDParams{
+		ID:     categoryID,
+		UserID: userID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrInvalidRecurringCategory
+		}
+		return err
+	}
+	if cat.Kind != CategoryKindExpense || cat.ArchivedAt.Valid {
+		return ErrInvalidRecurringCategory
+	}
+	return nil
+}
+
+func (db *PgRecurringDB) CreateRecurringExpense(userID, categoryID string, amountCents int64, note, frequency string, startDate time.Time, endDate *time.Time) (MockRecurringExpense, error) {
+	uid := stringToUUID(userID)
+	cid := stringToUUID(categoryID)
+
+	if err := db.checkRecurringCategory(uid, cid); err != nil {
+		return MockRecurringExpense{}, err
+	}
+
+	row, err := db.queries.CreateRecurringExpense(context.Background(), sqlc.CreateRecurringExpenseParams{
+		UserID:      uid,
+		CategoryID:  cid,
+		AmountCents: amountCents,
+		Note:        note,
+		Frequency:   frequency,
+		StartDate:   pgtype.Date{Time: startDate, Valid: true},
+		EndDate:     dateToPgDate(endDate),
+	})
+	if err != nil {
+		return MockRecurringExpense{}, err
+	}
+
+	return recurringFromRow(row), nil
+}
+
+func (db *PgRecurringDB) GetRecurringExpensesByUser(userID string) ([]MockRecurringExpense, error) {
+	rows, err := db.queries.GetRecurringExpensesByUser(context.Background(), stringToUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]MockRecurringExpense, len(rows))
+	for i, row := range rows {
+		items[i] = recurringFromRow(row)
+	}
+	return items, nil
+}
+
+func (db *PgRecurringDB) UpdateRecurringExpense(id, userID, categoryID string, amountCents int64, note, frequency string, startDate time.Time, endDate *time.Time) (MockRecurringExpense, error) {
+	rid := stringToUUID(id)
+	uid := stringToUUID(userID)
+	cid := stringToUUID(categoryID)
+
+	existing, err := db.queries.GetRecurringExpenseByID(context.Background(), sqlc.GetRecurringExpenseByIDParams{
+		ID:     rid,
+		UserID: uid,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockRecurringExpense{}, ErrRecurringExpenseNotFound
+		}
+		return MockRecurringExpense{}, err
+	}
+
+	if err := db.checkRecurringCategory(uid, cid); err != nil {
+		return MockRecurringExpense{}, err
+	}
+
+	// Everything before the current next_date has already been posted, so the
+	// new schedule resumes from there instead of backfilling.
+	nextDate := recurring.NextOnOrAfter(startDate, frequency, existing.NextDate.Time)
+
+	row, err := db.queries.UpdateRecurringExpense(context.Background(), sqlc.UpdateRecurringExpenseParams{
+		ID:          rid,
+		UserID:      uid,
+		CategoryID:  cid,
+		AmountCents: amountCents,
+		Note:        note,
+		Frequency:   frequency,
+		StartDate:   pgtype.Date{Time: startDate, Valid: true},
+		EndDate:     dateToPgDate(endDate),
+		NextDate:    pgtype.Date{Time: nextDate, Valid: true},
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockRecurringExpense{}, ErrRecurringExpenseNotFound
+		}
+		return MockRecurringExpense{}, err
+	}
+
+	return recurringFromRow(row), nil
+}
+
+func (db *PgRecurringDB) DeleteRecurringExpense(id, userID string) error {
+	rowsAffected, err := db.queries.DeleteRecurringExpense(context.Background(), sqlc.DeleteRecurringExpenseParams{
+		ID:     stringToUUID(id),
+		UserID: stringToUUID(userID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrRecurringExpenseNotFound
+	}
+	return nil
+}
+
+func recurringFromRow(row sqlc.RecurringExpense) MockRecurringExpense {
+	r := MockRecurringExpense{
+		ID:          uuidToString(row.ID),
+		UserID:      uuidToString(row.UserID),
+		CategoryID:  uuidToString(row.CategoryID),
+		AmountCents: row.AmountCents,
+		Note:        row.Note,
+		Frequency:   row.Frequency,
+		StartDate:   row.StartDate.Time,
+		NextDate:    row.NextDate.Time,
+		CreatedAt:   row.CreatedAt.Time,
+		UpdatedAt:   row.UpdatedAt.Time,
+	}
+	if row.EndDate.Valid {
+		endDate := row.EndDate.Time
+		r.EndDate = &endDate
+	}
+	return r
+}
@@ -0,0 +1,241 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+// mockRecurringDB implements handler.RecurringDB for testing.
+type mockRecurringDB struct {
+	items  []handler.MockRecurringExpense
+	nextID int
+}
+
+func newMockRecurringDB() *mockRecurringDB {
+	return &mockRecurringDB{
+		items:  make([]handler.MockRecurringExpense, 0),
+		nextID: 1,
+	}
+}
+
+func (m *mockRecurringDB) CreateRecurringExpense(userID, categoryID string, amountCents int64, note, frequency string, startDate time.Time, endDate *time.Time) (handler.MockRecurringExpense, error) {
+	if categoryID == testIncomeCategoryID {
+		return handler.MockRecurringExpense{}, handler.ErrInvalidRecurringCategory
+	}
+	r := handler.MockRecurringExpense{
+		ID:          "rec-" + string(rune('0'+m.nextID)),
+		UserID:      userID,
+		CategoryID:  categoryID,
+		AmountCents: amountCents,
+		Note:        note,
+		Frequency:   frequency,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		NextDate:    startDate,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	m.nextID++
+	m.items = append(m.items, r)
+	return r, nil
+}
+
+func (m *mockRecurringDB) GetRecurringExpensesByUser(userID string) ([]handler.MockRecurringExpense, error) {
+	var result []handler.MockRecurringExpense
+	for _, r := range m.items {
+		if r.UserID == userID {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockRecurringDB) UpdateRecurringExpense(id, userID, categoryID string, amountCents int64, note, frequency string, startDate time.Time, endDate *time.Time) (handler.MockRecurringExpense, error) {
+	for i, r := range m.items {
+		if r.ID == id && r.UserID == userID {
+			m.items[i].CategoryID = categoryID
+			m.items[i].AmountCents = amountCents
+			m.items[i].Note = note
+			m.items[i].Frequency = frequency
+			m.items[i].StartDate = startDate
+			m.items[i].EndDate = endDate
+			m.items[i].UpdatedAt = time.Now()
+			return m.items[i], nil
+		}
+	}
+	return handler.MockRecurringExpense{}, handler.ErrRecurringExpenseNotFound
+}
+
+func (m *mockRecurringDB) DeleteRecurringExpense(id, userID string) error {
+	for i, r := range m.items {
+		if r.ID == id && r.UserID == userID {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return nil
+		}
+	}
+	return handler.ErrRecurringExpenseNotFound
+}
+
+func setupRecurringRouter(db handler.RecurringDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewRecurringHandler(db)
+
+	recurring := r.Group("/api/v1/recurring-expenses")
+	recurring.Use(func(c *gin.Context) {
+		c.Set("user_id", testUserID)
+		c.Next()
+	})
+	{
+		recurring.POST("", h.Create)
+		recurring.GET("", h.List)
+		recurring.PUT("/:id", h.Update)
+		recurring.DELETE("/:id", h.Delete)
+	}
+	return r
+}
+
+func postRecurring(r *gin.Engine, payload map[string]any) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/recurring-expenses", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateRecurring_Success(t *testing.T) {
+	db := newMockRecurringDB()
+	r := setupRecurringRouter(db)
+
+	w := postRecurring(r, map[string]any{
+		"category_id":  "550e8400-e29b-41d4-a716-446655440001",
+		"amount_cents": 120000,
+		"note":         "Rent",
+		"frequency":    "monthly",
+		"start_date":   "2026-01-31",
+		"end_date":     "2026-12-31",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["frequency"] != "monthly" {
+		t.Fatalf("expected frequency monthly, got %v", resp["frequency"])
+	}
+	if resp["next_date"] != "2026-01-31" {
+		t.Fatalf("expected next_date 2026-01-31, got %v", resp["next_date"])
+	}
+	if resp["end_date"] != "2026-12-31" {
+		t.Fatalf("expected end_date 2026-12-31, got %v", resp["end_date"])
+	}
+}
+
+func TestCreateRecurring_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]any
+	}{
+		{"missing category", map[string]any{"amount_cents": 1000, "frequency": "monthly"}},
+		{"invalid amount", map[string]any{"category_id": "cat-1", "amount_cents": 0, "frequency": "monthly"}},
+		{"invalid frequency", map[string]any{"category_id": "cat-1", "amount_cents": 1000, "frequency": "hourly"}},
+		{"invalid start date", map[string]any{"category_id": "cat-1", "amount_cents": 1000, "frequency": "weekly", "start_date": "03/01/2026"}},
+		{"end before start", map[string]any{"category_id": "cat-1", "amount_cents": 1000, "frequency": "weekly", "start_date": "2026-03-01", "end_date": "2026-02-01"}},
+		{"income category", map[string]any{"category_id": testIncomeCategoryID, "amount_cents": 1000, "frequency": "weekly"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupRecurringRouter(newMockRecurringDB())
+			w := postRecurring(r, tt.payload)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestListRecurring(t *testing.T) {
+	db := newMockRecurringDB()
+	r := setupRecurringRouter(db)
+
+	postRecurring(r, map[string]any{"category_id": "cat-1", "amount_cents": 999, "frequency": "monthly", "note": "Streaming"})
+	postRecurring(r, map[string]any{"category_id": "cat-1", "amount_cents": 120000, "frequency": "monthly", "note": "Rent"})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/recurring-expenses", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp []map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp) != 2 {
+		t.Fatalf("expected 2 templates, got %d", len(resp))
+	}
+	if resp[0]["end_date"] != nil {
+		t.Fatalf("expected null end_date, got %v", resp[0]["end_date"])
+	}
+}
+
+func TestUpdateRecurring(t *testing.T) {
+	db := newMockRecurringDB()
+	r := setupRecurringRouter(db)
+	postRecurring(r, map[string]any{"category_id": "cat-1", "amount_cents": 999, "frequency": "monthly", "start_date": "2026-01-01"})
+
+	body, _ := json.Marshal(map[string]any{
+		"category_id":  "cat-1",
+		"amount_cents": 1299,
+		"frequency":    "yearly",
+		"start_date":   "2026-01-01",
+	})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/recurring-expenses/rec-1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if db.items[0].AmountCents != 1299 || db.items[0].Frequency != "yearly" {
+		t.Fatalf("template not updated: %+v", db.items[0])
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/recurring-expenses/missing", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDeleteRecurring(t *testing.T) {
+	db := newMockRecurringDB()
+	r := setupRecurringRouter(db)
+	postRecurring(r, map[string]any{"category_id": "cat-1", "amount_cents": 999, "frequency": "weekly"})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/recurring-expenses/rec-1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/recurring-expenses/rec-1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,555 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sentinel errors for split and settlement operations.
+var (
+	ErrSplitNotFound = errors.New("split not found")
+)
+
+// Split methods.
+const (
+	SplitMethodEqual  = "equal"
+	SplitMethodShares = "shares"
+	SplitMethodExact  = "exact"
+)
+
+// MockSplitExpense is the expense representation used by the SplitDB interface.
+// UserID is the member who paid.
+type MockSplitExpense struct {
+	ID          string
+	UserID      string
+	AmountCents int64
+	Currency    string
+}
+
+// MockExpenseShare is one member's share of a split expense, in the
+// expense's currency.
+type MockExpenseShare struct {
+	UserID      string
+	UserEmail   string
+	Shares      int
+	AmountCents int64
+}
+
+// MockExpenseSplit is the split representation used by the SplitDB interface.
+type MockExpenseSplit struct {
+	Method string
+	Shares []MockExpenseShare
+}
+
+// MockMemberBalance is a member's net balance in the family's base currency.
+// A positive balance means the member is owed money.
+type MockMemberBalance struct {
+	UserID    string
+	UserEmail string
+	NetCents  int64
+}
+
+// MockSettlement is the settlement representation used by the SplitDB interface.
+type MockSettlement struct {
+	ID            string
+	FromUserID    string
+	FromUserEmail string
+	ToUserID      string
+	ToUserEmail   string
+	AmountCents   int64
+	Currency      string
+	Note          string
+	SettledOn     time.Time
+	CreatedAt     time.Time
+}
+
+// SplitDB abstracts database operations for expense splits and settlements.
+// This allows testing with mock implementations.
+//
+// Splits are scoped to the family they were recorded in, so an expense's
+// splits in a family its payer has left are not visible to a new family.
+type SplitDB interface {
+	GetFamilyExpense(familyID, expenseID string) (MockSplitExpense, error)
+	GetExpenseSplit(familyID, expenseID string) (MockExpenseSplit, error)
+	SetExpenseSplit(familyID, expenseID string, split MockExpenseSplit) error
+	DeleteExpenseSplit(familyID, expenseID string) error
+	GetFamilyBalances(familyID string) ([]MockMemberBalance, error)
+	CreateSettlement(familyID, fromUserID, toUserID string, amountCents int64, currency, note string, settledOn time.Time) (MockSettlement, error)
+	GetFamilySettlements(familyID string, limit, offset int) ([]MockSettlement, error)
+}
+
+// SplitHandler handles expense split, balance and settlement HTTP requests.
+type SplitHandler struct {
+	db       SplitDB
+	familyDB FamilyDB
+}
+
+// NewSplitHandler creates a SplitHandler with the given databases.
+func NewSplitHandler(db SplitDB, familyDB FamilyDB) *SplitHandler {
+	return &SplitHandler{db: db, familyDB: familyDB}
+}
+
+type splitMemberRequest struct {
+	UserID      string `json:"user_id"`
+	Shares      int    `json:"shares"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+type setSplitRequest struct {
+	Method  string               `json:"method"`
+	Members []splitMemberRequest `json:"members"`
+}
+
+// GetSplit handles GET /api/v1/families/:familyId/expenses/:id/split.
+func (h *SplitHandler) GetSplit(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
+		return
+	}
+
+	expense, err := h.db.GetFamilyExpense(family.ID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	split, err := h.db.GetExpenseSplit(family.ID, expense.ID)
+	if err != nil {
+		if errors.Is(err, ErrSplitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense is not split"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, splitResponse(expense, split))
+}
+
+// SetSplit handles PUT /api/v1/families/:familyId/expenses/:id/split.
+// The expense is divided among the listed members equally, by shares or by
+// exact amounts, replacing any previous split. With the equal method,
+// omitting members splits the expense across the whole family. Only the
+// payer or the family admin can split an expense.
+func (h *SplitHandler) SetSplit(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req setSplitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	req.Method = strings.ToLower(req.Method)
+	if req.Method != SplitMethodEqual && req.Method != SplitMethodShares && req.Method != SplitMethodExact {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "method must be one of: equal, shares, exact"})
+		return
+	}
+
+	family, ok := authorizeFamily(c, h.familyDB, PermContribute)
+	if !ok {
+		return
+	}
+
+	expense, err := h.db.GetFamilyExpense(family.ID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	if expense.UserID != userID && !HasPermission(family.Role, PermManageFamily) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the payer or an admin can split this expense"})
+		return
+	}
+
+	members, err := h.familyDB.GetFamilyMembers(family.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	emails := make(map[string]string, len(members))
+	for _, m := range members {
+		emails[m.UserID] = m.Email
+	}
+
+	if len(req.Members) == 0 && req.Method == SplitMethodEqual {
+		for _, m := range members {
+			req.Members = append(req.Members, splitMemberRequest{UserID: m.UserID})
+		}
+	}
+
+	shares, err := computeSplit(req.Method, expense.AmountCents, req.Members)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for i, s := range shares {
+		email, ok := emails[s.UserID]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "all members must belong to the family"})
+			return
+		}
+		shares[i].UserEmail = email
+	}
+
+	split := MockExpenseSplit{Method: req.Method, Shares: shares}
+	if err := h.db.SetExpenseSplit(family.ID, expense.ID, split); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, splitResponse(expense, split))
+}
+
+// DeleteSplit handles DELETE /api/v1/families/:familyId/expenses/:id/split.
+func (h *SplitHandler) DeleteSplit(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	family, ok := authorizeFamily(c, h.familyDB, PermContribute)
+	if !ok {
+		return
+	}
+
+	expense, err := h.db.GetFamilyExpense(family.ID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrExpenseNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	if expense.UserID != userID && !HasPermission(family.Role, PermManageFamily) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the payer or an admin can split this expense"})
+		return
+	}
+
+	if err := h.db.DeleteExpenseSplit(family.ID, expense.ID); err != nil {
+		if errors.Is(err, ErrSplitNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Expense is not split"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Balances handles GET /api/v1/families/:familyId/balances.
+// It returns each member's net balance and a list of transfers that
+// settles everyone up, simplified so nobody both pays and receives.
+func (h *SplitHandler) Balances(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
+		return
+	}
+
+	balances, err := h.db.GetFamilyBalances(family.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	members := make([]gin.H, len(balances))
+	for i, b := range balances {
+		members[i] = gin.H{
+			"user_id":    b.UserID,
+			"user_email": b.UserEmail,
+			"net_cents":  b.NetCents,
+		}
+	}
+
+	transfers := simplifyDebts(balances)
+	settleUp := make([]gin.H, len(transfers))
+	for i, t := range transfers {
+		settleUp[i] = gin.H{
+			"from_user_id":    t.from.UserID,
+			"from_user_email": t.from.UserEmail,
+			"to_user_id":      t.to.UserID,
+			"to_user_email":   t.to.UserEmail,
+			"amount_cents":    t.amountCents,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"currency":  family.BaseCurrency,
+		"balances":  members,
+		"settle_up": settleUp,
+	})
+}
+
+type createSettlementRequest struct {
+	ToUserID    string `json:"to_user_id"`
+	AmountCents int64  `json:"amount_cents"`
+	Currency    string `json:"currency"`
+	Note        string `json:"note"`
+	SettledOn   string `json:"settled_on"`
+}
+
+// CreateSettlement handles POST /api/v1/families/:familyId/settlements.
+// It records that the current user paid to_user_id back. The amount is in
+// the family's base currency unless currency is given.
+func (h *SplitHandler) CreateSettlement(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req createSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if req.ToUserID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to_user_id is required"})
+		return
+	}
+	if req.ToUserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot settle with yourself"})
+		return
+	}
+	if req.AmountCents <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount_cents must be greater than 0"})
+		return
+	}
+
+	currency, ok := parseCurrency(req.Currency)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be a 3-letter ISO 4217 code"})
+		return
+	}
+
+	settledOn := time.Now()
+	if req.SettledOn != "" {
+		var err error
+		settledOn, err = time.Parse("2006-01-02", req.SettledOn)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "settled_on must be in YYYY-MM-DD format"})
+			return
+		}
+	}
+
+	family, ok := authorizeFamily(c, h.familyDB, PermContribute)
+	if !ok {
+		return
+	}
+	if currency == "" {
+		currency = family.BaseCurrency
+	}
+
+	members, err := h.familyDB.GetFamilyMembers(family.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	emails := make(map[string]string, len(members))
+	for _, m := range members {
+		emails[m.UserID] = m.Email
+	}
+	if _, ok := emails[req.ToUserID]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to_user_id must belong to the family"})
+		return
+	}
+
+	settlement, err := h.db.CreateSettlement(family.ID, userID, req.ToUserID, req.AmountCents, currency, req.Note, settledOn)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported currency"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+	settlement.FromUserEmail = emails[userID]
+	settlement.ToUserEmail = emails[req.ToUserID]
+
+	c.JSON(http.StatusCreated, settlementResponse(settlement))
+}
+
+// ListSettlements handles GET /api/v1/families/:familyId/settlements.
+func (h *SplitHandler) ListSettlements(c *gin.Context) {
+	family, ok := authorizeFamily(c, h.familyDB, PermViewFamily)
+	if !ok {
+		return
+	}
+
+	limit, offset := parsePagination(c)
+
+	settlements, err := h.db.GetFamilySettlements(family.ID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	result := make([]gin.H, len(settlements))
+	for i, s := range settlements {
+		result[i] = settlementResponse(s)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// computeSplit divides amountCents among members according to method.
+// Cents that do not divide evenly go to the members listed first (equal) or
+// with the largest remainders (shares), so shares always add up exactly.
+func computeSplit(method string, amountCents int64, members []splitMemberRequest) ([]MockExpenseShare, error) {
+	if len(members) == 0 {
+		return nil, errors.New("members is required")
+	}
+
+	seen := make(map[string]bool, len(members))
+	for _, m := range members {
+		if m.UserID == "" {
+			return nil, errors.New("user_id is required for every member")
+		}
+		if seen[m.UserID] {
+			return nil, errors.New("members must not contain duplicates")
+		}
+		seen[m.UserID] = true
+	}
+
+	shares := make([]MockExpenseShare, len(members))
+	switch method {
+	case SplitMethodEqual:
+		n := int64(len(members))
+		for i, m := range members {
+			shares[i] = MockExpenseShare{UserID: m.UserID, Shares: 1, AmountCents: amountCents / n}
+			if int64(i) < amountCents%n {
+				shares[i].AmountCents++
+			}
+		}
+
+	case SplitMethodShares:
+		var total int64
+		for _, m := range members {
+			if m.Shares <= 0 {
+				return nil, errors.New("shares must be greater than 0")
+			}
+			total += int64(m.Shares)
+		}
+
+		remainders := make([]int64, len(members))
+		var assigned int64
+		for i, m := range members {
+			exact := amountCents * int64(m.Shares)
+			shares[i] = MockExpenseShare{UserID: m.UserID, Shares: m.Shares, AmountCents: exact / total}
+			remainders[i] = exact % total
+			assigned += shares[i].AmountCents
+		}
+
+		order := make([]int, len(members))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return remainders[order[a]] > remainders[order[b]]
+		})
+		for i := int64(0); i < amountCents-assigned; i++ {
+			shares[order[i]].AmountCents++
+		}
+
+	case SplitMethodExact:
+		var sum int64
+		for i, m := range members {
+			if m.AmountCents < 0 {
+				return nil, errors.New("amount_cents must not be negative")
+			}
+			shares[i] = MockExpenseShare{UserID: m.UserID, Shares: 1, AmountCents: m.AmountCents}
+			sum += m.AmountCents
+		}
+		if sum != amountCents {
+			return nil, errors.New("amounts must add up to the expense amount")
+		}
+	}
+
+	return shares, nil
+}
+
+// debtTransfer is a single payment suggested to settle balances.
+type debtTransfer struct {
+	from        MockMemberBalance
+	to          MockMemberBalance
+	amountCents int64
+}
+
+// simplifyDebts turns net balances into transfers by repeatedly matching the
+// largest debtor with the largest creditor. Each step settles at least one
+// member, so there are at most len(balances)-1 transfers.
+func simplifyDebts(balances []MockMemberBalance) []debtTransfer {
+	var debtors, creditors []MockMemberBalance
+	for _, b := range balances {
+		switch {
+		case b.NetCents < 0:
+			debtors = append(debtors, b)
+		case b.NetCents > 0:
+			creditors = append(creditors, b)
+		}
+	}
+	sort.SliceStable(debtors, func(i, j int) bool { return debtors[i].NetCents < debtors[j].NetCents })
+	sort.SliceStable(creditors, func(i, j int) bool { return creditors[i].NetCents > creditors[j].NetCents })
+
+	transfers := []debtTransfer{}
+	for len(debtors) > 0 && len(creditors) > 0 {
+		d, cr := &debtors[0], &creditors[0]
+		amount := min(-d.NetCents, cr.NetCents)
+		transfers = append(transfers, debtTransfer{from: *d, to: *cr, amountCents: amount})
+
+		d.NetCents += amount
+		cr.NetCents -= amount
+		if d.NetCents == 0 {
+			debtors = debtors[1:]
+		}
+		if cr.NetCents == 0 {
+			creditors = creditors[1:]
+		}
+	}
+	return transfers
+}
+
+func splitResponse(expense MockSplitExpense, split MockExpenseSplit) gin.H {
+	members := make([]gin.H, len(split.Shares))
+	for i, s := range split.Shares {
+		members[i] = gin.H{
+			"user_id":      s.UserID,
+			"user_email":   s.UserEmail,
+			"shares":       s.Shares,
+			"amount_cents": s.AmountCents,
+		}
+	}
+	return gin.H{
+		"expense_id":   expense.ID,
+		"paid_by":      expense.UserID,
+		"amount_cents": expense.AmountCents,
+		"currency":     expense.Currency,
+		"method":       split.Method,
+		"members":      members,
+	}
+}
+
+func settlementResponse(s MockSettlement) gin.H {
+	return gin.H{
+		"id":              s.ID,
+		"from_user_id":    s.FromUserID,
+		"from_user_email": s.FromUserEmail,
+		"to_user_id":      s.ToUserID,
+		"to_user_email":   s.ToUserEmail,
+		"amount_cents":    s.AmountCents,
+		"currency":        s.Currency,
+		"note":            s.Note,
+		"settled_on":      s.SettledOn.Format("2006-01-02"),
+		"created_at":      s.CreatedAt,
+	}
+}
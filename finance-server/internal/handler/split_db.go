@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// PgSplitDB implements SplitDB using sqlc-generated queries against PostgreSQL.
+type PgSplitDB struct {
+	queries *sqlc.Queries
+	pool    *pgxpool.Pool
+}
+
+// NewPgSplitDB creates a PgSplitDB wrapping sqlc.Queries.
+// The pool is used to replace splits in a transaction.
+func NewPgSplitDB(queries *sqlc.Queries, pool *pgxpool.Pool) *PgSplitDB {
+	return &PgSplitDB{queries: queries, pool: pool}
+}
+
+func (db *PgSplitDB) GetFamilyExpense(familyID, expenseID string) (MockSplitExpense, error) {
+	row, err := db.queries.GetFamilyExpenseByID(context.Background(), sqlc.GetFamilyExpenseByIDParams{
+		ID:       stringToUUID(expenseID),
+		FamilyID: stringToUUID(familyID),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return MockSplitExpense{}, ErrExpenseNotFound
+		}
+		return MockSplitExpense{}, err
+	}
+
+	return MockSplitExpense{
+		ID:          uuidToString(row.ID),
+		UserID:      uuidToString(row.UserID),
+		AmountCents: row.AmountCents,
+		Currency:    row.Currency,
+	}, nil
+}
+
+func (db *PgSplitDB) GetExpenseSplit(familyID, expenseID string) (MockExpenseSplit, error) {
+	rows, err := db.queries.GetExpenseSplits(context.Background(), sqlc.GetExpenseSplitsParams{
+		ExpenseID: stringToUUID(expenseID),
+		FamilyID:  stringToUUID(familyID),
+	})
+	if err != nil {
+		return MockExpenseSplit{}, err
+	}
+	if len(rows) == 0 {
+		return MockExpenseSplit{}, ErrSplitNotFound
+	}
+
+	split := MockExpenseSplit{Method: rows[0].Method, Shares: make([]MockExpenseShare, len(rows))}
+	for i, row := range rows {
+		split.Shares[i] = MockExpenseShare{
+			UserID:      uuidToString(row.UserID),
+			UserEmail:   row.UserEmail,
+			Shares:      int(row.Shares),
+			AmountCents: row.AmountCents,
+		}
+	}
+	return split, nil
+}
+
+func (db *PgSplitDB) SetExpenseSplit(familyID, expenseID string, split MockExpenseSplit) error {
+	ctx := context.Background()
+	eid := stringToUUID(expenseID)
+	fid := stringToUUID(familyID)
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := db.queries.WithTx(tx)
+	_, err = qtx.DeleteExpenseSplits(ctx, sqlc.DeleteExpenseSplitsParams{
+		ExpenseID: eid,
+		FamilyID:  fid,
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range split.Shares {
+		err := qtx.CreateExpenseSplit(ctx, sqlc.CreateExpenseSplitParams{
+			ExpenseID:   eid,
+			FamilyID:    fid,
+			UserID:      stringToUUID(s.UserID),
+			Method:      split.Method,
+			Shares:      int32(s.Shares),
+			AmountCents: s.AmountCents,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (db *PgSplitDB) DeleteExpenseSplit(familyID, expenseID string) error {
+	rowsAffected, err := db.queries.DeleteExpenseSplits(context.Background(), sqlc.DeleteExpenseSplitsParams{
+		ExpenseID: stringToUUID(expenseID),
+		FamilyID:  stringToUUID(familyID),
+	})
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrSplitNotFound
+	}
+	return nil
+}
+
+func (db *PgSplitDB) GetFamilyBalances(familyID string) ([]MockMemberBalance, error) {
+	rows, err := db.queries.GetFamilyBalances(context.Background(), stringToUUID(familyID))
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]MockMemberBalance, len(rows))
+	for i, row := range rows {
+		balances[i] = MockMemberBalance{
+			UserID:    uuidToString(row.UserID),
+			UserEmail: row.UserEmail,
+			NetCents:  row.NetCents,
+		}
+	}
+	return balances, nil
+}
+
+func (db *PgSplitDB) CreateSettlement(familyID, fromUserID, toUserID string, amountCents int64, currency, note string, settledOn time.Time) (MockSettlement, error) {
+	row, err := db.queries.CreateSettlement(context.Background(), sqlc.CreateSettlementParams{
+		FamilyID:    stringToUUID(familyID),
+		FromUserID:  stringToUUID(fromUserID),
+		ToUserID:    stringToUUID(toUserID),
+		AmountCents: amountCents,
+		Currency:    currency,
+		Note:        note,
+		SettledOn:   pgtype.Date{Time: settledOn, Valid: true},
+	})
+	if err != nil {
+		return MockSettlement{}, currencyError(err)
+	}
+
+	return MockSettlement{
+		ID:          uuidToString(row.ID),
+		FromUserID:  uuidToString(row.FromUserID),
+		ToUserID:    uuidToString(row.ToUserID),
+		AmountCents: row.AmountCents,
+		Currency:    row.Currency,
+		Note:        row.Note,
+		SettledOn:   row.SettledOn.Time,
+		CreatedAt:   row.CreatedAt.Time,
+	}, nil
+}
+
+func (db *PgSplitDB) GetFamilySettlements(familyID string, limit, offset int) ([]MockSettlement, error) {
+	rows, err := db.queries.GetFamilySettlements(context.Background(), sqlc.GetFamilySettlementsParams{
+		FamilyID: stringToUUID(familyID),
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	settlements := make([]MockSettlement, len(rows))
+	for i, row := range rows {
+		settlements[i] = MockSettlement{
+			ID:            uuidToString(row.ID),
+			FromUserID:    uuidToString(row.FromUserID),
+			FromUserEmail: row.FromUserEmail,
+			ToUserID:      uuidToString(row.ToUserID),
+			ToUserEmail:   row.ToUserEmail,
+			AmountCents:   row.AmountCents,
+			Currency:      row.Currency,
+			Note:          row.Note,
+			SettledOn:     row.SettledOn.Time,
+			CreatedAt:     row.CreatedAt.Time,
+		}
+	}
+	return settlements, nil
+}
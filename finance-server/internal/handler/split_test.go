@@ -0,0 +1,445 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nnc/finance-tracker/server/internal/handler"
+)
+
+// splitKey identifies a split by family and expense.
+type splitKey struct {
+	familyID  string
+	expenseID string
+}
+
+// mockSplitDB implements handler.SplitDB for testing.
+type mockSplitDB struct {
+	expenses    map[string]handler.MockSplitExpense // expenseID -> expense
+	splits      map[splitKey]handler.MockExpenseSplit
+	balances    []handler.MockMemberBalance
+	settlements []handler.MockSettlement
+}
+
+func newMockSplitDB() *mockSplitDB {
+	return &mockSplitDB{
+		expenses: map[string]handler.MockSplitExpense{
+			"exp-1": {ID: "exp-1", UserID: "user-2", AmountCents: 1000, Currency: "UAH"},
+		},
+		splits: make(map[splitKey]handler.MockExpenseSplit),
+	}
+}
+
+func (m *mockSplitDB) GetFamilyExpense(familyID, expenseID string) (handler.MockSplitExpense, error) {
+	e, ok := m.expenses[expenseID]
+	if !ok {
+		return handler.MockSplitExpense{}, handler.ErrExpenseNotFound
+	}
+	return e, nil
+}
+
+func (m *mockSplitDB) GetExpenseSplit(familyID, expenseID string) (handler.MockExpenseSplit, error) {
+	s, ok := m.splits[splitKey{familyID, expenseID}]
+	if !ok {
+		return handler.MockExpenseSplit{}, handler.ErrSplitNotFound
+	}
+	return s, nil
+}
+
+func (m *mockSplitDB) SetExpenseSplit(familyID, expenseID string, split handler.MockExpenseSplit) error {
+	m.splits[splitKey{familyID, expenseID}] = split
+	return nil
+}
+
+func (m *mockSplitDB) DeleteExpenseSplit(familyID, expenseID string) error {
+	key := splitKey{familyID, expenseID}
+	if _, ok := m.splits[key]; !ok {
+		return handler.ErrSplitNotFound
+	}
+	delete(m.splits, key)
+	return nil
+}
+
+func (m *mockSplitDB) GetFamilyBalances(familyID string) ([]handler.MockMemberBalance, error) {
+	return m.balances, nil
+}
+
+func (m *mockSplitDB) CreateSettlement(familyID, fromUserID, toUserID string, amountCents int64, currency, note string, settledOn time.Time) (handler.MockSettlement, error) {
+	if !mockCurrencyKnown(currency) {
+		return handler.MockSettlement{}, handler.ErrInvalidCurrency
+	}
+	s := handler.MockSettlement{
+		ID:          "settlement-1",
+		FromUserID:  fromUserID,
+		ToUserID:    toUserID,
+		AmountCents: amountCents,
+		Currency:    currency,
+		Note:        note,
+		SettledOn:   settledOn,
+		CreatedAt:   time.Now(),
+	}
+	m.settlements = append(m.settlements, s)
+	return s, nil
+}
+
+func (m *mockSplitDB) GetFamilySettlements(familyID string, limit, offset int) ([]handler.MockSettlement, error) {
+	return m.settlements, nil
+}
+
+// newSplitFamily returns a family with an admin (user-1) and two members.
+func newSplitFamily() *mockFamilyDB {
+	fdb := newMockFamilyDB()
+	fdb.families["family-1"] = &handler.MockFamily{ID: "family-1", Name: "Smith Family", AdminUserID: "user-1", BaseCurrency: "UAH"}
+	for _, id := range []string{"user-1", "user-2", "user-3"} {
+		fdb.userFamily[id] = "family-1"
+		fdb.members["family-1"] = append(fdb.members["family-1"], handler.MockFamilyMember{
+			FamilyID: "family-1",
+			UserID:   id,
+			Email:    id + "@example.com",
+		})
+	}
+	return fdb
+}
+
+func setupSplitRouter(db handler.SplitDB, familyDB handler.FamilyDB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.NewSplitHandler(db, familyDB)
+
+	families := r.Group("/api/v1/families", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User-ID"))
+		c.Next()
+	})
+	{
+		families.GET("/me/expenses/:id/split", h.GetSplit)
+		families.PUT("/me/expenses/:id/split", h.SetSplit)
+		families.DELETE("/me/expenses/:id/split", h.DeleteSplit)
+		families.GET("/me/balances", h.Balances)
+		families.GET("/me/settlements", h.ListSettlements)
+		families.POST("/me/settlements", h.CreateSettlement)
+	}
+	return r
+}
+
+func splitAmounts(t *testing.T, w *httptest.ResponseRecorder) []int64 {
+	t.Helper()
+	var resp struct {
+		Members []struct {
+			AmountCents int64 `json:"amount_cents"`
+		} `json:"members"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	amounts := make([]int64, len(resp.Members))
+	for i, m := range resp.Members {
+		amounts[i] = m.AmountCents
+	}
+	return amounts
+}
+
+func TestSetSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]any
+		want    []int64
+	}{
+		{
+			name:    "equal across the whole family",
+			payload: map[string]any{"method": "equal"},
+			want:    []int64{334, 333, 333},
+		},
+		{
+			name: "equal between listed members",
+			payload: map[string]any{"method": "equal", "members": []map[string]any{
+				{"user_id": "user-2"}, {"user_id": "user-3"},
+			}},
+			want: []int64{500, 500},
+		},
+		{
+			name: "by shares",
+			payload: map[string]any{"method": "shares", "members": []map[string]any{
+				{"user_id": "user-1", "shares": 1}, {"user_id": "user-2", "shares": 2},
+			}},
+			want: []int64{333, 667},
+		},
+		{
+			name: "exact amounts",
+			payload: map[string]any{"method": "exact", "members": []map[string]any{
+				{"user_id": "user-1", "amount_cents": 250}, {"user_id": "user-2", "amount_cents": 750},
+			}},
+			want: []int64{250, 750},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newMockSplitDB()
+			r := setupSplitRouter(db, newSplitFamily())
+
+			w := putJSON(r, "/api/v1/families/me/expenses/exp-1/split", "user-2", tt.payload)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			got := splitAmounts(t, w)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d members, got %v", len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected amounts %v, got %v", tt.want, got)
+				}
+			}
+			stored := db.splits[splitKey{"family-1", "exp-1"}]
+			if len(stored.Shares) != len(tt.want) {
+				t.Fatalf("split was not stored: %+v", stored)
+			}
+		})
+	}
+}
+
+func TestSetSplit_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]any
+	}{
+		{"unknown method", map[string]any{"method": "percent"}},
+		{"exact does not add up", map[string]any{"method": "exact", "members": []map[string]any{
+			{"user_id": "user-1", "amount_cents": 100}, {"user_id": "user-2", "amount_cents": 100},
+		}}},
+		{"zero shares", map[string]any{"method": "shares", "members": []map[string]any{
+			{"user_id": "user-1", "shares": 0}, {"user_id": "user-2", "shares": 1},
+		}}},
+		{"duplicate member", map[string]any{"method": "equal", "members": []map[string]any{
+			{"user_id": "user-1"}, {"user_id": "user-1"},
+		}}},
+		{"not a family member", map[string]any{"method": "equal", "members": []map[string]any{
+			{"user_id": "user-1"}, {"user_id": "user-9"},
+		}}},
+		{"no members", map[string]any{"method": "shares"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupSplitRouter(newMockSplitDB(), newSplitFamily())
+			w := putJSON(r, "/api/v1/families/me/expenses/exp-1/split", "user-2", tt.payload)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSetSplit_Permissions(t *testing.T) {
+	t.Run("admin can split another member's expense", func(t *testing.T) {
+		r := setupSplitRouter(newMockSplitDB(), newSplitFamily())
+		w := putJSON(r, "/api/v1/families/me/expenses/exp-1/split", "user-1", map[string]any{"method": "equal"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("other member cannot split", func(t *testing.T) {
+		r := setupSplitRouter(newMockSplitDB(), newSplitFamily())
+		w := putJSON(r, "/api/v1/families/me/expenses/exp-1/split", "user-3", map[string]any{"method": "equal"})
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("expense outside the family", func(t *testing.T) {
+		r := setupSplitRouter(newMockSplitDB(), newSplitFamily())
+		w := putJSON(r, "/api/v1/families/me/expenses/exp-9/split", "user-2", map[string]any{"method": "equal"})
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestGetAndDeleteSplit(t *testing.T) {
+	db := newMockSplitDB()
+	r := setupSplitRouter(db, newSplitFamily())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses/exp-1/split", nil)
+	req.Header.Set("X-User-ID", "user-3")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before splitting, got %d: %s", w.Code, w.Body.String())
+	}
+
+	putJSON(r, "/api/v1/families/me/expenses/exp-1/split", "user-2", map[string]any{"method": "equal"})
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses/exp-1/split", nil)
+	req.Header.Set("X-User-ID", "user-3")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["method"] != "equal" || resp["paid_by"] != "user-2" {
+		t.Fatalf("unexpected split: %v", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/families/me/expenses/exp-1/split", nil)
+	req.Header.Set("X-User-ID", "user-2")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := db.splits[splitKey{"family-1", "exp-1"}]; ok {
+		t.Fatal("split should be deleted")
+	}
+}
+
+func TestSplit_OtherFamily(t *testing.T) {
+	db := newMockSplitDB()
+	other := splitKey{"family-2", "exp-1"}
+	db.splits[other] = handler.MockExpenseSplit{Method: "equal"}
+	r := setupSplitRouter(db, newSplitFamily())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/expenses/exp-1/split", nil)
+	req.Header.Set("X-User-ID", "user-3")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for another family's split, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/v1/families/me/expenses/exp-1/split", nil)
+	req.Header.Set("X-User-ID", "user-2")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := db.splits[other]; !ok {
+		t.Fatal("another family's split should not be deleted")
+	}
+}
+
+func TestBalances(t *testing.T) {
+	db := newMockSplitDB()
+	db.balances = []handler.MockMemberBalance{
+		{UserID: "user-1", UserEmail: "user-1@example.com", NetCents: 3000},
+		{UserID: "user-2", UserEmail: "user-2@example.com", NetCents: -1000},
+		{UserID: "user-3", UserEmail: "user-3@example.com", NetCents: -2000},
+		{UserID: "user-4", UserEmail: "user-4@example.com", NetCents: 0},
+	}
+	r := setupSplitRouter(db, newSplitFamily())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/balances", nil)
+	req.Header.Set("X-User-ID", "user-2")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Currency string           `json:"currency"`
+		Balances []map[string]any `json:"balances"`
+		SettleUp []struct {
+			FromUserID  string `json:"from_user_id"`
+			ToUserID    string `json:"to_user_id"`
+			AmountCents int64  `json:"amount_cents"`
+		} `json:"settle_up"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp.Currency != "UAH" || len(resp.Balances) != 4 {
+		t.Fatalf("unexpected response: %s", w.Body.String())
+	}
+	if len(resp.SettleUp) != 2 {
+		t.Fatalf("expected 2 transfers, got %+v", resp.SettleUp)
+	}
+	first, second := resp.SettleUp[0], resp.SettleUp[1]
+	if first.FromUserID != "user-3" || first.ToUserID != "user-1" || first.AmountCents != 2000 {
+		t.Fatalf("unexpected first transfer: %+v", first)
+	}
+	if second.FromUserID != "user-2" || second.ToUserID != "user-1" || second.AmountCents != 1000 {
+		t.Fatalf("unexpected second transfer: %+v", second)
+	}
+}
+
+func TestBalances_SettledUp(t *testing.T) {
+	r := setupSplitRouter(newMockSplitDB(), newSplitFamily())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/families/me/balances", nil)
+	req.Header.Set("X-User-ID", "user-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	settleUp, ok := resp["settle_up"].([]any)
+	if !ok || len(settleUp) != 0 {
+		t.Fatalf("expected empty settle_up array, got %v", resp["settle_up"])
+	}
+}
+
+func postSettlement(r *gin.Engine, userID string, payload map[string]any) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/families/me/settlements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", userID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCreateSettlement(t *testing.T) {
+	t.Run("success defaults to family currency", func(t *testing.T) {
+		db := newMockSplitDB()
+		r := setupSplitRouter(db, newSplitFamily())
+
+		w := postSettlement(r, "user-2", map[string]any{"to_user_id": "user-1", "amount_cents": 1000, "settled_on": "2026-03-10"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp map[string]any
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp["currency"] != "UAH" || resp["settled_on"] != "2026-03-10" || resp["to_user_email"] != "user-1@example.com" {
+			t.Fatalf("unexpected settlement: %v", resp)
+		}
+		if len(db.settlements) != 1 || db.settlements[0].FromUserID != "user-2" {
+			t.Fatalf("settlement was not stored: %+v", db.settlements)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		payload map[string]any
+	}{
+		{"missing recipient", map[string]any{"amount_cents": 1000}},
+		{"self", map[string]any{"to_user_id": "user-2", "amount_cents": 1000}},
+		{"zero amount", map[string]any{"to_user_id": "user-1", "amount_cents": 0}},
+		{"not a family member", map[string]any{"to_user_id": "user-9", "amount_cents": 1000}},
+		{"invalid date", map[string]any{"to_user_id": "user-1", "amount_cents": 1000, "settled_on": "10.03.2026"}},
+		{"unknown currency", map[string]any{"to_user_id": "user-1", "amount_cents": 1000, "currency": "GBP"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := setupSplitRouter(newMockSplitDB(), newSplitFamily())
+			w := postSettlement(r, "user-2", tt.payload)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("no family", func(t *testing.T) {
+		r := setupSplitRouter(newMockSplitDB(), newMockFamilyDB())
+		w := postSettlement(r, "user-2", map[string]any{"to_user_id": "user-1", "amount_cents": 1000})
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -8,15 +8,24 @@ import (
 )
 
 // CategoryTotal represents a category's aggregated expense data.
+// ParentID is empty for top-level categories.
 type CategoryTotal struct {
 	CategoryID    string
 	CategoryName  string
 	CategoryColor string
 	CategoryIcon  string
+	ParentID      string
 	TotalCents    int64
 	Count         int
 }
 
+// TagTotal represents a tag's aggregated expense data.
+type TagTotal struct {
+	TagName    string
+	TotalCents int64
+	Count      int
+}
+
 // DateTotal represents a single date's aggregated expense total.
 type DateTotal struct {
 	Date       string // "2006-01-02" format
@@ -24,69 +33,139 @@ type DateTotal struct {
 }
 
 // SummaryDB abstracts database operations for expense summaries.
+// Expense totals are converted to the user's base currency. Rollup totals
+// are per top-level category and include the expenses of its subcategories.
 type SummaryDB interface {
 	GetCategoryTotals(userID string, dateFrom, dateTo time.Time) ([]CategoryTotal, error)
+	GetCategoryRollupTotals(userID string, dateFrom, dateTo time.Time) ([]CategoryTotal, error)
 	GetDailyTotals(userID string, dateFrom, dateTo time.Time) ([]DateTotal, error)
+	GetIncomeCategoryTotals(userID string, dateFrom, dateTo time.Time) ([]CategoryTotal, error)
+	GetTagTotals(userID string, dateFrom, dateTo time.Time) ([]TagTotal, error)
+	GetUserBaseCurrency(userID string) (string, error)
 }
 
 // SummaryHandler handles summary HTTP requests.
 type SummaryHandler struct {
-	db SummaryDB
+	db       SummaryDB
+	budgetDB BudgetDB
 }
 
-// NewSummaryHandler creates a SummaryHandler with the given database.
-func NewSummaryHandler(db SummaryDB) *SummaryHandler {
-	return &SummaryHandler{db: db}
+// NewSummaryHandler creates a SummaryHandler with the given databases.
+func NewSummaryHandler(db SummaryDB, budgetDB BudgetDB) *SummaryHandler {
+	return &SummaryHandler{db: db, budgetDB: budgetDB}
 }
 
 // Summary handles GET /api/v1/expenses/summary.
+// See parseSummaryPeriod for the accepted period parameters.
 func (h *SummaryHandler) Summary(c *gin.Context) {
-	month := c.Query("month")
-	if month == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "month query parameter is required (format: YYYY-MM)"})
+	period, compare, ok := parseSummaryPeriod(c)
+	if !ok {
 		return
 	}
+	dateFrom, dateTo := period.From, period.To
+
+	userID := c.GetString("user_id")
 
-	parsed, err := time.Parse("2006-01", month)
+	categoryTotals, err := h.db.GetCategoryTotals(userID, dateFrom, dateTo)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "month query parameter is required (format: YYYY-MM)"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	dateFrom := parsed
-	dateTo := time.Date(parsed.Year(), parsed.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	rollupTotals, err := h.db.GetCategoryRollupTotals(userID, dateFrom, dateTo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
 
-	userID := c.GetString("user_id")
+	dailyTotals, err := h.db.GetDailyTotals(userID, dateFrom, dateTo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
 
-	categoryTotals, err := h.db.GetCategoryTotals(userID, dateFrom, dateTo)
+	incomeTotals, err := h.db.GetIncomeCategoryTotals(userID, dateFrom, dateTo)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	dailyTotals, err := h.db.GetDailyTotals(userID, dateFrom, dateTo)
+	baseCurrency, err := h.db.GetUserBaseCurrency(userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
+	var previousTotals []CategoryTotal
+	if compare != nil {
+		previousTotals, err = h.db.GetCategoryTotals(userID, compare.From, compare.To)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+	}
+
+	// Budgets are monthly, so they are only reported for month periods.
+	var budgets []MockBudget
+	if period.Kind == PeriodMonth {
+		budgets, err = h.budgetDB.GetUserBudgets(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+	}
+
 	var totalCents int64
 	for _, ct := range categoryTotals {
 		totalCents += ct.TotalCents
 	}
 
+	var incomeCents int64
+	for _, it := range incomeTotals {
+		incomeCents += it.TotalCents
+	}
+
 	byCategory := make([]gin.H, len(categoryTotals))
 	for i, ct := range categoryTotals {
+		var parentID any
+		if ct.ParentID != "" {
+			parentID = ct.ParentID
+		}
 		byCategory[i] = gin.H{
 			"category_id":    ct.CategoryID,
 			"category_name":  ct.CategoryName,
 			"category_color": ct.CategoryColor,
 			"category_icon":  ct.CategoryIcon,
+			"parent_id":      parentID,
 			"total_cents":    ct.TotalCents,
 			"count":          ct.Count,
 		}
 	}
 
+	byParentCategory := make([]gin.H, len(rollupTotals))
+	for i, rt := range rollupTotals {
+		byParentCategory[i] = gin.H{
+			"category_id":    rt.CategoryID,
+			"category_name":  rt.CategoryName,
+			"category_color": rt.CategoryColor,
+			"category_icon":  rt.CategoryIcon,
+			"total_cents":    rt.TotalCents,
+			"count":          rt.Count,
+		}
+	}
+
+	incomeByCategory := make([]gin.H, len(incomeTotals))
+	for i, it := range incomeTotals {
+		incomeByCategory[i] = gin.H{
+			"category_id":    it.CategoryID,
+			"category_name":  it.CategoryName,
+			"category_color": it.CategoryColor,
+			"category_icon":  it.CategoryIcon,
+			"total_cents":    it.TotalCents,
+			"count":          it.Count,
+		}
+	}
+
 	byDate := make([]gin.H, len(dailyTotals))
 	for i, dt := range dailyTotals {
 		byDate[i] = gin.H{
@@ -95,10 +174,91 @@ func (h *SummaryHandler) Summary(c *gin.Context) {
 		}
 	}
 
+	resp := gin.H{
+		"period":             period.JSON(),
+		"currency":           baseCurrency,
+		"total_cents":        totalCents,
+		"expense_cents":      totalCents,
+		"income_cents":       incomeCents,
+		"net_cents":          incomeCents - totalCents,
+		"by_category":        byCategory,
+		"by_parent_category": byParentCategory,
+		"income_by_category": incomeByCategory,
+		"by_date":            byDate,
+		"budgets":            nil,
+	}
+	if period.Kind == PeriodMonth {
+		resp["month"] = period.From.Format("2006-01")
+		resp["budgets"] = budgetStatus(budgets, spentByCategory(categoryTotals, rollupTotals), totalCents)
+	}
+	if compare != nil {
+		resp["comparison"] = periodComparison(*compare, categoryTotals, previousTotals)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// TagSummary handles GET /api/v1/expenses/summary/tags.
+// It totals expenses per tag between the from and to dates, inclusive. An
+// expense with several tags counts towards each of them, so the tag totals
+// can add up to more than was spent.
+func (h *SummaryHandler) TagSummary(c *gin.Context) {
+	dateFrom, errFrom := time.Parse("2006-01-02", c.Query("from"))
+	dateTo, errTo := time.Parse("2006-01-02", c.Query("to"))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to are required (format: YYYY-MM-DD)"})
+		return
+	}
+	if dateTo.Before(dateFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	userID := c.GetString("user_id")
+
+	tagTotals, err := h.db.GetTagTotals(userID, dateFrom, dateTo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	baseCurrency, err := h.db.GetUserBaseCurrency(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		return
+	}
+
+	byTag := make([]gin.H, len(tagTotals))
+	for i, tt := range tagTotals {
+		byTag[i] = gin.H{
+			"tag":         tt.TagName,
+			"total_cents": tt.TotalCents,
+			"count":       tt.Count,
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"month":       month,
-		"total_cents": totalCents,
-		"by_category": byCategory,
-		"by_date":     byDate,
+		"from":     dateFrom.Format("2006-01-02"),
+		"to":       dateTo.Format("2006-01-02"),
+		"currency": baseCurrency,
+		"by_tag":   byTag,
 	})
 }
+
+// spentByCategory maps category IDs to their spend for budget checks.
+// Subcategories count their own expenses, top-level categories also those
+// of their subcategories.
+func spentByCategory(leaves, rollups []CategoryTotal) map[string]int64 {
+	spent := make(map[string]int64, len(leaves)+len(rollups))
+	for _, ct := range leaves {
+		if ct.CategoryID != "" {
+			spent[ct.CategoryID] = ct.TotalCents
+		}
+	}
+	for _, rt := range rollups {
+		if rt.CategoryID != "" {
+			spent[rt.CategoryID] = rt.TotalCents
+		}
+	}
+	return spent
+}
@@ -30,6 +30,33 @@ func (db *PgSummaryDB) GetCategoryTotals(userID string, dateFrom, dateTo time.Ti
 		return nil, err
 	}
 
+	totals := make([]CategoryTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = CategoryTotal{
+			CategoryID:    uuidToString(row.CategoryID),
+			CategoryName:  row.CategoryName,
+			CategoryColor: row.CategoryColor,
+			CategoryIcon:  row.CategoryIcon,
+			ParentID:      uuidToString(row.ParentID),
+			TotalCents:    row.TotalCents,
+			Count:         int(row.ExpenseCount),
+		}
+	}
+	return totals, nil
+}
+
+func (db *PgSummaryDB) GetCategoryRollupTotals(userID string, dateFrom, dateTo time.Time) ([]CategoryTotal, error) {
+	uid := stringToUUID(userID)
+
+	rows, err := db.queries.GetCategoryRollupTotals(context.Background(), sqlc.GetCategoryRollupTotalsParams{
+		UserID:        uid,
+		ExpenseDate:   pgtype.Date{Time: dateFrom, Valid: true},
+		ExpenseDate_2: pgtype.Date{Time: dateTo, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	totals := make([]CategoryTotal, len(rows))
 	for i, row := range rows {
 		totals[i] = CategoryTotal{
@@ -65,3 +92,54 @@ func (db *PgSummaryDB) GetDailyTotals(userID string, dateFrom, dateTo time.Time)
 	}
 	return totals, nil
 }
+
+func (db *PgSummaryDB) GetIncomeCategoryTotals(userID string, dateFrom, dateTo time.Time) ([]CategoryTotal, error) {
+	uid := stringToUUID(userID)
+
+	rows, err := db.queries.GetIncomeCategoryTotals(context.Background(), sqlc.GetIncomeCategoryTotalsParams{
+		UserID:       uid,
+		IncomeDate:   pgtype.Date{Time: dateFrom, Valid: true},
+		IncomeDate_2: pgtype.Date{Time: dateTo, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]CategoryTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = CategoryTotal{
+			CategoryID:    uuidToString(row.CategoryID),
+			CategoryName:  row.CategoryName,
+			CategoryColor: row.CategoryColor,
+			CategoryIcon:  row.CategoryIcon,
+			TotalCents:    row.TotalCents,
+			Count:         int(row.IncomeCount),
+		}
+	}
+	return totals, nil
+}
+
+func (db *PgSummaryDB) GetTagTotals(userID string, dateFrom, dateTo time.Time) ([]TagTotal, error) {
+	rows, err := db.queries.GetTagTotals(context.Background(), sqlc.GetTagTotalsParams{
+		UserID:        stringToUUID(userID),
+		ExpenseDate:   pgtype.Date{Time: dateFrom, Valid: true},
+		ExpenseDate_2: pgtype.Date{Time: dateTo, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]TagTotal, len(rows))
+	for i, row := range rows {
+		totals[i] = TagTotal{
+			TagName:    row.TagName,
+			TotalCents: row.TotalCents,
+			Count:      int(row.ExpenseCount),
+		}
+	}
+	return totals, nil
+}
+
+func (db *PgSummaryDB) GetUserBaseCurrency(userID string) (string, error) {
+	return db.queries.GetUserBaseCurrency(context.Background(), stringToUUID(userID))
+}
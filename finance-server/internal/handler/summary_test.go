@@ -15,17 +15,48 @@ import (
 // mockSummaryDB implements handler.SummaryDB for testing.
 type mockSummaryDB struct {
 	categoryTotals []handler.CategoryTotal
+	rollupTotals   []handler.CategoryTotal
 	dailyTotals    []handler.DateTotal
+	incomeTotals   []handler.CategoryTotal
+	tagTotals      []handler.TagTotal
+	baseCurrency   string
 	err            error
+
+	// categoryTotalsFrom overrides categoryTotals for ranges starting on the
+	// given date, so comparisons can see different data.
+	categoryTotalsFrom map[string][]handler.CategoryTotal
+	ranges             [][2]string
+}
+
+func (m *mockSummaryDB) GetUserBaseCurrency(userID string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	if m.baseCurrency == "" {
+		return "UAH", nil
+	}
+	return m.baseCurrency, nil
 }
 
 func (m *mockSummaryDB) GetCategoryTotals(userID string, dateFrom, dateTo time.Time) ([]handler.CategoryTotal, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
+	from := dateFrom.Format("2006-01-02")
+	m.ranges = append(m.ranges, [2]string{from, dateTo.Format("2006-01-02")})
+	if totals, ok := m.categoryTotalsFrom[from]; ok {
+		return totals, nil
+	}
 	return m.categoryTotals, nil
 }
 
+func (m *mockSummaryDB) GetCategoryRollupTotals(userID string, dateFrom, dateTo time.Time) ([]handler.CategoryTotal, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.rollupTotals, nil
+}
+
 func (m *mockSummaryDB) GetDailyTotals(userID string, dateFrom, dateTo time.Time) ([]handler.DateTotal, error) {
 	if m.err != nil {
 		return nil, m.err
@@ -33,10 +64,25 @@ func (m *mockSummaryDB) GetDailyTotals(userID string, dateFrom, dateTo time.Time
 	return m.dailyTotals, nil
 }
 
-func setupSummaryRouter(db handler.SummaryDB) *gin.Engine {
+func (m *mockSummaryDB) GetIncomeCategoryTotals(userID string, dateFrom, dateTo time.Time) ([]handler.CategoryTotal, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.incomeTotals, nil
+}
+
+func (m *mockSummaryDB) GetTagTotals(userID string, dateFrom, dateTo time.Time) ([]handler.TagTotal, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.ranges = append(m.ranges, [2]string{dateFrom.Format("2006-01-02"), dateTo.Format("2006-01-02")})
+	return m.tagTotals, nil
+}
+
+func setupSummaryRouter(db handler.SummaryDB, budgetDB handler.BudgetDB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	h := handler.NewSummaryHandler(db)
+	h := handler.NewSummaryHandler(db, budgetDB)
 
 	expenses := r.Group("/api/v1/expenses")
 	expenses.Use(func(c *gin.Context) {
@@ -45,6 +91,7 @@ func setupSummaryRouter(db handler.SummaryDB) *gin.Engine {
 	})
 	{
 		expenses.GET("/summary", h.Summary)
+		expenses.GET("/summary/tags", h.TagSummary)
 	}
 	return r
 }
@@ -75,7 +122,7 @@ func TestSummary_Success(t *testing.T) {
 			{Date: "2026-03-10", TotalCents: 55000},
 		},
 	}
-	r := setupSummaryRouter(db)
+	r := setupSummaryRouter(db, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?month=2026-03", nil)
 	w := httptest.NewRecorder()
@@ -91,6 +138,9 @@ func TestSummary_Success(t *testing.T) {
 	if resp["month"] != "2026-03" {
 		t.Fatalf("expected month 2026-03, got %v", resp["month"])
 	}
+	if resp["currency"] != "UAH" {
+		t.Fatalf("expected currency UAH, got %v", resp["currency"])
+	}
 
 	// total_cents should be sum of category totals: 45000 + 30000 = 75000
 	if resp["total_cents"] != float64(75000) {
@@ -134,9 +184,51 @@ func TestSummary_Success(t *testing.T) {
 	}
 }
 
+func TestSummary_IncomeAndNet(t *testing.T) {
+	db := &mockSummaryDB{
+		categoryTotals: []handler.CategoryTotal{
+			{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 45000, Count: 12},
+		},
+		incomeTotals: []handler.CategoryTotal{
+			{CategoryID: "cat-9", CategoryName: "Salary", TotalCents: 200000, Count: 1},
+			{CategoryID: "cat-10", CategoryName: "Refunds", TotalCents: 5000, Count: 2},
+		},
+	}
+	r := setupSummaryRouter(db, newMockBudgetDB())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?month=2026-03", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp["expense_cents"] != float64(45000) {
+		t.Fatalf("expected expense_cents 45000, got %v", resp["expense_cents"])
+	}
+	if resp["income_cents"] != float64(205000) {
+		t.Fatalf("expected income_cents 205000, got %v", resp["income_cents"])
+	}
+	if resp["net_cents"] != float64(160000) {
+		t.Fatalf("expected net_cents 160000, got %v", resp["net_cents"])
+	}
+
+	incomeByCategory, ok := resp["income_by_category"].([]any)
+	if !ok {
+		t.Fatal("income_by_category should be an array")
+	}
+	if len(incomeByCategory) != 2 {
+		t.Fatalf("expected 2 income categories, got %d", len(incomeByCategory))
+	}
+}
+
 func TestSummary_MissingMonth(t *testing.T) {
 	db := &mockSummaryDB{}
-	r := setupSummaryRouter(db)
+	r := setupSummaryRouter(db, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary", nil)
 	w := httptest.NewRecorder()
@@ -156,7 +248,7 @@ func TestSummary_MissingMonth(t *testing.T) {
 
 func TestSummary_InvalidMonth(t *testing.T) {
 	db := &mockSummaryDB{}
-	r := setupSummaryRouter(db)
+	r := setupSummaryRouter(db, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?month=bad-format", nil)
 	w := httptest.NewRecorder()
@@ -172,7 +264,7 @@ func TestSummary_EmptyData(t *testing.T) {
 		categoryTotals: []handler.CategoryTotal{},
 		dailyTotals:    []handler.DateTotal{},
 	}
-	r := setupSummaryRouter(db)
+	r := setupSummaryRouter(db, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?month=2026-03", nil)
 	w := httptest.NewRecorder()
@@ -210,7 +302,7 @@ func TestSummary_DBError(t *testing.T) {
 	db := &mockSummaryDB{
 		err: errors.New("database connection failed"),
 	}
-	r := setupSummaryRouter(db)
+	r := setupSummaryRouter(db, newMockBudgetDB())
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?month=2026-03", nil)
 	w := httptest.NewRecorder()
@@ -226,3 +318,272 @@ func TestSummary_DBError(t *testing.T) {
 		t.Fatalf("expected 'Internal server error', got %v", resp["error"])
 	}
 }
+
+func TestSummary_Periods(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		kind     string
+		from, to string
+	}{
+		{"legacy month", "month=2026-02", "month", "2026-02-01", "2026-02-28"},
+		{"legacy salary month", "month=2026-02&month_start=25", "month", "2026-02-25", "2026-03-24"},
+		{"week", "period=week&date=2026-03-18", "week", "2026-03-16", "2026-03-22"},
+		{"week starting sunday", "period=week&date=2026-03-18&week_start=sunday", "week", "2026-03-15", "2026-03-21"},
+		{"salary month", "period=month&date=2026-03-10&month_start=25", "month", "2026-02-25", "2026-03-24"},
+		{"quarter", "period=quarter&date=2026-05-10", "quarter", "2026-04-01", "2026-06-30"},
+		{"year", "period=year&date=2026-05-10", "year", "2026-01-01", "2026-12-31"},
+		{"custom", "period=custom&from=2026-03-05&to=2026-03-19", "custom", "2026-03-05", "2026-03-19"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockSummaryDB{}
+			r := setupSummaryRouter(db, newMockBudgetDB())
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var resp map[string]any
+			json.Unmarshal(w.Body.Bytes(), &resp)
+
+			period, _ := resp["period"].(map[string]any)
+			if period["kind"] != tt.kind || period["from"] != tt.from || period["to"] != tt.to {
+				t.Fatalf("expected %s %s..%s, got %v", tt.kind, tt.from, tt.to, period)
+			}
+			if len(db.ranges) != 1 || db.ranges[0] != [2]string{tt.from, tt.to} {
+				t.Fatalf("expected totals queried for %s..%s, got %v", tt.from, tt.to, db.ranges)
+			}
+			if _, hasMonth := resp["month"]; hasMonth != (tt.kind == "month") {
+				t.Fatalf("month key presence mismatch for %s: %v", tt.kind, resp["month"])
+			}
+		})
+	}
+}
+
+func TestSummary_InvalidPeriod(t *testing.T) {
+	queries := []string{
+		"period=fortnight",
+		"period=week&date=18-03-2026",
+		"period=custom&from=2026-03-05",
+		"period=custom&from=2026-03-19&to=2026-03-05",
+		"period=week&week_start=someday",
+		"period=month&month_start=29",
+		"month=2026-03&compare=next",
+	}
+
+	for _, q := range queries {
+		db := &mockSummaryDB{}
+		r := setupSummaryRouter(db, newMockBudgetDB())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?"+q, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", q, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestSummary_ComparePrevious(t *testing.T) {
+	db := &mockSummaryDB{
+		categoryTotals: []handler.CategoryTotal{
+			{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 45000, Count: 12},
+			{CategoryID: "cat-2", CategoryName: "Transport", TotalCents: 20000, Count: 4},
+		},
+		categoryTotalsFrom: map[string][]handler.CategoryTotal{
+			"2026-02-01": {
+				{CategoryID: "cat-1", CategoryName: "Food", TotalCents: 30000, Count: 9},
+				{CategoryID: "cat-3", CategoryName: "Health", TotalCents: 5000, Count: 1},
+			},
+		},
+	}
+	r := setupSummaryRouter(db, newMockBudgetDB())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?month=2026-03&compare=previous", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	comparison, ok := resp["comparison"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected comparison object, got %v", resp["comparison"])
+	}
+	period := comparison["period"].(map[string]any)
+	if period["from"] != "2026-02-01" || period["to"] != "2026-02-28" {
+		t.Fatalf("expected February as previous period, got %v", period)
+	}
+	if comparison["total_cents"] != float64(35000) {
+		t.Fatalf("expected previous total 35000, got %v", comparison["total_cents"])
+	}
+	if comparison["delta_cents"] != float64(30000) {
+		t.Fatalf("expected delta 30000, got %v", comparison["delta_cents"])
+	}
+
+	byCategory := comparison["by_category"].([]any)
+	if len(byCategory) != 3 {
+		t.Fatalf("expected 3 categories across both periods, got %d", len(byCategory))
+	}
+	deltas := map[string]float64{}
+	for _, raw := range byCategory {
+		entry := raw.(map[string]any)
+		deltas[entry["category_id"].(string)] = entry["delta_cents"].(float64)
+	}
+	if deltas["cat-1"] != 15000 || deltas["cat-2"] != 20000 || deltas["cat-3"] != -5000 {
+		t.Fatalf("unexpected category deltas: %v", deltas)
+	}
+}
+
+func TestSummary_CompareSameLastYear(t *testing.T) {
+	tests := []struct {
+		query    string
+		from, to string
+	}{
+		{"period=custom&from=2024-02-01&to=2024-02-29", "2023-02-01", "2023-02-28"},
+		{"month=2024-02", "2023-02-01", "2023-02-28"},
+		{"period=quarter&date=2026-05-10&month_start=15", "2025-04-15", "2025-07-14"},
+		{"period=week&date=2026-03-18", "2025-03-17", "2025-03-23"},
+	}
+
+	for _, tt := range tests {
+		db := &mockSummaryDB{}
+		r := setupSummaryRouter(db, newMockBudgetDB())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?"+tt.query+"&compare=same_last_year", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.query, w.Code, w.Body.String())
+		}
+		if len(db.ranges) != 2 || db.ranges[1] != [2]string{tt.from, tt.to} {
+			t.Fatalf("%s: expected comparison over %s..%s, got %v", tt.query, tt.from, tt.to, db.ranges)
+		}
+	}
+}
+
+func TestSummary_ParentCategoryRollup(t *testing.T) {
+	const foodID = "550e8400-e29b-41d4-a716-446655440001"
+	const groceriesID = "550e8400-e29b-41d4-a716-446655440002"
+	const restaurantsID = "550e8400-e29b-41d4-a716-446655440003"
+
+	db := &mockSummaryDB{
+		categoryTotals: []handler.CategoryTotal{
+			{CategoryID: groceriesID, CategoryName: "Groceries", ParentID: foodID, TotalCents: 30000, Count: 6},
+			{CategoryID: restaurantsID, CategoryName: "Restaurants", ParentID: foodID, TotalCents: 15000, Count: 3},
+			{CategoryID: foodID, CategoryName: "Food", TotalCents: 5000, Count: 1},
+		},
+		rollupTotals: []handler.CategoryTotal{
+			{CategoryID: foodID, CategoryName: "Food", TotalCents: 50000, Count: 10},
+		},
+	}
+	budgetDB := newMockBudgetDB()
+	budgetDB.UpsertUserBudget(testUserID, foodID, 40000)
+	budgetDB.UpsertUserBudget(testUserID, groceriesID, 35000)
+	r := setupSummaryRouter(db, budgetDB)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary?month=2026-03", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	byCategory := resp["by_category"].([]any)
+	if len(byCategory) != 3 {
+		t.Fatalf("expected 3 leaf categories, got %d", len(byCategory))
+	}
+	if groceries := byCategory[0].(map[string]any); groceries["parent_id"] != foodID {
+		t.Fatalf("expected Groceries under Food, got %v", groceries["parent_id"])
+	}
+	if food := byCategory[2].(map[string]any); food["parent_id"] != nil {
+		t.Fatalf("expected top-level Food to have no parent, got %v", food["parent_id"])
+	}
+
+	byParent := resp["by_parent_category"].([]any)
+	if len(byParent) != 1 || byParent[0].(map[string]any)["total_cents"] != float64(50000) {
+		t.Fatalf("expected Food rolled up to 50000, got %v", byParent)
+	}
+
+	spent := map[string]float64{}
+	for _, raw := range resp["budgets"].(map[string]any)["by_category"].([]any) {
+		entry := raw.(map[string]any)
+		spent[entry["category_id"].(string)] = entry["spent_cents"].(float64)
+	}
+	if spent[foodID] != 50000 || spent[groceriesID] != 30000 {
+		t.Fatalf("expected parent budget to use rolled-up spend, got %v", spent)
+	}
+}
+
+func TestTagSummary(t *testing.T) {
+	db := &mockSummaryDB{
+		tagTotals: []handler.TagTotal{
+			{TagName: "vacation-2026", TotalCents: 120000, Count: 8},
+			{TagName: "kids", TotalCents: 30000, Count: 3},
+		},
+	}
+	r := setupSummaryRouter(db, newMockBudgetDB())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary/tags?from=2026-06-01&to=2026-08-31", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]any
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if resp["from"] != "2026-06-01" || resp["to"] != "2026-08-31" {
+		t.Fatalf("expected the requested range, got %v to %v", resp["from"], resp["to"])
+	}
+	if len(db.ranges) != 1 || db.ranges[0] != [2]string{"2026-06-01", "2026-08-31"} {
+		t.Fatalf("expected totals for the requested range, got %v", db.ranges)
+	}
+	byTag := resp["by_tag"].([]any)
+	if len(byTag) != 2 {
+		t.Fatalf("expected 2 tags, got %d", len(byTag))
+	}
+	first := byTag[0].(map[string]any)
+	if first["tag"] != "vacation-2026" || first["total_cents"] != float64(120000) || first["count"] != float64(8) {
+		t.Fatalf("unexpected first tag total: %v", first)
+	}
+}
+
+func TestTagSummary_InvalidRange(t *testing.T) {
+	queries := []string{
+		"",
+		"from=2026-06-01",
+		"from=2026-06-01&to=31-08-2026",
+		"from=2026-08-31&to=2026-06-01",
+	}
+
+	for _, q := range queries {
+		r := setupSummaryRouter(&mockSummaryDB{}, newMockBudgetDB())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/expenses/summary/tags?"+q, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: expected 400, got %d: %s", q, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxExpenseTags bounds the number of tags on one expense.
+	maxExpenseTags = 20
+	// maxTagLength bounds the length of a tag name in characters.
+	maxTagLength = 50
+)
+
+var (
+	errTooManyTags = fmt.Errorf("at most %d tags are allowed", maxExpenseTags)
+	errTagTooLong  = fmt.Errorf("tags must be at most %d characters", maxTagLength)
+)
+
+// normalizeTags trims and lowercases tag names, drops empty and duplicate
+// ones, and sorts the rest. A nil input stays nil so that callers can tell
+// "no tags given" from "no tags".
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// checkTags validates normalized tags for storing on an expense.
+func checkTags(tags []string) error {
+	if len(tags) > maxExpenseTags {
+		return errTooManyTags
+	}
+	for _, tag := range tags {
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return errTagTooLong
+		}
+	}
+	return nil
+}
+
+// tagsJSON returns tags for a response, rendering no tags as an empty list.
+func tagsJSON(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
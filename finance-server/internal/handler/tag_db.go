@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+// addExpenseTags attaches tags to an expense, creating the ones the user
+// does not have yet. Run it in the transaction that writes the expense.
+func addExpenseTags(ctx context.Context, q *sqlc.Queries, userID, expenseID pgtype.UUID, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if err := q.CreateTags(ctx, sqlc.CreateTagsParams{UserID: userID, Names: tags}); err != nil {
+		return err
+	}
+	return q.AddExpenseTags(ctx, sqlc.AddExpenseTagsParams{
+		ExpenseID: expenseID,
+		UserID:    userID,
+		Names:     tags,
+	})
+}
+
+// replaceExpenseTags sets the tags of an expense to exactly tags.
+func replaceExpenseTags(ctx context.Context, q *sqlc.Queries, userID, expenseID pgtype.UUID, tags []string) error {
+	if err := q.DeleteExpenseTags(ctx, expenseID); err != nil {
+		return err
+	}
+	return addExpenseTags(ctx, q, userID, expenseID, tags)
+}
+
+// expenseTags returns the tags of the given expenses keyed by expense ID,
+// each sorted by name.
+func expenseTags(ctx context.Context, q *sqlc.Queries, ids []string) (map[string][]string, error) {
+	tags := make(map[string][]string)
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	uuids := make([]pgtype.UUID, len(ids))
+	for i, id := range ids {
+		uuids[i] = stringToUUID(id)
+	}
+	rows, err := q.GetExpenseTags(ctx, uuids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		id := uuidToString(row.ExpenseID)
+		tags[id] = append(tags[id], row.Name)
+	}
+	return tags, nil
+}
+
+// attachExpenseTags fills in the Tags of expenses.
+func attachExpenseTags(ctx context.Context, q *sqlc.Queries, expenses []MockExpense) error {
+	ids := make([]string, len(expenses))
+	for i, e := range expenses {
+		ids[i] = e.ID
+	}
+	tags, err := expenseTags(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	for i := range expenses {
+		expenses[i].Tags = tags[expenses[i].ID]
+	}
+	return nil
+}
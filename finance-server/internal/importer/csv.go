@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVMapping describes how statement columns map to expense fields.
+// Columns are referenced by header name (case-insensitive) or, when the file
+// has no header row, by zero-based index.
+type CSVMapping struct {
+	DateColumn   string
+	AmountColumn string
+	NoteColumn   string
+	// DateFormat is a pattern such as "YYYY-MM-DD" or "DD.MM.YYYY".
+	DateFormat string
+	// Delimiter defaults to ','.
+	Delimiter rune
+	// DecimalSeparator defaults to '.'.
+	DecimalSeparator byte
+	NoHeader         bool
+	// ExpensesPositive treats positive amounts as expenses, for exports that
+	// list card spending as positive numbers.
+	ExpensesPositive bool
+}
+
+// ParseCSV reads a CSV statement using the given column mapping.
+func ParseCSV(r io.Reader, m CSVMapping) (Result, error) {
+	if m.DateColumn == "" || m.AmountColumn == "" {
+		return Result{}, errors.New("date and amount columns are required")
+	}
+	if m.DateFormat == "" {
+		m.DateFormat = "YYYY-MM-DD"
+	}
+	if m.DecimalSeparator == 0 {
+		m.DecimalSeparator = '.'
+	}
+	layout := dateLayout(m.DateFormat)
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if m.Delimiter != 0 {
+		reader.Comma = m.Delimiter
+	}
+
+	var dateIdx, amountIdx, noteIdx int
+	line := 0
+
+	if m.NoHeader {
+		var err error
+		if dateIdx, err = columnIndex(nil, m.DateColumn); err != nil {
+			return Result{}, err
+		}
+		if amountIdx, err = columnIndex(nil, m.AmountColumn); err != nil {
+			return Result{}, err
+		}
+		noteIdx = -1
+		if m.NoteColumn != "" {
+			if noteIdx, err = columnIndex(nil, m.NoteColumn); err != nil {
+				return Result{}, err
+			}
+		}
+	} else {
+		header, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return Result{}, errors.New("file is empty")
+			}
+			return Result{}, &ParseError{Line: 1, Err: err}
+		}
+		line = 1
+		if len(header) > 0 {
+			header[0] = strings.TrimPrefix(header[0], "\ufeff")
+		}
+		if dateIdx, err = columnIndex(header, m.DateColumn); err != nil {
+			return Result{}, err
+		}
+		if amountIdx, err = columnIndex(header, m.AmountColumn); err != nil {
+			return Result{}, err
+		}
+		noteIdx = -1
+		if m.NoteColumn != "" {
+			if noteIdx, err = columnIndex(header, m.NoteColumn); err != nil {
+				return Result{}, err
+			}
+		}
+	}
+
+	var res Result
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return Result{}, &ParseError{Line: line, Err: err}
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		if dateIdx >= len(record) || amountIdx >= len(record) || noteIdx >= len(record) {
+			return Result{}, &ParseError{Line: line, Err: errors.New("missing columns")}
+		}
+
+		date, err := time.Parse(layout, strings.TrimSpace(record[dateIdx]))
+		if err != nil {
+			return Result{}, &ParseError{Line: line, Err: fmt.Errorf("date %q does not match format %s", record[dateIdx], m.DateFormat)}
+		}
+
+		cents, err := parseAmount(record[amountIdx], m.DecimalSeparator)
+		if err != nil {
+			return Result{}, &ParseError{Line: line, Err: err}
+		}
+		if m.ExpensesPositive {
+			cents = -cents
+		}
+
+		var note string
+		if noteIdx >= 0 {
+			note = record[noteIdx]
+		}
+		res.collect(line, date, cents, note)
+	}
+
+	return res, nil
+}
+
+// columnIndex resolves a column reference against the header row.
+// With a nil header the reference must be a zero-based index.
+func columnIndex(header []string, ref string) (int, error) {
+	if header != nil {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(ref)) {
+				return i, nil
+			}
+		}
+	}
+	idx, err := strconv.Atoi(ref)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("column %q not found", ref)
+	}
+	if header != nil && idx >= len(header) {
+		return 0, fmt.Errorf("column %q not found", ref)
+	}
+	return idx, nil
+}
@@ -0,0 +1,180 @@
+// Package importer parses bank statement exports (CSV, OFX, QIF) into
+// expense rows ready for review and import.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Supported statement formats.
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+	FormatQIF = "qif"
+)
+
+// ErrUnsupportedFormat is returned by Parse for an unknown format.
+var ErrUnsupportedFormat = errors.New("unsupported import format")
+
+// Row is a single expense parsed from a statement.
+// AmountCents is always positive; credits are dropped during parsing.
+type Row struct {
+	Line        int
+	Date        time.Time
+	AmountCents int64
+	Note        string
+}
+
+// Result holds the parsed expense rows and the number of non-expense
+// (credit or zero-amount) transactions that were skipped.
+type Result struct {
+	Rows    []Row
+	Skipped int
+}
+
+// ParseError describes a malformed line in the input.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Options controls parsing. Mapping is only used for CSV input; DateFormat
+// optionally overrides date detection for QIF.
+type Options struct {
+	Mapping    CSVMapping
+	DateFormat string
+}
+
+// Parse reads a statement in the given format.
+func Parse(format string, r io.Reader, opts Options) (Result, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return ParseCSV(r, opts.Mapping)
+	case FormatOFX:
+		return ParseOFX(r)
+	case FormatQIF:
+		return ParseQIF(r, opts.DateFormat)
+	}
+	return Result{}, ErrUnsupportedFormat
+}
+
+// DetectFormat guesses the format from a file name's extension.
+// It returns an empty string when the extension is not recognized.
+func DetectFormat(filename string) string {
+	lower := strings.ToLower(filename)
+	for _, f := range []string{FormatCSV, FormatOFX, FormatQIF} {
+		if strings.HasSuffix(lower, "."+f) {
+			return f
+		}
+	}
+	if strings.HasSuffix(lower, ".qfx") {
+		return FormatOFX
+	}
+	return ""
+}
+
+// collect appends a signed transaction to res, keeping only debits.
+// Statements report money leaving the account as negative amounts.
+func (res *Result) collect(line int, date time.Time, signedCents int64, note string) {
+	if signedCents >= 0 {
+		res.Skipped++
+		return
+	}
+	res.Rows = append(res.Rows, Row{
+		Line:        line,
+		Date:        date,
+		AmountCents: -signedCents,
+		Note:        strings.TrimSpace(note),
+	})
+}
+
+// parseAmount converts a decimal string such as "-1,234.56", "(12.30)" or
+// "12,30" (with decimalSep ',') into signed cents.
+func parseAmount(s string, decimalSep byte) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	var intPart, fracPart strings.Builder
+	seenSep := false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '-':
+			negative = !negative
+		case ch == '+':
+		case ch >= '0' && ch <= '9':
+			if seenSep {
+				fracPart.WriteByte(ch)
+			} else {
+				intPart.WriteByte(ch)
+			}
+		case ch == decimalSep:
+			if seenSep {
+				return 0, fmt.Errorf("invalid amount %q", s)
+			}
+			seenSep = true
+		case ch == ',' || ch == '.' || ch == ' ' || ch == '\'':
+			// thousands separators
+		case ch == '$' || ch >= 0x80 || (ch|0x20 >= 'a' && ch|0x20 <= 'z'):
+			// currency symbols and codes are ignored
+		default:
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	frac := fracPart.String()
+	if len(frac) > 2 {
+		if strings.Trim(frac[2:], "0") != "" {
+			return 0, fmt.Errorf("amount %q has more than 2 decimal places", s)
+		}
+		frac = frac[:2]
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+
+	digits := intPart.String() + frac
+	if strings.Trim(digits, "0") == "" {
+		return 0, nil
+	}
+
+	var cents int64
+	for _, d := range digits {
+		cents = cents*10 + int64(d-'0')
+		if cents < 0 {
+			return 0, fmt.Errorf("amount %q is too large", s)
+		}
+	}
+	if negative {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+// dateLayout converts a user-facing pattern such as "DD.MM.YYYY" into a Go
+// time layout. Patterns without any of these tokens are returned unchanged,
+// so Go layouts are accepted as well.
+func dateLayout(pattern string) string {
+	r := strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02")
+	return r.Replace(pattern)
+}
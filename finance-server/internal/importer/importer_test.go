@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		sep  byte
+		want int64
+	}{
+		{"-12.50", '.', -1250},
+		{"1,234.56", '.', 123456},
+		{"(7.5)", '.', -750},
+		{"-12,30", ',', -1230},
+		{"1.234,56", ',', 123456},
+		{"$ -3.00", '.', -300},
+		{"-4.100", '.', -410},
+		{"0.00", '.', 0},
+		{"EUR -9", '.', -900},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAmount(tt.in, tt.sep)
+		if err != nil {
+			t.Fatalf("parseAmount(%q) returned error: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Fatalf("parseAmount(%q): expected %d, got %d", tt.in, tt.want, got)
+		}
+	}
+
+	for _, bad := range []string{"", "12.345", "1.2.3", "12#"} {
+		if _, err := parseAmount(bad, '.'); err == nil {
+			t.Fatalf("parseAmount(%q): expected error", bad)
+		}
+	}
+}
+
+func TestParseCSV_HeaderMapping(t *testing.T) {
+	input := "Booking Date;Description;Amount\n" +
+		"01.03.2026;Coffee Shop;-3,50\n" +
+		"02.03.2026;Salary;2500,00\n" +
+		"03.03.2026;\"Grocery; Store\";-42,10\n"
+
+	res, err := ParseCSV(strings.NewReader(input), CSVMapping{
+		DateColumn:       "booking date",
+		AmountColumn:     "Amount",
+		NoteColumn:       "Description",
+		DateFormat:       "DD.MM.YYYY",
+		Delimiter:        ';',
+		DecimalSeparator: ',',
+	})
+	if err != nil {
+		t.Fatalf("ParseCSV returned error: %v", err)
+	}
+
+	if len(res.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(res.Rows))
+	}
+	if res.Skipped != 1 {
+		t.Fatalf("expected 1 skipped credit, got %d", res.Skipped)
+	}
+	if res.Rows[0].AmountCents != 350 || res.Rows[0].Note != "Coffee Shop" {
+		t.Fatalf("unexpected first row: %+v", res.Rows[0])
+	}
+	if got := res.Rows[1].Date.Format("2006-01-02"); got != "2026-03-03" {
+		t.Fatalf("expected date 2026-03-03, got %s", got)
+	}
+	if res.Rows[1].Line != 4 {
+		t.Fatalf("expected line 4, got %d", res.Rows[1].Line)
+	}
+}
+
+func TestParseCSV_IndexMappingPositiveExpenses(t *testing.T) {
+	input := "2026-03-01,12.00,Taxi\n2026-03-02,8.25,Lunch\n"
+
+	res, err := ParseCSV(strings.NewReader(input), CSVMapping{
+		DateColumn:       "0",
+		AmountColumn:     "1",
+		NoteColumn:       "2",
+		NoHeader:         true,
+		ExpensesPositive: true,
+	})
+	if err != nil {
+		t.Fatalf("ParseCSV returned error: %v", err)
+	}
+	if len(res.Rows) != 2 || res.Rows[1].AmountCents != 825 {
+		t.Fatalf("unexpected rows: %+v", res.Rows)
+	}
+}
+
+func TestParseCSV_Errors(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader("date,amount\n2026-03-01,-1\n"), CSVMapping{DateColumn: "date", AmountColumn: "value"})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected missing column error, got %v", err)
+	}
+
+	_, err = ParseCSV(strings.NewReader("date,amount\n03/01/2026,-1\n"), CSVMapping{DateColumn: "date", AmountColumn: "amount"})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Fatalf("expected parse error on line 2, got %v", err)
+	}
+}
+
+func TestParseOFX_SGML(t *testing.T) {
+	input := `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260305120000.000[-5:EST]
+<TRNAMT>-15.99
+<FITID>1001
+<NAME>NETFLIX.COM
+<MEMO>Subscription
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260306
+<TRNAMT>100.00
+<NAME>Refund
+</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+	res, err := ParseOFX(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseOFX returned error: %v", err)
+	}
+	if len(res.Rows) != 1 || res.Skipped != 1 {
+		t.Fatalf("expected 1 row and 1 skipped, got %d and %d", len(res.Rows), res.Skipped)
+	}
+	row := res.Rows[0]
+	if row.AmountCents != 1599 || row.Note != "NETFLIX.COM - Subscription" || row.Date.Format("2006-01-02") != "2026-03-05" {
+		t.Fatalf("unexpected row: %+v", row)
+	}
+}
+
+func TestParseOFX_XML(t *testing.T) {
+	input := `<?xml version="1.0"?><OFX><STMTTRN><DTPOSTED>20260307</DTPOSTED><TRNAMT>-2.40</TRNAMT><NAME>Bakery &amp; Co</NAME></STMTTRN></OFX>`
+
+	res, err := ParseOFX(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseOFX returned error: %v", err)
+	}
+	if len(res.Rows) != 1 || res.Rows[0].Note != "Bakery & Co" || res.Rows[0].AmountCents != 240 {
+		t.Fatalf("unexpected rows: %+v", res.Rows)
+	}
+}
+
+func TestParseQIF(t *testing.T) {
+	input := "!Type:Bank\n" +
+		"D3/ 1'26\nT-45.00\nPGas Station\n^\n" +
+		"D03/02/2026\nT1,000.00\nPEmployer\n^\n" +
+		"D3/3/26\nU-7.25\nPBookstore\nMPaperback\n^\n"
+
+	res, err := ParseQIF(strings.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("ParseQIF returned error: %v", err)
+	}
+	if len(res.Rows) != 2 || res.Skipped != 1 {
+		t.Fatalf("expected 2 rows and 1 skipped, got %d and %d", len(res.Rows), res.Skipped)
+	}
+	if got := res.Rows[0].Date.Format("2006-01-02"); got != "2026-03-01" {
+		t.Fatalf("expected 2026-03-01, got %s", got)
+	}
+	if res.Rows[1].Note != "Bookstore - Paperback" || res.Rows[1].AmountCents != 725 {
+		t.Fatalf("unexpected row: %+v", res.Rows[1])
+	}
+}
+
+func TestParse_UnsupportedFormat(t *testing.T) {
+	if _, err := Parse("xlsx", strings.NewReader(""), Options{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+	if got := DetectFormat("statement.QFX"); got != FormatOFX {
+		t.Fatalf("expected ofx, got %q", got)
+	}
+}
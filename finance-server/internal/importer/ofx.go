@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ParseOFX reads the bank transactions from an OFX 1.x (SGML) or 2.x (XML)
+// statement. Leaf elements in OFX 1.x are not closed, so values are read up
+// to the next tag or line break.
+func ParseOFX(r io.Reader) (Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Result{}, err
+	}
+	content := string(data)
+	upper := strings.ToUpper(content)
+
+	if !strings.Contains(upper, "<OFX>") {
+		return Result{}, errors.New("not an OFX file")
+	}
+
+	var res Result
+	pos := 0
+	for {
+		start := strings.Index(upper[pos:], "<STMTTRN>")
+		if start < 0 {
+			break
+		}
+		start += pos
+		end := strings.Index(upper[start:], "</STMTTRN>")
+		if end < 0 {
+			return Result{}, &ParseError{Line: lineAt(content, start), Err: errors.New("unterminated STMTTRN")}
+		}
+		end += start
+
+		block := content[start:end]
+		line := lineAt(content, start)
+
+		posted := ofxValue(block, "DTPOSTED")
+		date, err := parseOFXDate(posted)
+		if err != nil {
+			return Result{}, &ParseError{Line: line, Err: err}
+		}
+
+		cents, err := parseAmount(ofxValue(block, "TRNAMT"), '.')
+		if err != nil {
+			return Result{}, &ParseError{Line: line, Err: err}
+		}
+
+		note := ofxValue(block, "NAME")
+		if memo := ofxValue(block, "MEMO"); memo != "" && memo != note {
+			if note == "" {
+				note = memo
+			} else {
+				note += " - " + memo
+			}
+		}
+
+		res.collect(line, date, cents, note)
+		pos = end + len("</STMTTRN>")
+	}
+
+	return res, nil
+}
+
+// ofxValue returns the text content of the first <tag> in block.
+func ofxValue(block, tag string) string {
+	upper := strings.ToUpper(block)
+	open := "<" + tag + ">"
+	i := strings.Index(upper, open)
+	if i < 0 {
+		return ""
+	}
+	rest := block[i+len(open):]
+	if j := strings.IndexAny(rest, "<\r\n"); j >= 0 {
+		rest = rest[:j]
+	}
+	return unescapeOFX(strings.TrimSpace(rest))
+}
+
+func unescapeOFX(s string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'").Replace(s)
+}
+
+// parseOFXDate parses an OFX datetime such as "20260301", "20260301120000"
+// or "20260301120000.000[-5:EST]". Only the calendar date is kept.
+func parseOFXDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", s)
+	}
+	t, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", s)
+	}
+	return t, nil
+}
+
+func lineAt(content string, offset int) int {
+	return strings.Count(content[:offset], "\n") + 1
+}
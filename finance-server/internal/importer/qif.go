@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// qifDateLayouts are tried in order when no explicit date format is given.
+// QIF exports are predominantly US-style month/day dates.
+var qifDateLayouts = []string{"1/2/2006", "1/2/06", "2006-01-02", "1-2-2006", "1-2-06", "1.2.2006"}
+
+// ParseQIF reads a Quicken Interchange Format statement. Records are
+// terminated by "^"; D is the date, T (or U) the amount, P the payee and
+// M the memo. dateFormat optionally forces a layout such as "DD/MM/YYYY".
+func ParseQIF(r io.Reader, dateFormat string) (Result, error) {
+	scanner := bufio.NewScanner(r)
+
+	var (
+		res       Result
+		line      int
+		recLine   int
+		date      string
+		amount    string
+		payee     string
+		memo      string
+		hasFields bool
+	)
+
+	flush := func() error {
+		defer func() {
+			date, amount, payee, memo, hasFields = "", "", "", "", false
+		}()
+		if !hasFields {
+			return nil
+		}
+		if date == "" || amount == "" {
+			return &ParseError{Line: recLine, Err: errors.New("record is missing date or amount")}
+		}
+		d, err := parseQIFDate(date, dateFormat)
+		if err != nil {
+			return &ParseError{Line: recLine, Err: err}
+		}
+		cents, err := parseAmount(amount, '.')
+		if err != nil {
+			return &ParseError{Line: recLine, Err: err}
+		}
+		note := payee
+		if memo != "" && memo != payee {
+			if note == "" {
+				note = memo
+			} else {
+				note += " - " + memo
+			}
+		}
+		res.collect(recLine, d, cents, note)
+		return nil
+	}
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if text == "" || strings.HasPrefix(text, "!") {
+			continue
+		}
+		if text == "^" {
+			if err := flush(); err != nil {
+				return Result{}, err
+			}
+			continue
+		}
+
+		if !hasFields {
+			recLine = line
+			hasFields = true
+		}
+		value := strings.TrimSpace(text[1:])
+		switch text[0] {
+		case 'D':
+			date = value
+		case 'T', 'U':
+			if amount == "" {
+				amount = value
+			}
+		case 'P':
+			payee = value
+		case 'M':
+			memo = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Result{}, err
+	}
+	if err := flush(); err != nil {
+		return Result{}, err
+	}
+
+	return res, nil
+}
+
+// parseQIFDate handles Quicken's variants, including apostrophe years
+// ("1/5'26") and space-padded fields ("1/ 5/26").
+func parseQIFDate(s, dateFormat string) (time.Time, error) {
+	s = strings.ReplaceAll(strings.ReplaceAll(s, "'", "/"), " ", "")
+
+	if dateFormat != "" {
+		t, err := time.Parse(dateLayout(dateFormat), s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("date %q does not match format %s", s, dateFormat)
+		}
+		return t, nil
+	}
+
+	for _, layout := range qifDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", s)
+}
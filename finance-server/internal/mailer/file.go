@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes each message as an .eml file in a directory instead of
+// sending it, so invitations can be followed in local development.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a FileMailer writing to dir. The directory is
+// created on first send.
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	now := time.Now()
+	data, err := render(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o750); err != nil {
+		return fmt.Errorf("create mail dir: %w", err)
+	}
+	f, err := os.CreateTemp(m.dir, now.UTC().Format("20060102T150405Z")+"-*.eml")
+	if err != nil {
+		return fmt.Errorf("create mail file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write mail file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write mail file: %w", err)
+	}
+
+	log.Printf("Mail: %q to %s written to %s", msg.Subject, msg.To, filepath.Base(f.Name()))
+	return nil
+}
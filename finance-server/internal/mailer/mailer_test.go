@@ -0,0 +1,151 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testMessage = Message{
+	To:      "anna@example.com",
+	Subject: "Join Smith Family — invitation",
+	Body:    "Hi!\nOpen this link to join:\nhttps://example.com/invite/abc\n",
+}
+
+func TestRender(t *testing.T) {
+	data, err := render("Finance Tracker <noreply@example.com>", testMessage, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("rendered message does not parse: %v", err)
+	}
+	if got := msg.Header.Get("To"); got != testMessage.To {
+		t.Fatalf("unexpected To: %q", got)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != testMessage.Subject {
+		t.Fatalf("unexpected Subject: %q", subject)
+	}
+	if !strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>") {
+		t.Fatalf("unexpected Message-ID: %q", msg.Header.Get("Message-ID"))
+	}
+	body, _ := io.ReadAll(msg.Body)
+	if !strings.Contains(string(body), "https://example.com/invite/abc\r\n") {
+		t.Fatalf("body should keep the link on a CRLF line: %q", body)
+	}
+}
+
+func TestRender_RejectsHeaderInjection(t *testing.T) {
+	for _, msg := range []Message{
+		{To: "not an address", Subject: "Hi"},
+		{To: "anna@example.com\r\nBcc: eve@example.com", Subject: "Hi"},
+		{To: "anna@example.com", Subject: "Hi\r\nBcc: eve@example.com"},
+	} {
+		if _, err := render("noreply@example.com", msg, time.Now()); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("render(%q, %q): expected ErrInvalidMessage, got %v", msg.To, msg.Subject, err)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := NewFileMailer(dir, "noreply@example.com")
+
+	if err := m.Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "To: anna@example.com\r\n") {
+		t.Fatalf("unexpected file contents: %q", data)
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	type envelope struct {
+		from, to string
+		data     []byte
+	}
+	received := make(chan envelope, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := textproto.NewReader(bufio.NewReader(conn))
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+
+		var env envelope
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadLine()
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL":
+				env.from = line
+				reply("250 OK")
+			case "RCPT":
+				env.to = line
+				reply("250 OK")
+			case "DATA":
+				reply("354 Go ahead")
+				env.data, _ = r.ReadDotBytes()
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				received <- env
+				return
+			default:
+				reply("502 Unsupported")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	m := NewSMTPMailer(SMTPConfig{Host: host, Port: port, From: "Finance Tracker <noreply@example.com>"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.Send(ctx, testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	env := <-received
+	if env.from != "MAIL FROM:<noreply@example.com>" && !strings.HasPrefix(env.from, "MAIL FROM:<noreply@example.com> ") {
+		t.Fatalf("unexpected MAIL command: %q", env.from)
+	}
+	if env.to != "RCPT TO:<anna@example.com>" {
+		t.Fatalf("unexpected RCPT command: %q", env.to)
+	}
+	if !strings.Contains(string(env.data), "Subject: =?utf-8?q?") {
+		t.Fatalf("expected an encoded subject, got %q", env.data)
+	}
+}
@@ -1,16 +1,16 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/nnc/finance-tracker/server/internal/service"
 )
 
-// AuthMiddleware validates JWT tokens from the Authorization header.
-// On success, it sets "user_id" in the Gin context from the token's Subject claim.
+// AuthMiddleware validates JWT access tokens from the Authorization header.
+// Refresh tokens are rejected. On success, it sets "user_id" in the Gin
+// context from the token's Subject claim.
 func AuthMiddleware(jwtSecret []byte) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -21,24 +21,12 @@ func AuthMiddleware(jwtSecret []byte) gin.HandlerFunc {
 
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 
-		token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (any, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := service.ParseToken(tokenStr, jwtSecret, service.TokenTypeAccess)
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
 
-		claims, ok := token.Claims.(*jwt.RegisteredClaims)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			return
-		}
-
 		c.Set("user_id", claims.Subject)
 		c.Next()
 	}
@@ -78,3 +78,21 @@ func TestAuthMiddleware_MissingBearerPrefix(t *testing.T) {
 		t.Fatalf("expected 401, got %d", w.Code)
 	}
 }
+
+func TestAuthMiddleware_RejectsRefreshToken(t *testing.T) {
+	authSvc := service.NewAuthService(testJWTSecret)
+	pair, err := authSvc.GenerateTokenPair("user-123")
+	if err != nil {
+		t.Fatalf("failed to generate token pair: %v", err)
+	}
+
+	r := setupMiddlewareRouter()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+pair.RefreshToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+}
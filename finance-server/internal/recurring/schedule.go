@@ -0,0 +1,98 @@
+// Package recurring computes occurrence dates for recurring expense templates.
+package recurring
+
+import "time"
+
+// Supported template frequencies.
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// ValidFrequency reports whether frequency is one of the supported values.
+func ValidFrequency(frequency string) bool {
+	switch frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+		return true
+	}
+	return false
+}
+
+// Occurrence returns the n-th (zero-based) occurrence of a schedule beginning at start.
+// Monthly and yearly schedules are anchored to the start date's day of month and
+// clamp to the last day of shorter months, so a template starting on Jan 31 falls
+// on Feb 28 and then Mar 31 rather than drifting.
+func Occurrence(start time.Time, frequency string, n int) time.Time {
+	start = truncateDay(start)
+
+	switch frequency {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, n)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case FrequencyMonthly:
+		return addMonthsClamped(start, n)
+	case FrequencyYearly:
+		return addMonthsClamped(start, 12*n)
+	}
+	return start
+}
+
+// NextOnOrAfter returns the first occurrence of the schedule on or after t.
+func NextOnOrAfter(start time.Time, frequency string, t time.Time) time.Time {
+	start = truncateDay(start)
+	t = truncateDay(t)
+	if !t.After(start) {
+		return start
+	}
+
+	var n int
+	switch frequency {
+	case FrequencyDaily:
+		n = daysBetween(start, t)
+	case FrequencyWeekly:
+		n = (daysBetween(start, t) + 6) / 7
+	case FrequencyMonthly:
+		n = monthsBetween(start, t)
+	case FrequencyYearly:
+		n = monthsBetween(start, t) / 12
+	default:
+		return start
+	}
+
+	occ := Occurrence(start, frequency, n)
+	for occ.Before(t) {
+		n++
+		occ = Occurrence(start, frequency, n)
+	}
+	return occ
+}
+
+// Next returns the first occurrence of the schedule strictly after t.
+func Next(start time.Time, frequency string, t time.Time) time.Time {
+	return NextOnOrAfter(start, frequency, truncateDay(t).AddDate(0, 0, 1))
+}
+
+func addMonthsClamped(start time.Time, months int) time.Time {
+	firstOfMonth := time.Date(start.Year(), start.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := start.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,84 @@
+package recurring
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestOccurrence_MonthlyClampsToMonthEnd(t *testing.T) {
+	start := date("2026-01-31")
+	want := []string{"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30"}
+
+	for n, w := range want {
+		got := Occurrence(start, FrequencyMonthly, n).Format("2006-01-02")
+		if got != w {
+			t.Fatalf("occurrence %d: expected %s, got %s", n, w, got)
+		}
+	}
+}
+
+func TestOccurrence_YearlyLeapDay(t *testing.T) {
+	start := date("2028-02-29")
+
+	if got := Occurrence(start, FrequencyYearly, 1).Format("2006-01-02"); got != "2029-02-28" {
+		t.Fatalf("expected 2029-02-28, got %s", got)
+	}
+	if got := Occurrence(start, FrequencyYearly, 4).Format("2006-01-02"); got != "2032-02-29" {
+		t.Fatalf("expected 2032-02-29, got %s", got)
+	}
+}
+
+func TestNextOnOrAfter(t *testing.T) {
+	tests := []struct {
+		name      string
+		start     string
+		frequency string
+		t         string
+		want      string
+	}{
+		{"before start", "2026-03-10", FrequencyMonthly, "2026-01-01", "2026-03-10"},
+		{"on occurrence", "2026-03-10", FrequencyWeekly, "2026-03-17", "2026-03-17"},
+		{"between weekly occurrences", "2026-03-10", FrequencyWeekly, "2026-03-18", "2026-03-24"},
+		{"daily", "2026-03-10", FrequencyDaily, "2026-04-02", "2026-04-02"},
+		{"monthly later day in month", "2026-01-15", FrequencyMonthly, "2026-03-20", "2026-04-15"},
+		{"monthly anchored after clamp", "2026-01-31", FrequencyMonthly, "2026-03-01", "2026-03-31"},
+		{"yearly", "2024-06-01", FrequencyYearly, "2026-06-02", "2027-06-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextOnOrAfter(date(tt.start), tt.frequency, date(tt.t)).Format("2006-01-02")
+			if got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNext_StrictlyAfter(t *testing.T) {
+	start := date("2026-01-31")
+
+	got := Next(start, FrequencyMonthly, date("2026-02-28")).Format("2006-01-02")
+	if got != "2026-03-31" {
+		t.Fatalf("expected 2026-03-31, got %s", got)
+	}
+}
+
+func TestValidFrequency(t *testing.T) {
+	for _, f := range []string{FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly} {
+		if !ValidFrequency(f) {
+			t.Fatalf("expected %q to be valid", f)
+		}
+	}
+	if ValidFrequency("hourly") {
+		t.Fatal("expected hourly to be invalid")
+	}
+}
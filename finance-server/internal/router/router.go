@@ -5,12 +5,14 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/nnc/finance-tracker/server/internal/handler"
+	"github.com/nnc/finance-tracker/server/internal/mailer"
 	"github.com/nnc/finance-tracker/server/internal/middleware"
 	"github.com/nnc/finance-tracker/server/internal/service"
+	"github.com/nnc/finance-tracker/server/internal/storage"
 )
 
 // Setup creates and configures the Gin router with CORS middleware and routes.
-func Setup(db handler.AuthDB, categoryDB handler.CategoryDB, expenseDB handler.ExpenseDB, summaryDB handler.SummaryDB, familyDB handler.FamilyDB, familyViewDB handler.FamilyViewDB, authSvc *service.AuthService) *gin.Engine {
+func Setup(db handler.AuthDB, categoryDB handler.CategoryDB, categoryRuleDB handler.CategoryRuleDB, expenseDB handler.ExpenseDB, incomeDB handler.IncomeDB, recurringDB handler.RecurringDB, summaryDB handler.SummaryDB, budgetDB handler.BudgetDB, familyDB handler.FamilyDB, familyViewDB handler.FamilyViewDB, currencyDB handler.CurrencyDB, splitDB handler.SplitDB, attachmentDB handler.AttachmentDB, activityDB handler.ActivityDB, familyCategoryDB handler.FamilyCategoryDB, store storage.Storage, mail mailer.Mailer, appURL string, authSvc *service.AuthService) *gin.Engine {
 	r := gin.Default()
 
 	r.Use(corsMiddleware())
@@ -33,7 +35,19 @@ func Setup(db handler.AuthDB, categoryDB handler.CategoryDB, expenseDB handler.E
 		protected := api.Group("/")
 		protected.Use(middleware.AuthMiddleware(authSvc.JWTSecret()))
 		{
-			categoryHandler := handler.NewCategoryHandler(categoryDB)
+			currencyHandler := handler.NewCurrencyHandler(currencyDB, familyDB)
+			currencies := protected.Group("currencies")
+			{
+				currencies.GET("", currencyHandler.List)
+			}
+
+			users := protected.Group("users")
+			{
+				users.GET("/me/settings", currencyHandler.GetSettings)
+				users.PUT("/me/settings", currencyHandler.UpdateSettings)
+			}
+
+			categoryHandler := handler.NewCategoryHandler(categoryDB, activityDB)
 			categories := protected.Group("categories")
 			{
 				categories.POST("", categoryHandler.Create)
@@ -44,35 +58,115 @@ func Setup(db handler.AuthDB, categoryDB handler.CategoryDB, expenseDB handler.E
 				categories.DELETE("/:id", categoryHandler.Delete)
 			}
 
-			expenseHandler := handler.NewExpenseHandler(expenseDB)
-			summaryHandler := handler.NewSummaryHandler(summaryDB)
+			categoryRuleHandler := handler.NewCategoryRuleHandler(categoryRuleDB)
+			categoryRules := protected.Group("category-rules")
+			{
+				categoryRules.POST("", categoryRuleHandler.Create)
+				categoryRules.GET("", categoryRuleHandler.List)
+				categoryRules.DELETE("/:id", categoryRuleHandler.Delete)
+			}
+
+			expenseHandler := handler.NewExpenseHandler(expenseDB, categoryRuleDB, activityDB)
+			summaryHandler := handler.NewSummaryHandler(summaryDB, budgetDB)
+			insightsHandler := handler.NewInsightsHandler(summaryDB)
+			importHandler := handler.NewImportHandler(expenseDB, categoryRuleDB)
+			exportHandler := handler.NewExportHandler(expenseDB, familyDB, familyViewDB)
+			attachmentHandler := handler.NewAttachmentHandler(attachmentDB, store)
 			expenses := protected.Group("expenses")
 			{
 				expenses.GET("/summary", summaryHandler.Summary)
+				expenses.GET("/summary/tags", summaryHandler.TagSummary)
+				expenses.GET("/insights", insightsHandler.Insights)
+				expenses.POST("/import/preview", importHandler.Preview)
+				expenses.POST("/import", importHandler.Commit)
+				expenses.GET("/export", exportHandler.Export)
+				expenses.GET("/suggest-category", expenseHandler.SuggestCategory)
 				expenses.POST("", expenseHandler.Create)
 				expenses.GET("", expenseHandler.List)
 				expenses.PUT("/:id", expenseHandler.Update)
 				expenses.DELETE("/:id", expenseHandler.Delete)
+				expenses.POST("/:id/attachments", attachmentHandler.Upload)
+				expenses.GET("/:id/attachments", attachmentHandler.List)
+				expenses.GET("/:id/attachments/:attachmentId", attachmentHandler.Download)
+				expenses.DELETE("/:id/attachments/:attachmentId", attachmentHandler.Delete)
+			}
+
+			incomeHandler := handler.NewIncomeHandler(incomeDB)
+			incomes := protected.Group("incomes")
+			{
+				incomes.POST("", incomeHandler.Create)
+				incomes.GET("", incomeHandler.List)
+				incomes.PUT("/:id", incomeHandler.Update)
+				incomes.DELETE("/:id", incomeHandler.Delete)
+			}
+
+			recurringHandler := handler.NewRecurringHandler(recurringDB)
+			recurringExpenses := protected.Group("recurring-expenses")
+			{
+				recurringExpenses.POST("", recurringHandler.Create)
+				recurringExpenses.GET("", recurringHandler.List)
+				recurringExpenses.PUT("/:id", recurringHandler.Update)
+				recurringExpenses.DELETE("/:id", recurringHandler.Delete)
+			}
+
+			budgetHandler := handler.NewBudgetHandler(budgetDB, familyDB)
+			budgets := protected.Group("budgets")
+			{
+				budgets.GET("", budgetHandler.List)
+				budgets.PUT("", budgetHandler.Set)
+				budgets.DELETE("/:id", budgetHandler.Delete)
 			}
 
-			familyHandler := handler.NewFamilyHandler(familyDB)
+			familyHandler := handler.NewFamilyHandler(familyDB, activityDB, mail, appURL)
 			families := protected.Group("families")
 			{
 				families.POST("", familyHandler.CreateFamily)
-				families.GET("/me", familyHandler.GetMyFamily)
-				families.DELETE("/me", familyHandler.DeleteMyFamily)
-				families.DELETE("/me/members/:userId", familyHandler.RemoveMember)
-				families.POST("/me/leave", familyHandler.LeaveFamily)
-				families.POST("/me/invitations", familyHandler.CreateInvitation)
-				families.DELETE("/me/invitations/:id", familyHandler.RevokeInvitation)
-
-				familyViewHandler := handler.NewFamilyViewHandler(familyDB, familyViewDB)
-				families.GET("/me/expenses", familyViewHandler.FamilyFeed)
-				families.GET("/me/summary", familyViewHandler.FamilySummary)
+				families.GET("", familyHandler.ListFamilies)
+				families.PUT("/active", familyHandler.SetActiveFamily)
+				families.GET("/:familyId", familyHandler.GetMyFamily)
+				families.DELETE("/:familyId", familyHandler.DeleteMyFamily)
+				families.PUT("/:familyId/settings", currencyHandler.UpdateFamilySettings)
+				families.DELETE("/:familyId/members/:userId", familyHandler.RemoveMember)
+				families.POST("/:familyId/members/:userId/promote", familyHandler.PromoteMember)
+				families.POST("/:familyId/members/:userId/demote", familyHandler.DemoteMember)
+				families.POST("/:familyId/transfer-ownership", familyHandler.TransferOwnership)
+				families.POST("/:familyId/leave", familyHandler.LeaveFamily)
+				families.POST("/:familyId/invitations", familyHandler.CreateInvitation)
+				families.DELETE("/:familyId/invitations/:id", familyHandler.RevokeInvitation)
+
+				familyCategoryHandler := handler.NewFamilyCategoryHandler(familyCategoryDB, familyDB, activityDB)
+				families.GET("/:familyId/categories", familyCategoryHandler.List)
+				families.POST("/:familyId/categories", familyCategoryHandler.Create)
+				families.PUT("/:familyId/categories/:id", familyCategoryHandler.Update)
+				families.DELETE("/:familyId/categories/:id", familyCategoryHandler.Delete)
+				families.GET("/:familyId/category-mappings", familyCategoryHandler.ListMappings)
+				families.PUT("/:familyId/category-mappings/:categoryId", familyCategoryHandler.SetMapping)
+				families.DELETE("/:familyId/category-mappings/:categoryId", familyCategoryHandler.DeleteMapping)
+
+				activityHandler := handler.NewActivityHandler(activityDB, familyDB)
+				families.GET("/:familyId/activity", activityHandler.List)
+
+				familyViewHandler := handler.NewFamilyViewHandler(familyDB, familyViewDB, budgetDB)
+				families.GET("/:familyId/expenses", familyViewHandler.FamilyFeed)
+				families.GET("/:familyId/expenses/export", exportHandler.FamilyExport)
+				families.GET("/:familyId/summary", familyViewHandler.FamilySummary)
+
+				splitHandler := handler.NewSplitHandler(splitDB, familyDB)
+				families.GET("/:familyId/expenses/:id/split", splitHandler.GetSplit)
+				families.PUT("/:familyId/expenses/:id/split", splitHandler.SetSplit)
+				families.DELETE("/:familyId/expenses/:id/split", splitHandler.DeleteSplit)
+				families.GET("/:familyId/balances", splitHandler.Balances)
+				families.GET("/:familyId/settlements", splitHandler.ListSettlements)
+				families.POST("/:familyId/settlements", splitHandler.CreateSettlement)
+
+				families.GET("/:familyId/budgets", budgetHandler.ListFamily)
+				families.PUT("/:familyId/budgets", budgetHandler.SetFamily)
+				families.DELETE("/:familyId/budgets/:id", budgetHandler.DeleteFamily)
 			}
 
 			invitations := protected.Group("invitations")
 			{
+				invitations.GET("", familyHandler.ListInvitations)
 				invitations.GET("/:token", familyHandler.GetInvitationInfo)
 				invitations.POST("/accept", familyHandler.AcceptInvitation)
 			}
@@ -82,11 +176,29 @@ func Setup(db handler.AuthDB, categoryDB handler.CategoryDB, expenseDB handler.E
 	return r
 }
 
+// SetupOperator creates the router for operator-only endpoints. It has no
+// authentication and must only be served on an address that is not exposed
+// publicly. Exchange rates are set here because they are shared by all
+// users: a rate change alters everyone's converted totals.
+func SetupOperator(currencyDB handler.CurrencyDB) *gin.Engine {
+	r := gin.Default()
+
+	// SetRate does not use the family database.
+	currencyHandler := handler.NewCurrencyHandler(currencyDB, nil)
+	api := r.Group("/api/v1")
+	{
+		api.PUT("/currencies/:code/rates", currencyHandler.SetRate)
+	}
+
+	return r
+}
+
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", handler.HeaderNextCursor+", "+handler.HeaderTotalCount+", "+handler.HeaderTotalCents)
 		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(http.StatusNoContent)
 			return
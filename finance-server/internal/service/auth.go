@@ -17,6 +17,29 @@ type TokenPair struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// Token types carried in the typ claim.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// Audiences of access and refresh tokens. Access tokens are presented to
+// the API, refresh tokens only to the token refresh endpoint.
+const (
+	AccessAudience  = "finance-tracker-api"
+	RefreshAudience = "finance-tracker-refresh"
+)
+
+// ErrWrongTokenType is returned when a token of one type is presented where
+// another is expected.
+var ErrWrongTokenType = errors.New("wrong token type")
+
+// Claims are the JWT claims of access and refresh tokens.
+type Claims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
 // AuthService handles password hashing and JWT token operations.
 type AuthService struct {
 	jwtSecret []byte
@@ -63,10 +86,14 @@ func (s *AuthService) GenerateTokenPair(userID string) (*TokenPair, error) {
 	now := time.Now()
 
 	// Access token: 15 minutes
-	accessClaims := jwt.RegisteredClaims{
-		Subject:   userID,
-		ExpiresAt: jwt.NewNumericDate(now.Add(15 * time.Minute)),
-		IssuedAt:  jwt.NewNumericDate(now),
+	accessClaims := Claims{
+		Type: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{AccessAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(15 * time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
 	accessStr, err := accessToken.SignedString(s.jwtSecret)
@@ -75,11 +102,15 @@ func (s *AuthService) GenerateTokenPair(userID string) (*TokenPair, error) {
 	}
 
 	// Refresh token: 30 days with jti for revocation tracking
-	refreshClaims := jwt.RegisteredClaims{
-		ID:        uuid.New().String(),
-		Subject:   userID,
-		ExpiresAt: jwt.NewNumericDate(now.Add(30 * 24 * time.Hour)),
-		IssuedAt:  jwt.NewNumericDate(now),
+	refreshClaims := Claims{
+		Type: TokenTypeRefresh,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{RefreshAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(30 * 24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
 	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
 	refreshStr, err := refreshToken.SignedString(s.jwtSecret)
@@ -91,22 +122,44 @@ func (s *AuthService) GenerateTokenPair(userID string) (*TokenPair, error) {
 }
 
 // ValidateAccessToken parses and validates a JWT access token string.
-// Returns the registered claims if valid.
-func (s *AuthService) ValidateAccessToken(tokenStr string) (*jwt.RegisteredClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(t *jwt.Token) (any, error) {
+// Returns the claims if valid.
+func (s *AuthService) ValidateAccessToken(tokenStr string) (*Claims, error) {
+	return ParseToken(tokenStr, s.jwtSecret, TokenTypeAccess)
+}
+
+// ValidateRefreshToken parses and validates a JWT refresh token string.
+// Returns the claims if valid.
+func (s *AuthService) ValidateRefreshToken(tokenStr string) (*Claims, error) {
+	return ParseToken(tokenStr, s.jwtSecret, TokenTypeRefresh)
+}
+
+// ParseToken parses and validates a JWT signed with secret, and checks that
+// it is a token of the given type meant for that type's audience. An access
+// token is thereby never accepted where a refresh token is expected, nor the
+// other way round.
+func ParseToken(tokenStr string, secret []byte, tokenType string) (*Claims, error) {
+	audience := AccessAudience
+	if tokenType == TokenTypeRefresh {
+		audience = RefreshAudience
+	}
+
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (any, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return s.jwtSecret, nil
-	})
+		return secret, nil
+	}, jwt.WithAudience(audience))
 	if err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
 		return nil, errors.New("invalid token claims")
 	}
+	if claims.Type != tokenType {
+		return nil, ErrWrongTokenType
+	}
 
 	return claims, nil
 }
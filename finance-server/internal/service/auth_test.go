@@ -103,14 +103,18 @@ func TestRefreshTokenClaims(t *testing.T) {
 	}
 
 	// Parse refresh token to check claims
-	token, err := jwt.ParseWithClaims(pair.RefreshToken, &jwt.RegisteredClaims{}, func(t *jwt.Token) (any, error) {
+	token, err := jwt.ParseWithClaims(pair.RefreshToken, &Claims{}, func(t *jwt.Token) (any, error) {
 		return []byte(testJWTSecret), nil
 	})
 	if err != nil {
 		t.Fatalf("Failed to parse refresh token: %v", err)
 	}
 
-	claims := token.Claims.(*jwt.RegisteredClaims)
+	claims := token.Claims.(*Claims)
+
+	if claims.Type != TokenTypeRefresh {
+		t.Fatalf("Expected typ=%s, got %s", TokenTypeRefresh, claims.Type)
+	}
 
 	if claims.Subject != "user-uuid-123" {
 		t.Fatalf("Expected sub=user-uuid-123, got %s", claims.Subject)
@@ -172,3 +176,62 @@ func TestValidatePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTokens_RejectWrongType(t *testing.T) {
+	svc := NewAuthService(testJWTSecret)
+
+	pair, err := svc.GenerateTokenPair("user-uuid-123")
+	if err != nil {
+		t.Fatalf("GenerateTokenPair returned error: %v", err)
+	}
+
+	if _, err := svc.ValidateRefreshToken(pair.RefreshToken); err != nil {
+		t.Fatalf("ValidateRefreshToken returned error for a refresh token: %v", err)
+	}
+	if _, err := svc.ValidateAccessToken(pair.RefreshToken); err == nil {
+		t.Fatal("ValidateAccessToken should reject a refresh token")
+	}
+	if _, err := svc.ValidateRefreshToken(pair.AccessToken); err == nil {
+		t.Fatal("ValidateRefreshToken should reject an access token")
+	}
+}
+
+func TestValidateAccessToken_RejectsUntypedToken(t *testing.T) {
+	svc := NewAuthService(testJWTSecret)
+
+	// A token without typ and audience, as issued before tokens were typed.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "user-uuid-123",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+	})
+	tokenStr, err := token.SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	if _, err := svc.ValidateAccessToken(tokenStr); err == nil {
+		t.Fatal("ValidateAccessToken should reject a token without a type")
+	}
+}
+
+func TestValidateAccessToken_RejectsWrongAudience(t *testing.T) {
+	svc := NewAuthService(testJWTSecret)
+
+	// Typed as an access token, but meant for the refresh endpoint.
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Type: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-uuid-123",
+			Audience:  jwt.ClaimStrings{RefreshAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+		},
+	})
+	tokenStr, err := token.SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	if _, err := svc.ValidateAccessToken(tokenStr); err == nil {
+		t.Fatal("ValidateAccessToken should reject a token for another audience")
+	}
+}
@@ -0,0 +1,36 @@
+// Package storage keeps binary blobs such as receipt photos outside the
+// database. Callers store the returned key and use it to read or delete the
+// blob later.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned by Open when no blob exists for the key.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage is a pluggable blob store.
+type Storage interface {
+	// Upload stores size bytes read from r and returns the generated key.
+	Upload(ctx context.Context, r io.Reader, contentType string, size int64) (string, error)
+	// Open returns the blob stored under key. The caller closes it.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// newKey returns a fresh random key. Keys are UUIDs so they are safe to use
+// as file names and object paths without escaping.
+func newKey() string {
+	return uuid.NewString()
+}
+
+// validKey reports whether key could have been produced by newKey.
+func validKey(key string) bool {
+	return uuid.Validate(key) == nil
+}
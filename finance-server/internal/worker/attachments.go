@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+	"github.com/nnc/finance-tracker/server/internal/storage"
+)
+
+// attachmentBatchSize is the number of queued blobs removed per query.
+const attachmentBatchSize = 100
+
+// AttachmentWorker removes the blobs of deleted expense attachments.
+//
+// Deleting an attachment row, whether directly or through the cascade from
+// its expense, queues the storage key in attachment_deletions. The worker
+// deletes the blob first and the queue entry second, so a crash in between
+// only repeats a delete, which the stores treat as a no-op.
+type AttachmentWorker struct {
+	queries  *sqlc.Queries
+	store    storage.Storage
+	interval time.Duration
+}
+
+// NewAttachmentWorker creates an AttachmentWorker that runs every interval.
+func NewAttachmentWorker(pool *pgxpool.Pool, store storage.Storage, interval time.Duration) *AttachmentWorker {
+	return &AttachmentWorker{
+		queries:  sqlc.New(pool),
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start runs the worker in a goroutine until ctx is cancelled.
+func (w *AttachmentWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if removed, err := w.RunOnce(ctx); err != nil {
+				log.Printf("Attachment cleanup: %v", err)
+			} else if removed > 0 {
+				log.Printf("Attachment cleanup: removed %d blobs", removed)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce drains the deletion queue and returns the number of blobs removed.
+// It stops at the first store error so an unreachable store is retried on
+// the next tick rather than in a tight loop.
+func (w *AttachmentWorker) RunOnce(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		keys, err := w.queries.GetAttachmentDeletions(ctx, attachmentBatchSize)
+		if err != nil {
+			return removed, err
+		}
+
+		for _, key := range keys {
+			if err := w.store.Delete(ctx, key); err != nil {
+				return removed, err
+			}
+			if err := w.queries.DeleteAttachmentDeletion(ctx, key); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		if len(keys) < attachmentBatchSize {
+			return removed, nil
+		}
+	}
+}
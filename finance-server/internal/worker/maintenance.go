@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+)
+
+const (
+	// maintenanceLockKey is the PostgreSQL advisory lock held while a
+	// maintenance pass runs, so only one replica does the work.
+	maintenanceLockKey int64 = 0x66696e616e6365 // "finance"
+	// refreshTokenBatchSize is the number of refresh tokens deleted per query.
+	refreshTokenBatchSize = 1000
+)
+
+// maintenanceStats is published at /debug/vars under "maintenance".
+var maintenanceStats = expvar.NewMap("maintenance")
+
+// MaintenanceResult counts the rows changed by one maintenance pass.
+type MaintenanceResult struct {
+	InvitationsExpired   int64
+	RefreshTokensDeleted int64
+}
+
+// MaintenanceWorker marks expired family invitations and deletes refresh
+// tokens that expired or were revoked more than the retention window ago.
+//
+// Each pass holds a session-level advisory lock on a dedicated connection;
+// replicas that fail to take it skip the pass rather than wait.
+type MaintenanceWorker struct {
+	pool      *pgxpool.Pool
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewMaintenanceWorker creates a MaintenanceWorker that runs every interval
+// and keeps stale refresh tokens for retention before deleting them.
+func NewMaintenanceWorker(pool *pgxpool.Pool, retention, interval time.Duration) *MaintenanceWorker {
+	return &MaintenanceWorker{
+		pool:      pool,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Start runs the worker in a goroutine until ctx is cancelled.
+func (w *MaintenanceWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			res, ran, err := w.RunOnce(ctx, time.Now())
+			switch {
+			case err != nil:
+				maintenanceStats.Add("errors", 1)
+				log.Printf("Maintenance: %v", err)
+			case !ran:
+				maintenanceStats.Add("skipped", 1)
+			default:
+				maintenanceStats.Add("runs", 1)
+				maintenanceStats.Add("invitations_expired", res.InvitationsExpired)
+				maintenanceStats.Add("refresh_tokens_deleted", res.RefreshTokensDeleted)
+				if res.InvitationsExpired > 0 || res.RefreshTokensDeleted > 0 {
+					log.Printf("Maintenance: expired %d invitations, deleted %d refresh tokens",
+						res.InvitationsExpired, res.RefreshTokensDeleted)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce performs one maintenance pass. It reports ran=false without doing
+// anything when another instance holds the maintenance lock.
+func (w *MaintenanceWorker) RunOnce(ctx context.Context, now time.Time) (res MaintenanceResult, ran bool, err error) {
+	// Session-level advisory locks belong to a connection, so the lock, the
+	// work and the unlock all go through the same one.
+	conn, err := w.pool.Acquire(ctx)
+	if err != nil {
+		return res, false, err
+	}
+	defer conn.Release()
+
+	q := sqlc.New(conn)
+	locked, err := q.TryAdvisoryLock(ctx, maintenanceLockKey)
+	if err != nil {
+		return res, false, fmt.Errorf("lock: %w", err)
+	}
+	if !locked {
+		return res, false, nil
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even after ctx ends;
+		// otherwise drop the connection, which releases it too.
+		if _, err := q.AdvisoryUnlock(context.Background(), maintenanceLockKey); err != nil {
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	res.InvitationsExpired, err = q.ExpireInvitations(ctx)
+	if err != nil {
+		return res, true, fmt.Errorf("expire invitations: %w", err)
+	}
+
+	cutoff := pgtype.Timestamptz{Time: now.Add(-w.retention), Valid: true}
+	for {
+		n, err := q.DeleteStaleRefreshTokens(ctx, sqlc.DeleteStaleRefreshTokensParams{
+			Cutoff:    cutoff,
+			BatchSize: refreshTokenBatchSize,
+		})
+		res.RefreshTokensDeleted += n
+		if err != nil {
+			return res, true, fmt.Errorf("delete refresh tokens: %w", err)
+		}
+		if n < refreshTokenBatchSize {
+			return res, true, nil
+		}
+	}
+}
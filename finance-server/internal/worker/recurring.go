@@ -0,0 +1,163 @@
+// Package worker runs background jobs alongside the API server.
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nnc/finance-tracker/server/internal/db/sqlc"
+	"github.com/nnc/finance-tracker/server/internal/recurring"
+)
+
+const (
+	// recurringBatchSize is the number of due templates locked per transaction.
+	recurringBatchSize = 100
+	// maxOccurrencesPerPass bounds how far a single template catches up in one
+	// transaction; anything left over is picked up by the next batch.
+	maxOccurrencesPerPass = 366
+)
+
+// RecurringWorker materializes due recurring expense templates into expenses.
+//
+// Each occurrence is inserted with ON CONFLICT DO NOTHING against the
+// (recurring_id, expense_date) unique constraint, and the template's next_date
+// is advanced in the same transaction, so restarts and concurrent instances
+// never double-post.
+type RecurringWorker struct {
+	pool     *pgxpool.Pool
+	queries  *sqlc.Queries
+	interval time.Duration
+}
+
+// NewRecurringWorker creates a RecurringWorker that runs every interval.
+func NewRecurringWorker(pool *pgxpool.Pool, interval time.Duration) *RecurringWorker {
+	return &RecurringWorker{
+		pool:     pool,
+		queries:  sqlc.New(pool),
+		interval: interval,
+	}
+}
+
+// Start runs the worker in a goroutine until ctx is cancelled. The first pass
+// runs immediately so occurrences missed while the server was down are posted
+// on startup.
+func (w *RecurringWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if created, err := w.RunOnce(ctx, time.Now()); err != nil {
+				log.Printf("Recurring expenses: %v", err)
+			} else if created > 0 {
+				log.Printf("Recurring expenses: created %d expenses", created)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce materializes every occurrence due on or before today and returns
+// the number of expenses created.
+func (w *RecurringWorker) RunOnce(ctx context.Context, today time.Time) (int, error) {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	total := 0
+	for {
+		created, processed, failed, err := w.runBatch(ctx, today)
+		total += created
+		if err != nil {
+			return total, err
+		}
+		// Stop on failures so a broken template is not retried in a tight loop;
+		// the next tick will try again.
+		if processed < recurringBatchSize || failed > 0 {
+			return total, nil
+		}
+	}
+}
+
+func (w *RecurringWorker) runBatch(ctx context.Context, today time.Time) (created, processed, failed int, err error) {
+	tx, err := w.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := w.queries.WithTx(tx)
+	due, err := qtx.GetDueRecurringExpenses(ctx, sqlc.GetDueRecurringExpensesParams{
+		NextDate: pgtype.Date{Time: today, Valid: true},
+		Limit:    recurringBatchSize,
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	for _, tmpl := range due {
+		// A savepoint per template keeps one bad row from aborting the batch.
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return created, len(due), failed, err
+		}
+
+		n, err := materialize(ctx, w.queries.WithTx(sp), tmpl, today)
+		if err != nil {
+			sp.Rollback(ctx)
+			failed++
+			log.Printf("Recurring expenses: template %s: %v", tmpl.ID, err)
+			continue
+		}
+		if err := sp.Commit(ctx); err != nil {
+			return created, len(due), failed, err
+		}
+		created += n
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, len(due), failed, err
+	}
+	return created, len(due), failed, nil
+}
+
+// materialize posts the template's occurrences from next_date through today
+// (bounded by end_date) and advances next_date past them.
+func materialize(ctx context.Context, q *sqlc.Queries, tmpl sqlc.RecurringExpense, today time.Time) (int, error) {
+	created := 0
+	next := tmpl.NextDate.Time
+
+	for i := 0; i < maxOccurrencesPerPass && !next.After(today); i++ {
+		if tmpl.EndDate.Valid && next.After(tmpl.EndDate.Time) {
+			break
+		}
+
+		rows, err := q.CreateRecurringOccurrence(ctx, sqlc.CreateRecurringOccurrenceParams{
+			UserID:      tmpl.UserID,
+			CategoryID:  tmpl.CategoryID,
+			AmountCents: tmpl.AmountCents,
+			Note:        tmpl.Note,
+			ExpenseDate: pgtype.Date{Time: next, Valid: true},
+			RecurringID: tmpl.ID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		created += int(rows)
+		next = recurring.Next(tmpl.StartDate.Time, tmpl.Frequency, next)
+	}
+
+	err := q.SetRecurringExpenseNextDate(ctx, sqlc.SetRecurringExpenseNextDateParams{
+		ID:       tmpl.ID,
+		NextDate: pgtype.Date{Time: next, Valid: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}